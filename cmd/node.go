@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"net"
+	"net/http"
+	"net/http/pprof"
 	"path/filepath"
 
 	"github.com/deso-smart/deso-backend/v3/config"
@@ -17,6 +20,9 @@ type Node struct {
 	GlobalState *badger.DB
 	Config      *config.Config
 	CoreNode    *coreCmd.Node
+
+	// Only set if --enable-pprof is set.
+	PprofServer *http.Server
 }
 
 func NewNode(config *config.Config, coreNode *coreCmd.Node) *Node {
@@ -30,9 +36,9 @@ func NewNode(config *config.Config, coreNode *coreCmd.Node) *Node {
 func (node *Node) Start() {
 	var err error
 
-	// For the global state, we use a local db unless a remote node is set in
-	// which case all global state set/fetch calls will proxy to the remote.
-	if node.Config.GlobalStateRemoteNode == "" {
+	// For the global state, we use a local db unless a remote node or redis server is set
+	// in which case all global state set/fetch calls will go there instead.
+	if node.Config.GlobalStateRemoteNode == "" && node.Config.GlobalStateRedisAddr == "" {
 		globalStateDir := filepath.Join(lib.GetBadgerDbPath(node.CoreNode.Config.DataDirectory), "global_state")
 		globalStateOpts := badger.DefaultOptions(globalStateDir)
 		globalStateOpts.MemTableSize = 1024 << 20
@@ -76,11 +82,52 @@ func (node *Node) Start() {
 	}
 
 	go node.APIServer.Start()
+
+	if node.Config.EnablePprof {
+		node.startPprofServer()
+	}
+}
+
+// startPprofServer serves the pprof endpoints on their own listener so that they are never reachable through the
+// public API port.
+func (node *Node) startPprofServer() {
+	host, _, err := net.SplitHostPort(node.Config.PprofAddress)
+	if err != nil {
+		glog.Fatalf("Invalid --pprof-address %s: %v", node.Config.PprofAddress, err)
+	}
+	ip := net.ParseIP(host)
+	if host == "" || (ip != nil && ip.IsUnspecified()) {
+		glog.Fatalf("--pprof-address %s would serve pprof on every interface; use a loopback or "+
+			"admin-only IP instead", node.Config.PprofAddress)
+	}
+	if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		glog.Warningf("Serving pprof on non-loopback address %s; make sure it isn't publicly reachable",
+			node.Config.PprofAddress)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	node.PprofServer = &http.Server{Addr: node.Config.PprofAddress, Handler: mux}
+
+	go func() {
+		glog.Infof("Serving pprof on %s", node.Config.PprofAddress)
+		if err := node.PprofServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			glog.Errorf("Problem serving pprof: %v", err)
+		}
+	}()
 }
 
 func (node *Node) Stop() {
 	node.APIServer.Stop()
 
+	if node.PprofServer != nil {
+		_ = node.PprofServer.Close()
+	}
+
 	if node.GlobalState != nil {
 		_ = node.GlobalState.Close()
 	}
@@ -50,6 +50,11 @@ func init() {
 	runCmd.PersistentFlags().Uint64("api-port", 0,
 		"When set, determines the port on which this node will listen for json "+
 			"requests. If unset, the port will default to what is present in the DeSoParams set.")
+	runCmd.PersistentFlags().String("tls-cert-file", "",
+		"Path to a PEM-encoded TLS certificate. When set along with --tls-key-file, the API is served over "+
+			"HTTPS on api-port instead of plaintext HTTP.")
+	runCmd.PersistentFlags().String("tls-key-file", "",
+		"Path to the PEM-encoded private key for --tls-cert-file.")
 
 	// Onboarding
 	runCmd.PersistentFlags().String("starter-deso-seed", "",
@@ -89,6 +94,25 @@ func init() {
 	runCmd.PersistentFlags().String("global-state-remote-secret", "",
 		"When a remote node is being used to set/fetch global state, a secret "+
 			"is also required to restrict access.")
+	runCmd.PersistentFlags().String("global-state-redis-addr", "",
+		"The HOST:PORT of a redis server to store global state in. This lets several nodes share "+
+			"global state without designating one of them as the remote node. Can't be combined "+
+			"with --global-state-remote-node.")
+	runCmd.PersistentFlags().String("global-state-redis-username", "",
+		"The username to AUTH with on the global state redis server. Leave this empty to use the "+
+			"default user.")
+	runCmd.PersistentFlags().String("global-state-redis-password", "",
+		"The password to AUTH with on the global state redis server. AUTH is skipped when this is empty.")
+	runCmd.PersistentFlags().Int("global-state-redis-db", 0,
+		"The database on the global state redis server to store global state in.")
+	runCmd.PersistentFlags().Bool("global-state-redis-tls", false,
+		"Connect to the global state redis server over TLS.")
+	runCmd.PersistentFlags().Uint64("idempotency-key-ttl-seconds", 86400,
+		"How long the transaction constructed for a request with an Idempotency-Key header is saved in global "+
+			"state. Repeating the request with the same key and public key during this time returns the saved "+
+			"transaction instead of constructing a new one. Public routes also require a JWT for the "+
+			"transactor in the request body. This only dedupes construction, not inclusion on chain. Set to 0 "+
+			"to ignore the header.")
 
 	// Hot Feed
 	runCmd.PersistentFlags().Bool("run-hot-feed-routine", false,
@@ -97,6 +121,42 @@ func init() {
 	runCmd.PersistentFlags().Bool("hot-feed-media-required", false,
 		"If set, hot feed excludes posts without media.")
 
+	// Caching
+	runCmd.PersistentFlags().Bool("enable-username-to-pkid-cache", false,
+		"Cache username to PKID lookups in memory. The cache is cleared whenever this node broadcasts a swap "+
+			"identity or update profile transaction, but changes made through other nodes may not be seen until "+
+			"entries expire.")
+	runCmd.PersistentFlags().Uint64("username-to-pkid-cache-ttl-seconds", 30,
+		"How long username to PKID lookups are cached for. Only used if --enable-username-to-pkid-cache is set.")
+	runCmd.PersistentFlags().Uint64("dao-coin-market-stats-cache-ttl-seconds", 30,
+		"How long the 24h stats returned by GetDAOCoinMarketStats are cached for. Set to 0 to compute them on "+
+			"every request.")
+	runCmd.PersistentFlags().Uint64("dao-coin-limit-orders-cache-ttl-millis", 2000,
+		"How long the open orders for each side of a DAO coin pair are cached for by GetDAOCoinLimitOrders. The "+
+			"cache is invalidated whenever this node broadcasts an order for the pair, and within a second of "+
+			"an order for the pair relayed by a peer reaching the mempool or a new block being mined. Set to 0 "+
+			"to disable the cache.")
+
+	// Pagination
+	runCmd.PersistentFlags().Uint64("default-page-size", 100,
+		"Page size used by paginated endpoints when the request doesn't specify one.")
+	runCmd.PersistentFlags().Uint64("max-page-size", 1000,
+		"Largest page size paginated endpoints will return. Larger requested page sizes are clamped to this.")
+
+	// Profiling
+	runCmd.PersistentFlags().Bool("enable-pprof", false,
+		"If set, serves the net/http/pprof profiling endpoints on --pprof-address. These are never served on "+
+			"the API port.")
+	runCmd.PersistentFlags().String("pprof-address", "127.0.0.1:6060",
+		"The <IP>:<Port> to serve the pprof endpoints on when --enable-pprof is set. This should be a loopback "+
+			"or other admin-only interface; an address without an IP, which would listen on every interface, "+
+			"is rejected.")
+
+	// Metrics
+	runCmd.PersistentFlags().Bool("enable-metrics", false,
+		"If set, records request count, latency, and status code per route along with global state call counts, "+
+			"and serves them in the Prometheus text format on /metrics.")
+
 	// Web Security
 	runCmd.PersistentFlags().StringSlice("access-control-allow-origins", []string{"*"},
 		"Accepts a comma-separated lists of origin domains that will be allowed as the "+
@@ -108,6 +168,14 @@ func init() {
 		"If set, runs our secure header middleware in development mode, which disables some "+
 			"of the options. The default is true to make it easy to run a node locally. "+
 			"See https://github.com/unrolled/secure for more info. Note that")
+	runCmd.PersistentFlags().Uint64("max-request-body-size-bytes", 10*1e6,
+		"The maximum size of a request body, in bytes, the node will read. Requests over the limit "+
+			"are rejected. Defaults to 10MB. Uploads are allowed more: 20MB for upload-image and "+
+			"256MB for admin/upload-referral-csv, unless this is set higher.")
+	runCmd.PersistentFlags().String("route-max-request-body-size-bytes", "",
+		"A comma-separated list of 'path=bytes' mappings that override max-request-body-size-bytes "+
+			"for individual routes, e.g. \"/api/v0/admin/upload-referral-csv=52428800\". The node "+
+			"fails to start if a mapping can't be parsed.")
 
 	// Analytics + Profiling
 	runCmd.PersistentFlags().String("amplitude-key", "", "Client-side amplitude key for instrumenting user behavior.")
@@ -154,6 +222,19 @@ func init() {
 	runCmd.PersistentFlags().String("jumio-token", "", "Jumio Token")
 	runCmd.PersistentFlags().String("jumio-secret", "", "Jumio Secret Key")
 
+	// Referrals
+	runCmd.PersistentFlags().Bool("enable-referrals", true, "Set to false on nodes that don't run "+
+		"referral campaigns. The referral endpoints, including the admin endpoints for managing referral hashes, "+
+		"then return a 404 without reading or writing global state.")
+	runCmd.PersistentFlags().Uint64("max-referral-csv-rows", 10000, "Maximum number of rows, including the "+
+		"header, accepted when uploading a referral CSV. Set to 0 for no limit.")
+	runCmd.PersistentFlags().Uint64("max-referral-usd-cents", 100000, "Maximum referrer or referee amount, "+
+		"in USD cents, that admins can set when creating or updating a referral hash. Defaults to $1000.")
+	runCmd.PersistentFlags().Bool("case-insensitive-referral-hashes", false, "If set, referral hashes that "+
+		"don't match exactly are looked up again ignoring case. Base58 is case-sensitive, so two hashes can differ "+
+		"only by case. New hashes avoid this, but for hashes created before the lookup index existed, the first "+
+		"one written wins.")
+
 	// Video Upload
 	runCmd.PersistentFlags().String("cloudflare-stream-token", "", "API Token with Edit access to Cloudflare's stream service")
 	runCmd.PersistentFlags().String("cloudflare-account-id", "", "Cloudflare Account ID")
@@ -161,9 +242,21 @@ func init() {
 	// Global State
 	runCmd.PersistentFlags().Bool("expose-global-state", false, "Expose global state data to all origins")
 	runCmd.PersistentFlags().String("global-state-api-url", "", "URL to use to fetch global state data. Only used if expose-global-state is false. If not provided, use own global state.")
+	runCmd.PersistentFlags().Bool("expose-global-state-api-url", false, "Include global-state-api-url in the "+
+		"response of the get-global-state-mode endpoint")
 
 	// Run Supply Monitoring Routine
 	runCmd.PersistentFlags().Bool("run-supply-monitoring-routine", false, "Run a goroutine to monitor total supply and rich list")
+	runCmd.PersistentFlags().Uint64("supply-alert-top-holders-count", 1,
+		"Number of top holders in the rich list whose combined balance is checked against "+
+			"--supply-alert-top-holders-percentage.")
+	runCmd.PersistentFlags().Float64("supply-alert-top-holders-percentage", 0,
+		"If set, the supply monitoring routine sends an alert when the top holders own more than this "+
+			"percentage (0-100) of the total supply. Only active if --run-supply-monitoring-routine is set.")
+	runCmd.PersistentFlags().String("supply-alert-webhook-url", "",
+		"URL the supply monitoring routine POSTs a JSON alert to when a supply threshold is crossed.")
+	runCmd.PersistentFlags().Uint64("rich-list-length", 1000,
+		"Number of top holders the supply monitoring routine keeps in the rich list. Capped at 10000.")
 
 	// Tag transaction with node source
 	runCmd.PersistentFlags().Uint64("node-source", 0, "Node ID to tag transaction with. Maps to ../core/lib/nodes.go")
@@ -15,6 +15,9 @@ import (
 type Config struct {
 	// Core
 	APIPort uint16
+	// If both are set, the API is served over HTTPS. Plaintext HTTP is used if neither is set.
+	TLSCertFile string
+	TLSKeyFile  string
 
 	// Onboarding
 	StarterDESOSeed         string
@@ -30,17 +33,51 @@ type Config struct {
 	// Global State
 	GlobalStateRemoteNode   string
 	GlobalStateRemoteSecret string
+	// If set, global state is stored in the redis server at this address so that it can be shared by several nodes.
+	GlobalStateRedisAddr     string
+	GlobalStateRedisUsername string
+	GlobalStateRedisPassword string
+	GlobalStateRedisDB       int
+	GlobalStateRedisTLS      bool
+	// How long a response to a request with an Idempotency-Key header is saved for. Zero ignores the header.
+	IdempotencyKeyTTLSeconds uint64
 
 	// Hot Feed
 	RunHotFeedRoutine    bool
 	HotFeedMediaRequired bool
 
+	// Caching
+	EnableUsernameToPKIDCache     bool
+	UsernameToPKIDCacheTTLSeconds uint64
+	// How long GetDAOCoinMarketStats results are cached for. Zero disables the cache.
+	DAOCoinMarketStatsCacheTTLSeconds uint64
+	// How long the open orders for each side of a DAO coin pair are cached for. Zero disables the cache.
+	DAOCoinLimitOrdersCacheTTLMillis uint64
+
+	// Pagination
+	// Page size used by paginated endpoints when the request doesn't specify one.
+	DefaultPageSize uint64
+	// Largest page size paginated endpoints will return. Larger requested page sizes are clamped to this.
+	MaxPageSize uint64
+
+	// Profiling
+	EnablePprof  bool
+	PprofAddress string
+
+	// Metrics
+	// If true, per-route request metrics and global state call counts are served in the Prometheus format on /metrics.
+	EnableMetrics bool
+
 	// Web Security
 	AccessControlAllowOrigins []string
 	SecureHeaderDevelopment   bool
 	SecureHeaderAllowHosts    []string
 	AdminPublicKeys           []string
 	SuperAdminPublicKeys      []string
+	// Maximum request body size, in bytes, read by any route.
+	MaxRequestBodySizeBytes uint64
+	// Per-route overrides of MaxRequestBodySizeBytes keyed by route path, e.g. "/api/v0/admin/upload-referral-csv".
+	RouteMaxRequestBodySizeBytes map[string]uint64
 
 	// Analytics
 	AmplitudeKey string
@@ -71,6 +108,16 @@ type Config struct {
 	JumioToken  string
 	JumioSecret string
 
+	// Referrals
+	// If false, every referral endpoint returns a 404 without touching global state.
+	EnableReferrals bool
+	// Maximum number of rows, including the header, accepted by the referral CSV upload. Zero means no limit.
+	MaxReferralCSVRows uint64
+	// Maximum referrer or referee amount, in USD cents, that an admin can set on a referral hash.
+	MaxReferralUSDCents uint64
+	// If true, referral hashes that don't match exactly are looked up again ignoring case.
+	CaseInsensitiveReferralHashes bool
+
 	// Video Upload
 	CloudflareStreamToken string
 	CloudflareAccountId   string
@@ -78,9 +125,20 @@ type Config struct {
 	// Global State
 	ExposeGlobalState bool
 	GlobalStateAPIUrl string
+	// If true, GetGlobalStateMode includes GlobalStateAPIUrl in its response.
+	ExposeGlobalStateAPIUrl bool
 
 	// Supply Monitoring Routine
 	RunSupplyMonitoringRoutine bool
+	// Number of top holders whose combined share of the total supply is checked against
+	// SupplyAlertTopHoldersPercentage.
+	SupplyAlertTopHoldersCount uint64
+	// If nonzero, an alert is sent when the top holders own more than this percentage of the total supply.
+	SupplyAlertTopHoldersPercentage float64
+	// URL the supply monitoring routine POSTs alerts to when a threshold is crossed.
+	SupplyAlertWebhookURL string
+	// Number of top holders kept in the cached rich list.
+	RichListLength uint64
 
 	// ID to tag node source
 	NodeSource uint64
@@ -102,6 +160,8 @@ func LoadConfig(coreConfig *coreCmd.Config) *Config {
 		// TODO: pull this out of core. we shouldn't need core's config here
 		config.APIPort = coreConfig.Params.DefaultJSONPort
 	}
+	config.TLSCertFile = viper.GetString("tls-cert-file")
+	config.TLSKeyFile = viper.GetString("tls-key-file")
 
 	// Onboarding
 	config.StarterDESOSeed = viper.GetString("starter-deso-seed")
@@ -128,17 +188,56 @@ func LoadConfig(coreConfig *coreCmd.Config) *Config {
 	// Global State
 	config.GlobalStateRemoteNode = viper.GetString("global-state-remote-node")
 	config.GlobalStateRemoteSecret = viper.GetString("global-state-remote-secret")
+	config.GlobalStateRedisAddr = viper.GetString("global-state-redis-addr")
+	config.GlobalStateRedisUsername = viper.GetString("global-state-redis-username")
+	config.GlobalStateRedisPassword = viper.GetString("global-state-redis-password")
+	config.GlobalStateRedisDB = viper.GetInt("global-state-redis-db")
+	config.GlobalStateRedisTLS = viper.GetBool("global-state-redis-tls")
+	config.IdempotencyKeyTTLSeconds = viper.GetUint64("idempotency-key-ttl-seconds")
 
 	// Hot Feed
 	config.RunHotFeedRoutine = viper.GetBool("run-hot-feed-routine")
 	config.HotFeedMediaRequired = viper.GetBool("hot-feed-media-required")
 
+	// Caching
+	config.EnableUsernameToPKIDCache = viper.GetBool("enable-username-to-pkid-cache")
+	config.UsernameToPKIDCacheTTLSeconds = viper.GetUint64("username-to-pkid-cache-ttl-seconds")
+	config.DAOCoinMarketStatsCacheTTLSeconds = viper.GetUint64("dao-coin-market-stats-cache-ttl-seconds")
+	config.DAOCoinLimitOrdersCacheTTLMillis = viper.GetUint64("dao-coin-limit-orders-cache-ttl-millis")
+
+	// Pagination
+	config.DefaultPageSize = viper.GetUint64("default-page-size")
+	config.MaxPageSize = viper.GetUint64("max-page-size")
+
+	// Profiling
+	config.EnablePprof = viper.GetBool("enable-pprof")
+	config.PprofAddress = viper.GetString("pprof-address")
+
+	// Metrics
+	config.EnableMetrics = viper.GetBool("enable-metrics")
+
 	// Web Security
 	config.AccessControlAllowOrigins = viper.GetStringSlice("access-control-allow-origins")
 	config.SecureHeaderDevelopment = viper.GetBool("secure-header-development")
 	config.SecureHeaderAllowHosts = viper.GetStringSlice("secure-header-allow-hosts")
 	config.AdminPublicKeys = viper.GetStringSlice("admin-public-keys")
 	config.SuperAdminPublicKeys = viper.GetStringSlice("super-admin-public-keys")
+	config.MaxRequestBodySizeBytes = viper.GetUint64("max-request-body-size-bytes")
+	routeMaxRequestBodySizeBytes := viper.GetString("route-max-request-body-size-bytes")
+	if len(routeMaxRequestBodySizeBytes) > 0 {
+		config.RouteMaxRequestBodySizeBytes = make(map[string]uint64)
+		for _, pair := range strings.Split(routeMaxRequestBodySizeBytes, ",") {
+			entry := strings.Split(strings.TrimSpace(pair), "=")
+			if len(entry) != 2 {
+				panic(fmt.Sprintf("Invalid route-max-request-body-size-bytes mapping: %s", pair))
+			}
+			maxBytes, err := strconv.ParseUint(entry[1], 10, 64)
+			if err != nil || maxBytes == 0 {
+				panic(fmt.Sprintf("Invalid route-max-request-body-size-bytes size for %s: %s", entry[0], entry[1]))
+			}
+			config.RouteMaxRequestBodySizeBytes[entry[0]] = maxBytes
+		}
+	}
 
 	// Analytics
 	config.AmplitudeKey = viper.GetString("amplitude-key")
@@ -176,6 +275,12 @@ func LoadConfig(coreConfig *coreCmd.Config) *Config {
 	config.JumioToken = viper.GetString("jumio-token")
 	config.JumioSecret = viper.GetString("jumio-secret")
 
+	// Referrals
+	config.EnableReferrals = viper.GetBool("enable-referrals")
+	config.MaxReferralCSVRows = viper.GetUint64("max-referral-csv-rows")
+	config.MaxReferralUSDCents = viper.GetUint64("max-referral-usd-cents")
+	config.CaseInsensitiveReferralHashes = viper.GetBool("case-insensitive-referral-hashes")
+
 	// Video Upload
 	config.CloudflareStreamToken = viper.GetString("cloudflare-stream-token")
 	config.CloudflareAccountId = viper.GetString("cloudflare-account-id")
@@ -183,9 +288,14 @@ func LoadConfig(coreConfig *coreCmd.Config) *Config {
 	// Global State
 	config.ExposeGlobalState = viper.GetBool("expose-global-state")
 	config.GlobalStateAPIUrl = viper.GetString("global-state-api-url")
+	config.ExposeGlobalStateAPIUrl = viper.GetBool("expose-global-state-api-url")
 
 	// Supply Monitoring Routine
 	config.RunSupplyMonitoringRoutine = viper.GetBool("run-supply-monitoring-routine")
+	config.SupplyAlertTopHoldersCount = viper.GetUint64("supply-alert-top-holders-count")
+	config.SupplyAlertTopHoldersPercentage = viper.GetFloat64("supply-alert-top-holders-percentage")
+	config.SupplyAlertWebhookURL = viper.GetString("supply-alert-webhook-url")
+	config.RichListLength = viper.GetUint64("rich-list-length")
 
 	// Node source ID
 	config.NodeSource = viper.GetUint64("node-source")
@@ -20,7 +20,7 @@ type SetUSDCentsToDeSoExchangeRateResponse struct {
 
 // SetUSDCentsToDeSoReserveExchangeRate sets the minimum price to buy DeSo from this node.
 func (fes *APIServer) SetUSDCentsToDeSoReserveExchangeRate(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SetUSDCentsToDeSoExchangeRateRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SetUSDCentsToDeSoReserveExchangeRate: Problem parsing request body: %v", err))
@@ -97,7 +97,7 @@ type SetBuyDeSoFeeBasisPointsResponse struct {
 
 // SetBuyDeSoFeeBasisPoints sets the percentage fee applied to all DeSo buys on this node.
 func (fes *APIServer) SetBuyDeSoFeeBasisPoints(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SetBuyDeSoFeeBasisPointsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SetBuyDeSoFeeBasisPoints: Problem parsing request body: %v", err))
@@ -26,7 +26,7 @@ type AdminPinPostResponse struct{}
 
 // AdminPinPost  ...
 func (fes *APIServer) AdminPinPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminPinPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateGlobalFeed: Problem parsing request body: %v", err))
@@ -101,7 +101,7 @@ type AdminUpdateGlobalFeedResponse struct{}
 // AdminUpdateGlobalFeed ...
 // NOTE: This function adds posts to the global feed as well as to the hot feed approved posts.
 func (fes *APIServer) AdminUpdateGlobalFeed(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateGlobalFeedRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateGlobalFeed: Problem parsing request body: %v", err))
@@ -188,7 +188,7 @@ type AdminRemoveNilPostsResponse struct{}
 
 func (fes *APIServer) AdminRemoveNilPosts(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminRemoveNilPostsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateGlobalFeed: Problem parsing request body: %v", err))
@@ -40,7 +40,7 @@ type AdminSetTransactionFeeForTransactionTypeResponse struct {
 
 // AdminSetTransactionFeeForTransactionType sets the minimum price to buy DeSo from this node.
 func (fes *APIServer) AdminSetTransactionFeeForTransactionType(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminSetTransactionFeeForTransactionTypeRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminSetTransactionFeeForTransactionType: Problem parsing request body: %v", err))
@@ -98,7 +98,7 @@ type AdminSetAllTransactionFeesResponse struct {
 
 // AdminSetAllTransactionFees overwrites transaction fees for all transaction types.
 func (fes *APIServer) AdminSetAllTransactionFees(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminSetAllTransactionFeesRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminSetAllTransactionFees: Problem parsing request body: %v", err))
@@ -291,7 +291,7 @@ type AdminAddExemptPublicKey struct {
 
 // AdminAddExemptPublicKey adds or removes a public key from the list of public keys exempt from node fees.
 func (fes *APIServer) AdminAddExemptPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminAddExemptPublicKey{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminAddExemptPublicKey: Problem parsing request body: %v", err))
@@ -301,7 +301,7 @@ func (fes *APIServer) AdminAddExemptPublicKey(ww http.ResponseWriter, req *http.
 	// Convert the PublicKeyBase58Check string to a public key byte slice.
 	publicKeyBytes, _, err := lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 	if err != nil || len(publicKeyBytes) != btcec.PubKeyBytesLenCompressed {
-		_AddBadRequestError(ww, fmt.Sprintf("AdminAddExemptPublicKey: Problem decoding public key %s: %v",
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AdminAddExemptPublicKey: Problem decoding public key %s: %v",
 			requestData.PublicKeyBase58Check, err))
 	}
 
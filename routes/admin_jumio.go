@@ -20,7 +20,7 @@ type AdminResetJumioRequest struct {
 }
 
 func (fes *APIServer) AdminResetJumioForPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminResetJumioRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminResetJumioForPublicKey: Problem parsing request body: %v", err))
@@ -113,7 +113,7 @@ type AdminUpdateJumioDeSoResponse struct {
 }
 
 func (fes *APIServer) AdminUpdateJumioDeSo(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateJumioDeSoRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateJumioDeSo: Problem parsing request body: %v", err))
@@ -146,7 +146,7 @@ type AdminUpdateJumioUSDCentsResponse struct {
 }
 
 func (fes *APIServer) AdminUpdateJumioUSDCents(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateJumioUSDCentsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateJumioDeSo: Problem parsing request body: %v", err))
@@ -185,7 +185,7 @@ type AdminUpdateJumioKickbackUSDCentsResponse struct {
 }
 
 func (fes *APIServer) AdminUpdateJumioKickbackUSDCents(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateJumioKickbackUSDCentsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateJumioKickbackUSDCents: Problem parsing request body: %v", err))
@@ -227,7 +227,7 @@ type AdminJumioCallback struct {
 
 // AdminJumioCallback Note: this endpoint is mainly for testing purposes.
 func (fes *APIServer) AdminJumioCallback(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminJumioCallback{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminJumioCallback: Problem parsing request body: %v", err))
@@ -299,7 +299,7 @@ type AdminUpdateJumioCountrySignUpBonusRequest struct {
 
 // AdminUpdateJumioCountrySignUpBonus allows admins to adjust the configuration of sign up bonuses at a country level
 func (fes *APIServer) AdminUpdateJumioCountrySignUpBonus(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateJumioCountrySignUpBonusRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateJumioCountrySignUpBonusMetadata: "+
@@ -108,7 +108,7 @@ func (fes *APIServer) GetPostsForNFTDropEntry(dropEntryToReturn *NFTDropEntry,
 }
 
 func (fes *APIServer) AdminGetNFTDrop(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetNFTDropRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetNFTDrop: Error parsing request body: %v", err))
@@ -169,7 +169,7 @@ type AdminUpdateNFTDropResponse struct {
 }
 
 func (fes *APIServer) AdminUpdateNFTDrop(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateNFTDropRequest{}
 	err := decoder.Decode(&requestData)
 	if err != nil {
@@ -340,7 +340,7 @@ func (fes *APIServer) NodeControl(ww http.ResponseWriter, req *http.Request) {
 	//fes.DataLock.Lock()
 	//defer fes.DataLock.Unlock()
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := NodeControlRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("NodeControlRequest: Problem parsing request body: %v", err))
@@ -382,7 +382,7 @@ func (fes *APIServer) NodeControl(ww http.ResponseWriter, req *http.Request) {
 			for _, pkStr := range pkStrings {
 				publicKeyBytes, _, err := lib.Base58CheckDecode(pkStr)
 				if err != nil {
-					_AddBadRequestError(ww, fmt.Sprintf("NodeControlRequest: Problem decoding miner public key from base58 %s: %v", pkStr, err))
+					_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("NodeControlRequest: Problem decoding miner public key from base58 %s: %v", pkStr, err))
 					return
 				}
 				pk, err := btcec.ParsePubKey(publicKeyBytes, btcec.S256())
@@ -2,16 +2,24 @@ package routes
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/rand"
 	"encoding/csv"
 	"encoding/gob"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -19,6 +27,7 @@ import (
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/dgraph-io/badger/v3"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 )
@@ -33,8 +42,88 @@ const (
 	CSVColumnRequiresJumio  = 6
 	CSVColumnTstampNanos    = 11
 	CSVColumnIsActive       = 12
+
+	// Optional so that CSVs downloaded before links could expire can still be uploaded.
+	CSVColumnExpiresAtTStampNanos = 13
 )
 
+// The current on-disk encoding version for ReferralInfo. Version 1 is JSON; records without a SchemaVersion were
+// written with gob.
+const ReferralInfoSchemaVersion = 1
+
+type versionedReferralInfo struct {
+	SchemaVersion uint64
+	ReferralInfo
+}
+
+// encodeReferralInfo encodes a ReferralInfo for global state as JSON tagged with ReferralInfoSchemaVersion.
+func encodeReferralInfo(referralInfo *ReferralInfo) (_referralInfoBytes []byte, _err error) {
+	return json.Marshal(versionedReferralInfo{
+		SchemaVersion: ReferralInfoSchemaVersion,
+		ReferralInfo:  *referralInfo,
+	})
+}
+
+// decodeReferralInfo decodes a ReferralInfo written by encodeReferralInfo. Legacy records that were gob-encoded
+// are decoded with gob instead.
+func decodeReferralInfo(referralInfoBytes []byte) (_referralInfo *ReferralInfo, _err error) {
+	if len(referralInfoBytes) > 0 && referralInfoBytes[0] == '{' {
+		versionedInfo := versionedReferralInfo{}
+		if err := json.Unmarshal(referralInfoBytes, &versionedInfo); err == nil && versionedInfo.SchemaVersion > 0 {
+			if versionedInfo.SchemaVersion > ReferralInfoSchemaVersion {
+				return nil, fmt.Errorf("decodeReferralInfo: Unsupported schema version %d",
+					versionedInfo.SchemaVersion)
+			}
+			return &versionedInfo.ReferralInfo, nil
+		}
+	}
+
+	referralInfo := ReferralInfo{}
+	if err := gob.NewDecoder(bytes.NewReader(referralInfoBytes)).Decode(&referralInfo); err != nil {
+		return nil, err
+	}
+	return &referralInfo, nil
+}
+
+// The current on-disk encoding version for RefereePayoutInfo. Version 1 is JSON; records without a SchemaVersion
+// were written with gob.
+const RefereePayoutInfoSchemaVersion = 1
+
+type versionedRefereePayoutInfo struct {
+	SchemaVersion uint64
+	RefereePayoutInfo
+}
+
+// encodeRefereePayoutInfo encodes a RefereePayoutInfo for the referee index as JSON tagged with
+// RefereePayoutInfoSchemaVersion, like encodeReferralInfo.
+func encodeRefereePayoutInfo(refereePayoutInfo *RefereePayoutInfo) (_refereePayoutInfoBytes []byte, _err error) {
+	return json.Marshal(versionedRefereePayoutInfo{
+		SchemaVersion:     RefereePayoutInfoSchemaVersion,
+		RefereePayoutInfo: *refereePayoutInfo,
+	})
+}
+
+// decodeRefereePayoutInfo decodes a RefereePayoutInfo written by encodeRefereePayoutInfo. Legacy records that were
+// gob-encoded are decoded with gob instead.
+func decodeRefereePayoutInfo(refereePayoutInfoBytes []byte) (_refereePayoutInfo *RefereePayoutInfo, _err error) {
+	if len(refereePayoutInfoBytes) > 0 && refereePayoutInfoBytes[0] == '{' {
+		versionedInfo := versionedRefereePayoutInfo{}
+		if err := json.Unmarshal(refereePayoutInfoBytes, &versionedInfo); err == nil && versionedInfo.SchemaVersion > 0 {
+			if versionedInfo.SchemaVersion > RefereePayoutInfoSchemaVersion {
+				return nil, fmt.Errorf("decodeRefereePayoutInfo: Unsupported schema version %d",
+					versionedInfo.SchemaVersion)
+			}
+			return &versionedInfo.RefereePayoutInfo, nil
+		}
+	}
+
+	refereePayoutInfo := RefereePayoutInfo{}
+	if err := gob.NewDecoder(bytes.NewReader(refereePayoutInfoBytes)).Decode(&refereePayoutInfo); err != nil {
+		return nil, err
+	}
+	return &refereePayoutInfo, nil
+}
+
 func (fes *APIServer) putReferralHashWithInfo(
 	referralHashBase58 string,
 	referralInfo *ReferralInfo,
@@ -43,10 +132,16 @@ func (fes *APIServer) putReferralHashWithInfo(
 
 	dbKey := GlobalStateKeyForReferralHashToReferralInfo(referralHashBytes)
 
-	// Encode the updated entry and stick it in the database.
-	referralInfoDataBuf := bytes.NewBuffer([]byte{})
-	gob.NewEncoder(referralInfoDataBuf).Encode(referralInfo)
-	err := fes.GlobalState.Put(dbKey, referralInfoDataBuf.Bytes())
+	// Encode the updated entry and stick it in the database along with its case-insensitive index entry, if needed.
+	referralInfoBytes, err := encodeReferralInfo(referralInfo)
+	if err != nil {
+		return fmt.Errorf("putReferralHashWithInfo: Problem encoding referralInfo: %v", err)
+	}
+	indexKVPairs, err := fes.getReferralHashIndexKVPairs(referralHashBase58)
+	if err != nil {
+		return fmt.Errorf("putReferralHashWithInfo: %v", err)
+	}
+	err = fes.GlobalState.BatchPut(append([]KVPair{{Key: dbKey, Value: referralInfoBytes}}, indexKVPairs...))
 	if err != nil {
 		return errors.Wrap(fmt.Errorf(
 			"putReferralHashWithInfo: Problem putting updated referralInfo: %v", err), "")
@@ -55,9 +150,109 @@ func (fes *APIServer) putReferralHashWithInfo(
 	return nil
 }
 
+// ErrReferralHashNotFound is returned (wrapped) by getInfoForReferralHashBase58 when there is no ReferralInfo
+// stored for the referral hash. Use errors.Cause to check for it.
+var ErrReferralHashNotFound = errors.New("referral hash not found")
+
+// getReferralHashIndexKVPairs returns the write that adds a referral hash to the case-insensitive lookup index, if it
+// isn't indexed yet. The index is kept up to date even when --case-insensitive-referral-hashes is off so that the
+// flag can be turned on later. If another hash that differs only by case is already indexed, nothing is written so
+// the first hash indexed keeps resolving.
+func (fes *APIServer) getReferralHashIndexKVPairs(referralHashBase58 string) (_kvPairs []KVPair, _err error) {
+	indexKey := GlobalStateKeyForLowercaseReferralHashToReferralHash(referralHashBase58)
+	indexedReferralHash, err := fes.GlobalState.Get(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"getReferralHashIndexKVPairs: Problem getting index entry for %s: %v", referralHashBase58, err)
+	}
+	if indexedReferralHash != nil {
+		return nil, nil
+	}
+	return []KVPair{{Key: indexKey, Value: []byte(referralHashBase58)}}, nil
+}
+
+// resolveReferralHashBase58 strips the whitespace users tend to paste along with referral hashes. If
+// --case-insensitive-referral-hashes is set and there's no exact match, it returns the indexed hash that matches
+// ignoring case instead. An exact match always wins, since base58 hashes can legitimately differ only by case.
+func (fes *APIServer) resolveReferralHashBase58(referralHashBase58 string) (_referralHashBase58 string, _err error) {
+	referralHashBase58 = strings.TrimSpace(referralHashBase58)
+	if fes.Config == nil || !fes.Config.CaseInsensitiveReferralHashes {
+		return referralHashBase58, nil
+	}
+
+	referralInfoBytes, err := fes.GlobalState.Get(GlobalStateKeyForReferralHashToReferralInfo([]byte(referralHashBase58)))
+	if err != nil {
+		return "", fmt.Errorf("resolveReferralHashBase58: Problem getting referral info (%s): %v", referralHashBase58, err)
+	}
+	if referralInfoBytes != nil {
+		return referralHashBase58, nil
+	}
+	indexedReferralHash, err := fes.GlobalState.Get(GlobalStateKeyForLowercaseReferralHashToReferralHash(referralHashBase58))
+	if err != nil {
+		return "", fmt.Errorf(
+			"resolveReferralHashBase58: Problem getting index entry for %s: %v", referralHashBase58, err)
+	}
+	if indexedReferralHash != nil {
+		return string(indexedReferralHash), nil
+	}
+	return referralHashBase58, nil
+}
+
+// The number of referral hashes backfillReferralHashIndex reads and indexes at a time.
+const referralHashIndexBackfillPageSize = 1000
+
+// backfillReferralHashIndex adds referral hashes created before the case-insensitive index existed to it, so that
+// turning on --case-insensitive-referral-hashes also resolves those hashes. Hashes that are already indexed, or that
+// differ only by case from a hash that is, are left alone like they are by getReferralHashIndexKVPairs.
+func (fes *APIServer) backfillReferralHashIndex() error {
+	prefix := _GlobalStatePrefixReferralHashToReferralInfo
+	startKey := prefix
+	numIndexed := 0
+	for {
+		keys, _, err := fes.GlobalState.Seek(
+			startKey, prefix, 0, referralHashIndexBackfillPageSize, false /*reverse*/, false /*fetchValues*/)
+		if err != nil {
+			return fmt.Errorf("backfillReferralHashIndex: Problem seeking referral hashes: %v", err)
+		}
+
+		// Two hashes in a page can differ only by case, so only the first one's index entry is queued.
+		var kvPairs []KVPair
+		queuedIndexKeys := make(map[string]bool)
+		for _, key := range keys {
+			indexKVPairs, err := fes.getReferralHashIndexKVPairs(string(key[len(prefix):]))
+			if err != nil {
+				return fmt.Errorf("backfillReferralHashIndex: %v", err)
+			}
+			for _, kvPair := range indexKVPairs {
+				if !queuedIndexKeys[string(kvPair.Key)] {
+					queuedIndexKeys[string(kvPair.Key)] = true
+					kvPairs = append(kvPairs, kvPair)
+				}
+			}
+		}
+		if len(kvPairs) > 0 {
+			if err = fes.GlobalState.BatchPut(kvPairs); err != nil {
+				return fmt.Errorf("backfillReferralHashIndex: Problem writing index entries: %v", err)
+			}
+			numIndexed += len(kvPairs)
+		}
+
+		if len(keys) < referralHashIndexBackfillPageSize {
+			break
+		}
+		startKey = append(append([]byte{}, keys[len(keys)-1]...), 0)
+	}
+	glog.Infof("backfillReferralHashIndex: Indexed %d referral hashes", numIndexed)
+	return nil
+}
+
 func (fes *APIServer) getInfoForReferralHashBase58(
 	referralHashBase58 string,
 ) (_referralInfo *ReferralInfo, _err error) {
+	referralHashBase58, err := fes.resolveReferralHashBase58(referralHashBase58)
+	if err != nil {
+		return nil, fmt.Errorf("getInfoForReferralHashBase58: %v", err)
+	}
 	referralHashBytes := []byte(referralHashBase58)
 
 	dbKey := GlobalStateKeyForReferralHashToReferralInfo(referralHashBytes)
@@ -68,20 +263,54 @@ func (fes *APIServer) getInfoForReferralHashBase58(
 		return nil, errors.Wrap(fmt.Errorf(
 			"getInfoForReferralHash: Problem putting updated referralInfo: %v", err), "")
 	}
-	referralInfo := ReferralInfo{}
-	if referralInfoBytes != nil {
-		err = gob.NewDecoder(bytes.NewReader(referralInfoBytes)).Decode(&referralInfo)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"getInfoForReferralHash: Failed decoding referral info (%s): %v",
-				referralHashBase58, err)
-		}
-	} else {
-		return nil, fmt.Errorf(
+	if referralInfoBytes == nil {
+		return nil, errors.Wrapf(ErrReferralHashNotFound,
 			"getInfoForReferralHashBase58: got nil bytes for hash (%s)", referralHashBase58)
 	}
+	referralInfo, err := decodeReferralInfo(referralInfoBytes)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"getInfoForReferralHash: Failed decoding referral info (%s): %v",
+			referralHashBase58, err)
+	}
 
-	return &referralInfo, nil
+	return referralInfo, nil
+}
+
+// lockReferralHash takes the per referral hash lock used to serialize updates to a referral hash's ReferralInfo and
+// returns a function that releases it.
+func (fes *APIServer) lockReferralHash(referralHashBase58 string) (_unlock func()) {
+	mtx, _ := fes.mtxReferralInfoByHash.LoadOrStore(referralHashBase58, &sync.Mutex{})
+	mtx.(*sync.Mutex).Lock()
+	return mtx.(*sync.Mutex).Unlock
+}
+
+// updateReferralInfoForReferralHash reads the latest ReferralInfo for a referral hash, applies updateFn to it, and
+// writes the result back. The ReferralInfo is stored as a single blob, so all updates to an existing referral hash
+// should go through this function to avoid losing concurrent updates (ex: two payouts bumping the totals at the same
+// time). Updates are serialized per referral hash within this node.
+func (fes *APIServer) updateReferralInfoForReferralHash(
+	referralHashBase58 string,
+	updateFn func(referralInfo *ReferralInfo) error,
+) (_updatedReferralInfo *ReferralInfo, _err error) {
+	// Resolve the hash first so that we lock and write the stored hash rather than the one that was passed in.
+	referralHashBase58, err := fes.resolveReferralHashBase58(referralHashBase58)
+	if err != nil {
+		return nil, err
+	}
+	defer fes.lockReferralHash(referralHashBase58)()
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(referralHashBase58)
+	if err != nil {
+		return nil, err
+	}
+	if err = updateFn(referralInfo); err != nil {
+		return nil, err
+	}
+	if err = fes.putReferralHashWithInfo(referralHashBase58, referralInfo); err != nil {
+		return nil, err
+	}
+	return referralInfo, nil
 }
 
 func (fes *APIServer) getReferralHashStatus(pkid *lib.PKID, referralHashBase58 string) bool {
@@ -96,6 +325,20 @@ func (fes *APIServer) getReferralHashStatus(pkid *lib.PKID, referralHashBase58 s
 	return reflect.DeepEqual(val, []byte{1})
 }
 
+// isReferralInfoExpired returns true if the referral link has an expiration timestamp and it has passed.
+func isReferralInfoExpired(referralInfo *ReferralInfo) bool {
+	return referralInfo.ExpiresAtTStampNanos != 0 && uint64(time.Now().UnixNano()) >= referralInfo.ExpiresAtTStampNanos
+}
+
+// isReferralHashActive returns true if the referral link's status is active and the link has not expired. Use
+// this rather than getReferralHashStatus when deciding whether a link can still be used.
+func (fes *APIServer) isReferralHashActive(referralInfo *ReferralInfo) bool {
+	if isReferralInfoExpired(referralInfo) {
+		return false
+	}
+	return fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58)
+}
+
 func (fes *APIServer) setReferralHashStatusForPKID(
 	pkid *lib.PKID, referralHashBase58 string, isActive bool,
 ) (_err error) {
@@ -113,17 +356,100 @@ func (fes *APIServer) setReferralHashStatusForPKID(
 	return nil
 }
 
-func generateNewReferralHash() (_newHash string, _err error) {
-	// Create a new referral hash. First we generate 16 random bytes of entropy (we should only need 8
-	// but we double this to be safe), then we Base58 encode those bytes and take the first 8 characters.
-	randBytes := make([]byte, 16)
-	rand.Read(randBytes) // Since we are using crypto/rand there is no need to do rand.Seed()
-	randBase58 := base58.Encode(randBytes)
-	if len(randBase58) < 8 {
-		return "", fmt.Errorf(
-			"AdminCreateReferralHash: randBase58 string is less than 8 characters (%d)", len(randBase58))
+// The number of times we'll generate a new referral hash when the previous one is already taken.
+const maxReferralHashGenerationAttempts = 5
+
+// generateNewReferralHash generates a random referral hash that referralHashExists reports as unused. Since we only
+// keep 8 characters, collisions become likely enough with many links that we retry up to
+// maxReferralHashGenerationAttempts times before giving up. Callers should pass fes.referralHashExists.
+func generateNewReferralHash(
+	referralHashExists func(referralHashBase58 string) (_exists bool, _err error),
+) (_newHash string, _err error) {
+	for attempt := 0; attempt < maxReferralHashGenerationAttempts; attempt++ {
+		// Create a new referral hash. First we generate 16 random bytes of entropy (we should only need 8
+		// but we double this to be safe), then we Base58 encode those bytes and take the first 8 characters.
+		randBytes := make([]byte, 16)
+		rand.Read(randBytes) // Since we are using crypto/rand there is no need to do rand.Seed()
+		randBase58 := base58.Encode(randBytes)
+		if len(randBase58) < 8 {
+			return "", fmt.Errorf(
+				"AdminCreateReferralHash: randBase58 string is less than 8 characters (%d)", len(randBase58))
+		}
+		candidateHash := randBase58[:8]
+
+		exists, err := referralHashExists(candidateHash)
+		if err != nil {
+			return "", fmt.Errorf("generateNewReferralHash: Problem checking for a collision: %v", err)
+		}
+		if !exists {
+			return candidateHash, nil
+		}
+		glog.Warningf("generateNewReferralHash: Referral hash %s already exists, trying again", candidateHash)
+	}
+	return "", fmt.Errorf(
+		"generateNewReferralHash: Could not generate an unused referral hash after %d attempts",
+		maxReferralHashGenerationAttempts)
+}
+
+// generateAndLockNewReferralHash generates an unused referral hash and returns it locked, so that the caller can put
+// its ReferralInfo before a concurrent create claims the same hash. The caller must call unlock once it's stored.
+func (fes *APIServer) generateAndLockNewReferralHash() (_referralHashBase58 string, _unlock func(), _err error) {
+	unlock := func() {}
+	referralHashBase58, err := generateNewReferralHash(func(candidateHash string) (bool, error) {
+		// Hold each candidate's lock across its exists check, releasing the previous candidate's if it was taken.
+		unlock()
+		unlock = fes.lockReferralHash(candidateHash)
+		return fes.referralHashExists(candidateHash)
+	})
+	if err != nil {
+		unlock()
+		return "", nil, err
+	}
+	return referralHashBase58, unlock, nil
+}
+
+// referralHashExists returns true if there is already a ReferralInfo stored for the referral hash, or for a
+// referral hash that only differs by case. The latter keeps new hashes unambiguous for case-insensitive lookups.
+func (fes *APIServer) referralHashExists(referralHashBase58 string) (_exists bool, _err error) {
+	referralInfoBytes, err := fes.GlobalState.Get(GlobalStateKeyForReferralHashToReferralInfo([]byte(referralHashBase58)))
+	if err != nil {
+		return false, fmt.Errorf("referralHashExists: Problem getting referral info (%s): %v", referralHashBase58, err)
+	}
+	if referralInfoBytes != nil {
+		return true, nil
+	}
+	indexedReferralHash, err := fes.GlobalState.Get(GlobalStateKeyForLowercaseReferralHashToReferralHash(referralHashBase58))
+	if err != nil {
+		return false, fmt.Errorf("referralHashExists: Problem getting index entry (%s): %v", referralHashBase58, err)
+	}
+	return indexedReferralHash != nil, nil
+}
+
+// requireReferralsEnabled writes a 404 and returns false if --enable-referrals is off. Every referral handler calls
+// this before reading its request or touching global state.
+func (fes *APIServer) requireReferralsEnabled(ww http.ResponseWriter) bool {
+	if !fes.Config.EnableReferrals {
+		_AddNotFoundError(ww, "requireReferralsEnabled: Referrals are not enabled on this node")
+		return false
+	}
+	return true
+}
+
+// The referral amount cap used if --max-referral-usd-cents isn't set.
+const fallbackMaxReferralUSDCents = 100000
+
+// validateReferralAmountsUSDCents checks that the referrer and referee amounts for a referral link are within
+// --max-referral-usd-cents.
+func (fes *APIServer) validateReferralAmountsUSDCents(referrerAmountUSDCents uint64, refereeAmountUSDCents uint64) error {
+	referralLimitUSDCents := uint64(fallbackMaxReferralUSDCents)
+	if fes.Config != nil && fes.Config.MaxReferralUSDCents > 0 {
+		referralLimitUSDCents = fes.Config.MaxReferralUSDCents
+	}
+	if referrerAmountUSDCents > referralLimitUSDCents || refereeAmountUSDCents > referralLimitUSDCents {
+		return fmt.Errorf("Referrer and referee amounts should not exceed $%d.%02d USD.",
+			referralLimitUSDCents/100, referralLimitUSDCents%100)
 	}
-	return randBase58[:8], nil
+	return nil
 }
 
 type AdminCreateReferralHashRequest struct {
@@ -137,6 +463,9 @@ type AdminCreateReferralHashRequest struct {
 	MaxReferrals           uint64 `safeForLogging:"true"`
 	RequiresJumio          bool   `safeForLogging:"true"`
 
+	// Optional. If set, the referral hash stops paying out at this time. Zero means the link never expires.
+	ExpiresAtTStampNanos uint64 `safeForLogging:"true"`
+
 	AdminPublicKey string `safeForLogging:"true"`
 }
 
@@ -145,7 +474,11 @@ type AdminCreateReferralHashResponse struct {
 }
 
 func (fes *APIServer) AdminCreateReferralHash(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminCreateReferralHashRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminCreateReferralHash: Problem parsing request body: %v", err))
@@ -158,10 +491,9 @@ func (fes *APIServer) AdminCreateReferralHash(ww http.ResponseWriter, req *http.
 		return
 	}
 
-	referralLimitUSD := uint64(100000)
-	if requestData.ReferrerAmountUSDCents > referralLimitUSD || requestData.RefereeAmountUSDCents > referralLimitUSD {
-		_AddBadRequestError(ww,
-			fmt.Sprintf("AdminCreateReferralHashRequest: Referrer and referee amounts should not exceed $1000 USD."))
+	if err := fes.validateReferralAmountsUSDCents(
+		requestData.ReferrerAmountUSDCents, requestData.RefereeAmountUSDCents); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminCreateReferralHashRequest: %v", err))
 		return
 	}
 
@@ -171,7 +503,7 @@ func (fes *APIServer) AdminCreateReferralHash(ww http.ResponseWriter, req *http.
 	if requestData.UserPublicKeyBase58Check != "" {
 		userPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 		if err != nil || len(userPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
-			_AddBadRequestError(ww, fmt.Sprintf("AdminCreateReferralHash: Problem decoding updater public key %s: %v",
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AdminCreateReferralHash: Problem decoding updater public key %s: %v",
 				requestData.UserPublicKeyBase58Check, err))
 			return
 		}
@@ -207,22 +539,25 @@ func (fes *APIServer) AdminCreateReferralHash(ww http.ResponseWriter, req *http.
 	}
 
 	// Generate a fresh referral hash for the new link.
-	referralHashBase58, err := generateNewReferralHash()
+	referralHashBase58, unlock, err := fes.generateAndLockNewReferralHash()
 	if err != nil {
 		_AddInternalServerError(ww, fmt.Sprintf(
 			"AdminCreateReferralHash: problem generating referral hash: %v", err))
 		return
 	}
+	defer unlock()
 
 	// Create and fill a ReferralInfo struct for the new referral hash.
 	referralInfo := &ReferralInfo{
-		ReferrerAmountUSDCents: requestData.ReferrerAmountUSDCents,
-		RefereeAmountUSDCents:  requestData.RefereeAmountUSDCents,
-		MaxReferrals:           requestData.MaxReferrals,
-		RequiresJumio:          requestData.RequiresJumio,
-		ReferralHashBase58:     referralHashBase58,
-		ReferrerPKID:           referrerPKID.PKID,
-		DateCreatedTStampNanos: uint64(time.Now().UnixNano()),
+		ReferrerAmountUSDCents:  requestData.ReferrerAmountUSDCents,
+		RefereeAmountUSDCents:   requestData.RefereeAmountUSDCents,
+		MaxReferrals:            requestData.MaxReferrals,
+		RequiresJumio:           requestData.RequiresJumio,
+		ReferralHashBase58:      referralHashBase58,
+		ReferrerPKID:            referrerPKID.PKID,
+		DateCreatedTStampNanos:  uint64(time.Now().UnixNano()),
+		ExpiresAtTStampNanos:    requestData.ExpiresAtTStampNanos,
+		CreatedByAdminPublicKey: requestData.AdminPublicKey,
 	}
 
 	// Encode the updated entry and stick it in the database.
@@ -265,6 +600,9 @@ type AdminUpdateReferralHashRequest struct {
 	RequiresJumio          bool   `safeForLogging:"true"`
 	IsActive               bool   `safeForLogging:"true"`
 
+	// Zero means the link never expires.
+	ExpiresAtTStampNanos uint64 `safeForLogging:"true"`
+
 	AdminPublicKey string `safeForLogging:"true"`
 }
 
@@ -273,7 +611,11 @@ type AdminUpdateReferralHashResponse struct {
 }
 
 func (fes *APIServer) AdminUpdateReferralHash(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateReferralHashRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateReferralHash: Problem parsing request body: %v", err))
@@ -286,26 +628,23 @@ func (fes *APIServer) AdminUpdateReferralHash(ww http.ResponseWriter, req *http.
 		return
 	}
 
-	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
-	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf(
-			"AdminUpdateeReferralHash: Problem putting updated referral hash and info: %v", err))
+	if err := fes.validateReferralAmountsUSDCents(
+		requestData.ReferrerAmountUSDCents, requestData.RefereeAmountUSDCents); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateReferralHashRequest: %v", err))
 		return
 	}
 
-	// Make a copy of the referral info. Note that the referrerPKID is a pointer but it should
-	// be safe to leave them pointing to the same PKID in this endpoint.
-	updatedReferralInfo := &ReferralInfo{}
-	*updatedReferralInfo = *referralInfo
-
-	// Update the referral info for this referral hash.
-	updatedReferralInfo.ReferrerAmountUSDCents = requestData.ReferrerAmountUSDCents
-	updatedReferralInfo.RefereeAmountUSDCents = requestData.RefereeAmountUSDCents
-	updatedReferralInfo.MaxReferrals = requestData.MaxReferrals
-	updatedReferralInfo.RequiresJumio = requestData.RequiresJumio
-
-	// Encode the updated entry and stick it in the database.
-	err = fes.putReferralHashWithInfo(requestData.ReferralHashBase58, updatedReferralInfo)
+	// Update the referral info for this referral hash. This re-reads the latest referral info so that we don't
+	// clobber the stats if a payout happens concurrently.
+	updatedReferralInfo, err := fes.updateReferralInfoForReferralHash(
+		requestData.ReferralHashBase58, func(referralInfo *ReferralInfo) error {
+			referralInfo.ReferrerAmountUSDCents = requestData.ReferrerAmountUSDCents
+			referralInfo.RefereeAmountUSDCents = requestData.RefereeAmountUSDCents
+			referralInfo.MaxReferrals = requestData.MaxReferrals
+			referralInfo.RequiresJumio = requestData.RequiresJumio
+			referralInfo.ExpiresAtTStampNanos = requestData.ExpiresAtTStampNanos
+			return nil
+		})
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
 			"AdminUpdateReferralHash: Problem putting updated referral hash and info: %v", err))
@@ -314,7 +653,7 @@ func (fes *APIServer) AdminUpdateReferralHash(ww http.ResponseWriter, req *http.
 
 	// Set the referral hash status.
 	err = fes.setReferralHashStatusForPKID(
-		referralInfo.ReferrerPKID, requestData.ReferralHashBase58, requestData.IsActive)
+		updatedReferralInfo.ReferrerPKID, updatedReferralInfo.ReferralHashBase58, requestData.IsActive)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
 			"AdminUpdateReferralHash: Problem setting referral hash status: %v", err))
@@ -339,6 +678,22 @@ type ReferralInfoResponse struct {
 	IsActive      bool
 	Info          ReferralInfo
 	ReferredUsers []ProfileEntryResponse
+
+	// The number of referees in the referee index for this referral hash, and how many more can be referred
+	// before MaxReferrals is hit. NumReferrals counts every referee, even when ReferredUsers is only one page.
+	// RemainingReferrals is always zero if there is no cap on referrals. These are only set by
+	// getReferralInfoResponsesForPubKey.
+	NumReferrals       uint64
+	RemainingReferrals uint64
+}
+
+// getRemainingReferrals returns how many more referrals a link with maxReferrals can take after numReferrals,
+// saturating at zero. Zero is also returned if maxReferrals is zero, since that means there is no cap.
+func getRemainingReferrals(maxReferrals uint64, numReferrals uint64) uint64 {
+	if maxReferrals <= numReferrals {
+		return 0
+	}
+	return maxReferrals - numReferrals
 }
 
 type SimpleReferralInfoResponse struct {
@@ -352,32 +707,77 @@ type AdminGetAllReferralInfoForUserRequest struct {
 	Username                 string `safeForLogging:"true"`
 
 	AdminPublicKey string `safeForLogging:"true"`
+
+	// Optional pagination. If Limit is zero, every referral hash from StartKey onwards is returned. Otherwise it's
+	// capped at --max-page-size. StartKey is the referral hash to start from (inclusive), normally the NextStartKey
+	// from the previous page. Referral hashes are returned in ascending order unless Reverse is set.
+	Limit    int    `safeForLogging:"true"`
+	StartKey string `safeForLogging:"true"`
+	Reverse  bool   `safeForLogging:"true"`
+
+	// Optional pagination of each referral hash's ReferredUsers. RefereeOffset referees are skipped, then at most
+	// RefereeLimit are returned. If RefereeLimit is zero, every referee after RefereeOffset is returned. Otherwise
+	// it's capped at --max-page-size. Use NumReferrals to tell when the last page has been fetched.
+	RefereeOffset int `safeForLogging:"true"`
+	RefereeLimit  int `safeForLogging:"true"`
 }
 
 type AdminGetAllReferralInfoForUserResponse struct {
 	ReferralInfoResponses []ReferralInfoResponse `safeForLogging:"true"`
+
+	// The StartKey for the next page, or empty if this is the last page.
+	NextStartKey string `safeForLogging:"true"`
 }
 
+// Referral hashes are always 8 characters, see generateNewReferralHash.
+const referralHashLen = 8
+
+// getReferralInfoResponsesForPubKey returns the referral links owned by a public key, optionally paginated. Pass an
+// empty startReferralHash, a zero limit, and reverse=false to get every link. The returned nextReferralHash is the
+// startReferralHash for the next page and is empty once there are no more links. If includeReferredUsers is set,
+// refereeOffset and refereeLimit select the page of each link's referees to look up profiles for, with a zero
+// refereeLimit meaning every referee after refereeOffset.
 func (fes *APIServer) getReferralInfoResponsesForPubKey(pkBytes []byte, includeReferredUsers bool,
-) (_referralInfoResponses []ReferralInfoResponse, _err error) {
+	refereeOffset int, refereeLimit int, startReferralHash string, limit int, reverse bool,
+) (_referralInfoResponses []ReferralInfoResponse, _nextReferralHash string, _err error) {
 
 	// Get the PKID for the pub key passed in.
 	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
 	if err != nil {
-		return nil, fmt.Errorf("putReferralHashWithInfo: Problem getting utxoView: %v", err)
+		return nil, "", fmt.Errorf("putReferralHashWithInfo: Problem getting utxoView: %v", err)
 	}
 	referrerPKID := utxoView.GetPKIDForPublicKey(pkBytes)
 	if referrerPKID == nil {
-		return nil, fmt.Errorf(
+		return nil, "", fmt.Errorf(
 			"putReferralHashWithInfo: nil PKID for pubkey: %v", lib.PkToString(pkBytes, fes.Params))
 	}
 
 	// Build a key to seek all of the referral hashes for this PKID.
 	dbSeekKey := GlobalStateSeekKeyForPKIDReferralHashes(referrerPKID.PKID)
+	startKey := append(append([]byte{}, dbSeekKey...), []byte(startReferralHash)...)
+	// Seeking in reverse pads the start key out to the full key length so that we start from the last key.
+	maxKeyLen := 0
+	if reverse {
+		maxKeyLen = len(dbSeekKey) + referralHashLen
+	}
+	// We fetch one extra entry so that we know where the next page starts.
+	numToFetch := 0
+	if limit > 0 {
+		numToFetch = limit + 1
+	}
 	keysFound, valsFound, err := fes.GlobalState.Seek(
-		dbSeekKey, dbSeekKey, 0, 0, false /*reverse*/, true /*fetchValue*/)
+		startKey, dbSeekKey, maxKeyLen, numToFetch, reverse, true /*fetchValue*/)
+	if err != nil {
+		return nil, "", fmt.Errorf("getReferralInfoResponsesForPubKey: Problem seeking referral hashes: %v", err)
+	}
 
 	referralHashStartIndex := 1 + len(referrerPKID.PKID)
+	nextReferralHash := ""
+	if limit > 0 && len(keysFound) > limit {
+		nextReferralHash = string(keysFound[limit][referralHashStartIndex:])
+		keysFound = keysFound[:limit]
+		valsFound = valsFound[:limit]
+	}
 	var referralInfoResponses []ReferralInfoResponse
 	for keyIndex, key := range keysFound {
 		// Chop out all the referral hashes from the keys found.
@@ -387,11 +787,11 @@ func (fes *APIServer) getReferralInfoResponsesForPubKey(pkBytes []byte, includeR
 		// Grab the 'IsActive' status for this hash.
 		isActiveBytes := valsFound[keyIndex]
 		if len(isActiveBytes) == 0 {
-			return nil, fmt.Errorf("fes.getReferralInfoResponsesForPubKey: got zero isActiveBytes: %s", referralHash)
+			return nil, "", fmt.Errorf("fes.getReferralInfoResponsesForPubKey: got zero isActiveBytes: %s", referralHash)
 		}
 		isActive, err := lib.ReadBoolByte(bytes.NewReader(isActiveBytes))
 		if err != nil {
-			return nil, errors.Wrapf(err, "fes.getReferralInfoResponsesForPubKey:"+
+			return nil, "", errors.Wrapf(err, "fes.getReferralInfoResponsesForPubKey:"+
 				"problem reading isActiveBytes")
 		}
 
@@ -399,68 +799,82 @@ func (fes *APIServer) getReferralInfoResponsesForPubKey(pkBytes []byte, includeR
 		dbKey := GlobalStateKeyForReferralHashToReferralInfo(referralHashBytes)
 		referralInfoBytes, err := fes.GlobalState.Get(dbKey)
 		if err != nil {
-			return nil, fmt.Errorf(
+			return nil, "", fmt.Errorf(
 				"fes.getReferralInfoResponsesForPubKey: error getting referral info (%s): %v",
 				referralHash, err)
 		}
 		referralInfo := ReferralInfo{}
 		if referralInfoBytes != nil {
-			err = gob.NewDecoder(bytes.NewReader(referralInfoBytes)).Decode(&referralInfo)
+			decodedReferralInfo, err := decodeReferralInfo(referralInfoBytes)
 			if err != nil {
-				return nil, fmt.Errorf(
+				return nil, "", fmt.Errorf(
 					"getReferralInfoResponsesForPubKey: Failed decoding referral info (%s): %v",
 					referralHash, err)
 			}
+			referralInfo = *decodedReferralInfo
+		}
+
+		// Look up all of the users referred by this referral hash. We only need the keys to count them.
+		refereeSeekKey := GlobalStateSeekKeyForPKIDReferralHashRefereePKIDs(
+			referrerPKID.PKID, referralHashBytes)
+		refereeKeys, _, err := fes.GlobalState.Seek(refereeSeekKey, refereeSeekKey, 0, 0, false, false)
+		if err != nil {
+			return nil, "", fmt.Errorf(
+				"getReferralInfoResponsesForPubKey: Failed to get referees (%s): %v",
+				referralHash, err)
 		}
 
 		referredUsers := []ProfileEntryResponse{}
 		if includeReferredUsers {
-			// Look up all of the users referred by this referral hash.
-			refereeSeekKey := GlobalStateSeekKeyForPKIDReferralHashRefereePKIDs(
-				referrerPKID.PKID, referralHashBytes)
-			refereeKeys, _, err := fes.GlobalState.Seek(refereeSeekKey, refereeSeekKey, 0, 0, false, false)
-			if err != nil {
-				return nil, fmt.Errorf(
-					"getReferralInfoResponsesForPubKey: Failed to get referees (%s): %v",
-					referralHash, err)
-			}
+			// Only the requested page of referees gets a profile lookup, since that's the expensive part.
+			refereePageKeys := getRefereeKeysPage(refereeKeys, refereeOffset, refereeLimit)
+
 			// Now we chop the RefereePKIDs out of the keys and look up their profiles.
 			// The key consists of: Prefix, ReferralPKID, ReferralHash, RefereePKID.
 			refereePKIDStartIdx := 1 + btcec.PubKeyBytesLenCompressed + 8
-			for _, keyBytes := range refereeKeys {
+			for _, keyBytes := range refereePageKeys {
 				refereePKIDBytes := keyBytes[refereePKIDStartIdx:]
 				refereePKID := &lib.PKID{}
 				copy(refereePKID[:], refereePKIDBytes)
 
-				profileEntry := utxoView.GetProfileEntryForPKID(refereePKID)
-				if profileEntry != nil {
-					profileEntryResponse := fes._profileEntryToResponse(profileEntry, utxoView)
-					referredUsers = append(referredUsers, *profileEntryResponse)
-				} else {
-					// This is an anon profile, so we just populate the pub key and call it good.
-					profileEntryResponse := ProfileEntryResponse{}
-					profileEntryResponse.PublicKeyBase58Check =
-						lib.PkToString(lib.PKIDToPublicKey(refereePKID), fes.Params)
-					referredUsers = append(referredUsers, profileEntryResponse)
-				}
+				referredUsers = append(referredUsers, *fes.getProfileEntryResponseForPKID(refereePKID, utxoView))
 			}
 		}
 
 		// Construct the referral info response and append it to our list.
 		referralInfoResponse := ReferralInfoResponse{
-			IsActive:      isActive,
-			Info:          referralInfo,
-			ReferredUsers: referredUsers,
+			IsActive:           isActive,
+			Info:               referralInfo,
+			ReferredUsers:      referredUsers,
+			NumReferrals:       uint64(len(refereeKeys)),
+			RemainingReferrals: getRemainingReferrals(referralInfo.MaxReferrals, uint64(len(refereeKeys))),
 		}
 		referralInfoResponses = append(referralInfoResponses, referralInfoResponse)
 
 	}
 
-	return referralInfoResponses, nil
+	return referralInfoResponses, nextReferralHash, nil
+}
+
+// getRefereeKeysPage returns the refereeLimit keys after the first refereeOffset, or every key after refereeOffset if
+// refereeLimit is zero.
+func getRefereeKeysPage(refereeKeys [][]byte, refereeOffset int, refereeLimit int) [][]byte {
+	if refereeOffset >= len(refereeKeys) {
+		return nil
+	}
+	refereeKeys = refereeKeys[refereeOffset:]
+	if refereeLimit > 0 && refereeLimit < len(refereeKeys) {
+		refereeKeys = refereeKeys[:refereeLimit]
+	}
+	return refereeKeys
 }
 
 func (fes *APIServer) AdminGetAllReferralInfoForUser(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetAllReferralInfoForUserRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -473,6 +887,22 @@ func (fes *APIServer) AdminGetAllReferralInfoForUser(ww http.ResponseWriter, req
 			fmt.Sprintf("AdminGetAllReferralInfoForUser: Must provide a valid username or public key."))
 		return
 	}
+	if requestData.Limit < 0 {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfoForUser: Limit must not be negative: %d",
+			requestData.Limit))
+		return
+	}
+	if requestData.RefereeOffset < 0 || requestData.RefereeLimit < 0 {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetAllReferralInfoForUser: RefereeOffset and RefereeLimit must not be negative: %d, %d",
+			requestData.RefereeOffset, requestData.RefereeLimit))
+		return
+	}
+	if requestData.StartKey != "" && len(requestData.StartKey) != referralHashLen {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfoForUser: StartKey must be a referral hash: %s",
+			requestData.StartKey))
+		return
+	}
 
 	// Decode the user public key, if provided.
 	var userPublicKeyBytes []byte
@@ -480,7 +910,7 @@ func (fes *APIServer) AdminGetAllReferralInfoForUser(ww http.ResponseWriter, req
 	if requestData.UserPublicKeyBase58Check != "" {
 		userPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 		if err != nil || len(userPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
-			_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfoForUser: Problem decoding updater public key %s: %v",
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AdminGetAllReferralInfoForUser: Problem decoding updater public key %s: %v",
 				requestData.UserPublicKeyBase58Check, err))
 			return
 		}
@@ -503,7 +933,17 @@ func (fes *APIServer) AdminGetAllReferralInfoForUser(ww http.ResponseWriter, req
 	}
 
 	// Get the referral link info structs.
-	referralInfoResponses, err := fes.getReferralInfoResponsesForPubKey(userPublicKeyBytes, true /*includeReferredUsers*/)
+	limit := 0
+	if requestData.Limit != 0 {
+		limit = fes.getPageSize(uint64(requestData.Limit))
+	}
+	refereeLimit := 0
+	if requestData.RefereeLimit != 0 {
+		refereeLimit = fes.getPageSize(uint64(requestData.RefereeLimit))
+	}
+	referralInfoResponses, nextStartKey, err := fes.getReferralInfoResponsesForPubKey(userPublicKeyBytes,
+		true /*includeReferredUsers*/, requestData.RefereeOffset, refereeLimit,
+		requestData.StartKey, limit, requestData.Reverse)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfoForUser: Problem putting new referral hash and info: %v", err))
 		return
@@ -512,6 +952,7 @@ func (fes *APIServer) AdminGetAllReferralInfoForUser(ww http.ResponseWriter, req
 	// If we made it this far we were successful, return without error.
 	res := AdminGetAllReferralInfoForUserResponse{
 		ReferralInfoResponses: referralInfoResponses,
+		NextStartKey:          nextStartKey,
 	}
 	if err := json.NewEncoder(ww).Encode(res); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfoForUser: Problem encoding response as JSON: %v", err))
@@ -519,47 +960,130 @@ func (fes *APIServer) AdminGetAllReferralInfoForUser(ww http.ResponseWriter, req
 	}
 }
 
+// referralInfoPageSize is the number of ReferralInfos getAllReferralInfos fetches from global state at a time.
+const referralInfoPageSize = 1000
+
 func (fes *APIServer) getAllReferralInfos() (
 	_referralInfos []ReferralInfo, _err error) {
 
-	dbSeekKey := _GlobalStatePrefixReferralHashToReferralInfo
-	_, valsFound, err := fes.GlobalState.Seek(
-		dbSeekKey, dbSeekKey, 0, 0, false /*reverse*/, true /*fetchValue*/)
+	var referralInfos []ReferralInfo
+	nextReferralHash := ""
+	for {
+		referralInfosPage, pageNextReferralHash, err := fes.getReferralInfosPage(nextReferralHash, referralInfoPageSize)
+		if err != nil {
+			return nil, err
+		}
+		referralInfos = append(referralInfos, referralInfosPage...)
+		if pageNextReferralHash == "" {
+			break
+		}
+		nextReferralHash = pageNextReferralHash
+	}
+
+	return referralInfos, nil
+}
+
+// seekReferralInfoBytesPage returns the referral hashes and encoded ReferralInfos for up to pageSize referral hashes,
+// starting from startReferralHash (inclusive) in referral hash order. An empty startReferralHash starts from the
+// beginning. The returned nextReferralHash is the cursor to pass as startReferralHash to get the next page, and is
+// empty once there are no more referral hashes.
+func (fes *APIServer) seekReferralInfoBytesPage(startReferralHash string, pageSize int) (
+	_referralHashes []string, _referralInfoBytes [][]byte, _nextReferralHash string, _err error) {
+
+	if pageSize <= 0 {
+		return nil, nil, "", fmt.Errorf("seekReferralInfoBytesPage: pageSize must be positive: %d", pageSize)
+	}
+
+	// We fetch one extra entry so that we know where the next page starts. Since Seek is inclusive of the start key,
+	// the extra entry is also the first entry of the next page.
+	dbSeekKey := GlobalStateKeyForReferralHashToReferralInfo([]byte(startReferralHash))
+	keysFound, valsFound, err := fes.GlobalState.Seek(
+		dbSeekKey, _GlobalStatePrefixReferralHashToReferralInfo, 0, pageSize+1, false /*reverse*/, true /*fetchValue*/)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("seekReferralInfoBytesPage: Problem seeking referral infos: %v", err)
+	}
+
+	prefixLen := len(_GlobalStatePrefixReferralHashToReferralInfo)
+	nextReferralHash := ""
+	if len(keysFound) > pageSize {
+		nextReferralHash = string(keysFound[pageSize][prefixLen:])
+		keysFound = keysFound[:pageSize]
+		valsFound = valsFound[:pageSize]
+	}
+
+	referralHashes := make([]string, 0, len(keysFound))
+	for _, keyBytes := range keysFound {
+		referralHashes = append(referralHashes, string(keyBytes[prefixLen:]))
+	}
+	return referralHashes, valsFound, nextReferralHash, nil
+}
+
+// getReferralInfosPage is the same as seekReferralInfoBytesPage but decodes the ReferralInfos. ReferralInfos that
+// fail to decode are logged and skipped.
+func (fes *APIServer) getReferralInfosPage(startReferralHash string, pageSize int) (
+	_referralInfos []ReferralInfo, _nextReferralHash string, _err error) {
+
+	referralHashes, valsFound, nextReferralHash, err := fes.seekReferralInfoBytesPage(startReferralHash, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
 
 	var referralInfos []ReferralInfo
 	for valIdx, valBytes := range valsFound {
 		referralInfo := ReferralInfo{}
 		if valBytes != nil && len(valBytes) != 0 {
-			err = gob.NewDecoder(bytes.NewReader(valBytes)).Decode(&referralInfo)
+			decodedReferralInfo, err := decodeReferralInfo(valBytes)
 			if err != nil {
 				glog.Errorf(
-					"ERROR: getReferralInfoResponsesForPubKey: Failed decoding referral info #%d: %v ; valBytes found: \"%v\"", valIdx, err, spew.Sdump(valBytes))
+					"ERROR: getReferralInfosPage: Failed decoding referral info (%s): %v ; valBytes found: \"%v\"",
+					referralHashes[valIdx], err, spew.Sdump(valBytes))
 				continue
 			}
+			referralInfo = *decodedReferralInfo
 		}
 
 		referralInfos = append(referralInfos, referralInfo)
 	}
 
-	return referralInfos, nil
+	return referralInfos, nextReferralHash, nil
 }
 
 func ReferralCSVHeaders() (_headers []string) {
 	return []string{
 		"ReferralHashBase58", "Username", "ReferrerPKIDBase58Check", "ReferrerAmountUSDCents", "RefereeAmountUSDCents",
 		"MaxReferrals", "RequiresJumio", "NumJumioAttempts", "NumJumioSuccesses", "TotalReferrerDeSoNanos",
-		"TotalRefereeDeSoNanos", "DateCreatedTStampNanos", "IsActive",
+		"TotalRefereeDeSoNanos", "DateCreatedTStampNanos", "IsActive", "ExpiresAtTStampNanos",
 	}
 }
 
-type AdminDownloadReferralCSVRequest struct{}
+type AdminDownloadReferralCSVRequest struct {
+	// Optional. If PageSize is set, only up to PageSize referral links starting from StartReferralHash (inclusive)
+	// are returned, capped at --max-page-size. Pass NextReferralHash from the previous response to get the next
+	// page. If PageSize is zero, every referral link is returned.
+	StartReferralHash string `safeForLogging:"true"`
+	PageSize          uint64 `safeForLogging:"true"`
+
+	// Optional. Either "json" (the default) for an AdminDownloadReferralCSVResponse or "csv" for a text/csv file
+	// with the ReferralCSVHeaders() columns. Sending an "Accept: text/csv" header also selects "csv". CSV pages
+	// return NextReferralHash in the X-Next-Referral-Hash header, and full CSV downloads are streamed.
+	Format string `safeForLogging:"true"`
+}
 
 type AdminDownloadReferralCSVResponse struct {
+	// Every page includes the header row.
 	CSVRows [][]string
+
+	// The referral hash to pass as StartReferralHash to get the next page. Empty once every referral link has
+	// been returned.
+	NextReferralHash string
 }
 
 func (fes *APIServer) AdminDownloadReferralCSV(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminDownloadReferralCSVRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -567,17 +1091,10 @@ func (fes *APIServer) AdminDownloadReferralCSV(ww http.ResponseWriter, req *http
 		return
 	}
 
-	// We create a list of rows that are constructed into a CSV on the frontend.
-	csvRows := [][]string{ReferralCSVHeaders()}
-
-	// We also track all the "status" keys so we can do a batch get at the end to figure out
-	// whether or not each referral link is active.
-	var activeStatusKeys [][]byte
-
-	referralInfos, err := fes.getAllReferralInfos()
+	isCSV, err := isCSVResponseFormat(req, requestData.Format)
 	if err != nil {
-		_AddInternalServerError(
-			ww, fmt.Sprintf("AdminDownloadReferralCSV: problem getting referralInfos: %v", err))
+		_AddBadRequestError(ww, fmt.Sprintf("AdminDownloadReferralCSV: %v", err))
+		return
 	}
 
 	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
@@ -586,59 +1103,56 @@ func (fes *APIServer) AdminDownloadReferralCSV(ww http.ResponseWriter, req *http
 		return
 	}
 
-	for _, referralInfo := range referralInfos {
-		profileEntry := utxoView.GetProfileEntryForPKID(referralInfo.ReferrerPKID)
-
-		usernameStr := ""
-		if profileEntry != nil {
-			usernameStr = string(profileEntry.Username)
-		}
-
-		nextRow := []string{}
-		nextRow = append(nextRow, referralInfo.ReferralHashBase58)
-		nextRow = append(nextRow, usernameStr)
-		nextRow = append(nextRow, lib.PkToString(lib.PKIDToPublicKey(referralInfo.ReferrerPKID), fes.Params))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.ReferrerAmountUSDCents, 10))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.RefereeAmountUSDCents, 10))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.MaxReferrals, 10))
-		nextRow = append(nextRow, strconv.FormatBool(referralInfo.RequiresJumio))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.NumJumioAttempts, 10))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.NumJumioSuccesses, 10))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.TotalReferrerDeSoNanos, 10))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.TotalRefereeDeSoNanos, 10))
-		nextRow = append(nextRow, strconv.FormatUint(referralInfo.DateCreatedTStampNanos, 10))
-		csvRows = append(csvRows, nextRow)
-
-		// Store this info to look up whether the link is active next.
-		referralHashBytes := []byte(referralInfo.ReferralHashBase58)
-		activeStatusKey := GlobalStateKeyForPKIDReferralHashToIsActive(referralInfo.ReferrerPKID, referralHashBytes)
-		activeStatusKeys = append(activeStatusKeys, activeStatusKey)
-	}
-
-	statusVals, err := fes.GlobalState.BatchGet(activeStatusKeys)
-	if err != nil {
-		_AddInternalServerError(
-			ww, fmt.Sprintf("AdminDownloadReferralCSV: problem getting referralInfo status: %v", err))
-	}
-	if len(statusVals) != len(csvRows)-1 {
-		_AddInternalServerError(ww, fmt.Sprintf(
-			"AdminDownloadReferralCSV: got incorrect number of statuses %d != %d",
-			len(statusVals), len(csvRows)-1))
+	// Full CSV downloads are streamed so that we never hold every referral link in memory.
+	if isCSV && requestData.PageSize == 0 {
+		fes.streamReferralCSV(req.Context(), ww, utxoView, acceptsGzip(req))
+		return
 	}
 
-	for statusValIdx, statusBytes := range statusVals {
-		status, err := lib.ReadBoolByte(bytes.NewReader(statusBytes))
+	// We create a list of rows that are constructed into a CSV on the frontend.
+	csvRows := [][]string{ReferralCSVHeaders()}
+	startReferralHash := requestData.StartReferralHash
+	pageSize := referralInfoPageSize
+	if requestData.PageSize != 0 {
+		pageSize = fes.getPageSize(requestData.PageSize)
+	}
+	var nextReferralHash string
+	for {
+		var referralInfos []ReferralInfo
+		referralInfos, nextReferralHash, err = fes.getReferralInfosPage(startReferralHash, pageSize)
 		if err != nil {
 			_AddInternalServerError(
-				ww, fmt.Sprintf("AdminDownloadReferralCSV: problem reading statusBytes with statusValIdx (%v)", statusValIdx))
+				ww, fmt.Sprintf("AdminDownloadReferralCSV: problem getting referralInfos: %v", err))
+			return
+		}
+
+		// Figure out whether or not each referral link is active.
+		statuses, err := fes.getReferralHashStatusesForReferralInfos(referralInfos)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("AdminDownloadReferralCSV: %v", err))
+			return
+		}
+		for ii, referralInfo := range referralInfos {
+			csvRows = append(csvRows, fes.buildReferralCSVRow(utxoView, &referralInfo, statuses[ii]))
+		}
+
+		// Full downloads keep going until we run out of referral links.
+		if requestData.PageSize != 0 || nextReferralHash == "" {
+			break
 		}
-		// Note we have to add one to the idx here since csvRows has a header.
-		csvRows[statusValIdx+1] = append(csvRows[statusValIdx+1], strconv.FormatBool(status))
+		startReferralHash = nextReferralHash
+	}
+
+	if isCSV {
+		ww.Header().Set("X-Next-Referral-Hash", nextReferralHash)
+		writeCSVAttachment(ww, req, "AdminDownloadReferralCSV", "referrals.csv", csvRows)
+		return
 	}
 
 	// If we made it this far we were successful, return without error.
 	res := AdminDownloadReferralCSVResponse{
-		CSVRows: csvRows,
+		CSVRows:          csvRows,
+		NextReferralHash: nextReferralHash,
 	}
 	if err := json.NewEncoder(ww).Encode(res); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -647,58 +1161,369 @@ func (fes *APIServer) AdminDownloadReferralCSV(ww http.ResponseWriter, req *http
 	}
 }
 
-func (fes *APIServer) updateOrCreateReferralInfoFromCSVRow(row []string) (_err error) {
-	// Sort out the referralHash.
-	referralInfo := ReferralInfo{}
-	if len(row[CSVColumnReferralHash]) == 0 {
-		// Generate a fresh referral hash for the new link.
-		referralHashBase58, err := generateNewReferralHash()
-		if err != nil {
-			return fmt.Errorf("updateOrCreateReferralInfoFromCSVRow: problem generating referral hash: %v", err)
-		}
-		referralInfo.ReferralHashBase58 = referralHashBase58
-	} else {
-		referralInfo.ReferralHashBase58 = row[CSVColumnReferralHash]
+// isCSVResponseFormat returns true if a download should be returned as a text/csv file rather than JSON. The format
+// field from the request body takes precedence over the Accept header.
+func isCSVResponseFormat(req *http.Request, format string) (_isCSV bool, _err error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "csv":
+		return true, nil
+	case "json":
+		return false, nil
+	case "":
+		return strings.Contains(req.Header.Get("Accept"), "text/csv"), nil
+	}
+	return false, fmt.Errorf("Invalid Format %q, must be either json or csv", format)
+}
 
-		// Since this is an existing referralInfo, we fetch it and copy it for the latest stats.
-		existingReferralInfo, err := fes.getInfoForReferralHashBase58(referralInfo.ReferralHashBase58)
-		if err != nil {
-			return fmt.Errorf(
-				"updateOrCreateReferralInfoFromCSVRow: error getting referral info (%s): %v",
-				referralInfo.ReferralHashBase58, err)
+// acceptsGzip returns true if the request's Accept-Encoding header allows a gzip-encoded response.
+func acceptsGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		encodingParams := strings.Split(encoding, ";")
+		if strings.TrimSpace(encodingParams[0]) != "gzip" {
+			continue
 		}
-		referralInfo = *existingReferralInfo
-	}
-
-	// Decode and fill the PKID.
+		// "gzip;q=0" means gzip is explicitly not acceptable.
+		for _, param := range encodingParams[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if quality, err := strconv.ParseFloat(param[len("q="):], 64); err == nil && quality == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// setCSVAttachmentHeaders sets the headers for a CSV file download named filename, gzip-encoded if the client
+// accepts it. Content-Encoding is undone by the client, so the file is still saved as plain CSV.
+func setCSVAttachmentHeaders(ww http.ResponseWriter, filename string, useGzip bool) {
+	ww.Header().Set("Content-Type", "text/csv")
+	ww.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ww.Header().Add("Vary", "Accept-Encoding")
+	if useGzip {
+		ww.Header().Set("Content-Encoding", "gzip")
+	}
+}
+
+// writeCSVAttachment writes rows to ww as a CSV file named filename, gzipped if the request accepts it. funcName is
+// used to label errors.
+func writeCSVAttachment(ww http.ResponseWriter, req *http.Request, funcName string, filename string, rows [][]string) {
+	var csvBuffer bytes.Buffer
+	if err := csv.NewWriter(&csvBuffer).WriteAll(rows); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("%s: Problem writing CSV: %v", funcName, err))
+		return
+	}
+	useGzip := acceptsGzip(req)
+	setCSVAttachmentHeaders(ww, filename, useGzip)
+	if !useGzip {
+		if _, err := ww.Write(csvBuffer.Bytes()); err != nil {
+			logRequestErrorf(req.Context(), "%s: Problem writing CSV response: %v", funcName, err)
+		}
+		return
+	}
+	gzipWriter := gzip.NewWriter(ww)
+	if _, err := gzipWriter.Write(csvBuffer.Bytes()); err != nil {
+		logRequestErrorf(req.Context(), "%s: Problem writing CSV response: %v", funcName, err)
+		return
+	}
+	if err := gzipWriter.Close(); err != nil {
+		logRequestErrorf(req.Context(), "%s: Problem writing CSV response: %v", funcName, err)
+	}
+}
+
+// streamReferralCSV writes every referral link to ww as a CSV file, one page of referral infos at a time. Once the
+// first row has been written we can no longer change the status code, so errors after that point are logged and
+// end the response early. If useGzip is set, each page is flushed through the gzip stream as it's written.
+func (fes *APIServer) streamReferralCSV(
+	ctx context.Context, ww http.ResponseWriter, utxoView *lib.UtxoView, useGzip bool,
+) {
+	referralInfos, nextReferralHash, err := fes.getReferralInfosPage("", referralInfoPageSize)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminDownloadReferralCSV: problem getting referralInfos: %v", err))
+		return
+	}
+
+	setCSVAttachmentHeaders(ww, "referrals.csv", useGzip)
+	var csvOutput io.Writer = ww
+	var gzipWriter *gzip.Writer
+	if useGzip {
+		// The gzip footer is only written once every row has been, so a download that ends early because of an
+		// error is rejected as truncated rather than saved as a partial file.
+		gzipWriter = gzip.NewWriter(ww)
+		csvOutput = gzipWriter
+	}
+	csvWriter := csv.NewWriter(csvOutput)
+	flusher, _ := ww.(http.Flusher)
+	if err = csvWriter.Write(ReferralCSVHeaders()); err != nil {
+		logRequestErrorf(ctx, "AdminDownloadReferralCSV: Problem writing CSV header: %v", err)
+		return
+	}
+
+	for {
+		statuses, err := fes.getReferralHashStatusesForReferralInfos(referralInfos)
+		if err != nil {
+			logRequestErrorf(ctx, "AdminDownloadReferralCSV: %v", err)
+			return
+		}
+		for ii, referralInfo := range referralInfos {
+			if err = csvWriter.Write(fes.buildReferralCSVRow(utxoView, &referralInfo, statuses[ii])); err != nil {
+				logRequestErrorf(ctx, "AdminDownloadReferralCSV: Problem writing CSV row: %v", err)
+				return
+			}
+		}
+		csvWriter.Flush()
+		if err = csvWriter.Error(); err != nil {
+			logRequestErrorf(ctx, "AdminDownloadReferralCSV: Problem flushing CSV rows: %v", err)
+			return
+		}
+		if gzipWriter != nil {
+			if err = gzipWriter.Flush(); err != nil {
+				logRequestErrorf(ctx, "AdminDownloadReferralCSV: Problem flushing gzip stream: %v", err)
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if nextReferralHash == "" {
+			if gzipWriter != nil {
+				if err = gzipWriter.Close(); err != nil {
+					logRequestErrorf(ctx, "AdminDownloadReferralCSV: Problem closing gzip stream: %v", err)
+				}
+			}
+			return
+		}
+		referralInfos, nextReferralHash, err = fes.getReferralInfosPage(nextReferralHash, referralInfoPageSize)
+		if err != nil {
+			logRequestErrorf(ctx, "AdminDownloadReferralCSV: problem getting referralInfos: %v", err)
+			return
+		}
+	}
+}
+
+// buildReferralCSVRow returns the ReferralCSVHeaders() columns for a referral link.
+func (fes *APIServer) buildReferralCSVRow(
+	utxoView *lib.UtxoView, referralInfo *ReferralInfo, isActive bool,
+) (_row []string) {
+	profileEntry := utxoView.GetProfileEntryForPKID(referralInfo.ReferrerPKID)
+
+	usernameStr := ""
+	if profileEntry != nil {
+		usernameStr = string(profileEntry.Username)
+	}
+
+	nextRow := []string{}
+	nextRow = append(nextRow, referralInfo.ReferralHashBase58)
+	nextRow = append(nextRow, usernameStr)
+	nextRow = append(nextRow, lib.PkToString(lib.PKIDToPublicKey(referralInfo.ReferrerPKID), fes.Params))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.ReferrerAmountUSDCents, 10))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.RefereeAmountUSDCents, 10))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.MaxReferrals, 10))
+	nextRow = append(nextRow, strconv.FormatBool(referralInfo.RequiresJumio))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.NumJumioAttempts, 10))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.NumJumioSuccesses, 10))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.TotalReferrerDeSoNanos, 10))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.TotalRefereeDeSoNanos, 10))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.DateCreatedTStampNanos, 10))
+	nextRow = append(nextRow, strconv.FormatBool(isActive))
+	nextRow = append(nextRow, strconv.FormatUint(referralInfo.ExpiresAtTStampNanos, 10))
+	return nextRow
+}
+
+// getReferralHashStatusesForReferralInfos batch gets whether each referral link is active. The returned statuses are
+// in the same order as referralInfos. Statuses are fetched referralInfoPageSize at a time so that a large list of
+// referral infos doesn't turn into one giant BatchGet.
+func (fes *APIServer) getReferralHashStatusesForReferralInfos(referralInfos []ReferralInfo) (_statuses []bool, _err error) {
+	statuses := make([]bool, 0, len(referralInfos))
+	for chunkStartIdx := 0; chunkStartIdx < len(referralInfos); chunkStartIdx += referralInfoPageSize {
+		chunkEndIdx := chunkStartIdx + referralInfoPageSize
+		if chunkEndIdx > len(referralInfos) {
+			chunkEndIdx = len(referralInfos)
+		}
+
+		var activeStatusKeys [][]byte
+		for _, referralInfo := range referralInfos[chunkStartIdx:chunkEndIdx] {
+			referralHashBytes := []byte(referralInfo.ReferralHashBase58)
+			activeStatusKey := GlobalStateKeyForPKIDReferralHashToIsActive(referralInfo.ReferrerPKID, referralHashBytes)
+			activeStatusKeys = append(activeStatusKeys, activeStatusKey)
+		}
+
+		statusVals, err := fes.GlobalState.BatchGet(activeStatusKeys)
+		if err != nil {
+			return nil, fmt.Errorf("problem getting referralInfo status: %v", err)
+		}
+		if len(statusVals) != len(activeStatusKeys) {
+			return nil, fmt.Errorf("got incorrect number of statuses %d != %d", len(statusVals), len(activeStatusKeys))
+		}
+
+		for statusValIdx, statusBytes := range statusVals {
+			status, err := lib.ReadBoolByte(bytes.NewReader(statusBytes))
+			if err != nil {
+				return nil, fmt.Errorf(
+					"problem reading statusBytes with statusValIdx (%v)", chunkStartIdx+statusValIdx)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+type AdminGetAllReferralInfoRequest struct {
+	// The referral hash to start from (inclusive). Leave empty to start from the beginning, and pass
+	// NextReferralHash from the previous response to get the next page.
+	StartReferralHash string `safeForLogging:"true"`
+	// Maximum number of referral links to return. Defaults to --default-page-size, capped at --max-page-size.
+	PageSize uint64 `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminReferralInfoResponse struct {
+	ReferralInfoResponse
+
+	ReferrerPublicKeyBase58Check string
+	ReferrerUsername             string
+}
+
+type AdminGetAllReferralInfoResponse struct {
+	ReferralInfoResponses []AdminReferralInfoResponse
+
+	// The referral hash to pass as StartReferralHash to get the next page. Empty once every referral link has
+	// been returned.
+	NextReferralHash string
+}
+
+// AdminGetAllReferralInfo returns a page of referral links as JSON. This contains the same data as
+// AdminDownloadReferralCSV without converting everything to strings.
+func (fes *APIServer) AdminGetAllReferralInfo(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetAllReferralInfoRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfo: Problem parsing request body: %v", err))
+		return
+	}
+
+	referralInfos, nextReferralHash, err := fes.getReferralInfosPage(
+		requestData.StartReferralHash, fes.getPageSize(requestData.PageSize))
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetAllReferralInfo: problem getting referralInfos: %v", err))
+		return
+	}
+	statuses, err := fes.getReferralHashStatusesForReferralInfos(referralInfos)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetAllReferralInfo: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetAllReferralInfo: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	res := AdminGetAllReferralInfoResponse{
+		ReferralInfoResponses: []AdminReferralInfoResponse{},
+		NextReferralHash:      nextReferralHash,
+	}
+	for ii, referralInfo := range referralInfos {
+		referralInfoResponse := AdminReferralInfoResponse{
+			ReferralInfoResponse: ReferralInfoResponse{
+				IsActive: statuses[ii],
+				Info:     referralInfo,
+			},
+		}
+		if referralInfo.ReferrerPKID != nil {
+			referralInfoResponse.ReferrerPublicKeyBase58Check = lib.PkToString(
+				utxoView.GetPublicKeyForPKID(referralInfo.ReferrerPKID), fes.Params)
+			if profileEntry := utxoView.GetProfileEntryForPKID(referralInfo.ReferrerPKID); profileEntry != nil {
+				referralInfoResponse.ReferrerUsername = string(profileEntry.Username)
+			}
+		}
+		res.ReferralInfoResponses = append(res.ReferralInfoResponses, referralInfoResponse)
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllReferralInfo: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// normalizeReferralCSVRow strips the whitespace from each column in the row.
+func normalizeReferralCSVRow(row []string) {
+	for ii := range row {
+		row[ii] = strings.TrimSpace(row[ii])
+	}
+}
+
+// validateReferralCSVRowCount returns an error if a referral CSV has more rows than the configured
+// MaxReferralCSVRows. A limit of zero means there is no limit.
+func (fes *APIServer) validateReferralCSVRowCount(numRows int) error {
+	if fes.Config.MaxReferralCSVRows > 0 && uint64(numRows) > fes.Config.MaxReferralCSVRows {
+		return fmt.Errorf("CSV has %d rows, which exceeds the maximum of %d rows", numRows, fes.Config.MaxReferralCSVRows)
+	}
+	return nil
+}
+
+// validateReferralCSVRowShape checks that a normalized row has the expected number of columns, that the header row
+// matches ReferralCSVHeaders, and that any referral hash provided is a reasonable length. It does not parse the
+// contents of the row; see parseReferralCSVRow.
+func validateReferralCSVRowShape(rowIdx int, row []string) error {
+	// All of the rows should have the same length. The ExpiresAtTStampNanos column is optional.
+	if len(row) < CSVColumnExpiresAtTStampNanos {
+		return fmt.Errorf("Unexpected number of columns (%d) at rowIdx %d", len(row), rowIdx)
+	}
+	if rowIdx == 0 {
+		headers := ReferralCSVHeaders()
+		if !reflect.DeepEqual(row, headers) && !reflect.DeepEqual(row, headers[:CSVColumnExpiresAtTStampNanos]) {
+			return fmt.Errorf("Unexpected column headers")
+		}
+		return nil
+	}
+	// Make sure the referralHash is reasonable, if provided.
+	if len(row[CSVColumnReferralHash]) != 8 && len(row[CSVColumnReferralHash]) != 0 {
+		return fmt.Errorf("Unexpected referralHash length (%d) at rowIdx %d", len(row[CSVColumnReferralHash]), rowIdx)
+	}
+	return nil
+}
+
+// parseReferralCSVRow parses the columns of a referral CSV row that are editable via upload. The returned
+// ReferralInfo only has ReferralHashBase58 (which may be empty), ReferrerPKID, the amounts, MaxReferrals,
+// RequiresJumio, DateCreatedTStampNanos, and ExpiresAtTStampNanos set. This does not read from or write to global state.
+func parseReferralCSVRow(row []string) (_referralInfo *ReferralInfo, _isActive bool, _err error) {
+	referralInfo := &ReferralInfo{
+		ReferralHashBase58: row[CSVColumnReferralHash],
+	}
+
+	// Decode and fill the PKID.
 	var err error
 	pkBytes, _, err := lib.Base58CheckDecode(row[CSVColumnPKID])
 	if err != nil || len(pkBytes) != btcec.PubKeyBytesLenCompressed {
-		return fmt.Errorf(
-			"updateOrCreateReferralInfoFromCSVRow: Problem decoding pkid %s: %v", row[1], err)
+		return nil, false, fmt.Errorf("Problem decoding pkid %s: %v", row[CSVColumnPKID], err)
 	}
 	referralInfo.ReferrerPKID = lib.PublicKeyToPKID(pkBytes)
 
-	// Update the non-stats elements of the ReferralInfo.
 	referralInfo.ReferrerAmountUSDCents, err = strconv.ParseUint(row[CSVColumnReferrerAmount], 10, 64)
 	if err != nil {
-		return fmt.Errorf(
-			"updateOrCreateReferralInfoFromCSVRow: error parsing referrer amount (%s): %v", row[2], err)
+		return nil, false, fmt.Errorf("error parsing referrer amount (%s): %v", row[CSVColumnReferrerAmount], err)
 	}
 	referralInfo.RefereeAmountUSDCents, err = strconv.ParseUint(row[CSVColumnRefereeAmount], 10, 64)
 	if err != nil {
-		return fmt.Errorf(
-			"updateOrCreateReferralInfoFromCSVRow: error parsing refereer amount (%s): %v", row[3], err)
+		return nil, false, fmt.Errorf("error parsing refereer amount (%s): %v", row[CSVColumnRefereeAmount], err)
 	}
 	referralInfo.MaxReferrals, err = strconv.ParseUint(row[CSVColumnMaxReferrals], 10, 64)
 	if err != nil {
-		return fmt.Errorf(
-			"updateOrCreateReferralInfoFromCSVRow: error parsing max referrals (%s): %v", row[4], err)
+		return nil, false, fmt.Errorf("error parsing max referrals (%s): %v", row[CSVColumnMaxReferrals], err)
 	}
 	referralInfo.RequiresJumio, err = strconv.ParseBool(row[CSVColumnRequiresJumio])
 	if err != nil {
-		return fmt.Errorf(
-			"updateOrCreateReferralInfoFromCSVRow: error parsing requires jumio (%s): %v", row[4], err)
+		return nil, false, fmt.Errorf("error parsing requires jumio (%s): %v", row[CSVColumnRequiresJumio], err)
 	}
 
 	tstampNanos := uint64(time.Now().UnixNano())
@@ -706,45 +1531,271 @@ func (fes *APIServer) updateOrCreateReferralInfoFromCSVRow(row []string) (_err e
 		var tstampFloat float64
 		tstampFloat, err = strconv.ParseFloat(row[CSVColumnTstampNanos], 10)
 		if err != nil {
-			return fmt.Errorf(
-				"updateOrCreateReferralInfoFromCSVRow: error parsing tstamp nanos (%s): %v", row[10], err)
+			return nil, false, fmt.Errorf("error parsing tstamp nanos (%s): %v", row[CSVColumnTstampNanos], err)
 		}
 		tstampNanos = uint64(tstampFloat)
 	}
 	referralInfo.DateCreatedTStampNanos = tstampNanos
 
-	// Set the updated referral info.
-	err = fes.putReferralHashWithInfo(referralInfo.ReferralHashBase58, &referralInfo)
-	if err != nil {
-		return fmt.Errorf(
-			"updateOrCreateReferralInfoFromCSVRow: problem putting referral info (%s): %v",
-			referralInfo.ReferralHashBase58, err)
+	// An empty or missing expiration means the link never expires.
+	if len(row) > CSVColumnExpiresAtTStampNanos && len(row[CSVColumnExpiresAtTStampNanos]) > 0 {
+		referralInfo.ExpiresAtTStampNanos, err = strconv.ParseUint(row[CSVColumnExpiresAtTStampNanos], 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf(
+				"error parsing expires at tstamp nanos (%s): %v", row[CSVColumnExpiresAtTStampNanos], err)
+		}
 	}
 
-	// Figure out the links "IsActive" status and then set it.
+	// Figure out the links "IsActive" status.
 	isActive := true
 	if len(row[CSVColumnIsActive]) > 0 {
 		isActive, err = strconv.ParseBool(row[CSVColumnIsActive])
 		if err != nil {
-			return fmt.Errorf(
-				"updateOrCreateReferralInfoFromCSVRow: error parsing requires jumio (%s): %v", row[4], err)
+			return nil, false, fmt.Errorf("error parsing is active (%s): %v", row[CSVColumnIsActive], err)
+		}
+	}
+
+	return referralInfo, isActive, nil
+}
+
+// The number of CSV rows whose writes are accumulated before AdminUploadReferralCSV flushes them with a single
+// GlobalState.BatchPut. Each row is two writes: the ReferralInfo and the referrer's status entry.
+const referralCSVBatchPutSize = 100
+
+// referralCSVBatchWriter accumulates the global state writes for referral CSV rows so that they can be flushed with
+// one BatchPut instead of two Puts per row. Rows that update an existing referral hash are applied to the latest
+// copy of its ReferralInfo at flush time, under that hash's lock, so that a concurrent stats update isn't
+// overwritten by a stale copy waiting in the batch.
+type referralCSVBatchWriter struct {
+	fes *APIServer
+
+	kvPairs []KVPair
+	numRows int
+	// The non-stats updates to apply to the existing referral hashes in this batch when it's flushed.
+	existingReferralHashUpdates map[string]func(referralInfo *ReferralInfo)
+	// Referral hashes generated for new links in this batch. They aren't in global state until the batch is flushed.
+	newReferralHashes map[string]bool
+	// If set, rows whose referrer doesn't have a profile in this view are rejected.
+	referrerUtxoView *lib.UtxoView
+}
+
+func (fes *APIServer) newReferralCSVBatchWriter(referrerUtxoView *lib.UtxoView) *referralCSVBatchWriter {
+	return &referralCSVBatchWriter{
+		fes:                         fes,
+		referrerUtxoView:            referrerUtxoView,
+		existingReferralHashUpdates: make(map[string]func(referralInfo *ReferralInfo)),
+		newReferralHashes:           make(map[string]bool),
+	}
+}
+
+// referralHashExists also treats referral hashes generated earlier in the batch as taken, ignoring case like
+// fes.referralHashExists.
+func (writer *referralCSVBatchWriter) referralHashExists(referralHashBase58 string) (_exists bool, _err error) {
+	for newReferralHash := range writer.newReferralHashes {
+		if strings.EqualFold(newReferralHash, referralHashBase58) {
+			return true, nil
+		}
+	}
+	return writer.fes.referralHashExists(referralHashBase58)
+}
+
+// hasQueuedReferralHash returns true if a row already queued in this batch writes to the referral hash. Callers
+// should flush before adding another row for it so that it's read back with the queued changes applied.
+func (writer *referralCSVBatchWriter) hasQueuedReferralHash(referralHashBase58 string) bool {
+	_, isExisting := writer.existingReferralHashUpdates[referralHashBase58]
+	return isExisting || writer.newReferralHashes[referralHashBase58]
+}
+
+// checkReferrerHasProfile returns an error if the referrer doesn't have a profile, which usually means the public key
+// in the CSV has a typo.
+func checkReferrerHasProfile(utxoView *lib.UtxoView, referrerPKID *lib.PKID, referrerPublicKeyBase58Check string) error {
+	profileEntry := utxoView.GetProfileEntryForPKID(referrerPKID)
+	if profileEntry == nil || profileEntry.IsDeleted() {
+		return fmt.Errorf("referrer %s does not have a profile", referrerPublicKeyBase58Check)
+	}
+	return nil
+}
+
+// resolveRowReferralHash replaces the row's referral hash with the stored hash it resolves to, so that the batch's
+// dedup check, the keys it writes, and the lock taken when it's flushed all use the same hash as
+// updateReferralInfoForReferralHash rather than however the CSV cased it. Rows for new links are left alone.
+func (writer *referralCSVBatchWriter) resolveRowReferralHash(row []string) (_err error) {
+	if len(row[CSVColumnReferralHash]) == 0 {
+		return nil
+	}
+	referralHashBase58, err := writer.fes.resolveReferralHashBase58(row[CSVColumnReferralHash])
+	if err != nil {
+		return fmt.Errorf("resolveRowReferralHash: %v", err)
+	}
+	row[CSVColumnReferralHash] = referralHashBase58
+	return nil
+}
+
+// addRow parses a CSV row and queues its writes. Nothing is queued if the row fails. The row's referral hash must
+// already be resolved with resolveRowReferralHash. Callers are responsible for flushing, see hasQueuedReferralHash.
+func (writer *referralCSVBatchWriter) addRow(row []string) (_err error) {
+	parsedReferralInfo, isActive, err := parseReferralCSVRow(row)
+	if err != nil {
+		return fmt.Errorf("addRow: %v", err)
+	}
+	if writer.referrerUtxoView != nil {
+		if err = checkReferrerHasProfile(
+			writer.referrerUtxoView, parsedReferralInfo.ReferrerPKID, row[CSVColumnPKID]); err != nil {
+			return fmt.Errorf("addRow: %v", err)
+		}
+	}
+
+	// Update the non-stats elements of the ReferralInfo.
+	updateNonStatsFields := func(referralInfo *ReferralInfo) {
+		referralInfo.ReferrerPKID = parsedReferralInfo.ReferrerPKID
+		referralInfo.ReferrerAmountUSDCents = parsedReferralInfo.ReferrerAmountUSDCents
+		referralInfo.RefereeAmountUSDCents = parsedReferralInfo.RefereeAmountUSDCents
+		referralInfo.MaxReferrals = parsedReferralInfo.MaxReferrals
+		referralInfo.RequiresJumio = parsedReferralInfo.RequiresJumio
+		referralInfo.DateCreatedTStampNanos = parsedReferralInfo.DateCreatedTStampNanos
+		referralInfo.ExpiresAtTStampNanos = parsedReferralInfo.ExpiresAtTStampNanos
+	}
+
+	// Sort out the referralHash.
+	referralHashBase58 := parsedReferralInfo.ReferralHashBase58
+	var kvPairs []KVPair
+	if len(referralHashBase58) == 0 {
+		// Generate a fresh referral hash for the new link.
+		referralHashBase58, err = generateNewReferralHash(writer.referralHashExists)
+		if err != nil {
+			return fmt.Errorf("addRow: problem generating referral hash: %v", err)
+		}
+		referralInfo := &ReferralInfo{ReferralHashBase58: referralHashBase58}
+		updateNonStatsFields(referralInfo)
+		referralInfoBytes, err := encodeReferralInfo(referralInfo)
+		if err != nil {
+			return fmt.Errorf("addRow: problem encoding referral info (%s): %v", referralHashBase58, err)
+		}
+		kvPairs = append(kvPairs, KVPair{
+			Key:   GlobalStateKeyForReferralHashToReferralInfo([]byte(referralHashBase58)),
+			Value: referralInfoBytes,
+		})
+	} else {
+		if writer.hasQueuedReferralHash(referralHashBase58) {
+			return fmt.Errorf("addRow: referral hash %s is already queued in this batch", referralHashBase58)
+		}
+
+		// Make sure the referral hash exists now so that the row fails on its own. Its ReferralInfo is read again
+		// when the batch is flushed so that we keep the latest stats.
+		if _, err = writer.fes.getInfoForReferralHashBase58(referralHashBase58); err != nil {
+			return fmt.Errorf("addRow: problem updating referral info (%s): %v", referralHashBase58, err)
+		}
+	}
+
+	indexKVPairs, err := writer.fes.getReferralHashIndexKVPairs(referralHashBase58)
+	if err != nil {
+		return fmt.Errorf("addRow: %v", err)
+	}
+	kvPairs = append(kvPairs, KVPair{
+		Key:   GlobalStateKeyForPKIDReferralHashToIsActive(parsedReferralInfo.ReferrerPKID, []byte(referralHashBase58)),
+		Value: []byte{lib.BoolToByte(isActive)},
+	})
+	writer.kvPairs = append(writer.kvPairs, kvPairs...)
+	writer.kvPairs = append(writer.kvPairs, indexKVPairs...)
+	if len(parsedReferralInfo.ReferralHashBase58) == 0 {
+		writer.newReferralHashes[referralHashBase58] = true
+	} else {
+		writer.existingReferralHashUpdates[referralHashBase58] = updateNonStatsFields
+	}
+	writer.numRows++
+
+	return nil
+}
+
+// flush writes every queued row. The existing referral hashes in the batch are locked in sorted order while their
+// latest ReferralInfos are read, updated, and written, so that two uploads touching the same hashes can't deadlock.
+func (writer *referralCSVBatchWriter) flush() (_err error) {
+	defer func() {
+		writer.kvPairs = nil
+		writer.numRows = 0
+		writer.existingReferralHashUpdates = make(map[string]func(referralInfo *ReferralInfo))
+		writer.newReferralHashes = make(map[string]bool)
+	}()
+
+	existingReferralHashes := make([]string, 0, len(writer.existingReferralHashUpdates))
+	for referralHashBase58 := range writer.existingReferralHashUpdates {
+		existingReferralHashes = append(existingReferralHashes, referralHashBase58)
+	}
+	sort.Strings(existingReferralHashes)
+
+	kvPairs := writer.kvPairs
+	for _, referralHashBase58 := range existingReferralHashes {
+		defer writer.fes.lockReferralHash(referralHashBase58)()
+
+		referralInfo, err := writer.fes.getInfoForReferralHashBase58(referralHashBase58)
+		if err != nil {
+			return fmt.Errorf("flush: problem updating referral info (%s): %v", referralHashBase58, err)
+		}
+		writer.existingReferralHashUpdates[referralHashBase58](referralInfo)
+		referralInfoBytes, err := encodeReferralInfo(referralInfo)
+		if err != nil {
+			return fmt.Errorf("flush: problem encoding referral info (%s): %v", referralHashBase58, err)
 		}
+		kvPairs = append(kvPairs, KVPair{
+			Key:   GlobalStateKeyForReferralHashToReferralInfo([]byte(referralHashBase58)),
+			Value: referralInfoBytes,
+		})
 	}
-	fes.setReferralHashStatusForPKID(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58, isActive)
 
+	if err := writer.fes.GlobalState.BatchPut(kvPairs); err != nil {
+		return fmt.Errorf("flush: problem writing %d rows: %v", writer.numRows, err)
+	}
 	return nil
 }
 
 type AdminUploadReferralCSVRequest struct {
 	CSVRows [][]string
+
+	// Sent as a "ContinueOnError" form value. If true, rows that fail to apply are reported in RowErrors and the
+	// remaining rows are still applied. Otherwise the upload stops at the first row that fails.
+	ContinueOnError bool
+
+	// Sent as an "AllowReferrersWithoutProfiles" form value. By default rows are rejected if the referrer doesn't
+	// have a profile. Set this if referrers are anonymous public keys.
+	AllowReferrersWithoutProfiles bool
 }
 
 type AdminUploadReferralCSVResponse struct {
 	LinksCreated uint64
 	LinksUpdated uint64
+
+	// Only populated if ContinueOnError was set.
+	RowErrors []ReferralCSVRowIssue
+}
+
+// maxDecompressedReferralCSVSizeBytes caps how large a gzipped referral CSV upload can be once decompressed, so a
+// small upload can't expand to fill memory when it's read.
+const maxDecompressedReferralCSVSizeBytes = 256 << 20
+
+// isGzipReferralCSVUpload returns true if an uploaded referral CSV is gzipped, as indicated by the part's
+// Content-Encoding header or a .csv.gz file name.
+func isGzipReferralCSVUpload(fileHeader *multipart.FileHeader) bool {
+	return strings.EqualFold(fileHeader.Header.Get("Content-Encoding"), "gzip") ||
+		strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".csv.gz")
+}
+
+// readAllWithMaxBytes reads all of reader, failing if it has more than maxBytes. It reads one byte past the limit
+// so that input past it isn't mistaken for shorter input.
+func readAllWithMaxBytes(reader io.Reader, maxBytes int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("Input is larger than the maximum of %d bytes", maxBytes)
+	}
+	return data, nil
 }
 
 func (fes *APIServer) AdminUploadReferralCSV(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
 	err := req.ParseMultipartForm(10 << 20)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: Problem parsing multipart form data: %v", err))
@@ -763,6 +1814,24 @@ func (fes *APIServer) AdminUploadReferralCSV(ww http.ResponseWriter, req *http.R
 		return
 	}
 	userPublicKey := userPublicKeys[0]
+	continueOnError := false
+	if continueOnErrorVals := req.Form["ContinueOnError"]; len(continueOnErrorVals) > 0 {
+		continueOnError, err = strconv.ParseBool(continueOnErrorVals[0])
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"AdminUploadReferralCSV: Problem parsing ContinueOnError (%s): %v", continueOnErrorVals[0], err))
+			return
+		}
+	}
+	allowReferrersWithoutProfiles := false
+	if allowVals := req.Form["AllowReferrersWithoutProfiles"]; len(allowVals) > 0 {
+		allowReferrersWithoutProfiles, err = strconv.ParseBool(allowVals[0])
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"AdminUploadReferralCSV: Problem parsing AllowReferrersWithoutProfiles (%s): %v", allowVals[0], err))
+			return
+		}
+	}
 	isValid, err := fes.ValidateJWT(userPublicKey, JWT)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: Error validating JWT: %v", err))
@@ -796,70 +1865,116 @@ func (fes *APIServer) AdminUploadReferralCSV(ww http.ResponseWriter, req *http.R
 		_AddBadRequestError(ww, fmt.Sprint("AdminUploadReferralCSV: File is nil"))
 		return
 	}
-	if contentType := fileHeader.Header.Get("Content-Type"); contentType != "text/csv" {
+	isGzipped := isGzipReferralCSVUpload(fileHeader)
+	if contentType := fileHeader.Header.Get("Content-Type"); contentType != "text/csv" &&
+		!(isGzipped && (contentType == "application/gzip" || contentType == "application/x-gzip")) {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: Invalid content type for file: %s",
 			contentType))
 		return
 	}
 
-	csvReader := csv.NewReader(file)
+	var csvFile io.Reader = file
+	if isGzipped {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: Problem decompressing file: %v", err))
+			return
+		}
+		defer gzipReader.Close()
+		csvBytes, err := readAllWithMaxBytes(gzipReader, maxDecompressedReferralCSVSizeBytes)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: Problem decompressing file: %v", err))
+			return
+		}
+		csvFile = bytes.NewReader(csvBytes)
+	}
+	csvReader := csv.NewReader(csvFile)
 	rows, err := csvReader.ReadAll()
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: Error reading CSV: %v", err))
 		return
 	}
+	if err = fes.validateReferralCSVRowCount(len(rows)); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: %v", err))
+		return
+	}
+
+	// Check the shape of every row, including the referral hash lengths, before doing any writes.
+	for rowIdx, row := range rows {
+		// Strip the whitespace from each string in the column
+		normalizeReferralCSVRow(row)
+
+		if err = validateReferralCSVRowShape(rowIdx, row); err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("AdminUploadReferralCSV: %v", err))
+			return
+		}
+	}
 
 	numLinksCreated := uint64(0)
 	numLinksUpdated := uint64(0)
+	var rowErrors []ReferralCSVRowIssue
 
-	// Iterate over the rows and and collect updated+created referralInfos.
-	for rowIdx, row := range rows {
-		// All of the rows should have the same length.
-		if len(row) < 11 {
-			_AddBadRequestError(ww, fmt.Sprintf(
-				"AdminUploadReferralCSV: Unexpected number of columns (%d) at rowIdx %d", len(row), rowIdx))
+	// Fetch the view used to check referrer profiles once rather than once per row.
+	var referrerUtxoView *lib.UtxoView
+	if !allowReferrersWithoutProfiles {
+		referrerUtxoView, err = fes.mempool.GetAugmentedUniversalView()
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("AdminUploadReferralCSV: Problem getting utxoView: %v", err))
 			return
 		}
+	}
 
-		// Strip the whitespace from each string in the column
-		for ii := range row {
-			row[ii] = strings.TrimSpace(row[ii])
+	// Iterate over the rows and and collect updated+created referralInfos. Writes are flushed in batches.
+	batchWriter := fes.newReferralCSVBatchWriter(referrerUtxoView)
+	for rowIdx, row := range rows {
+		if rowIdx == 0 {
+			continue
 		}
 
-		if rowIdx == 0 {
-			expectedHeaders := ReferralCSVHeaders()
-			if !reflect.DeepEqual(row, expectedHeaders) {
-				_AddBadRequestError(ww, fmt.Sprintf(
-					"AdminUploadReferralCSV: Unexpected column headers"))
-				return
-			}
-		} else {
-			// Make sure the referralHash is reasonable, if provided.
-			if len(row[CSVColumnReferralHash]) != 8 && len(row[CSVColumnReferralHash]) != 0 {
-				_AddBadRequestError(ww, fmt.Sprintf(
-					"AdminUploadReferralCSV: Unexpected referralHash length (%d) at rowIdx %d", len(row[0]), rowIdx))
-				return
+		err = batchWriter.resolveRowReferralHash(row)
+		if err == nil {
+			// Flush if the batch is full or already has a write for this row's referral hash.
+			if batchWriter.numRows >= referralCSVBatchPutSize ||
+				batchWriter.hasQueuedReferralHash(row[CSVColumnReferralHash]) {
+				if err = batchWriter.flush(); err != nil {
+					_AddInternalServerError(ww, fmt.Sprintf(
+						"AdminUploadReferralCSV: Problem writing rows before idx %d: %v", rowIdx, err))
+					return
+				}
 			}
 
-			if err = fes.updateOrCreateReferralInfoFromCSVRow(row); err != nil {
-				_AddInternalServerError(ww, fmt.Sprintf(
-					"AdminUploadReferralCSV: Problem updating idx %d: %v", rowIdx, err))
-				return
+			err = batchWriter.addRow(row)
+		}
+		if err != nil {
+			if continueOnError {
+				rowErrors = append(rowErrors, ReferralCSVRowIssue{RowIdx: rowIdx, Error: err.Error()})
+				continue
 			}
-
-			if len(row[CSVColumnReferralHash]) == 0 {
-				numLinksCreated++
-			} else {
-				numLinksUpdated++
+			// Rows before the failing one are still applied, as they would have been without batching.
+			if flushErr := batchWriter.flush(); flushErr != nil {
+				logRequestErrorf(req.Context(), "AdminUploadReferralCSV: Problem flushing rows before idx %d: %v", rowIdx, flushErr)
 			}
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminUploadReferralCSV: Problem updating idx %d: %v", rowIdx, err))
+			return
 		}
 
+		if len(row[CSVColumnReferralHash]) == 0 {
+			numLinksCreated++
+		} else {
+			numLinksUpdated++
+		}
+	}
+	if err = batchWriter.flush(); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminUploadReferralCSV: Problem writing rows: %v", err))
+		return
 	}
 
 	// If we made it this far we were successful, return without error.
 	res := AdminUploadReferralCSVResponse{
 		LinksCreated: numLinksCreated,
 		LinksUpdated: numLinksUpdated,
+		RowErrors:    rowErrors,
 	}
 	if err := json.NewEncoder(ww).Encode(res); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -868,32 +1983,233 @@ func (fes *APIServer) AdminUploadReferralCSV(ww http.ResponseWriter, req *http.R
 	}
 }
 
-func RefereeCSVHeaders() (_headers []string) {
-	// Note that we limit counts to 25 so that we don't have to fetch as much data.
-	return []string{
+type AdminValidateReferralRowsRequest struct {
+	// Rows in the same format as the file accepted by AdminUploadReferralCSV, including the header row.
+	CSVRows [][]string
+
+	// Skips the referrer profile check, see AdminUploadReferralCSVRequest.
+	AllowReferrersWithoutProfiles bool
+}
+
+type ReferralCSVRowIssue struct {
+	RowIdx int
+	Error  string
+}
+
+type AdminValidateReferralRowsResponse struct {
+	// The rows with whitespace stripped and parsed values re-encoded in the format AdminDownloadReferralCSV uses.
+	// Rows that fail validation are included as-is after stripping whitespace.
+	NormalizedCSVRows [][]string
+	RowIssues         []ReferralCSVRowIssue
+
+	LinksToCreate uint64
+	LinksToUpdate uint64
+	IsValid       bool
+}
+
+// AdminValidateReferralRows runs the same validation as AdminUploadReferralCSV against rows provided in the request
+// body without writing anything to global state.
+func (fes *APIServer) AdminValidateReferralRows(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminValidateReferralRowsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminValidateReferralRows: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.CSVRows) == 0 {
+		_AddBadRequestError(ww, "AdminValidateReferralRows: CSVRows must include a header row")
+		return
+	}
+	if err := fes.validateReferralCSVRowCount(len(requestData.CSVRows)); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminValidateReferralRows: %v", err))
+		return
+	}
+
+	var referrerUtxoView *lib.UtxoView
+	if !requestData.AllowReferrersWithoutProfiles {
+		var err error
+		referrerUtxoView, err = fes.mempool.GetAugmentedUniversalView()
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("AdminValidateReferralRows: Problem getting utxoView: %v", err))
+			return
+		}
+	}
+
+	res := AdminValidateReferralRowsResponse{
+		NormalizedCSVRows: [][]string{},
+		RowIssues:         []ReferralCSVRowIssue{},
+	}
+	for rowIdx, row := range requestData.CSVRows {
+		normalizeReferralCSVRow(row)
+		res.NormalizedCSVRows = append(res.NormalizedCSVRows, row)
+
+		if err := validateReferralCSVRowShape(rowIdx, row); err != nil {
+			res.RowIssues = append(res.RowIssues, ReferralCSVRowIssue{RowIdx: rowIdx, Error: err.Error()})
+			continue
+		}
+		if rowIdx == 0 {
+			continue
+		}
+
+		referralInfo, isActive, err := parseReferralCSVRow(row)
+		if err != nil {
+			res.RowIssues = append(res.RowIssues, ReferralCSVRowIssue{RowIdx: rowIdx, Error: err.Error()})
+			continue
+		}
+		if referrerUtxoView != nil {
+			if err = checkReferrerHasProfile(referrerUtxoView, referralInfo.ReferrerPKID, row[CSVColumnPKID]); err != nil {
+				res.RowIssues = append(res.RowIssues, ReferralCSVRowIssue{RowIdx: rowIdx, Error: err.Error()})
+				continue
+			}
+		}
+		if len(referralInfo.ReferralHashBase58) == 0 {
+			res.LinksToCreate++
+		} else {
+			// Updates require the referral hash to already exist.
+			if _, err = fes.getInfoForReferralHashBase58(referralInfo.ReferralHashBase58); err != nil {
+				res.RowIssues = append(res.RowIssues, ReferralCSVRowIssue{
+					RowIdx: rowIdx,
+					Error:  fmt.Sprintf("error getting referral info (%s): %v", referralInfo.ReferralHashBase58, err),
+				})
+				continue
+			}
+			res.LinksToUpdate++
+		}
+
+		normalizedRow := append([]string{}, row...)
+		normalizedRow[CSVColumnReferrerAmount] = strconv.FormatUint(referralInfo.ReferrerAmountUSDCents, 10)
+		normalizedRow[CSVColumnRefereeAmount] = strconv.FormatUint(referralInfo.RefereeAmountUSDCents, 10)
+		normalizedRow[CSVColumnMaxReferrals] = strconv.FormatUint(referralInfo.MaxReferrals, 10)
+		normalizedRow[CSVColumnRequiresJumio] = strconv.FormatBool(referralInfo.RequiresJumio)
+		if len(row[CSVColumnTstampNanos]) > 0 {
+			normalizedRow[CSVColumnTstampNanos] = strconv.FormatUint(referralInfo.DateCreatedTStampNanos, 10)
+		}
+		normalizedRow[CSVColumnIsActive] = strconv.FormatBool(isActive)
+		if len(row) > CSVColumnExpiresAtTStampNanos {
+			normalizedRow[CSVColumnExpiresAtTStampNanos] = strconv.FormatUint(referralInfo.ExpiresAtTStampNanos, 10)
+		}
+		res.NormalizedCSVRows[rowIdx] = normalizedRow
+	}
+	res.IsValid = len(res.RowIssues) == 0
+
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminValidateReferralRows: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// getOldestPostEntryForPublicKey returns the oldest top-level post that isn't hidden for a public key, or nil if
+// there isn't one. Core's GetPostsPaginatedForPublicKeyOrderedByTimestamp only walks backwards, so for badger we
+// seek forwards through the poster timestamp index ourselves. For postgres the posts come back from the view in no
+// particular order, so we fetch them all once and take the earliest.
+func getOldestPostEntryForPublicKey(utxoView *lib.UtxoView, publicKey []byte) (_postEntry *lib.PostEntry, _err error) {
+	if utxoView.Postgres != nil {
+		postEntries, err := utxoView.GetPostsPaginatedForPublicKeyOrderedByTimestamp(
+			publicKey, nil, math.MaxInt32, false, false)
+		if err != nil {
+			return nil, fmt.Errorf("getOldestPostEntryForPublicKey: Problem getting posts: %v", err)
+		}
+		var oldestPostEntry *lib.PostEntry
+		for _, postEntry := range postEntries {
+			if postEntry.ParentStakeID != nil || postEntry.IsHidden {
+				continue
+			}
+			if oldestPostEntry == nil || postEntry.TimestampNanos < oldestPostEntry.TimestampNanos {
+				oldestPostEntry = postEntry
+			}
+		}
+		return oldestPostEntry, nil
+	}
+
+	// The key consists of: Prefix, PosterPublicKey, TstampNanos, PostHash.
+	dbPrefix := append([]byte{}, lib.Prefixes.PrefixPosterPublicKeyTimestampPostHash...)
+	dbPrefix = append(dbPrefix, publicKey...)
+	postHashStartIdx := len(dbPrefix) + 8
+
+	var oldestPostEntry *lib.PostEntry
+	err := utxoView.Handle.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(dbPrefix); it.ValidForPrefix(dbPrefix); it.Next() {
+			rawKey := it.Item().Key()
+			if len(rawKey) < postHashStartIdx+lib.HashSizeBytes {
+				continue
+			}
+			postHash := &lib.BlockHash{}
+			copy(postHash[:], rawKey[postHashStartIdx:])
+			postEntry := utxoView.GetPostEntryForPostHash(postHash)
+			if postEntry == nil || postEntry.ParentStakeID != nil || postEntry.IsHidden {
+				continue
+			}
+			oldestPostEntry = postEntry
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getOldestPostEntryForPublicKey: Problem seeking posts: %v", err)
+	}
+	return oldestPostEntry, nil
+}
+
+func RefereeCSVHeaders() (_headers []string) {
+	// Note that we limit counts to 25 so that we don't have to fetch as much data.
+	return []string{
 		"ReferralHashBase58", "ReferrerPKIDBase58Check", "ReferrerUsername",
 		"RefereePKIDBase58Check", "RefereeUsername", "RefereeNumPosts (1000 max)",
-		"RefereeNumLikes", "RefereeNumDiamonds", "RefereeFirstPostDate (1000th post if max)",
+		"RefereeNumLikes", "RefereeNumDiamonds", "RefereeFirstPostDate",
 	}
 }
 
-type AdminDownloadRefereeCSVRequest struct{}
+type AdminDownloadRefereeCSVRequest struct {
+	// Optional. Either "json" (the default) for an AdminDownloadRefereeCSVResponse or "csv" for a text/csv file with
+	// the RefereeCSVHeaders() columns. Sending an "Accept: text/csv" header also selects "csv". CSV files omit rows
+	// that could not be built and report how many there were in the X-Failed-Row-Count header.
+	Format string `safeForLogging:"true"`
+}
 
 type AdminDownloadRefereeCSVResponse struct {
 	CSVRows [][]string
+
+	// Partial is true if one or more rows could not be built. The rows that failed are listed in FailedRows and are
+	// omitted from CSVRows.
+	Partial    bool
+	FailedRows []RefereeCSVFailedRow
+}
+
+type RefereeCSVFailedRow struct {
+	// Hex encoding of the global state key for the referee log that failed.
+	KeyHex string
+	Error  string
 }
 
 func (fes *APIServer) AdminDownloadRefereeCSV(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminDownloadRefereeCSVRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
 			"AdminDownloadRefereeCSV: Problem parsing request body: %v", err))
 		return
 	}
+	isCSV, err := isCSVResponseFormat(req, requestData.Format)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminDownloadRefereeCSV: %v", err))
+		return
+	}
 
 	// We create a list of rows that are constructed into a CSV on the frontend.
 	csvRows := [][]string{RefereeCSVHeaders()}
+	failedRows := []RefereeCSVFailedRow{}
 
 	// Get all of the referee logs.
 	keysFound, _, err := fes.GlobalState.Seek(
@@ -903,6 +2219,7 @@ func (fes *APIServer) AdminDownloadRefereeCSV(ww http.ResponseWriter, req *http.
 	if err != nil {
 		_AddInternalServerError(
 			ww, fmt.Sprintf("AdminDownloadRefereeCSV: problem getting referee logs: %v", err))
+		return
 	}
 
 	// Grab a utxoView in preparation of fetching copious amounts of data.
@@ -912,93 +2229,1595 @@ func (fes *APIServer) AdminDownloadRefereeCSV(ww http.ResponseWriter, req *http.
 		return
 	}
 
+	// A bad record shouldn't prevent the admin from getting the rest of the export, so we record
+	// any row that fails and keep going.
+	for _, keyBytes := range keysFound {
+		nextRow, err := fes.buildRefereeCSVRow(utxoView, keyBytes, true /*includeRefereeActivity*/)
+		if err != nil {
+			logRequestErrorf(req.Context(), "AdminDownloadRefereeCSV: Problem building row for key %v: %v", hex.EncodeToString(keyBytes), err)
+			failedRows = append(failedRows, RefereeCSVFailedRow{
+				KeyHex: hex.EncodeToString(keyBytes),
+				Error:  err.Error(),
+			})
+			continue
+		}
+		csvRows = append(csvRows, nextRow)
+	}
+
+	if isCSV {
+		ww.Header().Set("X-Failed-Row-Count", strconv.Itoa(len(failedRows)))
+		writeCSVAttachment(ww, req, "AdminDownloadRefereeCSV", "referees.csv", csvRows)
+		return
+	}
+
+	// If we made it this far we were successful, return without error.
+	res := AdminDownloadRefereeCSVResponse{
+		CSVRows:    csvRows,
+		Partial:    len(failedRows) > 0,
+		FailedRows: failedRows,
+	}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminDownloadRefereeCSV: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+func ReferralJoinedCSVHeaders(includeRefereeActivity bool) (_headers []string) {
+	headers := RefereeCSVHeaders()
+	if !includeRefereeActivity {
+		headers = headers[:refereeCSVNumIdentityColumns]
+	}
+	return append(headers,
+		"ReferrerAmountUSDCents", "RefereeAmountUSDCents", "MaxReferrals", "RequiresJumio", "IsActive")
+}
+
+type AdminDownloadReferralJoinedCSVRequest struct {
+	// If true, each row also includes the referee's post, like, and diamond counts. These require several db
+	// lookups per referee, so they are skipped by default.
+	IncludeRefereeActivity bool `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminDownloadReferralJoinedCSVResponse struct {
+	CSVRows [][]string
+
+	// Partial is true if one or more rows could not be built. The rows that failed are listed in FailedRows and are
+	// omitted from CSVRows.
+	Partial    bool
+	FailedRows []RefereeCSVFailedRow
+}
+
+// AdminDownloadReferralJoinedCSV returns one row per referee, like AdminDownloadRefereeCSV, with the terms of the
+// referral hash they signed up with joined onto the end of each row.
+func (fes *APIServer) AdminDownloadReferralJoinedCSV(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminDownloadReferralJoinedCSVRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminDownloadReferralJoinedCSV: Problem parsing request body: %v", err))
+		return
+	}
+
+	csvRows := [][]string{ReferralJoinedCSVHeaders(requestData.IncludeRefereeActivity)}
+	failedRows := []RefereeCSVFailedRow{}
+
+	keysFound, _, err := fes.GlobalState.Seek(
+		_GlobalStatePrefixPKIDReferralHashRefereePKID,
+		_GlobalStatePrefixPKIDReferralHashRefereePKID,
+		0, 0, false /*reverse*/, false /*fetchValue*/)
+	if err != nil {
+		_AddInternalServerError(
+			ww, fmt.Sprintf("AdminDownloadReferralJoinedCSV: problem getting referee logs: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminDownloadReferralJoinedCSV: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	// Most referral hashes have many referees, so we only look up each referral hash's terms once.
+	linkColumnsByReferralHash := make(map[string][]string)
+	for _, keyBytes := range keysFound {
+		nextRow, err := fes.buildRefereeCSVRow(utxoView, keyBytes, requestData.IncludeRefereeActivity)
+		if err != nil {
+			logRequestErrorf(req.Context(), "AdminDownloadReferralJoinedCSV: Problem building row for key %v: %v",
+				hex.EncodeToString(keyBytes), err)
+			failedRows = append(failedRows, RefereeCSVFailedRow{
+				KeyHex: hex.EncodeToString(keyBytes),
+				Error:  err.Error(),
+			})
+			continue
+		}
+
+		referralHashBase58 := nextRow[0]
+		linkColumns, exists := linkColumnsByReferralHash[referralHashBase58]
+		if !exists {
+			referralInfo, err := fes.getInfoForReferralHashBase58(referralHashBase58)
+			if err != nil {
+				failedRows = append(failedRows, RefereeCSVFailedRow{
+					KeyHex: hex.EncodeToString(keyBytes),
+					Error:  err.Error(),
+				})
+				continue
+			}
+			linkColumns = []string{
+				strconv.FormatUint(referralInfo.ReferrerAmountUSDCents, 10),
+				strconv.FormatUint(referralInfo.RefereeAmountUSDCents, 10),
+				strconv.FormatUint(referralInfo.MaxReferrals, 10),
+				strconv.FormatBool(referralInfo.RequiresJumio),
+				strconv.FormatBool(fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralHashBase58)),
+			}
+			linkColumnsByReferralHash[referralHashBase58] = linkColumns
+		}
+		csvRows = append(csvRows, append(nextRow, linkColumns...))
+	}
+
+	res := AdminDownloadReferralJoinedCSVResponse{
+		CSVRows:    csvRows,
+		Partial:    len(failedRows) > 0,
+		FailedRows: failedRows,
+	}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminDownloadReferralJoinedCSV: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// refereeCSVNumIdentityColumns is the number of leading RefereeCSVHeaders columns that identify the referral hash,
+// referrer, and referee. The remaining columns summarize the referee's activity and are expensive to compute.
+const refereeCSVNumIdentityColumns = 5
+
+// buildRefereeCSVRow assembles a single referee CSV row from a
+// _GlobalStatePrefixPKIDReferralHashRefereePKID key. Any panic from the underlying lookups is
+// recovered and returned as an error so that one bad record can't take down the whole export.
+// If includeRefereeActivity is false, only the first refereeCSVNumIdentityColumns columns are
+// returned and the per-referee post, like, and diamond lookups are skipped.
+func (fes *APIServer) buildRefereeCSVRow(
+	utxoView *lib.UtxoView, keyBytes []byte, includeRefereeActivity bool,
+) (_row []string, _err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			_row = nil
+			_err = fmt.Errorf("buildRefereeCSVRow: recovered from panic: %v", r)
+		}
+	}()
+
 	// Indexes to chop up the referee keys with.
 	referrerPKIDStartIdx := 1
 	referralHashStartIdx := referrerPKIDStartIdx + btcec.PubKeyBytesLenCompressed
 	refereePKIDStartIdx := referralHashStartIdx + 8
 
-	for _, keyBytes := range keysFound {
-		referralHashBytes := keyBytes[referralHashStartIdx:refereePKIDStartIdx]
+	if len(keyBytes) != refereePKIDStartIdx+btcec.PubKeyBytesLenCompressed {
+		return nil, fmt.Errorf("buildRefereeCSVRow: key has unexpected length %d", len(keyBytes))
+	}
 
-		// Chop the referrerPKID out of the key.
-		referrerPKIDBytes := keyBytes[referrerPKIDStartIdx:referralHashStartIdx]
-		referrerPKID := &lib.PKID{}
-		copy(referrerPKID[:], referrerPKIDBytes)
+	referralHashBytes := keyBytes[referralHashStartIdx:refereePKIDStartIdx]
 
-		// Chop the refereePKID out of the key.
-		refereePKIDBytes := keyBytes[refereePKIDStartIdx:]
-		refereePKID := &lib.PKID{}
-		copy(refereePKID[:], refereePKIDBytes)
-
-		// Gab the referrer and referee PKIDs.
-		referrerProfileEntry := utxoView.GetProfileEntryForPKID(referrerPKID)
-		refereeProfileEntry := utxoView.GetProfileEntryForPKID(refereePKID)
-
-		// Extract the username strings safely.
-		referrerUsernameStr := ""
-		if referrerProfileEntry != nil {
-			referrerUsernameStr = string(referrerProfileEntry.Username)
-		}
-		refereeUsernameStr := ""
-		if refereeProfileEntry != nil {
-			refereeUsernameStr = string(refereeProfileEntry.Username)
-		}
-
-		// Grab a list of posts for this user, up to 1000.
-		//
-		// RPH-FIXME: Because the existing core GetPostsPaginatedForPublicKey only iterates
-		// backwards we can't actually get the timestamp of the referee's first post if they
-		// have a lot of posts (e.g. @huntsauce level of posts). Leaving as is for now since
-		// it is not critical.
-		refereePostsLen := int64(-1)
-		refereePostEntries, err := utxoView.GetPostsPaginatedForPublicKeyOrderedByTimestamp(
-			refereePKID[:], nil, 1000, false, false)
-		if err == nil {
-			refereePostsLen = int64(len(refereePostEntries))
+	// Chop the referrerPKID out of the key.
+	referrerPKIDBytes := keyBytes[referrerPKIDStartIdx:referralHashStartIdx]
+	referrerPKID := &lib.PKID{}
+	copy(referrerPKID[:], referrerPKIDBytes)
+
+	// Chop the refereePKID out of the key.
+	refereePKIDBytes := keyBytes[refereePKIDStartIdx:]
+	refereePKID := &lib.PKID{}
+	copy(refereePKID[:], refereePKIDBytes)
+
+	// Gab the referrer and referee PKIDs.
+	referrerProfileEntry := utxoView.GetProfileEntryForPKID(referrerPKID)
+	refereeProfileEntry := utxoView.GetProfileEntryForPKID(refereePKID)
+
+	// Extract the username strings safely.
+	referrerUsernameStr := ""
+	if referrerProfileEntry != nil {
+		referrerUsernameStr = string(referrerProfileEntry.Username)
+	}
+	refereeUsernameStr := ""
+	if refereeProfileEntry != nil {
+		refereeUsernameStr = string(refereeProfileEntry.Username)
+	}
+
+	nextRow := []string{}
+	nextRow = append(nextRow, string(referralHashBytes))
+	nextRow = append(nextRow, lib.PkToString(lib.PKIDToPublicKey(referrerPKID), fes.Params))
+	nextRow = append(nextRow, referrerUsernameStr)
+	nextRow = append(nextRow, lib.PkToString(lib.PKIDToPublicKey(refereePKID), fes.Params))
+	nextRow = append(nextRow, refereeUsernameStr)
+	if !includeRefereeActivity {
+		return nextRow, nil
+	}
+
+	// Grab a list of posts for this user, up to 1000.
+	refereePostsLen := int64(-1)
+	refereePostEntries, err := utxoView.GetPostsPaginatedForPublicKeyOrderedByTimestamp(
+		refereePKID[:], nil, 1000, false, false)
+	if err == nil {
+		refereePostsLen = int64(len(refereePostEntries))
+	}
+
+	// Grab a list of post hashes liked by this user.
+	refereeLikesLen := int64(-1)
+	refereeLikedPostHashes, err := lib.DbGetPostHashesYouLike(utxoView.Handle, refereePKID[:])
+	if err == nil {
+		refereeLikesLen = int64(len(refereeLikedPostHashes))
+	}
+
+	// Grab the PKIDs diamonded by the referee.
+	refereeDiamondsLen := int64(-1)
+	refereeDiamondedPKIDs, err := lib.DbGetPKIDsThatDiamondedYouMap(
+		utxoView.Handle, refereePKID, true /*fetchYouDiamonded*/)
+	if err == nil {
+		refereeDiamondsLen = int64(len(refereeDiamondedPKIDs))
+	}
+
+	// Assemble the rest of the row.
+	nextRow = append(nextRow, strconv.FormatInt(refereePostsLen, 10))
+	nextRow = append(nextRow, strconv.FormatInt(refereeLikesLen, 10))
+	nextRow = append(nextRow, strconv.FormatInt(refereeDiamondsLen, 10))
+	// The posts above stop at 1000, so look up the oldest post separately.
+	oldestRefereePost, err := getOldestPostEntryForPublicKey(utxoView, refereePKID[:])
+	if err == nil && oldestRefereePost != nil {
+		nextRow = append(nextRow, time.Unix(0, int64(oldestRefereePost.TimestampNanos)).String())
+	} else {
+		nextRow = append(nextRow, "")
+	}
+
+	return nextRow, nil
+}
+
+const (
+	defaultCompactReferralInfosBatchSize = 1000
+	maxCompactReferralInfosBatchSize     = 10000
+)
+
+type AdminCompactReferralInfosRequest struct {
+	// The referral hash to start compacting from (inclusive). Leave empty to start from the beginning, and pass
+	// NextReferralHash from the previous response to resume.
+	StartReferralHash string `safeForLogging:"true"`
+	// Maximum number of ReferralInfos to process in this request. Defaults to 1000, capped at 10000.
+	BatchSize uint64 `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminCompactReferralInfosResponse struct {
+	NumProcessed uint64
+	NumRewritten uint64
+	NumFailed    uint64
+	// Referral hashes whose ReferralInfo could not be decoded or written back. These are left untouched.
+	FailedReferralHashes []string
+
+	// The referral hash to pass as StartReferralHash to continue compacting. Empty once every ReferralInfo has
+	// been processed.
+	NextReferralHash string
+	IsComplete       bool
+}
+
+// AdminCompactReferralInfos reads ReferralInfos in batches, re-encodes each one in the current format, and writes it
+// back. This normalizes ReferralInfos that were written with older versions of the struct.
+func (fes *APIServer) AdminCompactReferralInfos(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminCompactReferralInfosRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminCompactReferralInfos: Problem parsing request body: %v", err))
+		return
+	}
+
+	batchSize := requestData.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultCompactReferralInfosBatchSize
+	}
+	if batchSize > maxCompactReferralInfosBatchSize {
+		batchSize = maxCompactReferralInfosBatchSize
+	}
+
+	referralHashes, valsFound, nextReferralHash, err := fes.seekReferralInfoBytesPage(
+		requestData.StartReferralHash, int(batchSize))
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminCompactReferralInfos: %v", err))
+		return
+	}
+
+	res := AdminCompactReferralInfosResponse{
+		FailedReferralHashes: []string{},
+		NextReferralHash:     nextReferralHash,
+		IsComplete:           nextReferralHash == "",
+	}
+	for ii, referralHashBase58 := range referralHashes {
+		res.NumProcessed++
+
+		if _, err = decodeReferralInfo(valsFound[ii]); err != nil {
+			logRequestErrorf(req.Context(), "AdminCompactReferralInfos: Failed decoding referral info (%s): %v", referralHashBase58, err)
+			res.NumFailed++
+			res.FailedReferralHashes = append(res.FailedReferralHashes, referralHashBase58)
+			continue
 		}
 
-		// Grab a list of post hashes liked by this user.
-		refereeLikesLen := int64(-1)
-		refereeLikedPostHashes, err := lib.DbGetPostHashesYouLike(utxoView.Handle, refereePKID[:])
-		if err == nil {
-			refereeLikesLen = int64(len(refereeLikedPostHashes))
+		// Rewrite the latest copy rather than the one we just read in case it was updated in the meantime.
+		if _, err = fes.updateReferralInfoForReferralHash(referralHashBase58, func(*ReferralInfo) error {
+			return nil
+		}); err != nil {
+			logRequestErrorf(req.Context(), "AdminCompactReferralInfos: Failed writing referral info (%s): %v", referralHashBase58, err)
+			res.NumFailed++
+			res.FailedReferralHashes = append(res.FailedReferralHashes, referralHashBase58)
+			continue
 		}
+		res.NumRewritten++
+	}
 
-		// Grab the PKIDs diamonded by the referee.
-		refereeDiamondsLen := int64(-1)
-		refereeDiamondedPKIDs, err := lib.DbGetPKIDsThatDiamondedYouMap(
-			utxoView.Handle, refereePKID, true /*fetchYouDiamonded*/)
-		if err == nil {
-			refereeDiamondsLen = int64(len(refereeDiamondedPKIDs))
-		}
-
-		// Assemble the row.
-		nextRow := []string{}
-		nextRow = append(nextRow, string(referralHashBytes))
-		nextRow = append(nextRow, lib.PkToString(lib.PKIDToPublicKey(referrerPKID), fes.Params))
-		nextRow = append(nextRow, referrerUsernameStr)
-		nextRow = append(nextRow, lib.PkToString(lib.PKIDToPublicKey(refereePKID), fes.Params))
-		nextRow = append(nextRow, refereeUsernameStr)
-		nextRow = append(nextRow, strconv.FormatInt(refereePostsLen, 10))
-		nextRow = append(nextRow, strconv.FormatInt(refereeLikesLen, 10))
-		nextRow = append(nextRow, strconv.FormatInt(refereeDiamondsLen, 10))
-		if refereePostsLen > 0 {
-			oldestRefereePost := refereePostEntries[len(refereePostEntries)-1]
-			nextRow = append(nextRow, time.Unix(0, int64(oldestRefereePost.TimestampNanos)).String())
-		} else {
-			nextRow = append(nextRow, "")
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminCompactReferralInfos: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// UnknownReferralCreator is used in place of the creating admin's public key for referral hashes that were created
+// before CreatedByAdminPublicKey was recorded.
+const UnknownReferralCreator = "unknown creator"
+
+type AdminGetReferralsByCreatingAdminRequest struct {
+	// Public keys of the admins whose referral hashes should be returned. UnknownReferralCreator can be included to
+	// return referral hashes with no recorded creator. If empty, all referral hashes are returned.
+	CreatingAdminPublicKeysBase58Check []string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminGetReferralsByCreatingAdminResponse struct {
+	// Map of creating admin public key (or UnknownReferralCreator) to the referral hashes they created.
+	ReferralInfoResponsesByCreatingAdmin map[string][]ReferralInfoResponse
+}
+
+func (fes *APIServer) AdminGetReferralsByCreatingAdmin(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetReferralsByCreatingAdminRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferralsByCreatingAdmin: Problem parsing request body: %v", err))
+		return
+	}
+
+	creatingAdmins := make(map[string]bool)
+	for _, creatingAdminPublicKey := range requestData.CreatingAdminPublicKeysBase58Check {
+		creatingAdmins[creatingAdminPublicKey] = true
+	}
+
+	referralInfos, err := fes.getAllReferralInfos()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralsByCreatingAdmin: Problem getting referral infos: %v", err))
+		return
+	}
+
+	referralInfoResponsesByCreatingAdmin := make(map[string][]ReferralInfoResponse)
+	for _, referralInfo := range referralInfos {
+		creatingAdmin := referralInfo.CreatedByAdminPublicKey
+		if creatingAdmin == "" {
+			creatingAdmin = UnknownReferralCreator
+		}
+		if len(creatingAdmins) > 0 && !creatingAdmins[creatingAdmin] {
+			continue
 		}
+		referralInfoResponsesByCreatingAdmin[creatingAdmin] = append(
+			referralInfoResponsesByCreatingAdmin[creatingAdmin], ReferralInfoResponse{
+				IsActive: fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58),
+				Info:     referralInfo,
+			})
+	}
 
-		csvRows = append(csvRows, nextRow)
+	res := AdminGetReferralsByCreatingAdminResponse{
+		ReferralInfoResponsesByCreatingAdmin: referralInfoResponsesByCreatingAdmin,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferralsByCreatingAdmin: Problem encoding response as JSON: %v", err))
+		return
 	}
+}
 
-	// If we made it this far we were successful, return without error.
-	res := AdminDownloadRefereeCSVResponse{
-		CSVRows: csvRows,
+const (
+	ReferralIneligibleReasonNotFound        = "referral hash not found"
+	ReferralIneligibleReasonInactive        = "referral hash is not active"
+	ReferralIneligibleReasonExpired         = "referral hash has expired"
+	ReferralIneligibleReasonMaxReferrals    = "referral hash has reached its max referrals"
+	ReferralIneligibleReasonSelfReferral    = "referee is the referrer"
+	ReferralIneligibleReasonAlreadyCredited = "referee was already credited to a referral hash"
+)
+
+type AdminGetEligibleReferralsForRefereeRequest struct {
+	RefereePublicKeyBase58Check string   `safeForLogging:"true"`
+	ReferralHashesBase58        []string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type ReferralEligibilityResponse struct {
+	ReferralHashBase58 string
+	IsEligible         bool
+	// Empty if IsEligible is true. Otherwise one of the ReferralIneligibleReason constants.
+	Reason string
+}
+
+type AdminGetEligibleReferralsForRefereeResponse struct {
+	// One entry per referral hash in the request, in the same order.
+	Eligibility []ReferralEligibilityResponse
+	// The referral hash the referee has already been credited to, if any.
+	CreditedReferralHashBase58 string
+}
+
+// AdminGetEligibleReferralsForReferee checks which of a list of referral hashes a referee could be credited to. A referee
+// can only be credited once, so if they have already been credited to any referral hash, no hash is eligible.
+func (fes *APIServer) AdminGetEligibleReferralsForReferee(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
 	}
-	if err := json.NewEncoder(ww).Encode(res); err != nil {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetEligibleReferralsForRefereeRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetEligibleReferralsForReferee: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.ReferralHashesBase58) == 0 {
+		_AddBadRequestError(ww, "AdminGetEligibleReferralsForReferee: Must provide at least one referral hash")
+		return
+	}
+	// At most --max-page-size referral hashes can be checked at once.
+	maxCandidates := fes.getPageSize(uint64(len(requestData.ReferralHashesBase58)))
+	if len(requestData.ReferralHashesBase58) > maxCandidates {
 		_AddBadRequestError(ww, fmt.Sprintf(
-			"AdminDownloadRefereeCSV: Problem encoding response as JSON: %v", err))
+			"AdminGetEligibleReferralsForReferee: Cannot check more than %d referral hashes at once", maxCandidates))
+		return
+	}
+
+	refereePublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.RefereePublicKeyBase58Check)
+	if err != nil || len(refereePublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetEligibleReferralsForReferee: Problem decoding referee public key %s: %v",
+			requestData.RefereePublicKeyBase58Check, err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetEligibleReferralsForReferee: Problem fetching utxoView: %v", err))
+		return
+	}
+	refereePKID := utxoView.GetPKIDForPublicKey(refereePublicKeyBytes).PKID
+
+	// The referee's user metadata records the referral hash they signed up with, and whether the referrer was paid.
+	userMetadata, err := fes.getUserMetadataFromGlobalStateByPublicKeyBytes(refereePublicKeyBytes)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetEligibleReferralsForReferee: Problem getting user metadata: %v", err))
+		return
+	}
+	res := AdminGetEligibleReferralsForRefereeResponse{
+		Eligibility: []ReferralEligibilityResponse{},
+	}
+	if userMetadata.ReferrerDeSoTxnHash != "" {
+		res.CreditedReferralHashBase58 = userMetadata.ReferralHashBase58Check
+	}
+
+	// Look up the referral infos first, since we need the referrer PKIDs to check the referee index.
+	referralInfos := make([]*ReferralInfo, len(requestData.ReferralHashesBase58))
+	for ii, referralHashBase58 := range requestData.ReferralHashesBase58 {
+		referralInfo, err := fes.getInfoForReferralHashBase58(referralHashBase58)
+		if err != nil {
+			continue
+		}
+		referralInfos[ii] = referralInfo
+
+		// The referee index is the source of truth for who was credited to which referral hash, so we check it
+		// in addition to the user metadata.
+		refereeIndexKey := GlobalStateKeyForPKIDReferralHashRefereePKID(
+			referralInfo.ReferrerPKID, []byte(referralHashBase58), refereePKID)
+		refereeIndexVal, err := fes.GlobalState.Get(refereeIndexKey)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminGetEligibleReferralsForReferee: Problem checking referee index for referral hash %s: %v",
+				referralHashBase58, err))
+			return
+		}
+		if refereeIndexVal != nil && res.CreditedReferralHashBase58 == "" {
+			res.CreditedReferralHashBase58 = referralHashBase58
+		}
+	}
+
+	for ii, referralHashBase58 := range requestData.ReferralHashesBase58 {
+		referralInfo := referralInfos[ii]
+		eligibility := ReferralEligibilityResponse{
+			ReferralHashBase58: referralHashBase58,
+		}
+		switch {
+		case referralInfo == nil:
+			eligibility.Reason = ReferralIneligibleReasonNotFound
+		case res.CreditedReferralHashBase58 != "":
+			eligibility.Reason = ReferralIneligibleReasonAlreadyCredited
+		case referralInfo.ReferrerPKID != nil && referralInfo.ReferrerPKID.Eq(refereePKID):
+			eligibility.Reason = ReferralIneligibleReasonSelfReferral
+		case isReferralInfoExpired(referralInfo):
+			eligibility.Reason = ReferralIneligibleReasonExpired
+		case !fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58):
+			eligibility.Reason = ReferralIneligibleReasonInactive
+		case referralInfo.MaxReferrals > 0 && referralInfo.TotalReferrals >= referralInfo.MaxReferrals:
+			eligibility.Reason = ReferralIneligibleReasonMaxReferrals
+		default:
+			eligibility.IsEligible = true
+		}
+		res.Eligibility = append(res.Eligibility, eligibility)
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetEligibleReferralsForReferee: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type AdminGetLowConversionJumioReferralsRequest struct {
+	// Referral hashes with a Jumio conversion rate (NumJumioSuccesses / NumJumioAttempts) strictly below this value
+	// are returned. Must be between 0 and 1.
+	MaxConversionRate float64 `safeForLogging:"true"`
+	// Referral hashes with fewer Jumio attempts than this are skipped, since their conversion rate isn't meaningful.
+	MinJumioAttempts uint64 `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type LowConversionJumioReferralResponse struct {
+	ReferralInfoResponse
+
+	ConversionRate float64 `safeForLogging:"true"`
+}
+
+type AdminGetLowConversionJumioReferralsResponse struct {
+	// Sorted by conversion rate ascending, then by number of Jumio attempts descending.
+	LowConversionReferrals []LowConversionJumioReferralResponse `safeForLogging:"true"`
+}
+
+// AdminGetLowConversionJumioReferrals returns Jumio-required referral hashes with many Jumio attempts but few
+// successes. These are often a sign of abuse or of a broken verification flow.
+func (fes *APIServer) AdminGetLowConversionJumioReferrals(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetLowConversionJumioReferralsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetLowConversionJumioReferrals: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.MaxConversionRate < 0 || requestData.MaxConversionRate > 1 {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetLowConversionJumioReferrals: MaxConversionRate must be between 0 and 1, got %v",
+			requestData.MaxConversionRate))
+		return
+	}
+
+	referralInfos, err := fes.getAllReferralInfos()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminGetLowConversionJumioReferrals: Problem getting referral infos: %v", err))
+		return
+	}
+
+	lowConversionReferrals := []LowConversionJumioReferralResponse{}
+	for _, referralInfo := range referralInfos {
+		// Links that have never had a Jumio attempt have no conversion rate to speak of.
+		if !referralInfo.RequiresJumio || referralInfo.NumJumioAttempts == 0 ||
+			referralInfo.NumJumioAttempts < requestData.MinJumioAttempts {
+			continue
+		}
+		conversionRate := float64(referralInfo.NumJumioSuccesses) / float64(referralInfo.NumJumioAttempts)
+		if conversionRate >= requestData.MaxConversionRate {
+			continue
+		}
+		lowConversionReferrals = append(lowConversionReferrals, LowConversionJumioReferralResponse{
+			ReferralInfoResponse: ReferralInfoResponse{
+				IsActive: fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58),
+				Info:     referralInfo,
+			},
+			ConversionRate: conversionRate,
+		})
+	}
+	sort.Slice(lowConversionReferrals, func(ii, jj int) bool {
+		if lowConversionReferrals[ii].ConversionRate != lowConversionReferrals[jj].ConversionRate {
+			return lowConversionReferrals[ii].ConversionRate < lowConversionReferrals[jj].ConversionRate
+		}
+		return lowConversionReferrals[ii].Info.NumJumioAttempts > lowConversionReferrals[jj].Info.NumJumioAttempts
+	})
+
+	res := AdminGetLowConversionJumioReferralsResponse{
+		LowConversionReferrals: lowConversionReferrals,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetLowConversionJumioReferrals: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type AdminSwapReferralOwnershipRequest struct {
+	ReferralHashBase58A string `safeForLogging:"true"`
+	ReferralHashBase58B string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminSwapReferralOwnershipResponse struct {
+	ReferralInfoResponseA ReferralInfoResponse `safeForLogging:"true"`
+	ReferralInfoResponseB ReferralInfoResponse `safeForLogging:"true"`
+}
+
+// AdminSwapReferralOwnership swaps the referrers of two referral hashes. Each referral hash keeps its own IsActive
+// status, which is moved to its new referrer's status key. Past referee records are left with the referrer who was
+// paid for them.
+func (fes *APIServer) AdminSwapReferralOwnership(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminSwapReferralOwnershipRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminSwapReferralOwnership: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.ReferralHashBase58A == "" || requestData.ReferralHashBase58B == "" {
+		_AddBadRequestError(ww, "AdminSwapReferralOwnership: Must provide two referral hashes to swap")
+		return
+	}
+	// Resolve the hashes before locking them so that we take the same locks as updateReferralInfoForReferralHash.
+	hashA, err := fes.resolveReferralHashBase58(requestData.ReferralHashBase58A)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminSwapReferralOwnership: %v", err))
+		return
+	}
+	hashB, err := fes.resolveReferralHashBase58(requestData.ReferralHashBase58B)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminSwapReferralOwnership: %v", err))
+		return
+	}
+	if hashA == hashB {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminSwapReferralOwnership: Cannot swap referral hash %s with itself", hashA))
+		return
+	}
+
+	// Take both referral hash locks, always in the same order so that two concurrent swaps can't deadlock.
+	firstHash, secondHash := hashA, hashB
+	if secondHash < firstHash {
+		firstHash, secondHash = secondHash, firstHash
+	}
+	defer fes.lockReferralHash(firstHash)()
+	defer fes.lockReferralHash(secondHash)()
+
+	// Read and validate everything before doing any writes.
+	referralInfoA, err := fes.getInfoForReferralHashBase58(hashA)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminSwapReferralOwnership: Problem getting referral info for %s: %v", hashA, err))
+		return
+	}
+	referralInfoB, err := fes.getInfoForReferralHashBase58(hashB)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminSwapReferralOwnership: Problem getting referral info for %s: %v", hashB, err))
+		return
+	}
+	if referralInfoA.ReferrerPKID == nil || referralInfoB.ReferrerPKID == nil {
+		_AddBadRequestError(ww, "AdminSwapReferralOwnership: Both referral hashes must have a referrer")
+		return
+	}
+	referrerPKIDA := referralInfoA.ReferrerPKID
+	referrerPKIDB := referralInfoB.ReferrerPKID
+	isActiveA := fes.getReferralHashStatus(referrerPKIDA, hashA)
+	isActiveB := fes.getReferralHashStatus(referrerPKIDB, hashB)
+
+	// Write the new status keys and referral infos first so that each referral hash is always findable under at
+	// least one referrer, then remove the old status keys.
+	if err = fes.setReferralHashStatusForPKID(referrerPKIDB, hashA, isActiveA); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminSwapReferralOwnership: Problem setting status for %s: %v", hashA, err))
+		return
+	}
+	if err = fes.setReferralHashStatusForPKID(referrerPKIDA, hashB, isActiveB); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminSwapReferralOwnership: Problem setting status for %s: %v", hashB, err))
+		return
+	}
+	referralInfoA.ReferrerPKID = referrerPKIDB
+	referralInfoB.ReferrerPKID = referrerPKIDA
+	if err = fes.putReferralHashWithInfo(hashA, referralInfoA); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminSwapReferralOwnership: Problem putting %s: %v", hashA, err))
+		return
+	}
+	if err = fes.putReferralHashWithInfo(hashB, referralInfoB); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminSwapReferralOwnership: Problem putting %s: %v", hashB, err))
+		return
+	}
+	if !referrerPKIDA.Eq(referrerPKIDB) {
+		if err = fes.GlobalState.Delete(GlobalStateKeyForPKIDReferralHashToIsActive(
+			referrerPKIDA, []byte(hashA))); err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminSwapReferralOwnership: Problem deleting old status for %s: %v", hashA, err))
+			return
+		}
+		if err = fes.GlobalState.Delete(GlobalStateKeyForPKIDReferralHashToIsActive(
+			referrerPKIDB, []byte(hashB))); err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminSwapReferralOwnership: Problem deleting old status for %s: %v", hashB, err))
+			return
+		}
+	}
+
+	res := AdminSwapReferralOwnershipResponse{
+		ReferralInfoResponseA: ReferralInfoResponse{
+			IsActive: isActiveA,
+			Info:     *referralInfoA,
+		},
+		ReferralInfoResponseB: ReferralInfoResponse{
+			IsActive: isActiveB,
+			Info:     *referralInfoB,
+		},
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminSwapReferralOwnership: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type AdminHasUsedAnyReferralRequest struct {
+	RefereePublicKeyBase58Check string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminHasUsedAnyReferralResponse struct {
+	HasUsedAnyReferral bool
+	// The referral hash the referee signed up with or was credited to. Empty if HasUsedAnyReferral is false.
+	ReferralHashBase58 string
+	// True if the referrer has been paid for this referee. A referee can sign up with a referral hash and not be
+	// credited yet, ex: if the referral hash requires Jumio and the referee hasn't completed it.
+	IsCredited bool
+}
+
+// AdminHasUsedAnyReferral checks whether a referee has already signed up with, or been credited to, any referral hash.
+func (fes *APIServer) AdminHasUsedAnyReferral(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminHasUsedAnyReferralRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminHasUsedAnyReferral: Problem parsing request body: %v", err))
+		return
+	}
+
+	refereePublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.RefereePublicKeyBase58Check)
+	if err != nil || len(refereePublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminHasUsedAnyReferral: Problem decoding referee public key %s: %v",
+			requestData.RefereePublicKeyBase58Check, err))
+		return
+	}
+
+	// The user metadata records the referral hash the referee signed up with, so check it first.
+	userMetadata, err := fes.getUserMetadataFromGlobalStateByPublicKeyBytes(refereePublicKeyBytes)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminHasUsedAnyReferral: Problem getting user metadata: %v", err))
+		return
+	}
+	res := AdminHasUsedAnyReferralResponse{}
+	if userMetadata.ReferralHashBase58Check != "" {
+		res.HasUsedAnyReferral = true
+		res.ReferralHashBase58 = userMetadata.ReferralHashBase58Check
+		res.IsCredited = userMetadata.ReferrerDeSoTxnHash != ""
+	}
+
+	// Otherwise fall back to the index of referral hashes by referee. This catches referees whose user metadata
+	// doesn't reflect the referral, ex: if it was reset.
+	if !res.IsCredited {
+		utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("AdminHasUsedAnyReferral: Problem fetching utxoView: %v", err))
+			return
+		}
+		refereePKID := utxoView.GetPKIDForPublicKey(refereePublicKeyBytes).PKID
+		creditedReferralHash, err := fes.GlobalState.Get(GlobalStateKeyForRefereePKIDToReferralHash(refereePKID))
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminHasUsedAnyReferral: Problem getting referral hash for referee: %v", err))
+			return
+		}
+		if len(creditedReferralHash) != 0 {
+			res.HasUsedAnyReferral = true
+			res.ReferralHashBase58 = string(creditedReferralHash)
+			res.IsCredited = true
+		}
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminHasUsedAnyReferral: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// The number of referee index entries backfillRefereeReferralHashIndex reads and indexes at a time.
+const refereeReferralHashIndexBackfillPageSize = 1000
+
+// backfillRefereeReferralHashIndex adds referees credited before the index of referral hashes by referee existed to
+// it, so that AdminHasUsedAnyReferral can find them without scanning the referee index. Referees that are already
+// indexed are left alone.
+func (fes *APIServer) backfillRefereeReferralHashIndex() error {
+	// Keys are <prefix, referrer PKID, referral hash, referee PKID>.
+	prefix := _GlobalStatePrefixPKIDReferralHashRefereePKID
+	referralHashStartIdx := len(prefix) + btcec.PubKeyBytesLenCompressed
+	refereePKIDStartIdx := referralHashStartIdx + referralHashLen
+	startKey := prefix
+	numIndexed := 0
+	for {
+		keys, _, err := fes.GlobalState.Seek(startKey, prefix, 0, refereeReferralHashIndexBackfillPageSize,
+			false /*reverse*/, false /*fetchValues*/)
+		if err != nil {
+			return fmt.Errorf("backfillRefereeReferralHashIndex: Problem seeking referee index: %v", err)
+		}
+
+		// A referee should only be in the referee index once, but if they're in a page twice only the first entry
+		// is queued.
+		var kvPairs []KVPair
+		queuedIndexKeys := make(map[string]bool)
+		for _, key := range keys {
+			if len(key) != refereePKIDStartIdx+btcec.PubKeyBytesLenCompressed {
+				continue
+			}
+			refereePKID := &lib.PKID{}
+			copy(refereePKID[:], key[refereePKIDStartIdx:])
+			indexKey := GlobalStateKeyForRefereePKIDToReferralHash(refereePKID)
+			if queuedIndexKeys[string(indexKey)] {
+				continue
+			}
+			existingVal, err := fes.GlobalState.Get(indexKey)
+			if err != nil {
+				return fmt.Errorf("backfillRefereeReferralHashIndex: Problem getting index entry: %v", err)
+			}
+			if existingVal != nil {
+				continue
+			}
+			queuedIndexKeys[string(indexKey)] = true
+			kvPairs = append(kvPairs, KVPair{
+				Key:   indexKey,
+				Value: append([]byte{}, key[referralHashStartIdx:refereePKIDStartIdx]...),
+			})
+		}
+		if len(kvPairs) > 0 {
+			if err = fes.GlobalState.BatchPut(kvPairs); err != nil {
+				return fmt.Errorf("backfillRefereeReferralHashIndex: Problem writing index entries: %v", err)
+			}
+			numIndexed += len(kvPairs)
+		}
+
+		if len(keys) < refereeReferralHashIndexBackfillPageSize {
+			break
+		}
+		startKey = append(append([]byte{}, keys[len(keys)-1]...), 0)
+	}
+	glog.Infof("backfillRefereeReferralHashIndex: Indexed %d referees", numIndexed)
+	return nil
+}
+
+type AdminGetReferralPayoutDetailRequest struct {
+	ReferralHashBase58          string `safeForLogging:"true"`
+	RefereePublicKeyBase58Check string `safeForLogging:"true"`
+	// Optional. The referee index is keyed by the referrer at the time of the payout, so this must be set to look
+	// up a payout made before the referral hash's ownership was changed. Defaults to the current referrer.
+	ReferrerPublicKeyBase58Check string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminGetReferralPayoutDetailResponse struct {
+	// False for referees credited before payouts were recorded. In that case none of the other fields are set.
+	IsPayoutRecorded bool
+
+	// The USD cents per DeSo exchange rate in effect when the referee was credited.
+	UsdCentsPerDeSoExchangeRate uint64
+	RefereeDeSoNanos            uint64
+	ReferrerDeSoNanos           uint64
+	// The nanos paid converted back to USD cents at the recorded exchange rate.
+	RefereeUSDCents  float64
+	ReferrerUSDCents float64
+	TstampNanos      uint64
+}
+
+// AdminGetReferralPayoutDetail returns the amounts paid out, and the exchange rate used, when a referee was credited
+// to a referral hash.
+func (fes *APIServer) AdminGetReferralPayoutDetail(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetReferralPayoutDetailRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferralPayoutDetail: Problem parsing request body: %v", err))
+		return
+	}
+
+	refereePublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.RefereePublicKeyBase58Check)
+	if err != nil || len(refereePublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetReferralPayoutDetail: Problem decoding referee public key %s: %v",
+			requestData.RefereePublicKeyBase58Check, err))
+		return
+	}
+	var referrerPublicKeyBytes []byte
+	if requestData.ReferrerPublicKeyBase58Check != "" {
+		referrerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReferrerPublicKeyBase58Check)
+		if err != nil || len(referrerPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"AdminGetReferralPayoutDetail: Problem decoding referrer public key %s: %v",
+				requestData.ReferrerPublicKeyBase58Check, err))
+			return
+		}
+	}
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
+	if errors.Cause(err) == ErrReferralHashNotFound {
+		_AddNotFoundErrorWithCode(ww, ErrorCodeReferralHashNotFound, fmt.Sprintf(
+			"AdminGetReferralPayoutDetail: Referral hash %s not found", requestData.ReferralHashBase58))
+		return
+	}
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralPayoutDetail: Problem getting referral info: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralPayoutDetail: Problem fetching utxoView: %v", err))
+		return
+	}
+	refereePKID := utxoView.GetPKIDForPublicKey(refereePublicKeyBytes).PKID
+	referrerPKID := referralInfo.ReferrerPKID
+	if referrerPublicKeyBytes != nil {
+		referrerPKID = utxoView.GetPKIDForPublicKey(referrerPublicKeyBytes).PKID
+	}
+
+	refereeIndexVal, err := fes.getRefereeIndexValue(referrerPKID, referralInfo.ReferralHashBase58, refereePKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralPayoutDetail: %v", err))
+		return
+	}
+	if refereeIndexVal == nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetReferralPayoutDetail: Referee was not credited to referral hash %s",
+			referralInfo.ReferralHashBase58))
+		return
+	}
+
+	res := AdminGetReferralPayoutDetailResponse{}
+	// Entries written before payouts were recorded only hold a placeholder byte.
+	if len(refereeIndexVal) > 1 {
+		refereePayoutInfo, err := decodeRefereePayoutInfo(refereeIndexVal)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminGetReferralPayoutDetail: Problem decoding payout info: %v", err))
+			return
+		}
+		res.IsPayoutRecorded = true
+		res.UsdCentsPerDeSoExchangeRate = refereePayoutInfo.UsdCentsPerDeSoExchangeRate
+		res.RefereeDeSoNanos = refereePayoutInfo.RefereeDeSoNanos
+		res.ReferrerDeSoNanos = refereePayoutInfo.ReferrerDeSoNanos
+		res.RefereeUSDCents = float64(refereePayoutInfo.RefereeDeSoNanos) *
+			float64(refereePayoutInfo.UsdCentsPerDeSoExchangeRate) / float64(lib.NanosPerUnit)
+		res.ReferrerUSDCents = float64(refereePayoutInfo.ReferrerDeSoNanos) *
+			float64(refereePayoutInfo.UsdCentsPerDeSoExchangeRate) / float64(lib.NanosPerUnit)
+		res.TstampNanos = refereePayoutInfo.TstampNanos
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferralPayoutDetail: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// getRefereeIndexValue returns the referee index value recording that refereePKID was credited to a referral hash
+// under referrerPKID, or nil if there isn't one.
+func (fes *APIServer) getRefereeIndexValue(
+	referrerPKID *lib.PKID, referralHashBase58 string, refereePKID *lib.PKID) (_val []byte, _err error) {
+	val, err := fes.GlobalState.Get(GlobalStateKeyForPKIDReferralHashRefereePKID(
+		referrerPKID, []byte(referralHashBase58), refereePKID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "getRefereeIndexValue: Problem getting referee index entry")
+	}
+	return val, nil
+}
+
+type AdminDeleteReferralHashRequest struct {
+	ReferralHashBase58 string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminDeleteReferralHashResponse struct {
+	// False if the referral hash didn't exist (e.g. it was already deleted).
+	WasDeleted bool
+}
+
+// AdminDeleteReferralHash removes a referral hash's ReferralInfo and its status for the referrer. Deleting a
+// referral hash that doesn't exist succeeds with WasDeleted set to false. The referee index entries are kept so
+// that there is still a record of who was paid out through the referral hash.
+func (fes *APIServer) AdminDeleteReferralHash(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminDeleteReferralHashRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminDeleteReferralHash: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.ReferralHashBase58 == "" {
+		_AddBadRequestError(ww, "AdminDeleteReferralHash: Must provide a referral hash to delete")
+		return
+	}
+
+	// Resolve the hash before locking it so that we take the same lock as updateReferralInfoForReferralHash.
+	referralHashBase58, err := fes.resolveReferralHashBase58(requestData.ReferralHashBase58)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminDeleteReferralHash: %v", err))
+		return
+	}
+	defer fes.lockReferralHash(referralHashBase58)()
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(referralHashBase58)
+	if errors.Cause(err) == ErrReferralHashNotFound {
+		if err = json.NewEncoder(ww).Encode(AdminDeleteReferralHashResponse{}); err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("AdminDeleteReferralHash: Problem encoding response as JSON: %v", err))
+		}
+		return
+	}
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminDeleteReferralHash: Problem getting referral info for %s: %v", requestData.ReferralHashBase58, err))
+		return
+	}
+
+	// Delete the status before the info so that a failed delete can be retried; the status key can't be found
+	// without the ReferrerPKID in the info.
+	referralHashBytes := []byte(referralInfo.ReferralHashBase58)
+	if referralInfo.ReferrerPKID != nil {
+		if err = fes.GlobalState.Delete(GlobalStateKeyForPKIDReferralHashToIsActive(
+			referralInfo.ReferrerPKID, referralHashBytes)); err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminDeleteReferralHash: Problem deleting status for %s: %v", requestData.ReferralHashBase58, err))
+			return
+		}
+	}
+	if err = fes.GlobalState.Delete(GlobalStateKeyForReferralHashToReferralInfo(referralHashBytes)); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminDeleteReferralHash: Problem deleting referral info for %s: %v", requestData.ReferralHashBase58, err))
+		return
+	}
+	// Only remove the case-insensitive index entry if it points at this hash rather than one that differs by case.
+	indexKey := GlobalStateKeyForLowercaseReferralHashToReferralHash(referralInfo.ReferralHashBase58)
+	indexedReferralHash, err := fes.GlobalState.Get(indexKey)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminDeleteReferralHash: Problem getting index entry for %s: %v", requestData.ReferralHashBase58, err))
+		return
+	}
+	if string(indexedReferralHash) == referralInfo.ReferralHashBase58 {
+		if err = fes.GlobalState.Delete(indexKey); err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"AdminDeleteReferralHash: Problem deleting index entry for %s: %v", requestData.ReferralHashBase58, err))
+			return
+		}
+	}
+
+	if err = json.NewEncoder(ww).Encode(AdminDeleteReferralHashResponse{WasDeleted: true}); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminDeleteReferralHash: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// The maximum number of referral hashes that can be created in one AdminBatchCreateReferralHashes call.
+const maxBatchCreateReferralHashes = 1000
+
+type AdminBatchCreateReferralHashesEntry struct {
+	// A username or public name can be provided. If both are provided, public key is used.
+	UserPublicKeyBase58Check string `safeForLogging:"true"`
+	Username                 string `safeForLogging:"true"`
+
+	ReferrerAmountUSDCents uint64 `safeForLogging:"true"`
+	RefereeAmountUSDCents  uint64 `safeForLogging:"true"`
+	MaxReferrals           uint64 `safeForLogging:"true"`
+	RequiresJumio          bool   `safeForLogging:"true"`
+	ExpiresAtTStampNanos   uint64 `safeForLogging:"true"`
+}
+
+type AdminBatchCreateReferralHashesRequest struct {
+	Entries []AdminBatchCreateReferralHashesEntry `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminBatchCreateReferralHashesResult struct {
+	// Nil if the referral hash for the entry couldn't be created, in which case Error says why.
+	ReferralInfoResponse *ReferralInfoResponse `safeForLogging:"true"`
+	Error                string                `safeForLogging:"true"`
+}
+
+type AdminBatchCreateReferralHashesResponse struct {
+	// One result per entry, in the same order as the request's Entries.
+	Results         []AdminBatchCreateReferralHashesResult `safeForLogging:"true"`
+	NumLinksCreated uint64                                 `safeForLogging:"true"`
+}
+
+// AdminBatchCreateReferralHashes creates a referral hash for each entry. A bad entry doesn't stop the rest of the
+// batch from being created; its error is returned in the entry's result instead.
+func (fes *APIServer) AdminBatchCreateReferralHashes(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminBatchCreateReferralHashesRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminBatchCreateReferralHashes: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.Entries) == 0 {
+		_AddBadRequestError(ww, "AdminBatchCreateReferralHashes: Must provide at least one entry")
+		return
+	}
+	if len(requestData.Entries) > maxBatchCreateReferralHashes {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminBatchCreateReferralHashes: Got %d entries, which exceeds the maximum of %d",
+			len(requestData.Entries), maxBatchCreateReferralHashes))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminBatchCreateReferralHashes: Problem getting utxoView: %v", err))
+		return
+	}
+
+	res := AdminBatchCreateReferralHashesResponse{
+		Results: make([]AdminBatchCreateReferralHashesResult, len(requestData.Entries)),
+	}
+	for ii, entry := range requestData.Entries {
+		referralInfo, err := fes.createReferralHashForBatchEntry(utxoView, &entry, requestData.AdminPublicKey)
+		if err != nil {
+			res.Results[ii].Error = fmt.Sprintf("entry %d: %v", ii, err)
+			continue
+		}
+		res.Results[ii].ReferralInfoResponse = &ReferralInfoResponse{
+			IsActive:           true,
+			Info:               *referralInfo,
+			RemainingReferrals: referralInfo.MaxReferrals,
+		}
+		res.NumLinksCreated++
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminBatchCreateReferralHashes: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// createReferralHashForBatchEntry validates a single AdminBatchCreateReferralHashes entry and creates an active
+// referral hash for it.
+func (fes *APIServer) createReferralHashForBatchEntry(
+	utxoView *lib.UtxoView, entry *AdminBatchCreateReferralHashesEntry, adminPublicKey string,
+) (_referralInfo *ReferralInfo, _err error) {
+	if err := fes.validateReferralAmountsUSDCents(entry.ReferrerAmountUSDCents, entry.RefereeAmountUSDCents); err != nil {
+		return nil, err
+	}
+
+	// Figure out who the referrer is.
+	var referrerPublicKeyBytes []byte
+	if entry.UserPublicKeyBase58Check != "" {
+		publicKeyBytes, _, err := lib.Base58CheckDecode(entry.UserPublicKeyBase58Check)
+		if err != nil || len(publicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+			return nil, fmt.Errorf("Problem decoding public key %s: %v", entry.UserPublicKeyBase58Check, err)
+		}
+		referrerPublicKeyBytes = publicKeyBytes
+	} else if entry.Username != "" {
+		profile := utxoView.GetProfileEntryForUsername([]byte(entry.Username))
+		if profile == nil {
+			return nil, fmt.Errorf("No profile found for username %s", entry.Username)
+		}
+		referrerPublicKeyBytes = profile.PublicKey
+	} else {
+		return nil, fmt.Errorf("Must provide a valid username or public key")
+	}
+	referrerPKID := utxoView.GetPKIDForPublicKey(referrerPublicKeyBytes)
+	if referrerPKID == nil {
+		return nil, fmt.Errorf("nil PKID for pubkey: %v", lib.PkToString(referrerPublicKeyBytes, fes.Params))
+	}
+
+	referralHashBase58, unlock, err := fes.generateAndLockNewReferralHash()
+	if err != nil {
+		return nil, fmt.Errorf("Problem generating referral hash: %v", err)
+	}
+	defer unlock()
+
+	referralInfo := &ReferralInfo{
+		ReferrerAmountUSDCents:  entry.ReferrerAmountUSDCents,
+		RefereeAmountUSDCents:   entry.RefereeAmountUSDCents,
+		MaxReferrals:            entry.MaxReferrals,
+		RequiresJumio:           entry.RequiresJumio,
+		ReferralHashBase58:      referralHashBase58,
+		ReferrerPKID:            referrerPKID.PKID,
+		DateCreatedTStampNanos:  uint64(time.Now().UnixNano()),
+		ExpiresAtTStampNanos:    entry.ExpiresAtTStampNanos,
+		CreatedByAdminPublicKey: adminPublicKey,
+	}
+	if err = fes.putReferralHashWithInfo(referralHashBase58, referralInfo); err != nil {
+		return nil, fmt.Errorf("Problem putting referral hash %s: %v", referralHashBase58, err)
+	}
+	if err = fes.setReferralHashStatusForPKID(referrerPKID.PKID, referralHashBase58, true); err != nil {
+		return nil, fmt.Errorf("Problem setting referral hash status for %s: %v", referralHashBase58, err)
+	}
+	return referralInfo, nil
+}
+
+const (
+	ReferralStatsGranularityDay  = "day"
+	ReferralStatsGranularityWeek = "week"
+
+	// The longest time range AdminGetReferralStats will bucket, so that a bad range can't produce an unbounded
+	// number of buckets.
+	maxReferralStatsRange = 366 * 24 * time.Hour
+)
+
+type AdminGetReferralStatsRequest struct {
+	ReferralHashBase58 string `safeForLogging:"true"`
+
+	// Referees paid out at or after StartTStampNanos and before EndTStampNanos are bucketed. The range can be at
+	// most a year. The first bucket starts at StartTStampNanos.
+	StartTStampNanos uint64 `safeForLogging:"true"`
+	EndTStampNanos   uint64 `safeForLogging:"true"`
+
+	// Either "day" or "week". Defaults to "day".
+	Granularity string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type ReferralStatsBucket struct {
+	StartTStampNanos uint64
+	NumReferees      uint64
+	// The referee and referrer payouts combined.
+	TotalDeSoNanosPaidOut uint64
+}
+
+type AdminGetReferralStatsResponse struct {
+	Buckets []ReferralStatsBucket
+
+	// Referees credited before payouts were recorded have no timestamp, so they can't be bucketed. They are
+	// counted here instead, regardless of the requested range.
+	NumRefereesWithoutTimestamp uint64
+
+	// The all-time totals from the referral hash's ReferralInfo.
+	TotalReferrals         uint64
+	TotalReferrerDeSoNanos uint64
+	TotalRefereeDeSoNanos  uint64
+}
+
+// AdminGetReferralStats buckets the referees credited to a referral hash by when they were paid out. Referees are
+// read from the referee index under the referral hash's current referrer.
+func (fes *APIServer) AdminGetReferralStats(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetReferralStatsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferralStats: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.ReferralHashBase58 == "" {
+		_AddBadRequestError(ww, "AdminGetReferralStats: Must provide a ReferralHashBase58")
+		return
+	}
+
+	var bucketDuration time.Duration
+	switch requestData.Granularity {
+	case "", ReferralStatsGranularityDay:
+		bucketDuration = 24 * time.Hour
+	case ReferralStatsGranularityWeek:
+		bucketDuration = 7 * 24 * time.Hour
+	default:
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetReferralStats: Granularity must be %q or %q, got %q",
+			ReferralStatsGranularityDay, ReferralStatsGranularityWeek, requestData.Granularity))
+		return
+	}
+
+	if requestData.EndTStampNanos <= requestData.StartTStampNanos {
+		_AddBadRequestError(ww, "AdminGetReferralStats: EndTStampNanos must be after StartTStampNanos")
+		return
+	}
+	if requestData.EndTStampNanos-requestData.StartTStampNanos > uint64(maxReferralStatsRange) {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetReferralStats: Time range cannot be longer than %v", maxReferralStatsRange))
+		return
+	}
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
+	if errors.Cause(err) == ErrReferralHashNotFound {
+		_AddNotFoundErrorWithCode(ww, ErrorCodeReferralHashNotFound, fmt.Sprintf(
+			"AdminGetReferralStats: Referral hash %s not found", requestData.ReferralHashBase58))
+		return
+	}
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralStats: Problem getting referral info: %v", err))
+		return
+	}
+
+	numBuckets := (requestData.EndTStampNanos - requestData.StartTStampNanos + uint64(bucketDuration) - 1) /
+		uint64(bucketDuration)
+	res := AdminGetReferralStatsResponse{
+		Buckets:                make([]ReferralStatsBucket, numBuckets),
+		TotalReferrals:         referralInfo.TotalReferrals,
+		TotalReferrerDeSoNanos: referralInfo.TotalReferrerDeSoNanos,
+		TotalRefereeDeSoNanos:  referralInfo.TotalRefereeDeSoNanos,
+	}
+	for ii := range res.Buckets {
+		res.Buckets[ii].StartTStampNanos = requestData.StartTStampNanos + uint64(ii)*uint64(bucketDuration)
+	}
+
+	refereeSeekKey := GlobalStateSeekKeyForPKIDReferralHashRefereePKIDs(
+		referralInfo.ReferrerPKID, []byte(requestData.ReferralHashBase58))
+	_, refereeVals, err := fes.GlobalState.Seek(refereeSeekKey, refereeSeekKey, 0, 0, false, true)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralStats: Problem getting referees: %v", err))
+		return
+	}
+	for _, refereeVal := range refereeVals {
+		// Entries written before payouts were recorded only hold a placeholder byte.
+		if len(refereeVal) <= 1 {
+			res.NumRefereesWithoutTimestamp++
+			continue
+		}
+		refereePayoutInfo, err := decodeRefereePayoutInfo(refereeVal)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferralStats: Problem decoding payout info: %v", err))
+			return
+		}
+		if refereePayoutInfo.TstampNanos < requestData.StartTStampNanos ||
+			refereePayoutInfo.TstampNanos >= requestData.EndTStampNanos {
+			continue
+		}
+		bucket := &res.Buckets[(refereePayoutInfo.TstampNanos-requestData.StartTStampNanos)/uint64(bucketDuration)]
+		bucket.NumReferees++
+		bucket.TotalDeSoNanosPaidOut += refereePayoutInfo.RefereeDeSoNanos + refereePayoutInfo.ReferrerDeSoNanos
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferralStats: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type AdminGetRefereePayoutsRequest struct {
+	ReferralHashBase58 string `safeForLogging:"true"`
+	// Optional. Up to PageSize referees starting from StartRefereePublicKeyBase58Check (inclusive) are returned,
+	// capped at --max-page-size. Pass NextRefereePublicKeyBase58Check from the previous response to get the next
+	// page.
+	StartRefereePublicKeyBase58Check string `safeForLogging:"true"`
+	PageSize                         uint64 `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type RefereePayout struct {
+	RefereePublicKeyBase58Check string
+
+	// False for referees credited before payouts were recorded. In that case none of the fields below are set.
+	IsPayoutRecorded bool
+
+	UsdCentsPerDeSoExchangeRate uint64
+	RefereeDeSoNanos            uint64
+	ReferrerDeSoNanos           uint64
+	TstampNanos                 uint64
+	// Empty if that side wasn't paid or the payout was recorded before transaction hashes were tracked.
+	RefereeTxnHashHex  string
+	ReferrerTxnHashHex string
+}
+
+type AdminGetRefereePayoutsResponse struct {
+	// Pages are read in referee PKID order. Within a page, payouts are sorted by TstampNanos, oldest first, and
+	// referees without a recorded payout come first.
+	RefereePayouts []RefereePayout
+
+	// The referee to pass as StartRefereePublicKeyBase58Check to get the next page. Empty once every referee has
+	// been returned.
+	NextRefereePublicKeyBase58Check string
+}
+
+// AdminGetRefereePayouts returns what was paid out for each referee credited to a referral hash. Like
+// AdminGetReferralStats, referees are read from the referee index under the referral hash's current referrer.
+func (fes *APIServer) AdminGetRefereePayouts(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetRefereePayoutsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetRefereePayouts: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.ReferralHashBase58 == "" {
+		_AddBadRequestError(ww, "AdminGetRefereePayouts: Must provide a ReferralHashBase58")
+		return
+	}
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
+	if errors.Cause(err) == ErrReferralHashNotFound {
+		_AddNotFoundErrorWithCode(ww, ErrorCodeReferralHashNotFound, fmt.Sprintf(
+			"AdminGetRefereePayouts: Referral hash %s not found", requestData.ReferralHashBase58))
+		return
+	}
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetRefereePayouts: Problem getting referral info: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetRefereePayouts: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	refereeSeekKey := GlobalStateSeekKeyForPKIDReferralHashRefereePKIDs(
+		referralInfo.ReferrerPKID, []byte(referralInfo.ReferralHashBase58))
+	refereeStartKey := refereeSeekKey
+	if requestData.StartRefereePublicKeyBase58Check != "" {
+		startRefereePublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.StartRefereePublicKeyBase58Check)
+		if err != nil || len(startRefereePublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"AdminGetRefereePayouts: Problem decoding start referee public key %s: %v",
+				requestData.StartRefereePublicKeyBase58Check, err))
+			return
+		}
+		startRefereePKID := utxoView.GetPKIDForPublicKey(startRefereePublicKeyBytes).PKID
+		refereeStartKey = GlobalStateKeyForPKIDReferralHashRefereePKID(
+			referralInfo.ReferrerPKID, []byte(referralInfo.ReferralHashBase58), startRefereePKID)
+	}
+
+	// Fetch one extra key so we know whether there is another page.
+	pageSize := fes.getPageSize(requestData.PageSize)
+	refereeKeys, refereeVals, err := fes.GlobalState.Seek(
+		refereeStartKey, refereeSeekKey, 0, pageSize+1, false /*reverse*/, true /*fetchValue*/)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetRefereePayouts: Problem getting referees: %v", err))
+		return
+	}
+
+	res := AdminGetRefereePayoutsResponse{RefereePayouts: []RefereePayout{}}
+	if len(refereeKeys) > pageSize {
+		nextRefereePKID := &lib.PKID{}
+		copy(nextRefereePKID[:], refereeKeys[pageSize][len(refereeSeekKey):])
+		res.NextRefereePublicKeyBase58Check = lib.PkToString(
+			utxoView.GetPublicKeyForPKID(nextRefereePKID), fes.Params)
+		refereeKeys = refereeKeys[:pageSize]
+		refereeVals = refereeVals[:pageSize]
+	}
+	for ii, refereeKey := range refereeKeys {
+		if len(refereeKey) != len(refereeSeekKey)+btcec.PubKeyBytesLenCompressed {
+			continue
+		}
+		refereePKID := &lib.PKID{}
+		copy(refereePKID[:], refereeKey[len(refereeSeekKey):])
+		refereePayout := RefereePayout{
+			RefereePublicKeyBase58Check: lib.PkToString(utxoView.GetPublicKeyForPKID(refereePKID), fes.Params),
+		}
+
+		// Entries written before payouts were recorded only hold a placeholder byte.
+		if len(refereeVals[ii]) > 1 {
+			refereePayoutInfo, err := decodeRefereePayoutInfo(refereeVals[ii])
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf(
+					"AdminGetRefereePayouts: Problem decoding payout info for %s: %v",
+					refereePayout.RefereePublicKeyBase58Check, err))
+				return
+			}
+			refereePayout.IsPayoutRecorded = true
+			refereePayout.UsdCentsPerDeSoExchangeRate = refereePayoutInfo.UsdCentsPerDeSoExchangeRate
+			refereePayout.RefereeDeSoNanos = refereePayoutInfo.RefereeDeSoNanos
+			refereePayout.ReferrerDeSoNanos = refereePayoutInfo.ReferrerDeSoNanos
+			refereePayout.TstampNanos = refereePayoutInfo.TstampNanos
+			refereePayout.RefereeTxnHashHex = refereePayoutInfo.RefereeTxnHashHex
+			refereePayout.ReferrerTxnHashHex = refereePayoutInfo.ReferrerTxnHashHex
+		}
+		res.RefereePayouts = append(res.RefereePayouts, refereePayout)
+	}
+	sort.SliceStable(res.RefereePayouts, func(ii, jj int) bool {
+		return res.RefereePayouts[ii].TstampNanos < res.RefereePayouts[jj].TstampNanos
+	})
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetRefereePayouts: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type AdminGetReferrerForReferralHashRequest struct {
+	ReferralHashBase58 string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminGetReferrerForReferralHashResponse struct {
+	IsActive bool
+	Info     ReferralInfo
+	// Only PublicKeyBase58Check is set if the referrer doesn't have a profile.
+	ReferrerProfileEntryResponse *ProfileEntryResponse
+}
+
+// AdminGetReferrerForReferralHash returns a referral hash's info along with its referrer's profile, so the referrer
+// can be looked up from a link without first fetching the link's ReferrerPKID.
+func (fes *APIServer) AdminGetReferrerForReferralHash(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminGetReferrerForReferralHashRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminGetReferrerForReferralHash: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.ReferralHashBase58 == "" {
+		_AddBadRequestError(ww, "AdminGetReferrerForReferralHash: Must provide a ReferralHashBase58")
+		return
+	}
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
+	if errors.Cause(err) == ErrReferralHashNotFound {
+		_AddNotFoundErrorWithCode(ww, ErrorCodeReferralHashNotFound, fmt.Sprintf(
+			"AdminGetReferrerForReferralHash: Referral hash %s not found", requestData.ReferralHashBase58))
+		return
+	}
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"AdminGetReferrerForReferralHash: Problem getting referral info: %v", err))
+		return
+	}
+
+	utxoView, err := fes.mempool.GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("AdminGetReferrerForReferralHash: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	res := AdminGetReferrerForReferralHashResponse{
+		IsActive:                     fes.isReferralHashActive(referralInfo),
+		Info:                         *referralInfo,
+		ReferrerProfileEntryResponse: fes.getProfileEntryResponseForPKID(referralInfo.ReferrerPKID, utxoView),
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"AdminGetReferrerForReferralHash: Problem encoding response as JSON: %v", err))
 		return
 	}
 }
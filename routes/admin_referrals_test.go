@@ -0,0 +1,784 @@
+package routes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/deso-smart/deso-backend/v3/config"
+	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateReferralInfoForReferralHashConcurrent(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+
+	referralHashBase58 := "abcdefgh"
+	require.NoError(fes.putReferralHashWithInfo(referralHashBase58, &ReferralInfo{
+		ReferralHashBase58: referralHashBase58,
+	}))
+
+	// Every payout should be reflected in the totals, no matter how the updates interleave.
+	numPayouts := 50
+	var wg sync.WaitGroup
+	for ii := 0; ii < numPayouts; ii++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := fes.updateReferralInfoForReferralHash(referralHashBase58, func(referralInfo *ReferralInfo) error {
+				referralInfo.TotalReferrals++
+				referralInfo.TotalReferrerDeSoNanos += 10
+				referralInfo.TotalRefereeDeSoNanos += 5
+				return nil
+			})
+			require.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(referralHashBase58)
+	require.NoError(err)
+	require.Equal(uint64(numPayouts), referralInfo.TotalReferrals)
+	require.Equal(uint64(numPayouts*10), referralInfo.TotalReferrerDeSoNanos)
+	require.Equal(uint64(numPayouts*5), referralInfo.TotalRefereeDeSoNanos)
+
+	// Updating a referral hash that doesn't exist should fail without writing anything.
+	_, err = fes.updateReferralInfoForReferralHash("missing1", func(*ReferralInfo) error { return nil })
+	require.Error(err)
+}
+
+func TestReferralCSVBatchWriterFlush(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err)
+	referrerPublicKeyBase58Check := lib.Base58CheckEncode(
+		privKey.PubKey().SerializeCompressed(), false, &lib.DeSoTestnetParams)
+	csvRow := func(referralHashBase58 string, maxReferrals string) []string {
+		row := make([]string, CSVColumnExpiresAtTStampNanos+1)
+		row[CSVColumnReferralHash] = referralHashBase58
+		row[CSVColumnPKID] = referrerPublicKeyBase58Check
+		row[CSVColumnReferrerAmount] = "100"
+		row[CSVColumnRefereeAmount] = "200"
+		row[CSVColumnMaxReferrals] = maxReferrals
+		row[CSVColumnRequiresJumio] = "false"
+		return row
+	}
+	for _, referralHashBase58 := range []string{"aaaaaaaa", "bbbbbbbb"} {
+		require.NoError(fes.putReferralHashWithInfo(referralHashBase58, &ReferralInfo{
+			ReferralHashBase58: referralHashBase58,
+		}))
+	}
+
+	// Two batches that update the same hashes in opposite orders should both flush instead of deadlocking.
+	writer1 := fes.newReferralCSVBatchWriter(nil)
+	require.NoError(writer1.addRow(csvRow("aaaaaaaa", "5")))
+	require.NoError(writer1.addRow(csvRow("bbbbbbbb", "5")))
+	writer2 := fes.newReferralCSVBatchWriter(nil)
+	require.NoError(writer2.addRow(csvRow("bbbbbbbb", "7")))
+	require.NoError(writer2.addRow(csvRow("aaaaaaaa", "7")))
+
+	// Stats updated while the rows are queued shouldn't be overwritten by the flush.
+	_, err = fes.updateReferralInfoForReferralHash("aaaaaaaa", func(referralInfo *ReferralInfo) error {
+		referralInfo.TotalReferrals = 3
+		return nil
+	})
+	require.NoError(err)
+
+	flushErrs := make(chan error, 2)
+	go func() { flushErrs <- writer1.flush() }()
+	go func() { flushErrs <- writer2.flush() }()
+	for ii := 0; ii < 2; ii++ {
+		select {
+		case err = <-flushErrs:
+			require.NoError(err)
+		case <-time.After(10 * time.Second):
+			require.Fail("Timed out waiting for the batches to flush")
+		}
+	}
+
+	referralInfo, err := fes.getInfoForReferralHashBase58("aaaaaaaa")
+	require.NoError(err)
+	require.Equal(uint64(3), referralInfo.TotalReferrals)
+	require.Equal(uint64(100), referralInfo.ReferrerAmountUSDCents)
+	require.Contains([]uint64{5, 7}, referralInfo.MaxReferrals)
+
+	// A row for a referral hash that doesn't exist fails without queuing anything.
+	writer3 := fes.newReferralCSVBatchWriter(nil)
+	require.Error(writer3.addRow(csvRow("missing1", "5")))
+	require.Zero(writer3.numRows)
+	require.False(writer3.hasQueuedReferralHash("missing1"))
+}
+
+func TestGetReferralInfosPage(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+
+	referralHashes := []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dddddddd", "eeeeeeee"}
+	for _, referralHash := range referralHashes {
+		require.NoError(fes.putReferralHashWithInfo(referralHash, &ReferralInfo{ReferralHashBase58: referralHash}))
+	}
+
+	// Walking the pages with the cursor should return every referral hash exactly once, in order.
+	var pagedReferralHashes []string
+	nextReferralHash := ""
+	numPages := 0
+	for {
+		referralInfos, pageNextReferralHash, err := fes.getReferralInfosPage(nextReferralHash, 2)
+		require.NoError(err)
+		require.LessOrEqual(len(referralInfos), 2)
+		for _, referralInfo := range referralInfos {
+			pagedReferralHashes = append(pagedReferralHashes, referralInfo.ReferralHashBase58)
+		}
+		numPages++
+		if pageNextReferralHash == "" {
+			break
+		}
+		nextReferralHash = pageNextReferralHash
+	}
+	require.Equal(referralHashes, pagedReferralHashes)
+	require.Equal(3, numPages)
+
+	// A page that exactly reaches the end shouldn't return a cursor.
+	referralInfos, nextReferralHash, err := fes.getReferralInfosPage("dddddddd", 2)
+	require.NoError(err)
+	require.Len(referralInfos, 2)
+	require.Equal("", nextReferralHash)
+
+	allReferralInfos, err := fes.getAllReferralInfos()
+	require.NoError(err)
+	require.Len(allReferralInfos, len(referralHashes))
+
+	_, _, err = fes.getReferralInfosPage("", 0)
+	require.Error(err)
+}
+
+func TestGetRefereeKeysPage(t *testing.T) {
+	require := require.New(t)
+
+	refereeKeys := [][]byte{{1}, {2}, {3}, {4}, {5}}
+
+	// The defaults return every referee, as before pagination was added.
+	require.Equal(refereeKeys, getRefereeKeysPage(refereeKeys, 0, 0))
+
+	require.Equal([][]byte{{1}, {2}}, getRefereeKeysPage(refereeKeys, 0, 2))
+	require.Equal([][]byte{{3}, {4}}, getRefereeKeysPage(refereeKeys, 2, 2))
+	require.Equal([][]byte{{5}}, getRefereeKeysPage(refereeKeys, 4, 2))
+	require.Equal([][]byte{{4}, {5}}, getRefereeKeysPage(refereeKeys, 3, 0))
+	require.Equal(refereeKeys, getRefereeKeysPage(refereeKeys, 0, 10))
+	require.Empty(getRefereeKeysPage(refereeKeys, 5, 2))
+	require.Empty(getRefereeKeysPage(refereeKeys, 10, 0))
+	require.Empty(getRefereeKeysPage(nil, 0, 0))
+}
+
+func TestGenerateNewReferralHashRetriesOnCollision(t *testing.T) {
+	require := require.New(t)
+
+	// The first two candidates collide with existing referral hashes.
+	var candidates []string
+	referralHashExists := func(referralHashBase58 string) (bool, error) {
+		candidates = append(candidates, referralHashBase58)
+		return len(candidates) <= 2, nil
+	}
+	referralHashBase58, err := generateNewReferralHash(referralHashExists)
+	require.NoError(err)
+	require.Len(candidates, 3)
+	require.Equal(candidates[2], referralHashBase58)
+	require.Len(referralHashBase58, 8)
+
+	// If every candidate collides we give up rather than overwrite an existing referral hash.
+	numAttempts := 0
+	_, err = generateNewReferralHash(func(string) (bool, error) {
+		numAttempts++
+		return true, nil
+	})
+	require.Error(err)
+	require.Equal(maxReferralHashGenerationAttempts, numAttempts)
+}
+
+func TestReferralHashExists(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+
+	referralHashBase58, err := generateNewReferralHash(fes.referralHashExists)
+	require.NoError(err)
+	require.NoError(fes.putReferralHashWithInfo(referralHashBase58, &ReferralInfo{
+		ReferralHashBase58: referralHashBase58,
+	}))
+
+	exists, err := fes.referralHashExists(referralHashBase58)
+	require.NoError(err)
+	require.True(exists)
+
+	exists, err = fes.referralHashExists("notfound")
+	require.NoError(err)
+	require.False(exists)
+}
+
+func TestGetInfoForReferralHashBase58CaseInsensitive(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}, Config: &config.Config{}}
+
+	require.NoError(fes.putReferralHashWithInfo("Ab12Cd34", &ReferralInfo{ReferralHashBase58: "Ab12Cd34"}))
+	require.NoError(fes.putReferralHashWithInfo("aB12cD34", &ReferralInfo{ReferralHashBase58: "aB12cD34"}))
+
+	// Surrounding whitespace is always ignored, but case only matters when the flag is off.
+	referralInfo, err := fes.getInfoForReferralHashBase58(" Ab12Cd34\n")
+	require.NoError(err)
+	require.Equal("Ab12Cd34", referralInfo.ReferralHashBase58)
+	_, err = fes.getInfoForReferralHashBase58("ab12cd34")
+	require.Equal(ErrReferralHashNotFound, errors.Cause(err))
+
+	// With the flag on, exact matches still win and everything else resolves to the first hash indexed.
+	fes.Config.CaseInsensitiveReferralHashes = true
+	for lookupHash, expectedHash := range map[string]string{
+		"ab12cd34": "Ab12Cd34", "AB12CD34": "Ab12Cd34", "aB12cD34": "aB12cD34",
+	} {
+		referralInfo, err = fes.getInfoForReferralHashBase58(lookupHash)
+		require.NoError(err)
+		require.Equal(expectedHash, referralInfo.ReferralHashBase58)
+	}
+
+	// Updates should land on the stored hash rather than creating a new one.
+	_, err = fes.updateReferralInfoForReferralHash("ab12cd34", func(referralInfo *ReferralInfo) error {
+		referralInfo.TotalReferrals++
+		return nil
+	})
+	require.NoError(err)
+	referralInfo, err = fes.getInfoForReferralHashBase58("Ab12Cd34")
+	require.NoError(err)
+	require.Equal(uint64(1), referralInfo.TotalReferrals)
+
+	// New hashes can't collide with existing ones ignoring case.
+	exists, err := fes.referralHashExists("AB12CD34")
+	require.NoError(err)
+	require.True(exists)
+}
+
+func TestReferralHashCaseInsensitiveWrites(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{
+		GlobalState: &GlobalState{GlobalStateDB: db},
+		Config:      &config.Config{CaseInsensitiveReferralHashes: true},
+	}
+
+	// A hash written before the index existed only resolves regardless of case once it's backfilled.
+	legacyInfoBytes, err := encodeReferralInfo(&ReferralInfo{ReferralHashBase58: "LeGaCy12"})
+	require.NoError(err)
+	require.NoError(fes.GlobalState.Put(GlobalStateKeyForReferralHashToReferralInfo([]byte("LeGaCy12")), legacyInfoBytes))
+	_, err = fes.getInfoForReferralHashBase58("legacy12")
+	require.Equal(ErrReferralHashNotFound, errors.Cause(err))
+	require.NoError(fes.backfillReferralHashIndex())
+	referralInfo, err := fes.getInfoForReferralHashBase58("legacy12")
+	require.NoError(err)
+	require.Equal("LeGaCy12", referralInfo.ReferralHashBase58)
+
+	// A CSV row that cases the hash differently updates the stored hash and writes its status under it.
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err)
+	row := make([]string, CSVColumnExpiresAtTStampNanos+1)
+	row[CSVColumnReferralHash] = "LEGACY12"
+	row[CSVColumnPKID] = lib.Base58CheckEncode(privKey.PubKey().SerializeCompressed(), false, &lib.DeSoTestnetParams)
+	row[CSVColumnReferrerAmount] = "100"
+	row[CSVColumnRefereeAmount] = "200"
+	row[CSVColumnMaxReferrals] = "5"
+	row[CSVColumnRequiresJumio] = "false"
+	writer := fes.newReferralCSVBatchWriter(nil)
+	require.NoError(writer.resolveRowReferralHash(row))
+	require.Equal("LeGaCy12", row[CSVColumnReferralHash])
+	require.NoError(writer.addRow(row))
+	require.NoError(writer.flush())
+
+	referralInfo, err = fes.getInfoForReferralHashBase58("LeGaCy12")
+	require.NoError(err)
+	require.Equal(uint64(5), referralInfo.MaxReferrals)
+	wrongCaseInfoBytes, err := fes.GlobalState.Get(GlobalStateKeyForReferralHashToReferralInfo([]byte("LEGACY12")))
+	require.NoError(err)
+	require.Nil(wrongCaseInfoBytes)
+	require.True(fes.getReferralHashStatus(referralInfo.ReferrerPKID, "LeGaCy12"))
+}
+
+func TestDecodeReferralInfoGobAndJSON(t *testing.T) {
+	require := require.New(t)
+
+	referralInfo := &ReferralInfo{
+		ReferralHashBase58:     "abcdefgh",
+		ReferrerPKID:           &lib.PKID{1, 2, 3},
+		ReferrerAmountUSDCents: 100,
+		RefereeAmountUSDCents:  200,
+		MaxReferrals:           10,
+		RequiresJumio:          true,
+		TotalReferrals:         3,
+		DateCreatedTStampNanos: 12345,
+	}
+
+	// Legacy records were written with gob.
+	gobBuf := bytes.NewBuffer([]byte{})
+	require.NoError(gob.NewEncoder(gobBuf).Encode(referralInfo))
+	decodedGobInfo, err := decodeReferralInfo(gobBuf.Bytes())
+	require.NoError(err)
+	require.Equal(referralInfo, decodedGobInfo)
+
+	// New records are JSON tagged with the schema version.
+	jsonBytes, err := encodeReferralInfo(referralInfo)
+	require.NoError(err)
+	require.Contains(string(jsonBytes), `"SchemaVersion":1`)
+	decodedJSONInfo, err := decodeReferralInfo(jsonBytes)
+	require.NoError(err)
+	require.Equal(referralInfo, decodedJSONInfo)
+
+	// Records from a newer schema than we know about are rejected rather than silently misread.
+	_, err = decodeReferralInfo([]byte(`{"SchemaVersion":2}`))
+	require.Error(err)
+
+	// A legacy gob record should still be readable through global state after the switch.
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+	require.NoError(fes.GlobalState.Put(
+		GlobalStateKeyForReferralHashToReferralInfo([]byte(referralInfo.ReferralHashBase58)), gobBuf.Bytes()))
+	storedInfo, err := fes.getInfoForReferralHashBase58(referralInfo.ReferralHashBase58)
+	require.NoError(err)
+	require.Equal(referralInfo, storedInfo)
+}
+
+func TestDecodeRefereePayoutInfoGobAndJSON(t *testing.T) {
+	require := require.New(t)
+
+	refereePayoutInfo := &RefereePayoutInfo{
+		UsdCentsPerDeSoExchangeRate: 1000,
+		RefereeDeSoNanos:            100,
+		ReferrerDeSoNanos:           200,
+		TstampNanos:                 12345,
+		RefereeTxnHashHex:           "abcd",
+	}
+
+	// Legacy records were written with gob.
+	gobBuf := bytes.NewBuffer([]byte{})
+	require.NoError(gob.NewEncoder(gobBuf).Encode(refereePayoutInfo))
+	decodedGobInfo, err := decodeRefereePayoutInfo(gobBuf.Bytes())
+	require.NoError(err)
+	require.Equal(refereePayoutInfo, decodedGobInfo)
+
+	// New records are JSON tagged with the schema version.
+	jsonBytes, err := encodeRefereePayoutInfo(refereePayoutInfo)
+	require.NoError(err)
+	require.Contains(string(jsonBytes), `"SchemaVersion":1`)
+	decodedJSONInfo, err := decodeRefereePayoutInfo(jsonBytes)
+	require.NoError(err)
+	require.Equal(refereePayoutInfo, decodedJSONInfo)
+
+	_, err = decodeRefereePayoutInfo([]byte(`{"SchemaVersion":2}`))
+	require.Error(err)
+}
+
+func TestReferralAdminRoutesRequireSuperAdmin(t *testing.T) {
+	require := require.New(t)
+
+	newKeyAndJWT := func() (string, string) {
+		privKey, err := btcec.NewPrivateKey(btcec.S256())
+		require.NoError(err)
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		signedToken, err := token.SignedString(privKey.ToECDSA())
+		require.NoError(err)
+		return lib.PkToString(privKey.PubKey().SerializeCompressed(), &lib.DeSoTestnetParams), signedToken
+	}
+	superAdminPublicKey, _ := newKeyAndJWT()
+	adminPublicKey, adminJWT := newKeyAndJWT()
+	userPublicKey, userJWT := newKeyAndJWT()
+
+	fes := &APIServer{Config: &config.Config{
+		AdminPublicKeys:      []string{adminPublicKey},
+		SuperAdminPublicKeys: []string{superAdminPublicKey},
+		EnableReferrals:      true,
+	}}
+	router := fes.NewRouter()
+
+	// These routes must be registered as SuperAdminAccess, so even a regular admin is turned away by the router.
+	routePaths := []string{
+		RoutePathAdminCreateReferralHash,
+		RoutePathAdminUpdateReferralHash,
+		RoutePathAdminGetAllReferralInfoForUser,
+		RoutePathAdminDownloadReferralCSV,
+		RoutePathAdminDownloadRefereeCSV,
+	}
+	requestBodies := map[string]AdminRequest{
+		"admin":               {AdminPublicKey: adminPublicKey, JWT: adminJWT},
+		"non-admin":           {AdminPublicKey: userPublicKey, JWT: userJWT},
+		"stolen admin key":    {AdminPublicKey: superAdminPublicKey, JWT: userJWT},
+		"missing admin key":   {},
+		"invalid admin token": {AdminPublicKey: superAdminPublicKey, JWT: "invalid"},
+	}
+	for _, routePath := range routePaths {
+		for bodyName, requestBody := range requestBodies {
+			bodyBytes, err := json.Marshal(requestBody)
+			require.NoError(err)
+			req := httptest.NewRequest("POST", routePath, bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			require.Equal(http.StatusBadRequest, rr.Code, "%s: %s", routePath, bodyName)
+			require.Contains(rr.Body.String(), "CheckAdminPublicKey", "%s: %s", routePath, bodyName)
+		}
+	}
+}
+
+func TestReferralEndpointsDisabled(t *testing.T) {
+	require := require.New(t)
+
+	// With no GlobalState set, any handler that got past the check would panic.
+	fes := &APIServer{Config: &config.Config{SuperAdminPublicKeys: []string{"*"}, EnableReferrals: false}}
+	for handlerName, handler := range map[string]http.HandlerFunc{
+		"AdminCreateReferralHash":         fes.AdminCreateReferralHash,
+		"AdminGetAllReferralInfoForUser":  fes.AdminGetAllReferralInfoForUser,
+		"AdminUploadReferralCSV":          fes.AdminUploadReferralCSV,
+		"AdminGetReferrerForReferralHash": fes.AdminGetReferrerForReferralHash,
+		"GetReferralInfoForReferralHash":  fes.GetReferralInfoForReferralHash,
+	} {
+		req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("{}")))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		require.Equal(http.StatusNotFound, rr.Code, handlerName)
+	}
+}
+
+func TestAdminGetReferrerForReferralHash(t *testing.T) {
+	require := require.New(t)
+
+	chain, params, db := NewLowDifficultyBlockchain()
+	mempool, _ := NewTestMiner(t, chain, params, true /*isSender*/)
+	globalStateDB, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer globalStateDB.Close()
+	fes := &APIServer{
+		GlobalState: &GlobalState{GlobalStateDB: globalStateDB},
+		Config:      &config.Config{EnableReferrals: true},
+		Params:      params,
+		mempool:     mempool,
+	}
+
+	// One referrer has a profile and the other is anonymous.
+	profilePrivKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err)
+	profilePublicKey := profilePrivKey.PubKey().SerializeCompressed()
+	require.NoError(lib.DBPutProfileEntryMappings(db, nil, 0, &lib.ProfileEntry{
+		PublicKey: profilePublicKey,
+		Username:  []byte("referrer"),
+	}, lib.PublicKeyToPKID(profilePublicKey), params))
+	anonPrivKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err)
+	anonPublicKey := anonPrivKey.PubKey().SerializeCompressed()
+
+	require.NoError(fes.putReferralHashWithInfo("Ab12Cd34", &ReferralInfo{
+		ReferralHashBase58: "Ab12Cd34",
+		ReferrerPKID:       lib.PublicKeyToPKID(profilePublicKey),
+	}))
+	require.NoError(fes.putReferralHashWithInfo("Ef56Gh78", &ReferralInfo{
+		ReferralHashBase58: "Ef56Gh78",
+		ReferrerPKID:       lib.PublicKeyToPKID(anonPublicKey),
+	}))
+
+	getReferrer := func(referralHashBase58 string) (*httptest.ResponseRecorder, *AdminGetReferrerForReferralHashResponse) {
+		bodyBytes, err := json.Marshal(AdminGetReferrerForReferralHashRequest{ReferralHashBase58: referralHashBase58})
+		require.NoError(err)
+		rr := httptest.NewRecorder()
+		fes.AdminGetReferrerForReferralHash(rr, httptest.NewRequest("POST", "/", bytes.NewReader(bodyBytes)))
+		if rr.Code != http.StatusOK {
+			return rr, nil
+		}
+		res := &AdminGetReferrerForReferralHashResponse{}
+		require.NoError(json.NewDecoder(rr.Body).Decode(res))
+		return rr, res
+	}
+
+	// A referrer with a profile gets the full profile response.
+	rr, res := getReferrer("Ab12Cd34")
+	require.Equal(http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal("Ab12Cd34", res.Info.ReferralHashBase58)
+	require.Equal(lib.PkToString(profilePublicKey, params), res.ReferrerProfileEntryResponse.PublicKeyBase58Check)
+	require.Equal("referrer", res.ReferrerProfileEntryResponse.Username)
+
+	// An anonymous referrer only gets a public key.
+	rr, res = getReferrer("Ef56Gh78")
+	require.Equal(http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal("Ef56Gh78", res.Info.ReferralHashBase58)
+	require.Equal(ProfileEntryResponse{PublicKeyBase58Check: lib.PkToString(anonPublicKey, params)},
+		*res.ReferrerProfileEntryResponse)
+
+	rr, _ = getReferrer("Zz99Yy88")
+	require.Equal(http.StatusNotFound, rr.Code)
+	require.Contains(rr.Body.String(), ErrorCodeReferralHashNotFound)
+}
+
+func TestReserveAndReleaseReferralStarterDeSoNanos(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	// At $10 per DeSo, a $1 referee amount is 0.1 DeSo.
+	fes := &APIServer{
+		GlobalState:                 &GlobalState{GlobalStateDB: db},
+		Config:                      &config.Config{},
+		UsdCentsPerDeSoExchangeRate: 1000,
+	}
+	referrerPKID := &lib.PKID{1}
+	for referralHashBase58, referralInfo := range map[string]*ReferralInfo{
+		"Ab12Cd34": {RefereeAmountUSDCents: 100, MaxReferrals: 1},
+		"Ef56Gh78": {MaxReferrals: 1},
+	} {
+		referralInfo.ReferralHashBase58 = referralHashBase58
+		referralInfo.ReferrerPKID = referrerPKID
+		require.NoError(fes.putReferralHashWithInfo(referralHashBase58, referralInfo))
+		require.NoError(fes.setReferralHashStatusForPKID(referrerPKID, referralHashBase58, true))
+	}
+	requireTotals := func(expectedReferrals uint64, expectedRefereeDeSoNanos uint64) {
+		referralInfo, err := fes.getInfoForReferralHashBase58("Ab12Cd34")
+		require.NoError(err)
+		require.Equal(expectedReferrals, referralInfo.TotalReferrals)
+		require.Equal(expectedRefereeDeSoNanos, referralInfo.TotalRefereeDeSoNanos)
+	}
+
+	// Reserving counts the referral against MaxReferrals, so a second sign-up gets the phone prefix amount.
+	require.Equal(uint64(1e8), fes.reserveReferralStarterDeSoNanos("Ab12Cd34"))
+	requireTotals(1, 1e8)
+	require.Equal(uint64(0), fes.reserveReferralStarterDeSoNanos("Ab12Cd34"))
+	requireTotals(1, 1e8)
+
+	// Releasing a failed send frees the referral up again.
+	fes.releaseReferralStarterDeSoNanos("Ab12Cd34", 1e8)
+	requireTotals(0, 0)
+	require.Equal(uint64(1e8), fes.reserveReferralStarterDeSoNanos("Ab12Cd34"))
+	requireTotals(1, 1e8)
+
+	// Inactive links, links without a referee amount and missing links aren't used or counted.
+	fes.releaseReferralStarterDeSoNanos("Ab12Cd34", 1e8)
+	require.NoError(fes.setReferralHashStatusForPKID(referrerPKID, "Ab12Cd34", false))
+	require.Equal(uint64(0), fes.reserveReferralStarterDeSoNanos("Ab12Cd34"))
+	requireTotals(0, 0)
+	require.Equal(uint64(0), fes.reserveReferralStarterDeSoNanos("Ef56Gh78"))
+	referralInfo, err := fes.getInfoForReferralHashBase58("Ef56Gh78")
+	require.NoError(err)
+	require.Equal(uint64(0), referralInfo.TotalReferrals)
+	require.Equal(uint64(0), fes.reserveReferralStarterDeSoNanos("Zz99Yy88"))
+}
+
+func TestReferralCSVReferrerProfileCheck(t *testing.T) {
+	require := require.New(t)
+
+	chain, params, db := NewLowDifficultyBlockchain()
+	mempool, _ := NewTestMiner(t, chain, params, true /*isSender*/)
+	globalStateDB, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer globalStateDB.Close()
+	fes := &APIServer{
+		GlobalState: &GlobalState{GlobalStateDB: globalStateDB},
+		Config:      &config.Config{EnableReferrals: true},
+		Params:      params,
+		mempool:     mempool,
+	}
+
+	profilePrivKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err)
+	profilePublicKey := profilePrivKey.PubKey().SerializeCompressed()
+	require.NoError(lib.DBPutProfileEntryMappings(db, nil, 0, &lib.ProfileEntry{
+		PublicKey: profilePublicKey,
+		Username:  []byte("referrer"),
+	}, lib.PublicKeyToPKID(profilePublicKey), params))
+	anonPrivKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err)
+	csvRow := func(referrerPublicKeyBase58Check string) []string {
+		row := make([]string, CSVColumnExpiresAtTStampNanos+1)
+		row[CSVColumnPKID] = referrerPublicKeyBase58Check
+		row[CSVColumnReferrerAmount] = "100"
+		row[CSVColumnRefereeAmount] = "200"
+		row[CSVColumnMaxReferrals] = "5"
+		row[CSVColumnRequiresJumio] = "false"
+		return row
+	}
+	profileRow := csvRow(lib.PkToString(profilePublicKey, params))
+	anonRow := csvRow(lib.PkToString(anonPrivKey.PubKey().SerializeCompressed(), params))
+
+	validateRows := func(allowReferrersWithoutProfiles bool) *AdminValidateReferralRowsResponse {
+		bodyBytes, err := json.Marshal(AdminValidateReferralRowsRequest{
+			CSVRows:                       [][]string{ReferralCSVHeaders(), profileRow, anonRow},
+			AllowReferrersWithoutProfiles: allowReferrersWithoutProfiles,
+		})
+		require.NoError(err)
+		rr := httptest.NewRecorder()
+		fes.AdminValidateReferralRows(rr, httptest.NewRequest("POST", "/", bytes.NewReader(bodyBytes)))
+		require.Equal(http.StatusOK, rr.Code, rr.Body.String())
+		res := &AdminValidateReferralRowsResponse{}
+		require.NoError(json.NewDecoder(rr.Body).Decode(res))
+		return res
+	}
+
+	// By default the row whose referrer has no profile is reported by its index.
+	res := validateRows(false)
+	require.False(res.IsValid)
+	require.Equal(uint64(1), res.LinksToCreate)
+	require.Len(res.RowIssues, 1)
+	require.Equal(2, res.RowIssues[0].RowIdx)
+	require.Contains(res.RowIssues[0].Error, "does not have a profile")
+
+	// Anonymous referrers are accepted when the check is turned off.
+	res = validateRows(true)
+	require.True(res.IsValid)
+	require.Equal(uint64(2), res.LinksToCreate)
+	require.Empty(res.RowIssues)
+
+	// Uploads run the same check when they're given a view.
+	utxoView, err := mempool.GetAugmentedUniversalView()
+	require.NoError(err)
+	writer := fes.newReferralCSVBatchWriter(utxoView)
+	require.NoError(writer.addRow(profileRow))
+	require.Error(writer.addRow(anonRow))
+	require.Equal(1, writer.numRows)
+	require.NoError(fes.newReferralCSVBatchWriter(nil).addRow(anonRow))
+}
+
+func TestReferralCSVGzip(t *testing.T) {
+	require := require.New(t)
+
+	for acceptEncoding, expectedAcceptsGzip := range map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"deflate, gzip;q=1.0": true,
+		"br, gzip ; q=0.5":    true,
+		"gzip;q=0":            false,
+		"identity":            false,
+		"x-gzip":              false,
+	} {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		require.Equal(expectedAcceptsGzip, acceptsGzip(req), acceptEncoding)
+	}
+
+	// Downloads are only gzipped when the client asks, and decompress to the same CSV either way.
+	rows := [][]string{ReferralCSVHeaders(), {"abcdefgh", "name"}}
+	var expectedCSV bytes.Buffer
+	require.NoError(csv.NewWriter(&expectedCSV).WriteAll(rows))
+	for _, useGzip := range []bool{false, true} {
+		req := httptest.NewRequest("POST", "/", nil)
+		if useGzip {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		rr := httptest.NewRecorder()
+		writeCSVAttachment(rr, req, "TestReferralCSVGzip", "referrals.csv", rows)
+		require.Equal("text/csv", rr.Header().Get("Content-Type"))
+		body := rr.Body.Bytes()
+		if useGzip {
+			require.Equal("gzip", rr.Header().Get("Content-Encoding"))
+			gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+			require.NoError(err)
+			body, err = ioutil.ReadAll(gzipReader)
+			require.NoError(err)
+		} else {
+			require.Empty(rr.Header().Get("Content-Encoding"))
+		}
+		require.Equal(expectedCSV.Bytes(), body)
+	}
+
+	// Uploads are treated as gzipped based on the part's Content-Encoding or the file name.
+	require.True(isGzipReferralCSVUpload(&multipart.FileHeader{Filename: "referrals.CSV.GZ"}))
+	require.True(isGzipReferralCSVUpload(&multipart.FileHeader{
+		Filename: "referrals.csv", Header: textproto.MIMEHeader{"Content-Encoding": {"gzip"}}}))
+	require.False(isGzipReferralCSVUpload(&multipart.FileHeader{Filename: "referrals.csv"}))
+	require.False(isGzipReferralCSVUpload(&multipart.FileHeader{Filename: "referrals.gz"}))
+
+	// Decompressed uploads past the limit fail rather than being cut short.
+	data, err := readAllWithMaxBytes(bytes.NewReader(make([]byte, 10)), 10)
+	require.NoError(err)
+	require.Len(data, 10)
+	_, err = readAllWithMaxBytes(bytes.NewReader(make([]byte, 11)), 10)
+	require.Error(err)
+}
+
+func TestGenerateAndLockNewReferralHash(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+
+	// The new hash stays locked until the caller has stored it.
+	referralHashBase58, unlock, err := fes.generateAndLockNewReferralHash()
+	require.NoError(err)
+	mtx, ok := fes.mtxReferralInfoByHash.Load(referralHashBase58)
+	require.True(ok)
+	require.False(mtx.(*sync.Mutex).TryLock())
+	unlock()
+	require.True(mtx.(*sync.Mutex).TryLock())
+	mtx.(*sync.Mutex).Unlock()
+}
+
+func TestBackfillRefereeReferralHashIndex(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{GlobalState: &GlobalState{GlobalStateDB: db}}
+
+	referrerPKID := &lib.PKID{1}
+	oldRefereePKID := &lib.PKID{2}
+	newRefereePKID := &lib.PKID{3}
+	require.NoError(fes.GlobalState.Put(GlobalStateKeyForPKIDReferralHashRefereePKID(
+		referrerPKID, []byte("aaaaaaaa"), oldRefereePKID), []byte{1}))
+	require.NoError(fes.GlobalState.Put(GlobalStateKeyForPKIDReferralHashRefereePKID(
+		referrerPKID, []byte("bbbbbbbb"), newRefereePKID), []byte{1}))
+	// Referees that are already indexed are left alone.
+	require.NoError(fes.GlobalState.Put(GlobalStateKeyForRefereePKIDToReferralHash(newRefereePKID), []byte("cccccccc")))
+
+	require.NoError(fes.backfillRefereeReferralHashIndex())
+	referralHash, err := fes.GlobalState.Get(GlobalStateKeyForRefereePKIDToReferralHash(oldRefereePKID))
+	require.NoError(err)
+	require.Equal("aaaaaaaa", string(referralHash))
+	referralHash, err = fes.GlobalState.Get(GlobalStateKeyForRefereePKIDToReferralHash(newRefereePKID))
+	require.NoError(err)
+	require.Equal("cccccccc", string(referralHash))
+}
@@ -16,6 +16,8 @@ import (
 type GetGlobalParamsRequest struct {
 }
 
+// GetGlobalParamsResponse mirrors every field on lib.GlobalParamsEntry. New fields added to the entry should be added
+// here and in getGlobalParamsResponse.
 type GetGlobalParamsResponse struct {
 	// The current exchange rate.
 	USDCentsPerBitcoin uint64 `safeForLogging:"true"`
@@ -34,7 +36,7 @@ type GetGlobalParamsResponse struct {
 }
 
 func (fes *APIServer) GetGlobalParams(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetGlobalParamsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetGlobalParams: Problem parsing request body: %v", err))
@@ -47,19 +49,22 @@ func (fes *APIServer) GetGlobalParams(ww http.ResponseWriter, req *http.Request)
 		_AddBadRequestError(ww, fmt.Sprintf("GetGlobalParams: Error getting utxoView: %v", err))
 		return
 	}
-	globalParamsEntry := utxoView.GlobalParamsEntry
 	// Return all the data associated with the transaction in the response
-	res := GetGlobalParamsResponse{
+	res := getGlobalParamsResponse(utxoView.GlobalParamsEntry)
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetGlobalParams: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+func getGlobalParamsResponse(globalParamsEntry *lib.GlobalParamsEntry) GetGlobalParamsResponse {
+	return GetGlobalParamsResponse{
 		USDCentsPerBitcoin:          globalParamsEntry.USDCentsPerBitcoin,
 		CreateProfileFeeNanos:       globalParamsEntry.CreateProfileFeeNanos,
 		MinimumNetworkFeeNanosPerKB: globalParamsEntry.MinimumNetworkFeeNanosPerKB,
 		CreateNFTFeeNanos:           globalParamsEntry.CreateNFTFeeNanos,
 		MaxCopiesPerNFT:             globalParamsEntry.MaxCopiesPerNFT,
 	}
-	if err := json.NewEncoder(ww).Encode(res); err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetGlobalParams: Problem encoding response as JSON: %v", err))
-		return
-	}
 }
 
 // UpdateGlobalParamsRequest ...
@@ -80,6 +85,10 @@ type UpdateGlobalParamsRequest struct {
 	// The new minimum fee the network will accept
 	MinimumNetworkFeeNanosPerKB int64 `safeForLogging:"true"`
 
+	// By default, a request that wouldn't change any of the above params is rejected so that fees aren't spent on an
+	// empty update. Set this to construct the transaction anyway.
+	AllowNoOp bool `safeForLogging:"true"`
+
 	MinFeeRateNanosPerKB uint64 `safeForLogging:"true"`
 
 	// No need to specify ProfileEntryResponse in each TransactionFee
@@ -111,7 +120,7 @@ type UpdateGlobalParamsResponse struct {
 }
 
 func (fes *APIServer) UpdateGlobalParams(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := UpdateGlobalParamsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("UpdateGlobalParams: Problem parsing request body: %v", err))
@@ -163,6 +172,13 @@ func (fes *APIServer) UpdateGlobalParams(ww http.ResponseWriter, req *http.Reque
 		maxCopiesPerNFT = requestData.MaxCopiesPerNFT
 	}
 
+	if !requestData.AllowNoOp && usdCentsPerBitcoin < 0 && createProfileFeeNanos < 0 && createNFTFeeNanos < 0 &&
+		minimumNetworkFeeNanosPerKb < 0 && maxCopiesPerNFT < 0 {
+		_AddBadRequestError(ww, "UpdateGlobalParams: None of the provided values differ from the current global "+
+			"params, so the transaction would be a no-op. Set AllowNoOp to construct it anyway.")
+		return
+	}
+
 	// Try and create the update txn for the user.
 	txn, totalInput, changeAmount, fees, err := fes.blockchain.CreateUpdateGlobalParamsTxn(
 		updaterPkBytes,
@@ -259,7 +275,7 @@ func (fes *APIServer) getPublicKeyFromUsernameOrPublicKeyString(usernameOrPublic
 // SwapIdentity ...
 func (fes *APIServer) SwapIdentity(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SwapIdentityRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SwapIdentity: Problem parsing request body: %v", err))
@@ -334,12 +350,22 @@ type TestSignTransactionWithDerivedKeyRequest struct {
 
 	// Derived private key in base58Check.
 	DerivedKeySeedHex string `safeForLogging:"false"`
+
+	// If set, the signed transaction is checked against the derived key's on-chain authorization and
+	// TransactionSpendingLimit, and any problem is returned in SpendingLimitWarning.
+	ValidateSpendingLimits bool `safeForLogging:"true"`
+	// If set, the same check as ValidateSpendingLimits is run, but a problem is returned as an error instead of a warning.
+	StrictValidation bool `safeForLogging:"true"`
 }
 
 // TestSignTransactionWithDerivedKeyResponse ...
 type TestSignTransactionWithDerivedKeyResponse struct {
 	// Signed Transaction hex.
 	TransactionHex string `safeForLogging:"true"`
+
+	// Why the transaction would be rejected when signed with this derived key. Only set when ValidateSpendingLimits is
+	// set and the transaction fails validation.
+	SpendingLimitWarning string `safeForLogging:"true"`
 }
 
 // TestSignTransactionWithDerivedKey ...
@@ -348,7 +374,7 @@ type TestSignTransactionWithDerivedKeyResponse struct {
 // how to properly sign transactions with a derived key.
 func (fes *APIServer) TestSignTransactionWithDerivedKey(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := TestSignTransactionWithDerivedKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionWithDerivedKey: Problem parsing request body: %v", err))
@@ -370,24 +396,149 @@ func (fes *APIServer) TestSignTransactionWithDerivedKey(ww http.ResponseWriter,
 	}
 	privKeyBytes, _ := btcec.PrivKeyFromBytes(btcec.S256(), privBytes)
 
-	// Sign the transaction with a derived key. Since the txn extraData must be modified,
-	// we also get new transaction bytes, along with the signature.
-	newTxnBytes, txnSignatureBytes, err := lib.SignTransactionBytes(txnBytes, privKeyBytes, true)
+	signedTransactionHex, err := signTransactionBytesWithDerivedKey(txnBytes, privKeyBytes)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionWithDerivedKey: Problem signing transaction: %v", err))
 		return
 	}
 
-	// The response will contain the new transaction bytes and a signature.
-	var signedTransactionHex []byte
-	signedTransactionHex = newTxnBytes[0 : len(newTxnBytes)-1]
-	signedTransactionHex = append(signedTransactionHex, lib.UintToBuf(uint64(len(txnSignatureBytes)))...)
-	signedTransactionHex = append(signedTransactionHex, txnSignatureBytes...)
 	res := TestSignTransactionWithDerivedKeyResponse{
 		TransactionHex: hex.EncodeToString(signedTransactionHex),
 	}
+
+	if requestData.ValidateSpendingLimits || requestData.StrictValidation {
+		err = fes.validateDerivedKeyTransaction(signedTransactionHex, privKeyBytes.PubKey().SerializeCompressed())
+		if err != nil && requestData.StrictValidation {
+			_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionWithDerivedKey: Transaction would be "+
+				"rejected for this derived key: %v", err))
+			return
+		}
+		if err != nil {
+			res.SpendingLimitWarning = err.Error()
+		}
+	}
 	if err := json.NewEncoder(ww).Encode(res); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionWithDerivedKey: Problem encoding response as JSON: %v", err))
 		return
 	}
 }
+
+// validateDerivedKeyTransaction checks that the derived key is authorized for the transaction's owner and connects
+// the signed transaction to a copy of the current view, which is where the derived key's TransactionSpendingLimit is
+// enforced. Nothing is broadcast.
+func (fes *APIServer) validateDerivedKeyTransaction(signedTxnBytes []byte, derivedPublicKey []byte) error {
+	txn := &lib.MsgDeSoTxn{}
+	if err := txn.FromBytes(signedTxnBytes); err != nil {
+		return errors.Wrapf(err, "Problem deserializing signed transaction")
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		return errors.Wrapf(err, "Problem fetching utxoView")
+	}
+
+	blockHeight := uint64(fes.blockchain.BlockTip().Height) + 1
+	if err = utxoView.ValidateDerivedKey(txn.PublicKey, derivedPublicKey, blockHeight); err != nil {
+		return err
+	}
+	if _, err = fes.simulateSubmitTransaction(utxoView, txn); err != nil {
+		return err
+	}
+	return nil
+}
+
+// signTransactionBytesWithDerivedKey signs txnBytes with a derived key and returns the signed transaction bytes.
+func signTransactionBytesWithDerivedKey(txnBytes []byte, derivedPrivKey *btcec.PrivateKey) ([]byte, error) {
+	// Sign the transaction with a derived key. Since the txn extraData must be modified,
+	// we also get new transaction bytes, along with the signature.
+	newTxnBytes, txnSignatureBytes, err := lib.SignTransactionBytes(txnBytes, derivedPrivKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signed transaction is the new transaction bytes with the empty signature replaced by the real one.
+	var signedTransactionBytes []byte
+	signedTransactionBytes = newTxnBytes[0 : len(newTxnBytes)-1]
+	signedTransactionBytes = append(signedTransactionBytes, lib.UintToBuf(uint64(len(txnSignatureBytes)))...)
+	signedTransactionBytes = append(signedTransactionBytes, txnSignatureBytes...)
+	return signedTransactionBytes, nil
+}
+
+// MaxTestSignTransactionsWithDerivedKeyBatchSize is the max number of transactions in a
+// TestSignTransactionsWithDerivedKey request.
+const MaxTestSignTransactionsWithDerivedKeyBatchSize = 100
+
+// TestSignTransactionsWithDerivedKeyRequest ...
+type TestSignTransactionsWithDerivedKeyRequest struct {
+	// Transaction hexes, signed in order.
+	TransactionHexes []string `safeForLogging:"true"`
+
+	// Derived private key seed in hex, used to sign every transaction.
+	DerivedKeySeedHex string `safeForLogging:"false"`
+}
+
+// SignedTransactionResult ...
+type SignedTransactionResult struct {
+	// Signed Transaction hex. Empty if signing this transaction failed.
+	TransactionHex string `safeForLogging:"true"`
+
+	// Set if this transaction couldn't be signed.
+	Error string `safeForLogging:"true"`
+}
+
+// TestSignTransactionsWithDerivedKeyResponse ...
+type TestSignTransactionsWithDerivedKeyResponse struct {
+	// One result per transaction, in the same order as the request.
+	Results []SignedTransactionResult `safeForLogging:"true"`
+}
+
+// TestSignTransactionsWithDerivedKey ...
+// This endpoint must not be used by a frontend in a production environment,
+// instead it is meant to serve as a debugging tool for testing a sequence of
+// transactions signed with the same derived key. A transaction that fails to
+// sign has its error returned in its result without aborting the rest.
+func (fes *APIServer) TestSignTransactionsWithDerivedKey(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := TestSignTransactionsWithDerivedKeyRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionsWithDerivedKey: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.TransactionHexes) == 0 ||
+		len(requestData.TransactionHexes) > MaxTestSignTransactionsWithDerivedKeyBatchSize {
+		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionsWithDerivedKey: Must provide between 1 and %v "+
+			"TransactionHexes", MaxTestSignTransactionsWithDerivedKeyBatchSize))
+		return
+	}
+
+	// Get the derived private key from the request data.
+	privBytes, err := hex.DecodeString(requestData.DerivedKeySeedHex)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionsWithDerivedKey: Problem decoding seed hex %v", err))
+		return
+	}
+	privKeyBytes, _ := btcec.PrivKeyFromBytes(btcec.S256(), privBytes)
+
+	res := TestSignTransactionsWithDerivedKeyResponse{
+		Results: make([]SignedTransactionResult, len(requestData.TransactionHexes)),
+	}
+	for ii, transactionHex := range requestData.TransactionHexes {
+		txnBytes, err := hex.DecodeString(transactionHex)
+		if err != nil {
+			res.Results[ii].Error = fmt.Sprintf("Problem decoding transaction hex %v", err)
+			continue
+		}
+		signedTransactionBytes, err := signTransactionBytesWithDerivedKey(txnBytes, privKeyBytes)
+		if err != nil {
+			res.Results[ii].Error = fmt.Sprintf("Problem signing transaction: %v", err)
+			continue
+		}
+		res.Results[ii].TransactionHex = hex.EncodeToString(signedTransactionBytes)
+	}
+
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("TestSignTransactionsWithDerivedKey: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGlobalParamsResponse(t *testing.T) {
+	globalParamsEntry := &lib.GlobalParamsEntry{
+		USDCentsPerBitcoin:          1,
+		CreateProfileFeeNanos:       2,
+		CreateNFTFeeNanos:           3,
+		MaxCopiesPerNFT:             4,
+		MinimumNetworkFeeNanosPerKB: 5,
+	}
+	res := getGlobalParamsResponse(globalParamsEntry)
+	require.Equal(t, GetGlobalParamsResponse{
+		USDCentsPerBitcoin:          1,
+		CreateProfileFeeNanos:       2,
+		MinimumNetworkFeeNanosPerKB: 5,
+		CreateNFTFeeNanos:           3,
+		MaxCopiesPerNFT:             4,
+	}, res)
+
+	// Every field on the entry should be surfaced in the response under the same name
+	entryType := reflect.TypeOf(*globalParamsEntry)
+	resType := reflect.TypeOf(res)
+	for ii := 0; ii < entryType.NumField(); ii++ {
+		_, exists := resType.FieldByName(entryType.Field(ii).Name)
+		require.True(t, exists, "GetGlobalParamsResponse is missing %v", entryType.Field(ii).Name)
+	}
+}
@@ -48,7 +48,7 @@ type AdminUpdateUserGlobalMetadataRequest struct {
 // This endpoint differs from the standard "UpdateUserGlobalMetadata" in that it allows
 // anyone with access to the node's shared_secret to update any part of a User's metadata.
 func (fes *APIServer) AdminUpdateUserGlobalMetadata(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateUserGlobalMetadataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateUserGlobalMetadata: Problem parsing request body: %v", err))
@@ -67,7 +67,7 @@ func (fes *APIServer) AdminUpdateUserGlobalMetadata(ww http.ResponseWriter, req
 	if requestData.UserPublicKeyBase58Check != "" {
 		userPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 		if err != nil || len(userPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
-			_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateUserGlobalMetadata: Problem decoding updater public key %s: %v",
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AdminUpdateUserGlobalMetadata: Problem decoding updater public key %s: %v",
 				requestData.UserPublicKeyBase58Check, err))
 			return
 		}
@@ -219,7 +219,7 @@ type AdminResetPhoneNumberRequest struct {
 
 // Clears all phone number metadata for a given phone number - thus allowing it to be used again.
 func (fes *APIServer) AdminResetPhoneNumber(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminResetPhoneNumberRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminResetPhoneNumber: Problem parsing request body: %v", err))
@@ -299,7 +299,7 @@ func (fes *APIServer) getUserMetadataUsernameMaps(numToFetch int) (_publicKeyToU
 
 // AdminGetAllUserGlobalMetadata ...
 func (fes *APIServer) AdminGetAllUserGlobalMetadata(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetAllUserGlobalMetadataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllUserGlobalMetadata: Problem parsing request body: %v", err))
@@ -339,7 +339,7 @@ type AdminGetUserGlobalMetadataResponse struct {
 
 // AdminGetUserGlobalMetadata ...
 func (fes *APIServer) AdminGetUserGlobalMetadata(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetUserGlobalMetadataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetAllUserGlobalMetadata: Problem parsing request body: %v", err))
@@ -579,7 +579,7 @@ type AdminGrantVerificationBadgeResponse struct {
 // This operates on this node's global state and does not interact with the configured GlobalStateAPIUrl.
 func (fes *APIServer) AdminGrantVerificationBadge(ww http.ResponseWriter, req *http.Request) {
 	requestData := AdminGrantVerificationBadgeRequest{}
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGrantVerificationBadge: Problem parsing request body: %v", err))
 		return
@@ -685,7 +685,7 @@ type AdminRemoveVerificationBadgeResponse struct {
 // This operates on this node's global state and does not interact with the configured GlobalStateAPIUrl.
 func (fes *APIServer) AdminRemoveVerificationBadge(ww http.ResponseWriter, req *http.Request) {
 	requestData := AdminRemoveVerificationBadgeRequest{}
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminRemoveVerificationBadge: Problem parsing request body: %v", err))
 		return
@@ -782,7 +782,7 @@ type AdminGetVerifiedUsersResponse struct {
 
 // AdminGetVerifiedUsers gets a list of all verified users from this node's global state.
 func (fes *APIServer) AdminGetVerifiedUsers(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetVerifiedUsersRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetVerifiedUsers: Problem parsing request body: %v", err))
@@ -836,7 +836,7 @@ type AdminGetUsernameVerificationAuditLogsResponse struct {
 // AdminGetUsernameVerificationAuditLogs gets the verification audit logs for a given username from this node's global
 // state.  It does not look at the configured GlobalStateAPIUrl to fetch this information.
 func (fes *APIServer) AdminGetUsernameVerificationAuditLogs(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetUsernameVerificationAuditLogsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetVerifiedUsers: Problem parsing request body: %v", err))
@@ -920,7 +920,7 @@ type AdminGetUserAdminDataResponse struct {
 // AdminGetUserAdminData gets the audit logs for a particular public key and their associated metadata from this node's
 // global state. This does not use verifications fetched from other APIs.
 func (fes *APIServer) AdminGetUserAdminData(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetUserAdminDataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetUserMetadata: Problem parsing request body: %v", err))
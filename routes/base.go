@@ -24,12 +24,22 @@ func (fes *APIServer) Index(w http.ResponseWriter, r *http.Request) {
 
 // NOTE: This is a readiness check not a health check
 func (fes *APIServer) HealthCheck(ww http.ResponseWriter, rr *http.Request) {
+	if syncError := fes.getSyncError(); syncError != "" {
+		_AddBadRequestError(ww, syncError)
+		return
+	}
+
+	fmt.Fprint(ww, "200")
+}
+
+// getSyncError returns what the node is still waiting on before it can serve requests, or an empty string if it's
+// fully synced. HealthCheck and GetReadyCheck both use it.
+func (fes *APIServer) getSyncError() string {
 	// Check that the blockchain is fully current.
 	blockchainHeight := fes.blockchain.BlockTip().Height
 	if fes.blockchain.ChainState() != lib.SyncStateFullyCurrent {
-		_AddBadRequestError(ww, fmt.Sprintf("Waiting for blockchain to sync. "+
-			"Height: %v, SyncState: %v", blockchainHeight, fes.blockchain.ChainState()))
-		return
+		return fmt.Sprintf("Waiting for blockchain to sync. "+
+			"Height: %v, SyncState: %v", blockchainHeight, fes.blockchain.ChainState())
 	}
 
 	// Check that we've received our first transaction bundle. We skip this check
@@ -37,8 +47,7 @@ func (fes *APIServer) HealthCheck(ww http.ResponseWriter, rr *http.Request) {
 	// any mempool messages from our peers.
 	if !fes.backendServer.HasProcessedFirstTransactionBundle() &&
 		!fes.backendServer.DisableNetworking {
-		_AddBadRequestError(ww, "Waiting on mempool to sync")
-		return
+		return "Waiting on mempool to sync"
 	}
 
 	// If we have txindex configured then also do a check for that.
@@ -46,8 +55,36 @@ func (fes *APIServer) HealthCheck(ww http.ResponseWriter, rr *http.Request) {
 		fes.TXIndex.TXIndexChain.ChainState() != lib.SyncStateFullyCurrent {
 		txindexHeight := fes.TXIndex.TXIndexChain.BlockTip().Height
 
-		_AddBadRequestError(ww, fmt.Sprintf("Waiting for txindex to sync. "+
-			"Height: %v, SyncState: %v", txindexHeight, fes.TXIndex.TXIndexChain.ChainState()))
+		return fmt.Sprintf("Waiting for txindex to sync. "+
+			"Height: %v, SyncState: %v", txindexHeight, fes.TXIndex.TXIndexChain.ChainState())
+	}
+	return ""
+}
+
+// globalStateReadyCheckKey is read by GetReadyCheck to confirm global state is reachable. It doesn't need to exist.
+var globalStateReadyCheckKey = []byte("ready-check")
+
+// GetHealthCheck is a liveness check for load balancers. It does no work beyond confirming that the server is
+// accepting requests, so it returns 200 even while the node is syncing.
+func (fes *APIServer) GetHealthCheck(ww http.ResponseWriter, rr *http.Request) {
+	fmt.Fprint(ww, "200")
+}
+
+// GetReadyCheck returns 200 once the node is synced the same way HealthCheck checks and can serve reads from the
+// mempool and global state, and 503 while it's still syncing or either of those is unavailable.
+func (fes *APIServer) GetReadyCheck(ww http.ResponseWriter, rr *http.Request) {
+	if syncError := fes.getSyncError(); syncError != "" {
+		_AddServiceUnavailableError(ww, "GetReadyCheck: "+syncError)
+		return
+	}
+
+	if _, err := fes.backendServer.GetMempool().GetAugmentedUniversalView(); err != nil {
+		_AddServiceUnavailableError(ww, fmt.Sprintf("GetReadyCheck: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	if _, err := fes.GlobalState.Get(globalStateReadyCheckKey); err != nil {
+		_AddServiceUnavailableError(ww, fmt.Sprintf("GetReadyCheck: Problem reading from global state: %v", err))
 		return
 	}
 
@@ -371,7 +408,7 @@ type GetAppStateResponse struct {
 }
 
 func (fes *APIServer) GetAppState(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetAppStateRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -446,3 +483,79 @@ func (fes *APIServer) GetIngressCookie(ww http.ResponseWriter, req *http.Request
 		return
 	}
 }
+
+// txnTypeConstructionRouteNames maps each transaction type to the names of the routes that construct it. A
+// transaction type is considered supported if at least one of its routes is registered on the router.
+var txnTypeConstructionRouteNames = []struct {
+	TxnType    lib.TxnType
+	RouteNames []string
+}{
+	{lib.TxnTypeBasicTransfer, []string{"SendDeSo"}},
+	{lib.TxnTypeBitcoinExchange, []string{"ExchangeBitcoin"}},
+	{lib.TxnTypePrivateMessage, []string{"SendMessageStateless"}},
+	{lib.TxnTypeSubmitPost, []string{"SubmitPost"}},
+	{lib.TxnTypeUpdateProfile, []string{"UpdateProfile"}},
+	{lib.TxnTypeFollow, []string{"CreateFollowTxnStateless"}},
+	{lib.TxnTypeLike, []string{"CreateLikeStateless"}},
+	{lib.TxnTypeCreatorCoin, []string{"BuyOrSellCreatorCoin"}},
+	{lib.TxnTypeSwapIdentity, []string{"SwapIdentity"}},
+	{lib.TxnTypeUpdateGlobalParams, []string{"UpdateGlobalParams"}},
+	{lib.TxnTypeCreatorCoinTransfer, []string{"TransferCreatorCoin", "SendDiamonds"}},
+	{lib.TxnTypeCreateNFT, []string{"CreateNFT"}},
+	{lib.TxnTypeUpdateNFT, []string{"UpdateNFT"}},
+	{lib.TxnTypeAcceptNFTBid, []string{"AcceptNFTBid"}},
+	{lib.TxnTypeNFTBid, []string{"CreateNFTBid"}},
+	{lib.TxnTypeNFTTransfer, []string{"TransferNFT"}},
+	{lib.TxnTypeAcceptNFTTransfer, []string{"AcceptNFTTransfer"}},
+	{lib.TxnTypeBurnNFT, []string{"BurnNFT"}},
+	{lib.TxnTypeAuthorizeDerivedKey, []string{"AuthorizeDerivedKey"}},
+	{lib.TxnTypeMessagingGroup, []string{"RegisterMessagingGroupKey"}},
+	{lib.TxnTypeDAOCoin, []string{"DAOCoin"}},
+	{lib.TxnTypeDAOCoinTransfer, []string{"TransferDAOCoin"}},
+	{lib.TxnTypeDAOCoinLimitOrder, []string{"CreateDAOCoinLimitOrder", "CreateDAOCoinMarketOrder", "CancelDAOCoinLimitOrder"}},
+}
+
+type SupportedTransactionTypeResponse struct {
+	TxnType       uint64
+	TxnTypeString string
+	// The names of the registered routes that construct this transaction type.
+	RouteNames []string
+}
+
+type GetSupportedTransactionTypesResponse struct {
+	SupportedTransactionTypes []SupportedTransactionTypeResponse
+}
+
+// GetSupportedTransactionTypes returns the transaction types this node is able to construct. This is based only on
+// which routes are registered with the router, so any route that is disabled on this node is excluded.
+func (fes *APIServer) GetSupportedTransactionTypes(ww http.ResponseWriter, req *http.Request) {
+	if fes.router == nil {
+		_AddInternalServerError(ww, "GetSupportedTransactionTypes: Router has not been initialized")
+		return
+	}
+
+	res := GetSupportedTransactionTypesResponse{
+		SupportedTransactionTypes: []SupportedTransactionTypeResponse{},
+	}
+	for _, txnTypeRoutes := range txnTypeConstructionRouteNames {
+		var registeredRouteNames []string
+		for _, routeName := range txnTypeRoutes.RouteNames {
+			if fes.router.Get(routeName) != nil {
+				registeredRouteNames = append(registeredRouteNames, routeName)
+			}
+		}
+		if len(registeredRouteNames) == 0 {
+			continue
+		}
+		res.SupportedTransactionTypes = append(res.SupportedTransactionTypes, SupportedTransactionTypeResponse{
+			TxnType:       uint64(txnTypeRoutes.TxnType),
+			TxnTypeString: txnTypeRoutes.TxnType.String(),
+			RouteNames:    registeredRouteNames,
+		})
+	}
+
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetSupportedTransactionTypes: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/deso-smart/deso-core/v3/lib"
@@ -9,19 +11,38 @@ import (
 	"github.com/holiman/uint256"
 	"github.com/pkg/errors"
 	"io"
+	"math"
 	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// Orders are returned buying DAOCoin1 first, then buying DAOCoin2. Within each side they're sorted from best to worst
+// price, then by OrderID, so that pages are stable.
 type GetDAOCoinLimitOrdersRequest struct {
+	// Either coin can be "DESO", but not both. Passing "DESO" for both coins is an error. DAO coins can be given by
+	// their creator's public key or username.
 	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
 	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Optional. The max number of orders to return, capped at --max-page-size. All orders are returned if not set.
+	Limit int `safeForLogging:"true"`
+	// Optional. The NextOffset returned with the previous page. It records the last order's position rather than
+	// just its OrderID, so the next page picks up in the right place even if that order was filled or cancelled.
+	Offset string `safeForLogging:"true"`
+
+	// Optional. If set, USDPricePerCoinToBuy is populated on each order.
+	IncludeUSDPrices bool `safeForLogging:"true"`
 }
 
 type GetDAOCoinLimitOrdersResponse struct {
 	Orders []DAOCoinLimitOrderEntryResponse
+
+	// The Offset to pass to fetch the next page. Empty if there are no more orders.
+	NextOffset string `safeForLogging:"true"`
 }
 
 type DAOCoinLimitOrderEntryResponse struct {
@@ -45,15 +66,46 @@ type DAOCoinLimitOrderEntryResponse struct {
 	ExchangeRateCoinsToSellPerCoinToBuy float64 `safeForLogging:"true"` // Deprecated
 	QuantityToFill                      float64 `safeForLogging:"true"` // Deprecated
 
+	// The exact values stored on the order, as base-10 integer strings. ScaledExchangeRateString is the exchange
+	// rate scaled by 1e38, and QuantityToFillBaseUnits is the quantity in base units of the coin Quantity refers to.
+	// Unlike the float fields above, these don't lose precision.
+	ScaledExchangeRateString string `safeForLogging:"true"`
+	QuantityToFillBaseUnits  string `safeForLogging:"true"`
+
 	OperationType DAOCoinLimitOrderOperationTypeString
 
 	OrderID string
+
+	// A decimal string (ex: 1.23) for the USD value of one of this order's DAO coins implied by its price and the
+	// current $DESO price. This is the coin being bought when the order sells $DESO, and the coin being sold when
+	// the order buys $DESO, since the $DESO side is always worth the $DESO price. Only set when the request asks for
+	// USD prices and one of the coins is $DESO, since there's no USD price to anchor a DAO coin <> DAO coin order to.
+	USDPricePerCoinToBuy string `safeForLogging:"true"`
 }
 
 const DESOCoinIdentifierString = "DESO"
 
+// normalizeCoinIdentifier maps every way a request can refer to $DESO (DESOCoinIdentifierString, "$DESO", or an empty
+// string) to the empty string, and returns any other coin identifier unchanged. Compare coins with
+// isDESOCoinIdentifier rather than against DESOCoinIdentifierString so that all of these are treated the same.
+func normalizeCoinIdentifier(coinIdentifier string) string {
+	switch coinIdentifier {
+	case DESOCoinIdentifierString, "$" + DESOCoinIdentifierString, "":
+		return ""
+	}
+	return coinIdentifier
+}
+
+func isDESOCoinIdentifier(coinIdentifier string) bool {
+	return normalizeCoinIdentifier(coinIdentifier) == ""
+}
+
+// ErrGettingDAOCoinLimitOrders is returned (wrapped) by getDAOCoinLimitOrdersForCoinPair when the orders couldn't be
+// read, as opposed to the request being invalid.
+var ErrGettingDAOCoinLimitOrders = errors.New("problem getting limit orders")
+
 func (fes *APIServer) GetDAOCoinLimitOrders(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetDAOCoinLimitOrdersRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(
@@ -63,93 +115,345 @@ func (fes *APIServer) GetDAOCoinLimitOrders(ww http.ResponseWriter, req *http.Re
 		return
 	}
 
-	if requestData.DAOCoin1CreatorPublicKeyBase58Check == DESOCoinIdentifierString &&
-		requestData.DAOCoin2CreatorPublicKeyBase58Check == DESOCoinIdentifierString {
-		_AddBadRequestError(
-			ww,
-			fmt.Sprint("GetDAOCoinLimitOrders: Must provide either a "+
-				"DAOCoin1CreatorPublicKeyBase58Check or DAOCoin2CreatorPublicKeyBase58Check "+
-				"or both"),
-		)
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: Problem fetching utxoView: %v", err))
 		return
 	}
 
-	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	res, err := fes.getDAOCoinLimitOrdersForCoinPair(utxoView, &requestData)
+	if errors.Cause(err) == ErrGettingDAOCoinLimitOrders {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: %v", err))
+		return
+	}
 	if err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: Problem fetching utxoView: %v", err))
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: %v", err))
+		return
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: Problem encoding response as JSON: %v", err))
 		return
 	}
+}
+
+// getDAOCoinLimitOrdersForCoinPair returns the page of open orders for a coin pair described by requestData
+func (fes *APIServer) getDAOCoinLimitOrdersForCoinPair(
+	utxoView *lib.UtxoView,
+	requestData *GetDAOCoinLimitOrdersRequest,
+) (*GetDAOCoinLimitOrdersResponse, error) {
+	if isDESOCoinIdentifier(requestData.DAOCoin1CreatorPublicKeyBase58Check) &&
+		isDESOCoinIdentifier(requestData.DAOCoin2CreatorPublicKeyBase58Check) {
+		return nil, errors.Errorf("Must provide either a " +
+			"DAOCoin1CreatorPublicKeyBase58Check or DAOCoin2CreatorPublicKeyBase58Check " +
+			"or both")
+	}
+
+	if requestData.Limit < 0 {
+		return nil, errors.Errorf("Limit must not be negative: %v", requestData.Limit)
+	}
+	var offset *daoCoinLimitOrdersOffset
+	if requestData.Offset != "" {
+		var err error
+		if offset, err = parseDAOCoinLimitOrdersOffset(requestData.Offset); err != nil {
+			return nil, errors.Errorf("Invalid Offset %v: %v", requestData.Offset, err)
+		}
+	}
 
 	coin1PKID := &lib.ZeroPKID
 	coin2PKID := &lib.ZeroPKID
+	// The coins' public keys, which the responses are built with even if the request used usernames
+	coin1PublicKeyBase58Check := requestData.DAOCoin1CreatorPublicKeyBase58Check
+	coin2PublicKeyBase58Check := requestData.DAOCoin2CreatorPublicKeyBase58Check
 
-	if requestData.DAOCoin1CreatorPublicKeyBase58Check != DESOCoinIdentifierString {
-		coin1PKID, err = fes.getPKIDFromPublicKeyBase58Check(
+	var err error
+	if !isDESOCoinIdentifier(requestData.DAOCoin1CreatorPublicKeyBase58Check) {
+		coin1PKID, err = fes.getPKIDFromPublicKeyBase58CheckOrUsername(
 			utxoView,
 			requestData.DAOCoin1CreatorPublicKeyBase58Check,
 		)
 		if err != nil {
-			_AddBadRequestError(
-				ww,
-				fmt.Sprintf("GetDAOCoinLimitOrders: Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err),
-			)
-			return
+			return nil, errors.Errorf("Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err)
 		}
+		coin1PublicKeyBase58Check = lib.PkToString(utxoView.GetPublicKeyForPKID(coin1PKID), fes.Params)
 	}
 
-	if requestData.DAOCoin2CreatorPublicKeyBase58Check != DESOCoinIdentifierString {
-		coin2PKID, err = fes.getPKIDFromPublicKeyBase58Check(
+	if !isDESOCoinIdentifier(requestData.DAOCoin2CreatorPublicKeyBase58Check) {
+		coin2PKID, err = fes.getPKIDFromPublicKeyBase58CheckOrUsername(
 			utxoView,
 			requestData.DAOCoin2CreatorPublicKeyBase58Check,
 		)
 		if err != nil {
-			_AddBadRequestError(
-				ww,
-				fmt.Sprintf("GetDAOCoinLimitOrders: Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err),
-			)
-			return
+			return nil, errors.Errorf("Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err)
 		}
+		coin2PublicKeyBase58Check = lib.PkToString(utxoView.GetPublicKeyForPKID(coin2PKID), fes.Params)
 	}
 
-	ordersBuyingCoin1, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(coin1PKID, coin2PKID)
+	// A coin can't be traded for itself. This also catches a coin that's passed as both its creator's username and
+	// public key, and $DESO passed as both DESOCoinIdentifierString and the zero public key, since both resolve to
+	// the ZeroPKID
+	if coin1PKID.Eq(coin2PKID) {
+		return nil, errors.Errorf("DAOCoin1CreatorPublicKeyBase58Check %v and DAOCoin2CreatorPublicKeyBase58Check "+
+			"%v refer to the same coin", requestData.DAOCoin1CreatorPublicKeyBase58Check,
+			requestData.DAOCoin2CreatorPublicKeyBase58Check)
+	}
+
+	ordersBuyingCoin1, err := fes.getAllDAOCoinLimitOrdersForThisDAOCoinPair(utxoView, coin1PKID, coin2PKID)
 	if err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: Error getting limit orders: %v", err))
-		return
+		return nil, errors.Wrapf(ErrGettingDAOCoinLimitOrders, "%v", err)
 	}
 
-	ordersBuyingCoin2, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(coin2PKID, coin1PKID)
+	ordersBuyingCoin2, err := fes.getAllDAOCoinLimitOrdersForThisDAOCoinPair(utxoView, coin2PKID, coin1PKID)
 	if err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: Error getting limit orders: %v", err))
-		return
+		return nil, errors.Wrapf(ErrGettingDAOCoinLimitOrders, "%v", err)
+	}
+
+	sortDAOCoinLimitOrdersForPagination(ordersBuyingCoin1)
+	sortDAOCoinLimitOrdersForPagination(ordersBuyingCoin2)
+	allOrders := append(append([]*lib.DAOCoinLimitOrderEntry{}, ordersBuyingCoin1...), ordersBuyingCoin2...)
+	numOrdersBuyingCoin1 := len(ordersBuyingCoin1)
+
+	startIdx := 0
+	if offset != nil {
+		startIdx = sort.Search(len(allOrders), func(ii int) bool {
+			return offset.isBefore(ii >= numOrdersBuyingCoin1, allOrders[ii])
+		})
+	}
+	endIdx := len(allOrders)
+	nextOffset := ""
+	if requestData.Limit > 0 {
+		if limit := fes.getPageSize(uint64(requestData.Limit)); startIdx+limit < endIdx {
+			endIdx = startIdx + limit
+			lastOrder := allOrders[endIdx-1]
+			nextOffset = (&daoCoinLimitOrdersOffset{
+				isBuyingCoin2:      endIdx-1 >= numOrdersBuyingCoin1,
+				scaledExchangeRate: lastOrder.ScaledExchangeRateCoinsToSellPerCoinToBuy,
+				orderID:            lastOrder.OrderID,
+			}).String()
+		}
+	}
+
+	// Split the page back into the two sides so that each order's buying and selling coins are set correctly
+	pageOrdersBuyingCoin1 := allOrders[startIdx:endIdx]
+	pageOrdersBuyingCoin2 := []*lib.DAOCoinLimitOrderEntry{}
+	if endIdx > numOrdersBuyingCoin1 {
+		splitIdx := numOrdersBuyingCoin1
+		if startIdx > splitIdx {
+			splitIdx = startIdx
+		}
+		pageOrdersBuyingCoin1 = allOrders[startIdx:splitIdx]
+		pageOrdersBuyingCoin2 = allOrders[splitIdx:endIdx]
 	}
 
 	responses := append(
 		fes.buildDAOCoinLimitOrderResponsesFromEntriesForCoinPair(
 			utxoView,
-			requestData.DAOCoin1CreatorPublicKeyBase58Check,
-			requestData.DAOCoin2CreatorPublicKeyBase58Check,
-			ordersBuyingCoin1,
+			coin1PublicKeyBase58Check,
+			coin2PublicKeyBase58Check,
+			pageOrdersBuyingCoin1,
 		),
 		fes.buildDAOCoinLimitOrderResponsesFromEntriesForCoinPair(
 			utxoView,
-			requestData.DAOCoin2CreatorPublicKeyBase58Check,
-			requestData.DAOCoin1CreatorPublicKeyBase58Check,
-			ordersBuyingCoin2,
+			coin2PublicKeyBase58Check,
+			coin1PublicKeyBase58Check,
+			pageOrdersBuyingCoin2,
 		)...,
 	)
+	if requestData.IncludeUSDPrices {
+		fes.setUSDPricesOnDAOCoinLimitOrderResponses(responses)
+	}
 
-	if err = json.NewEncoder(ww).Encode(GetDAOCoinLimitOrdersResponse{Orders: responses}); err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrders: Problem encoding response as JSON: %v", err))
+	return &GetDAOCoinLimitOrdersResponse{
+		Orders:     responses,
+		NextOffset: nextOffset,
+	}, nil
+}
+
+// MaxGetDAOCoinLimitOrdersBatchPairs is the max number of coin pairs in a GetDAOCoinLimitOrdersBatch request
+const MaxGetDAOCoinLimitOrdersBatchPairs = 50
+
+type GetDAOCoinLimitOrdersBatchRequest struct {
+	// Each pair accepts the same fields as a GetDAOCoinLimitOrders request, including Limit and Offset
+	CoinPairs []GetDAOCoinLimitOrdersRequest `safeForLogging:"true"`
+}
+
+type DAOCoinLimitOrdersBatchResult struct {
+	// The same fields returned by GetDAOCoinLimitOrders for this pair
+	GetDAOCoinLimitOrdersResponse
+
+	// Set if this pair's orders couldn't be fetched, in which case the other fields are empty
+	Error string `safeForLogging:"true"`
+}
+
+type GetDAOCoinLimitOrdersBatchResponse struct {
+	// Keyed by getDAOCoinLimitOrdersBatchResultKey for each requested pair: "<DAOCoin1CreatorPublicKeyBase58Check>/
+	// <DAOCoin2CreatorPublicKeyBase58Check>", followed by "?Limit=<Limit>&Offset=<Offset>&IncludeUSDPrices=true" with
+	// only the options the pair sets, so that two pages of the same pair get separate results.
+	ResultsByCoinPair map[string]DAOCoinLimitOrdersBatchResult
+}
+
+func getDAOCoinLimitOrdersBatchKey(coinPair *GetDAOCoinLimitOrdersRequest) string {
+	return coinPair.DAOCoin1CreatorPublicKeyBase58Check + "/" + coinPair.DAOCoin2CreatorPublicKeyBase58Check
+}
+
+// getDAOCoinLimitOrdersBatchResultKey extends getDAOCoinLimitOrdersBatchKey with every option that changes a pair's
+// result. A pair without options keeps the plain coin pair key.
+func getDAOCoinLimitOrdersBatchResultKey(coinPair *GetDAOCoinLimitOrdersRequest) string {
+	var options []string
+	if coinPair.Limit != 0 {
+		options = append(options, fmt.Sprintf("Limit=%d", coinPair.Limit))
+	}
+	if coinPair.Offset != "" {
+		options = append(options, "Offset="+coinPair.Offset)
+	}
+	if coinPair.IncludeUSDPrices {
+		options = append(options, "IncludeUSDPrices=true")
+	}
+	if len(options) == 0 {
+		return getDAOCoinLimitOrdersBatchKey(coinPair)
+	}
+	return getDAOCoinLimitOrdersBatchKey(coinPair) + "?" + strings.Join(options, "&")
+}
+
+// GetDAOCoinLimitOrdersBatch returns the open orders for several coin pairs using a single view. An invalid pair has
+// its error reported inline rather than failing the whole request, but failing to read the orders fails it.
+func (fes *APIServer) GetDAOCoinLimitOrdersBatch(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinLimitOrdersBatchRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersBatch: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.CoinPairs) == 0 || len(requestData.CoinPairs) > MaxGetDAOCoinLimitOrdersBatchPairs {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinLimitOrdersBatch: Must provide between 1 and %v CoinPairs", MaxGetDAOCoinLimitOrdersBatchPairs))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersBatch: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	res := GetDAOCoinLimitOrdersBatchResponse{
+		ResultsByCoinPair: make(map[string]DAOCoinLimitOrdersBatchResult, len(requestData.CoinPairs)),
+	}
+	for ii := range requestData.CoinPairs {
+		coinPair := &requestData.CoinPairs[ii]
+		result := DAOCoinLimitOrdersBatchResult{}
+		coinPairResponse, err := fes.getDAOCoinLimitOrdersForCoinPair(utxoView, coinPair)
+		if errors.Cause(err) == ErrGettingDAOCoinLimitOrders {
+			_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersBatch: %v", err))
+			return
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.GetDAOCoinLimitOrdersResponse = *coinPairResponse
+		}
+		res.ResultsByCoinPair[getDAOCoinLimitOrdersBatchResultKey(coinPair)] = result
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersBatch: Problem encoding response as JSON: %v", err))
 		return
 	}
 }
 
+// daoCoinLimitOrdersOffset is an order's position in GetDAOCoinLimitOrders' sort order: the side of the pair it
+// buys, then its price, then its OrderID. It's encoded as "<1 or 2>:<ScaledExchangeRateCoinsToSellPerCoinToBuy>:
+// <OrderID>", where 1 or 2 is the coin the order buys.
+type daoCoinLimitOrdersOffset struct {
+	isBuyingCoin2      bool
+	scaledExchangeRate *uint256.Int
+	orderID            *lib.BlockHash
+}
+
+func (offset *daoCoinLimitOrdersOffset) String() string {
+	side := "1"
+	if offset.isBuyingCoin2 {
+		side = "2"
+	}
+	return side + ":" + offset.scaledExchangeRate.ToBig().String() + ":" + offset.orderID.String()
+}
+
+func parseDAOCoinLimitOrdersOffset(offsetString string) (*daoCoinLimitOrdersOffset, error) {
+	parts := strings.Split(offsetString, ":")
+	if len(parts) != 3 || (parts[0] != "1" && parts[0] != "2") {
+		return nil, errors.Errorf("must be a NextOffset returned by a previous request")
+	}
+	scaledExchangeRateAsBigInt, ok := big.NewInt(0).SetString(parts[1], 10)
+	if !ok || scaledExchangeRateAsBigInt.Sign() < 0 {
+		return nil, errors.Errorf("invalid exchange rate %v", parts[1])
+	}
+	scaledExchangeRate, overflows := uint256.FromBig(scaledExchangeRateAsBigInt)
+	if overflows {
+		return nil, errors.Errorf("exchange rate %v overflows", parts[1])
+	}
+	orderID, err := decodeBlockHashFromHex(parts[2])
+	if err != nil {
+		return nil, errors.Errorf("invalid OrderID %v: %v", parts[2], err)
+	}
+	return &daoCoinLimitOrdersOffset{
+		isBuyingCoin2:      parts[0] == "2",
+		scaledExchangeRate: scaledExchangeRate,
+		orderID:            orderID,
+	}, nil
+}
+
+// isBefore returns true if the offset sorts strictly before the order, which is on the side given by isBuyingCoin2.
+// Orders buying DAOCoin1 come first, and each side is sorted like sortDAOCoinLimitOrdersForPagination.
+func (offset *daoCoinLimitOrdersOffset) isBefore(isBuyingCoin2 bool, order *lib.DAOCoinLimitOrderEntry) bool {
+	if offset.isBuyingCoin2 != isBuyingCoin2 {
+		return !offset.isBuyingCoin2
+	}
+	if rateCmp := offset.scaledExchangeRate.Cmp(order.ScaledExchangeRateCoinsToSellPerCoinToBuy); rateCmp != 0 {
+		return rateCmp > 0
+	}
+	return bytes.Compare(offset.orderID[:], order.OrderID[:]) < 0
+}
+
+// sortDAOCoinLimitOrdersForPagination sorts orders for one side of a coin pair from best to worst price, breaking
+// ties by OrderID so that the order is deterministic
+func sortDAOCoinLimitOrdersForPagination(orders []*lib.DAOCoinLimitOrderEntry) {
+	sort.Slice(orders, func(ii, jj int) bool {
+		rateCmp := orders[ii].ScaledExchangeRateCoinsToSellPerCoinToBuy.Cmp(
+			orders[jj].ScaledExchangeRateCoinsToSellPerCoinToBuy)
+		if rateCmp != 0 {
+			return rateCmp > 0
+		}
+		return bytes.Compare(orders[ii].OrderID[:], orders[jj].OrderID[:]) < 0
+	})
+}
+
 type GetTransactorDAOCoinLimitOrdersRequest struct {
 	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Optional. If set, only orders buying or selling this coin are returned. Either can be "DESO".
+	BuyingDAOCoinCreatorPublicKeyBase58Check  string `safeForLogging:"true"`
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Optional. If set, orders are sorted by their Price or Quantity, in ascending order unless SortDescending is set.
+	// Orders are returned in no particular order otherwise.
+	SortBy         TransactorDAOCoinLimitOrdersSortBy `safeForLogging:"true"`
+	SortDescending bool                               `safeForLogging:"true"`
+
+	// Optional. If set, USDPricePerCoinToBuy is populated on each order.
+	IncludeUSDPrices bool `safeForLogging:"true"`
 }
 
+// TransactorDAOCoinLimitOrdersSortBy is the field to sort GetTransactorDAOCoinLimitOrders results by
+type TransactorDAOCoinLimitOrdersSortBy string
+
+const (
+	TransactorDAOCoinLimitOrdersSortByPrice    TransactorDAOCoinLimitOrdersSortBy = "price"
+	TransactorDAOCoinLimitOrdersSortByQuantity TransactorDAOCoinLimitOrdersSortBy = "quantity"
+)
+
 func (fes *APIServer) GetTransactorDAOCoinLimitOrders(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetTransactorDAOCoinLimitOrdersRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(
@@ -177,13 +481,67 @@ func (fes *APIServer) GetTransactorDAOCoinLimitOrders(ww http.ResponseWriter, re
 		return
 	}
 
+	if requestData.SortBy != "" &&
+		requestData.SortBy != TransactorDAOCoinLimitOrdersSortByPrice &&
+		requestData.SortBy != TransactorDAOCoinLimitOrdersSortByQuantity {
+		_AddBadRequestError(
+			ww,
+			fmt.Sprintf("GetTransactorDAOCoinLimitOrders: Invalid SortBy %v; must be %v or %v", requestData.SortBy,
+				TransactorDAOCoinLimitOrdersSortByPrice, TransactorDAOCoinLimitOrdersSortByQuantity),
+		)
+		return
+	}
+
+	var buyingCoinPKID, sellingCoinPKID *lib.PKID
+	if requestData.BuyingDAOCoinCreatorPublicKeyBase58Check != "" {
+		buyingCoinPKID, err = fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(
+			utxoView, requestData.BuyingDAOCoinCreatorPublicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestError(
+				ww,
+				fmt.Sprintf("GetTransactorDAOCoinLimitOrders: Invalid BuyingDAOCoinCreatorPublicKeyBase58Check: %v", err),
+			)
+			return
+		}
+	}
+	if requestData.SellingDAOCoinCreatorPublicKeyBase58Check != "" {
+		sellingCoinPKID, err = fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(
+			utxoView, requestData.SellingDAOCoinCreatorPublicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestError(
+				ww,
+				fmt.Sprintf("GetTransactorDAOCoinLimitOrders: Invalid SellingDAOCoinCreatorPublicKeyBase58Check: %v", err),
+			)
+			return
+		}
+	}
+
 	orders, err := utxoView.GetAllDAOCoinLimitOrdersForThisTransactor(transactorPKID)
 	if err != nil {
 		_AddInternalServerError(ww, fmt.Sprintf("GetTransactorDAOCoinLimitOrders: Error getting limit orders: %v", err))
 		return
 	}
 
-	responses := fes.buildDAOCoinLimitOrderResponsesForTransactor(utxoView, requestData.TransactorPublicKeyBase58Check, orders)
+	// Filter before building responses so that we don't look up public keys for orders we're going to drop
+	filteredOrders := []*lib.DAOCoinLimitOrderEntry{}
+	for _, order := range orders {
+		if buyingCoinPKID != nil && !buyingCoinPKID.Eq(order.BuyingDAOCoinCreatorPKID) {
+			continue
+		}
+		if sellingCoinPKID != nil && !sellingCoinPKID.Eq(order.SellingDAOCoinCreatorPKID) {
+			continue
+		}
+		filteredOrders = append(filteredOrders, order)
+	}
+
+	responses := fes.buildDAOCoinLimitOrderResponsesForTransactor(
+		req.Context(), utxoView, requestData.TransactorPublicKeyBase58Check, filteredOrders)
+	if requestData.SortBy != "" {
+		sortDAOCoinLimitOrderResponses(responses, requestData.SortBy, requestData.SortDescending)
+	}
+	if requestData.IncludeUSDPrices {
+		fes.setUSDPricesOnDAOCoinLimitOrderResponses(responses)
+	}
 
 	if err = json.NewEncoder(ww).Encode(GetDAOCoinLimitOrdersResponse{Orders: responses}); err != nil {
 		_AddInternalServerError(ww, fmt.Sprintf("GetTransactorDAOCoinLimitOrders: Problem encoding response as JSON: %v", err))
@@ -191,88 +549,494 @@ func (fes *APIServer) GetTransactorDAOCoinLimitOrders(ww http.ResponseWriter, re
 	}
 }
 
-func (fes *APIServer) getPKIDFromPublicKeyBase58Check(
-	utxoView *lib.UtxoView,
-	publicKeyBase58Check string,
-) (*lib.PKID, error) {
-	publicKeyBytes, err := GetPubKeyBytesFromBase58Check(publicKeyBase58Check)
-	if err != nil {
-		return nil, err
+// sortDAOCoinLimitOrderResponses sorts responses by their Price or Quantity decimal strings. The strings are compared
+// exactly rather than through the deprecated float fields, which can't tell apart values that differ past 15
+// significant digits. Ties are broken by OrderID so that the order is deterministic.
+func sortDAOCoinLimitOrderResponses(
+	responses []DAOCoinLimitOrderEntryResponse,
+	sortBy TransactorDAOCoinLimitOrdersSortBy,
+	sortDescending bool,
+) {
+	sortValues := make(map[string]*big.Rat, len(responses))
+	for _, response := range responses {
+		valueStr := response.Price
+		if sortBy == TransactorDAOCoinLimitOrdersSortByQuantity {
+			valueStr = response.Quantity
+		}
+		value, ok := big.NewRat(0, 1).SetString(valueStr)
+		if !ok {
+			value = big.NewRat(0, 1)
+		}
+		sortValues[response.OrderID] = value
 	}
 
-	pkid := utxoView.GetPKIDForPublicKey(publicKeyBytes).PKID
-
-	return pkid, nil
+	sort.Slice(responses, func(ii, jj int) bool {
+		valueCmp := sortValues[responses[ii].OrderID].Cmp(sortValues[responses[jj].OrderID])
+		if valueCmp == 0 {
+			return responses[ii].OrderID < responses[jj].OrderID
+		}
+		if sortDescending {
+			return valueCmp > 0
+		}
+		return valueCmp < 0
+	})
 }
 
-func (fes *APIServer) buildDAOCoinLimitOrderResponsesFromEntriesForCoinPair(
-	utxoView *lib.UtxoView,
-	buyingCoinPublicKeyBase58Check string,
-	sellingCoinPublicKeyBase58Check string,
-	orders []*lib.DAOCoinLimitOrderEntry,
-) []DAOCoinLimitOrderEntryResponse {
-	var responses []DAOCoinLimitOrderEntryResponse
+type GetTransactorExchangeExposureRequest struct {
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+}
 
-	for _, order := range orders {
-		transactorPublicKey := utxoView.GetPublicKeyForPKID(order.TransactorPKID)
+type DAOCoinExposureResponse struct {
+	// Either DESOCoinIdentifierString or the public key of the DAO coin's creator
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
 
-		response, err := buildDAOCoinLimitOrderResponse(
-			lib.Base58CheckEncode(transactorPublicKey, false, fes.Params),
-			buyingCoinPublicKeyBase58Check,
-			sellingCoinPublicKeyBase58Check,
-			order,
-		)
-		if err != nil {
-			continue
-		}
+	NumOpenOrders uint64
 
-		responses = append(responses, *response)
-	}
+	// The total quantity of the selling coin locked in the transactor's open orders as a decimal string (ex: 1.23),
+	// in base units, and as a float
+	LockedQuantity          string
+	LockedQuantityBaseUnits string
+	LockedQuantityFloat     float64
+}
 
-	return responses
+type GetTransactorExchangeExposureResponse struct {
+	// One entry per coin the transactor is selling in at least one open order, sorted by coin
+	Exposures []DAOCoinExposureResponse
 }
 
-func (fes *APIServer) buildDAOCoinLimitOrderResponsesForTransactor(
-	utxoView *lib.UtxoView,
-	transactorPublicKeyBase58Check string,
-	orders []*lib.DAOCoinLimitOrderEntry,
-) []DAOCoinLimitOrderEntryResponse {
-	var responses []DAOCoinLimitOrderEntryResponse
+// GetTransactorExchangeExposure sums the quantity of each coin that a transactor has committed to their open
+// DAO coin limit orders
+func (fes *APIServer) GetTransactorExchangeExposure(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetTransactorExchangeExposureRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetTransactorExchangeExposure: Problem parsing request body: %v", err))
+		return
+	}
 
-	for _, order := range orders {
-		buyingCoinPublicKeyBase58Check := fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, order.BuyingDAOCoinCreatorPKID)
-		sellingCoinPublicKeyBase58Check := fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, order.SellingDAOCoinCreatorPKID)
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetTransactorExchangeExposure: Problem fetching utxoView: %v", err))
+		return
+	}
 
-		response, err := buildDAOCoinLimitOrderResponse(
-			transactorPublicKeyBase58Check,
-			buyingCoinPublicKeyBase58Check,
-			sellingCoinPublicKeyBase58Check,
-			order,
+	transactorPKID, err := fes.getPKIDFromPublicKeyBase58Check(
+		utxoView,
+		requestData.TransactorPublicKeyBase58Check,
+	)
+	if err != nil {
+		_AddBadRequestError(
+			ww,
+			fmt.Sprintf("GetTransactorExchangeExposure: Invalid TransactorPublicKeyBase58Check: %v", err),
 		)
+		return
+	}
+
+	orders, err := utxoView.GetAllDAOCoinLimitOrdersForThisTransactor(transactorPKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetTransactorExchangeExposure: Error getting limit orders: %v", err))
+		return
+	}
+
+	// Group the open orders by selling coin and sum the quantity each one locks up
+	lockedBaseUnitsBySellingCoin := make(map[string]*uint256.Int)
+	numOrdersBySellingCoin := make(map[string]uint64)
+	for _, order := range orders {
+		orderSellingBaseUnits, err := order.BaseUnitsToSellUint256()
 		if err != nil {
-			glog.Errorf(
-				"buildDAOCoinLimitOrderResponsesForTransactor: Unable to build DAO coin limit order response for limit order with OrderID: %v",
-				order.OrderID,
+			// Same as the other read-only endpoints, we skip bad orders rather than failing the whole request
+			logRequestErrorf(req.Context(),
+				"GetTransactorExchangeExposure: Unable to calculate selling quantity for limit order with OrderID: %v: %v",
+				order.OrderID, err,
 			)
 			continue
 		}
 
-		responses = append(responses, *response)
+		sellingCoin := fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, order.SellingDAOCoinCreatorPKID)
+		totalBaseUnits, exists := lockedBaseUnitsBySellingCoin[sellingCoin]
+		if !exists {
+			totalBaseUnits = uint256.NewInt()
+		}
+		totalBaseUnits, err = lib.SafeUint256().Add(totalBaseUnits, orderSellingBaseUnits)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("GetTransactorExchangeExposure: Error adding open order selling quantity: %v", err))
+			return
+		}
+		lockedBaseUnitsBySellingCoin[sellingCoin] = totalBaseUnits
+		numOrdersBySellingCoin[sellingCoin]++
 	}
 
-	return responses
-}
+	res := GetTransactorExchangeExposureResponse{
+		Exposures: []DAOCoinExposureResponse{},
+	}
+	for sellingCoin, totalBaseUnits := range lockedBaseUnitsBySellingCoin {
+		lockedQuantityFloat, err := calculateScaledUint256AsFloat(
+			totalBaseUnits.ToBig(), getScalingFactorForCoin(sellingCoin).ToBig())
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("GetTransactorExchangeExposure: %v", err))
+			return
+		}
+		res.Exposures = append(res.Exposures, DAOCoinExposureResponse{
+			SellingDAOCoinCreatorPublicKeyBase58Check: sellingCoin,
+			NumOpenOrders:           numOrdersBySellingCoin[sellingCoin],
+			LockedQuantity:          CalculateDisplayUnitsFromBaseUnits(sellingCoin, totalBaseUnits),
+			LockedQuantityBaseUnits: totalBaseUnits.ToBig().String(),
+			LockedQuantityFloat:     lockedQuantityFloat,
+		})
+	}
+	sort.Slice(res.Exposures, func(ii, jj int) bool {
+		return res.Exposures[ii].SellingDAOCoinCreatorPublicKeyBase58Check <
+			res.Exposures[jj].SellingDAOCoinCreatorPublicKeyBase58Check
+	})
 
-func (fes *APIServer) getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView *lib.UtxoView, pkid *lib.PKID) string {
-	base58Check := DESOCoinIdentifierString
-	if !pkid.IsZeroPKID() {
-		base58Check = lib.Base58CheckEncode(utxoView.GetPublicKeyForPKID(pkid), false, fes.Params)
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetTransactorExchangeExposure: Problem encoding response as JSON: %v", err))
+		return
 	}
-	return base58Check
 }
 
-func buildDAOCoinLimitOrderResponse(
-	transactorPublicKeyBase58Check string,
+type ConvertCoinUnitsRequest struct {
+	// Either DESOCoinIdentifierString or the public key of the DAO coin's creator
+	CoinIdentifier string `safeForLogging:"true"`
+
+	// Exactly one of BaseUnits or DisplayUnits must be provided. BaseUnits is a decimal integer string
+	// (ex: "1500000000"), and DisplayUnits is a decimal string in whole coins (ex: "1.5")
+	BaseUnits    string `safeForLogging:"true"`
+	DisplayUnits string `safeForLogging:"true"`
+}
+
+type ConvertCoinUnitsResponse struct {
+	CoinIdentifier string
+
+	BaseUnits         string
+	DisplayUnits      string
+	DisplayUnitsFloat float64
+
+	// The number of base units per whole coin. 1e9 for DESO and 1e18 for DAO coins
+	BaseUnitsPerCoin string
+}
+
+func (fes *APIServer) ConvertCoinUnits(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := ConvertCoinUnitsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertCoinUnits: Problem parsing request body: %v", err))
+		return
+	}
+
+	if !isDESOCoinIdentifier(requestData.CoinIdentifier) {
+		if _, err := GetPubKeyBytesFromBase58Check(requestData.CoinIdentifier); err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("ConvertCoinUnits: Invalid CoinIdentifier: %v", err))
+			return
+		}
+	}
+
+	if (requestData.BaseUnits == "") == (requestData.DisplayUnits == "") {
+		_AddBadRequestError(ww, "ConvertCoinUnits: Must provide exactly one of BaseUnits or DisplayUnits")
+		return
+	}
+
+	var baseUnits *uint256.Int
+	var err error
+	if requestData.BaseUnits != "" {
+		baseUnits, err = parseBaseUnitsString(requestData.BaseUnits)
+	} else {
+		baseUnits, err = CalculateBaseUnitsFromDisplayUnits(requestData.CoinIdentifier, requestData.DisplayUnits)
+	}
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertCoinUnits: Problem converting coin units: %v", err))
+		return
+	}
+
+	scalingFactor := getScalingFactorForCoin(requestData.CoinIdentifier)
+	displayUnitsFloat, err := calculateScaledUint256AsFloat(baseUnits.ToBig(), scalingFactor.ToBig())
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertCoinUnits: Problem converting base units to float: %v", err))
+		return
+	}
+
+	res := ConvertCoinUnitsResponse{
+		CoinIdentifier:    requestData.CoinIdentifier,
+		BaseUnits:         baseUnits.ToBig().String(),
+		DisplayUnits:      CalculateDisplayUnitsFromBaseUnits(requestData.CoinIdentifier, baseUnits),
+		DisplayUnitsFloat: displayUnitsFloat,
+		BaseUnitsPerCoin:  scalingFactor.ToBig().String(),
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("ConvertCoinUnits: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type GetDAOCoinMinOrderSizeRequest struct {
+	// Either DESOCoinIdentifierString or the public key of the DAO coin's creator
+	BuyingDAOCoinCreatorPublicKeyBase58Check  string `safeForLogging:"true"`
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+}
+
+type GetDAOCoinMinOrderSizeResponse struct {
+	// Decimal strings (ex: 0.000000001) for the smallest quantity of each coin that can be specified in an order
+	BuyingCoinMinQuantity  string
+	SellingCoinMinQuantity string
+
+	BuyingCoinMinQuantityBaseUnits  string
+	SellingCoinMinQuantityBaseUnits string
+}
+
+// GetDAOCoinMinOrderSize returns the smallest order quantity that can be specified for each side of a coin pair. The
+// protocol only requires that quantities be nonzero, so this is one base unit of each coin: 1e-9 for $DESO and 1e-18
+// for DAO coins. Note that orders at the minimum size may still fail to match if the price makes the quantity of the
+// other coin round down to zero.
+func (fes *APIServer) GetDAOCoinMinOrderSize(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinMinOrderSizeRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinMinOrderSize: Problem parsing request body: %v", err))
+		return
+	}
+
+	if _, _, err := fes.getBuyingAndSellingDAOCoinPublicKeys(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+	); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinMinOrderSize: %v", err))
+		return
+	}
+
+	minQuantityBaseUnits := uint256.NewInt().SetUint64(1)
+	res := GetDAOCoinMinOrderSizeResponse{
+		BuyingCoinMinQuantity: CalculateDisplayUnitsFromBaseUnits(
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check, minQuantityBaseUnits),
+		SellingCoinMinQuantity: CalculateDisplayUnitsFromBaseUnits(
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check, minQuantityBaseUnits),
+		BuyingCoinMinQuantityBaseUnits:  minQuantityBaseUnits.ToBig().String(),
+		SellingCoinMinQuantityBaseUnits: minQuantityBaseUnits.ToBig().String(),
+	}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMinOrderSize: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type GetExchangeFeeScheduleRequest struct {
+	// Optional. If provided, node-level fees are omitted when this public key is exempt from them.
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+}
+
+type GetExchangeFeeScheduleResponse struct {
+	// The additional outputs this node adds to every DAO coin limit order transaction
+	NodeFees          []TransactionFee
+	TotalNodeFeeNanos uint64
+	// True if TransactorPublicKeyBase58Check is exempt from node-level fees
+	IsTransactorExempt bool
+
+	// The network's minimum fee rate from global params, and the fee rate this node uses by default when a request
+	// doesn't specify MinFeeRateNanosPerKB
+	MinimumNetworkFeeNanosPerKB uint64
+	DefaultFeeRateNanosPerKB    uint64
+}
+
+// GetExchangeFeeSchedule returns the fees this node applies to DAO coin limit order transactions
+func (fes *APIServer) GetExchangeFeeSchedule(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetExchangeFeeScheduleRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetExchangeFeeSchedule: Problem parsing request body: %v", err))
+		return
+	}
+
+	res := GetExchangeFeeScheduleResponse{
+		NodeFees: []TransactionFee{},
+	}
+
+	if requestData.TransactorPublicKeyBase58Check != "" {
+		if _, err := GetPubKeyBytesFromBase58Check(requestData.TransactorPublicKeyBase58Check); err != nil {
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetExchangeFeeSchedule: Invalid TransactorPublicKeyBase58Check: %v", err))
+			return
+		}
+		_, res.IsTransactorExempt = fes.ExemptPublicKeyMap[requestData.TransactorPublicKeyBase58Check]
+	}
+
+	// This mirrors getTransactionFee, which is what adds these outputs when the order transaction is constructed
+	if !res.IsTransactorExempt {
+		for _, output := range fes.TransactionFeeMap[lib.TxnTypeDAOCoinLimitOrder] {
+			res.NodeFees = append(res.NodeFees, TransactionFee{
+				PublicKeyBase58Check: lib.PkToString(output.PublicKey, fes.Params),
+				AmountNanos:          output.AmountNanos,
+			})
+			res.TotalNodeFeeNanos += output.AmountNanos
+		}
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetExchangeFeeSchedule: Problem fetching utxoView: %v", err))
+		return
+	}
+	res.DefaultFeeRateNanosPerKB = fes.MinFeeRateNanosPerKB
+	if utxoView.GlobalParamsEntry != nil {
+		res.MinimumNetworkFeeNanosPerKB = utxoView.GlobalParamsEntry.MinimumNetworkFeeNanosPerKB
+		if utxoView.GlobalParamsEntry.MinimumNetworkFeeNanosPerKB > 0 {
+			res.DefaultFeeRateNanosPerKB = utxoView.GlobalParamsEntry.MinimumNetworkFeeNanosPerKB
+		}
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetExchangeFeeSchedule: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+func (fes *APIServer) getPKIDFromPublicKeyBase58Check(
+	utxoView *lib.UtxoView,
+	publicKeyBase58Check string,
+) (*lib.PKID, error) {
+	publicKeyBytes, err := GetPubKeyBytesFromBase58Check(publicKeyBase58Check)
+	if err != nil {
+		return nil, err
+	}
+
+	pkid := utxoView.GetPKIDForPublicKey(publicKeyBytes).PKID
+
+	return pkid, nil
+}
+
+// getPKIDFromPublicKeyBase58CheckOrUsername resolves a creator given either their public key or the username of
+// their profile
+func (fes *APIServer) getPKIDFromPublicKeyBase58CheckOrUsername(
+	utxoView *lib.UtxoView,
+	publicKeyBase58CheckOrUsername string,
+) (*lib.PKID, error) {
+	publicKeyBytes, _, err := fes.GetPubKeyAndProfileEntryForUsernameOrPublicKeyBase58Check(
+		publicKeyBase58CheckOrUsername, utxoView)
+	if err != nil {
+		return nil, err
+	}
+
+	pkid := utxoView.GetPKIDForPublicKey(publicKeyBytes).PKID
+
+	return pkid, nil
+}
+
+// getPKIDForCoinPublicKeyBase58CheckOrDESO returns the ZeroPKID for any $DESO identifier accepted by
+// normalizeCoinIdentifier, and the PKID of the DAO coin's creator otherwise
+func (fes *APIServer) getPKIDForCoinPublicKeyBase58CheckOrDESO(
+	utxoView *lib.UtxoView,
+	coinPublicKeyBase58CheckOrDESO string,
+) (*lib.PKID, error) {
+	if isDESOCoinIdentifier(coinPublicKeyBase58CheckOrDESO) {
+		return &lib.ZeroPKID, nil
+	}
+	return fes.getPKIDFromPublicKeyBase58Check(utxoView, coinPublicKeyBase58CheckOrDESO)
+}
+
+func (fes *APIServer) buildDAOCoinLimitOrderResponsesFromEntriesForCoinPair(
+	utxoView *lib.UtxoView,
+	buyingCoinPublicKeyBase58Check string,
+	sellingCoinPublicKeyBase58Check string,
+	orders []*lib.DAOCoinLimitOrderEntry,
+) []DAOCoinLimitOrderEntryResponse {
+	var responses []DAOCoinLimitOrderEntryResponse
+
+	for _, order := range orders {
+		transactorPublicKey := utxoView.GetPublicKeyForPKID(order.TransactorPKID)
+
+		response, err := buildDAOCoinLimitOrderResponse(
+			lib.Base58CheckEncode(transactorPublicKey, false, fes.Params),
+			buyingCoinPublicKeyBase58Check,
+			sellingCoinPublicKeyBase58Check,
+			order,
+		)
+		if err != nil {
+			continue
+		}
+
+		responses = append(responses, *response)
+	}
+
+	return responses
+}
+
+func (fes *APIServer) buildDAOCoinLimitOrderResponsesForTransactor(
+	ctx context.Context,
+	utxoView *lib.UtxoView,
+	transactorPublicKeyBase58Check string,
+	orders []*lib.DAOCoinLimitOrderEntry,
+) []DAOCoinLimitOrderEntryResponse {
+	var responses []DAOCoinLimitOrderEntryResponse
+
+	for _, order := range orders {
+		buyingCoinPublicKeyBase58Check := fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, order.BuyingDAOCoinCreatorPKID)
+		sellingCoinPublicKeyBase58Check := fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, order.SellingDAOCoinCreatorPKID)
+
+		response, err := buildDAOCoinLimitOrderResponse(
+			transactorPublicKeyBase58Check,
+			buyingCoinPublicKeyBase58Check,
+			sellingCoinPublicKeyBase58Check,
+			order,
+		)
+		if err != nil {
+			logRequestErrorf(ctx,
+				"buildDAOCoinLimitOrderResponsesForTransactor: Unable to build DAO coin limit order response for limit order with OrderID: %v",
+				order.OrderID,
+			)
+			continue
+		}
+
+		responses = append(responses, *response)
+	}
+
+	return responses
+}
+
+func (fes *APIServer) getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView *lib.UtxoView, pkid *lib.PKID) string {
+	base58Check := DESOCoinIdentifierString
+	if !pkid.IsZeroPKID() {
+		base58Check = lib.Base58CheckEncode(utxoView.GetPublicKeyForPKID(pkid), false, fes.Params)
+	}
+	return base58Check
+}
+
+// setUSDPricesOnDAOCoinLimitOrderResponses sets USDPricePerCoinToBuy on orders where either coin is $DESO, using the
+// same $DESO price as GetExchangeRate. Orders are left unchanged if there's no $DESO price yet.
+func (fes *APIServer) setUSDPricesOnDAOCoinLimitOrderResponses(responses []DAOCoinLimitOrderEntryResponse) {
+	usdPerDESO := float64(fes.GetExchangeDeSoPrice()) / 100
+	if usdPerDESO == 0 {
+		return
+	}
+	for ii := range responses {
+		responses[ii].USDPricePerCoinToBuy = calculateUSDPricePerCoinToBuy(
+			responses[ii].BuyingDAOCoinCreatorPublicKeyBase58Check,
+			responses[ii].SellingDAOCoinCreatorPublicKeyBase58Check,
+			responses[ii].ExchangeRateCoinsToSellPerCoinToBuy,
+			usdPerDESO,
+		)
+	}
+}
+
+// calculateUSDPricePerCoinToBuy converts an exchange rate in coins to sell per coin to buy into the USD value of one
+// of the order's DAO coins, whichever side of the order it's on. Returns an empty string if neither coin is $DESO.
+func calculateUSDPricePerCoinToBuy(
+	buyingCoinPublicKeyBase58Check string,
+	sellingCoinPublicKeyBase58Check string,
+	exchangeRateCoinsToSellPerCoinToBuy float64,
+	usdPerDESO float64,
+) string {
+	if isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) {
+		// The exchange rate is in DAO coins per $DESO, so a DAO coin is worth 1 / exchangeRate $DESO.
+		if exchangeRateCoinsToSellPerCoinToBuy == 0 {
+			return ""
+		}
+		return formatFloatAsString(usdPerDESO / exchangeRateCoinsToSellPerCoinToBuy)
+	}
+	if isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) {
+		return formatFloatAsString(exchangeRateCoinsToSellPerCoinToBuy * usdPerDESO)
+	}
+	return ""
+}
+
+func buildDAOCoinLimitOrderResponse(
+	transactorPublicKeyBase58Check string,
 	buyingCoinPublicKeyBase58Check string,
 	sellingCoinPublicKeyBase58Check string,
 	order *lib.DAOCoinLimitOrderEntry,
@@ -336,6 +1100,9 @@ func buildDAOCoinLimitOrderResponse(
 		ExchangeRateCoinsToSellPerCoinToBuy: exchangeRate,
 		QuantityToFill:                      quantityToFill,
 
+		ScaledExchangeRateString: order.ScaledExchangeRateCoinsToSellPerCoinToBuy.ToBig().String(),
+		QuantityToFillBaseUnits:  order.QuantityToFillInBaseUnits.ToBig().String(),
+
 		OperationType: operationTypeString,
 
 		OrderID: order.OrderID.String(),
@@ -398,6 +1165,10 @@ func (fes *APIServer) GetBestAvailableExchangeRateCoinsToBuyPerCoinToSell(
 // CalculateScaledExchangeRateFromPriceString calculates a scaled ExchangeRateCoinsToSellPerCoinsToBuy given a decimal
 // price string (ex: "1.23456") that represents an exchange rate between the two coins where the numerator is the coin
 // defined by the operation type.
+//
+// This is the recommended entrypoint for building an order's exchange rate. The string is parsed without going
+// through a float64, so prices with more than the 15 significant digits supported by formatFloatAsString are kept
+// exactly.
 func CalculateScaledExchangeRateFromPriceString(
 	buyingCoinPublicKeyBase58Check string,
 	sellingCoinPublicKeyBase58Check string,
@@ -434,10 +1205,10 @@ func CalculateScaledExchangeRateFromPriceString(
 
 		// For DESO <-> DAO coin trades, we scale the calculated exchange rate up or down by 1e9 to account for the
 		// scaling factor difference between DESO nanos and DAO coin base units
-		if buyingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+		if isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) {
 			// Scale the exchange rate up by 1e9 if the buying coin is DESO
 			rawScaledExchangeRateAsBigInt.Mul(rawScaledExchangeRateAsBigInt, getDESOToDAOCoinBaseUnitsScalingFactor().ToBig())
-		} else if sellingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+		} else if isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) {
 			// Scale the exchange rate down by 1e9 if the selling coin is DESO if  and round the quotient up.
 			// For the same reason as above, we round up the quotient, so it matches with bid orders created using the
 			// same input price
@@ -460,7 +1231,7 @@ func CalculateScaledExchangeRateFromPriceString(
 	// Beyond this point, we know that the operation type is lib.DAOCoinLimitOrderOperationTypeBID
 
 	// Scale up the price to account for DAO Coin -> DESO trades
-	if buyingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) {
 		product := uint256.NewInt()
 		overflow := product.MulOverflow(rawScaledPrice, getDESOToDAOCoinBaseUnitsScalingFactor())
 		if overflow {
@@ -470,7 +1241,7 @@ func CalculateScaledExchangeRateFromPriceString(
 	}
 
 	// Scale down the price to account for DAO Coin -> DESO trades
-	if sellingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) {
 		// We intentionally want to round the exchange rate down for BID orders so precision loss does not prevent the
 		// order from not getting matched with an ASK order with the same input price
 		quotient := uint256.NewInt().Div(rawScaledPrice, getDESOToDAOCoinBaseUnitsScalingFactor())
@@ -510,7 +1281,7 @@ func CalculateScaledExchangeRateFromFloat(
 	if rawScaledExchangeRate.IsZero() {
 		return nil, errors.Errorf("The float value %f is too small to produce a scaled exchange rate", exchangeRateCoinsToSellPerCoinToBuy)
 	}
-	if buyingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) {
 		// Buying coin is $DESO
 		product := uint256.NewInt()
 		overflow := product.MulOverflow(rawScaledExchangeRate, getDESOToDAOCoinBaseUnitsScalingFactor())
@@ -518,7 +1289,7 @@ func CalculateScaledExchangeRateFromFloat(
 			return nil, errors.Errorf("Overflow when convering %f to a scaled exchange rate", exchangeRateCoinsToSellPerCoinToBuy)
 		}
 		return product, nil
-	} else if sellingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	} else if isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) {
 		// Selling coin is $DESO
 		quotient := uint256.NewInt().Div(rawScaledExchangeRate, getDESOToDAOCoinBaseUnitsScalingFactor())
 		if quotient.IsZero() {
@@ -541,9 +1312,9 @@ func CalculatePriceStringFromScaledExchangeRate(
 ) (string, error) {
 	scaledExchangeRateAsBigInt := scaledValueExchangeRate.ToBig()
 
-	if buyingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) {
 		scaledExchangeRateAsBigInt.Div(scaledExchangeRateAsBigInt, getDESOToDAOCoinBaseUnitsScalingFactor().ToBig())
-	} else if sellingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	} else if isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) {
 		scaledExchangeRateAsBigInt.Mul(scaledExchangeRateAsBigInt, getDESOToDAOCoinBaseUnitsScalingFactor().ToBig())
 	}
 
@@ -576,9 +1347,9 @@ func CalculateFloatFromScaledExchangeRate(
 	scaledValue *uint256.Int,
 ) (float64, error) {
 	scaledValueAsBigInt := scaledValue.ToBig()
-	if buyingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) {
 		scaledValueAsBigInt.Div(scaledValueAsBigInt, getDESOToDAOCoinBaseUnitsScalingFactor().ToBig())
-	} else if sellingCoinPublicKeyBase58Check == DESOCoinIdentifierString {
+	} else if isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) {
 		scaledValueAsBigInt.Mul(scaledValueAsBigInt, getDESOToDAOCoinBaseUnitsScalingFactor().ToBig())
 	}
 
@@ -696,6 +1467,40 @@ func calculateQuantityToFillAsDESONanos(quantityToFill string) (*uint256.Int, er
 	return scaledQuantity, nil
 }
 
+// CalculateDisplayUnitsFromBaseUnits given a coin identifier and an amount in base units, this calculates the decimal
+// string amount in whole coins, using 1e9 as the scaling factor for $DESO and 1e18 for DAO coins
+func CalculateDisplayUnitsFromBaseUnits(coinCreatorPublicKeyBase58Check string, baseUnits *uint256.Int) string {
+	return lib.FormatScaledUint256AsDecimalString(
+		baseUnits.ToBig(),
+		getScalingFactorForCoin(coinCreatorPublicKeyBase58Check).ToBig(),
+	)
+}
+
+// CalculateBaseUnitsFromDisplayUnits given a coin identifier and a decimal string amount in whole coins, this calculates
+// the amount in base units, using 1e9 as the scaling factor for $DESO and 1e18 for DAO coins
+func CalculateBaseUnitsFromDisplayUnits(coinCreatorPublicKeyBase58Check string, displayUnits string) (*uint256.Int, error) {
+	if err := validateNonNegativeDecimalString(displayUnits); err != nil {
+		return nil, err
+	}
+	return lib.ScaleFloatFormatStringToUint256(displayUnits, getScalingFactorForCoin(coinCreatorPublicKeyBase58Check))
+}
+
+// parses a non-negative decimal integer string (ex: "1500000000") into a uint256
+func parseBaseUnitsString(baseUnits string) (*uint256.Int, error) {
+	baseUnitsAsBigInt, ok := big.NewInt(0).SetString(baseUnits, 10)
+	if !ok {
+		return nil, errors.Errorf("Error parsing input %v as an integer string", baseUnits)
+	}
+	if baseUnitsAsBigInt.Sign() < 0 {
+		return nil, errors.Errorf("Input integer string %v is unexpectedly less than 0", baseUnits)
+	}
+	result, overflows := uint256.FromBig(baseUnitsAsBigInt)
+	if overflows {
+		return nil, errors.Errorf("Input integer string %v overflows uint256", baseUnits)
+	}
+	return result, nil
+}
+
 // given a buying coin, selling coin, and operation type, this determines if the QuantityToFill field
 // for the coin the quantity field refers to is $DESO. If it's not $DESO, then it's assumed to be a DAO coin
 func isCoinToFillDESO(
@@ -703,8 +1508,8 @@ func isCoinToFillDESO(
 	sellingCoinPublicKeyBase58Check string,
 	operationTypeString DAOCoinLimitOrderOperationTypeString,
 ) bool {
-	return buyingCoinPublicKeyBase58Check == DESOCoinIdentifierString && operationTypeString == DAOCoinLimitOrderOperationTypeStringBID ||
-		sellingCoinPublicKeyBase58Check == DESOCoinIdentifierString && operationTypeString == DAOCoinLimitOrderOperationTypeStringASK
+	return isDESOCoinIdentifier(buyingCoinPublicKeyBase58Check) && operationTypeString == DAOCoinLimitOrderOperationTypeStringBID ||
+		isDESOCoinIdentifier(sellingCoinPublicKeyBase58Check) && operationTypeString == DAOCoinLimitOrderOperationTypeStringASK
 }
 
 // DAOCoinLimitOrderOperationTypeString A convenience type that uses a string to represent BID / ASK side in the API,
@@ -728,16 +1533,26 @@ func orderOperationTypeToString(
 	return "", errors.Errorf("Unknown DAOCoinLimitOrderOperationType %v", operationType)
 }
 
+// normalizeOrderOperationTypeString returns the canonical uppercase form of an operation type, so "bid" and "Bid" are
+// accepted as BID. Handlers normalize their request's OperationType before using it, since helpers such as
+// isCoinToFillDESO compare against the canonical constants.
+func normalizeOrderOperationTypeString(
+	operationType DAOCoinLimitOrderOperationTypeString,
+) DAOCoinLimitOrderOperationTypeString {
+	return DAOCoinLimitOrderOperationTypeString(strings.ToUpper(string(operationType)))
+}
+
 func orderOperationTypeToUint64(
 	operationType DAOCoinLimitOrderOperationTypeString,
 ) (lib.DAOCoinLimitOrderOperationType, error) {
-	if operationType == DAOCoinLimitOrderOperationTypeStringASK {
+	switch normalizeOrderOperationTypeString(operationType) {
+	case DAOCoinLimitOrderOperationTypeStringASK:
 		return lib.DAOCoinLimitOrderOperationTypeASK, nil
-	}
-	if operationType == DAOCoinLimitOrderOperationTypeStringBID {
+	case DAOCoinLimitOrderOperationTypeStringBID:
 		return lib.DAOCoinLimitOrderOperationTypeBID, nil
 	}
-	return 0, errors.Errorf("Unknown string value for DAOCoinLimitOrderOperationType %v", operationType)
+	return 0, errors.Errorf("Invalid DAOCoinLimitOrderOperationType %q: must be one of %v or %v",
+		operationType, DAOCoinLimitOrderOperationTypeStringASK, DAOCoinLimitOrderOperationTypeStringBID)
 }
 
 type DAOCoinLimitOrderFillTypeString string
@@ -748,10 +1563,16 @@ const (
 	DAOCoinLimitOrderFillTypeImmediateOrCancel DAOCoinLimitOrderFillTypeString = "IMMEDIATE_OR_CANCEL"
 )
 
+// normalizeOrderFillTypeString returns the canonical uppercase form of a fill type, so "fill_or_kill" is accepted as
+// FILL_OR_KILL.
+func normalizeOrderFillTypeString(fillType DAOCoinLimitOrderFillTypeString) DAOCoinLimitOrderFillTypeString {
+	return DAOCoinLimitOrderFillTypeString(strings.ToUpper(string(fillType)))
+}
+
 func orderFillTypeToUint64(
 	fillType DAOCoinLimitOrderFillTypeString,
 ) (lib.DAOCoinLimitOrderFillType, error) {
-	switch fillType {
+	switch normalizeOrderFillTypeString(fillType) {
 	case DAOCoinLimitOrderFillTypeGoodTillCancelled:
 		return lib.DAOCoinLimitOrderFillTypeGoodTillCancelled, nil
 	case DAOCoinLimitOrderFillTypeFillOrKill:
@@ -759,7 +1580,65 @@ func orderFillTypeToUint64(
 	case DAOCoinLimitOrderFillTypeImmediateOrCancel:
 		return lib.DAOCoinLimitOrderFillTypeImmediateOrCancel, nil
 	}
-	return 0, errors.Errorf("Unknown DAO coin limit order fill type %v", fillType)
+	return 0, errors.Errorf("Invalid DAOCoinLimitOrderFillType %q: must be one of %v, %v, or %v", fillType,
+		DAOCoinLimitOrderFillTypeGoodTillCancelled, DAOCoinLimitOrderFillTypeFillOrKill,
+		DAOCoinLimitOrderFillTypeImmediateOrCancel)
+}
+
+// DAOCoinMarketOrderParams holds the parsed parameters for a DAO coin market order
+type DAOCoinMarketOrderParams struct {
+	ScaledExchangeRateCoinsToSellPerCoinToBuy *uint256.Int
+	QuantityToFillInBaseUnits                 *uint256.Int
+	OperationType                             lib.DAOCoinLimitOrderOperationType
+	FillType                                  lib.DAOCoinLimitOrderFillType
+}
+
+// CalculateDAOCoinMarketOrderParams builds the parameters for an order that fills against whatever is resting on the
+// book for the coin pair, regardless of price. Core treats an IMMEDIATE_OR_CANCEL or FILL_OR_KILL order with a zero
+// exchange rate as a market order that accepts the best available prices, so that's the exchange rate returned here.
+// The fill type defaults to IMMEDIATE_OR_CANCEL if empty; GOOD_TILL_CANCELLED isn't allowed since a market order
+// can't rest on the book.
+func CalculateDAOCoinMarketOrderParams(
+	buyingCoinPublicKeyBase58Check string,
+	sellingCoinPublicKeyBase58Check string,
+	quantity string,
+	operationTypeString DAOCoinLimitOrderOperationTypeString,
+	fillTypeString DAOCoinLimitOrderFillTypeString,
+) (*DAOCoinMarketOrderParams, error) {
+	operationTypeString = normalizeOrderOperationTypeString(operationTypeString)
+	operationType, err := orderOperationTypeToUint64(operationTypeString)
+	if err != nil {
+		return nil, err
+	}
+
+	if fillTypeString == "" {
+		fillTypeString = DAOCoinLimitOrderFillTypeImmediateOrCancel
+	}
+	fillTypeString = normalizeOrderFillTypeString(fillTypeString)
+	fillType, err := orderFillTypeToUint64(fillTypeString)
+	if err != nil {
+		return nil, err
+	}
+	if fillType == lib.DAOCoinLimitOrderFillTypeGoodTillCancelled {
+		return nil, errors.Errorf("%v fill type not supported for market orders", fillTypeString)
+	}
+
+	quantityToFillInBaseUnits, err := CalculateQuantityToFillAsBaseUnits(
+		buyingCoinPublicKeyBase58Check,
+		sellingCoinPublicKeyBase58Check,
+		operationTypeString,
+		quantity,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DAOCoinMarketOrderParams{
+		ScaledExchangeRateCoinsToSellPerCoinToBuy: uint256.NewInt(),
+		QuantityToFillInBaseUnits:                 quantityToFillInBaseUnits,
+		OperationType:                             operationType,
+		FillType:                                  fillType,
+	}, nil
 }
 
 // returns (1e18 / 1e9), which represents the difference in scaling factor for DAO coin base units and $DESO nanos
@@ -797,6 +1676,9 @@ func calculateScaledUint256AsFloat(v *big.Int, scalingFactor *big.Int) (float64,
 //
 // The range of supported values for f is [1e-15, 1e308] with precision for the 15 most significant digits. The
 // minimum value for this range artificially set to 1e-15, but can be extended all the way 1e-308 with a bit better math
+//
+// Callers that start with a decimal string should pass it through directly (ex: to
+// CalculateScaledExchangeRateFromPriceString) rather than round-tripping it through a float64 and this function.
 func formatFloatAsString(f float64) string {
 	fAsBigInt, _ := big.NewFloat(0).SetFloat64(f).Int(nil)
 	supportedPrecisionDigits := 15
@@ -854,7 +1736,7 @@ func (fes *APIServer) validateTransactorSellingCoinBalance(
 
 	// If buying $DESO, the buying PKID is the ZeroPKID. Else it's the DAO coin's PKID.
 	buyingCoinPKID := &lib.ZeroPKID
-	if buyingDAOCoinCreatorPublicKeyBase58Check != DESOCoinIdentifierString {
+	if !isDESOCoinIdentifier(buyingDAOCoinCreatorPublicKeyBase58Check) {
 		buyingCoinPKID, err = fes.getPKIDFromPublicKeyBase58Check(
 			utxoView, buyingDAOCoinCreatorPublicKeyBase58Check)
 		if err != nil {
@@ -868,7 +1750,7 @@ func (fes *APIServer) validateTransactorSellingCoinBalance(
 
 	// Calculate current balance for transactor.
 	transactorSellingBalanceBaseUnits := uint256.NewInt()
-	if sellingDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(sellingDAOCoinCreatorPublicKeyBase58Check) {
 		// Get $DESO balance nanos.
 		desoBalanceNanos, err := utxoView.GetDeSoBalanceNanosForPublicKey(transactorPublicKey)
 		if err != nil {
@@ -947,7 +1829,7 @@ func (fes *APIServer) validateDAOCoinOrderTransferRestriction(
 
 	// If buying $DESO, this never has a transfer restriction. We validate
 	// that you own sufficient of your selling coin elsewhere.
-	if buyingDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(buyingDAOCoinCreatorPublicKeyBase58Check) {
 		return nil
 	}
 
@@ -1018,7 +1900,7 @@ func (fes *APIServer) getDAOCoinLimitOrderSimulatedExecutionResult(
 	if err != nil {
 		return nil, err
 	}
-	if buyingDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(buyingDAOCoinCreatorPublicKeyBase58Check) {
 		// If the buying coin is DESO, then the ending balance change will have the transaction fee subtracted. In order to
 		// isolate the amount of the buying coin bought as a part of this order, we need to add back the transaction fee
 		buyingCoinEndingBalance.Add(buyingCoinEndingBalance, uint256.NewInt().SetUint64(txnFees))
@@ -1028,7 +1910,7 @@ func (fes *APIServer) getDAOCoinLimitOrderSimulatedExecutionResult(
 	if err != nil {
 		return nil, err
 	}
-	if sellingDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(sellingDAOCoinCreatorPublicKeyBase58Check) {
 		// If the selling coin is DESO, then the ending balance will have the network fee subtracted. In order to isolate
 		// the amount of the selling coin sold as a part of this order, we need to add back the transaction fee to the
 		// ending balance
@@ -1074,7 +1956,7 @@ func (fes *APIServer) getTransactorDesoOrDaoCoinBalance(
 		return nil, errors.Errorf("Error decoding transactor public key: %v", err)
 	}
 
-	if desoOrDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(desoOrDAOCoinCreatorPublicKeyBase58Check) {
 		// Get $DESO balance nanos.
 		desoBalanceNanos, err := utxoView.GetDeSoBalanceNanosForPublicKey(transactorPublicKey)
 		if err != nil {
@@ -1097,8 +1979,2430 @@ func (fes *APIServer) getTransactorDesoOrDaoCoinBalance(
 }
 
 func getScalingFactorForCoin(coinCreatorPublicKeyBase58Check string) *uint256.Int {
-	if coinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(coinCreatorPublicKeyBase58Check) {
 		return uint256.NewInt().SetUint64(lib.NanosPerUnit)
 	}
 	return uint256.NewInt().Set(lib.BaseUnitsPerCoin)
 }
+
+type GetEffectiveDAOCoinPriceRequest struct {
+	// The public key of the user who would be sending the order
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// The public key of the DAO coin being bought
+	BuyingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// The public key of the DAO coin being sold
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) that represents the quantity of coins being bought or sold. If operation type is BID,
+	// then this quantity refers to the coin being bought. If operation type is ASK, then it refers to the coin being sold
+	Quantity string `safeForLogging:"true"`
+
+	OperationType DAOCoinLimitOrderOperationTypeString `safeForLogging:"true"`
+
+	MinFeeRateNanosPerKB uint64           `safeForLogging:"true"`
+	TransactionFees      []TransactionFee `safeForLogging:"true"`
+}
+
+type GetEffectiveDAOCoinPriceResponse struct {
+	// Decimal strings (ex: 1.23) for the quantities of each coin that would be exchanged, excluding fees
+	BuyingCoinQuantityFilled  string
+	SellingCoinQuantityFilled string
+
+	// A decimal string (ex: 1.23) for the average price at which the order fills against the book, excluding fees. If
+	// operation type is BID, then the denominator is the coin being bought. If operation type is ASK, then the
+	// denominator is the coin being sold
+	Price string
+
+	// Same as Price, but with all $DESO fees folded into the $DESO side of the trade. For DAO coin <> DAO coin trades,
+	// fees cannot be expressed in either coin, so this is the same as Price and users should refer to TotalFeeNanos
+	EffectivePrice string
+
+	NetworkFeeNanos uint64
+	NodeFeeNanos    uint64
+	TotalFeeNanos   uint64
+}
+
+// GetEffectiveDAOCoinPrice simulates a market order for the given coin pair and quantity against the current book,
+// and returns the average fill price both with and without the transaction fees the transactor would pay
+func (fes *APIServer) GetEffectiveDAOCoinPrice(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetEffectiveDAOCoinPriceRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.TransactorPublicKeyBase58Check == "" {
+		_AddBadRequestError(ww, "GetEffectiveDAOCoinPrice: must provide a TransactorPublicKeyBase58Check")
+		return
+	}
+
+	requestData.OperationType = normalizeOrderOperationTypeString(requestData.OperationType)
+	operationType, err := orderOperationTypeToUint64(requestData.OperationType)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+
+	quantityToFillInBaseUnits, err := CalculateQuantityToFillAsBaseUnits(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.OperationType,
+		requestData.Quantity,
+	)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+
+	buyingCoinPublicKey, sellingCoinPublicKey, err := fes.getBuyingAndSellingDAOCoinPublicKeys(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+	)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	transactorPublicKey, _, err := lib.Base58CheckDecode(requestData.TransactorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: Error decoding transactor public key: %v", err))
+		return
+	}
+
+	buyingCoinStartingBalance, err := fes.getTransactorDesoOrDaoCoinBalance(
+		utxoView, requestData.TransactorPublicKeyBase58Check, requestData.BuyingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+	buyingCoinStartingBalance = uint256.NewInt().Set(buyingCoinStartingBalance)
+	sellingCoinStartingBalance, err := fes.getTransactorDesoOrDaoCoinBalance(
+		utxoView, requestData.TransactorPublicKeyBase58Check, requestData.SellingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+	sellingCoinStartingBalance = uint256.NewInt().Set(sellingCoinStartingBalance)
+
+	// Construct the same transaction a market order would produce, so the node-level fees and network fee match
+	// what the transactor would actually pay
+	orderRes, err := fes.createDAOCoinLimitOrderResponse(
+		utxoView,
+		requestData.TransactorPublicKeyBase58Check,
+		buyingCoinPublicKey,
+		sellingCoinPublicKey,
+		uint256.NewInt().SetUint64(0),
+		quantityToFillInBaseUnits,
+		operationType,
+		lib.DAOCoinLimitOrderFillTypeImmediateOrCancel,
+		nil,
+		requestData.MinFeeRateNanosPerKB,
+		requestData.TransactionFees,
+	)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+
+	// Node-level fees and any fees specified in the request are paid as outputs to public keys other than the transactor
+	nodeFeeNanos := uint64(0)
+	for _, output := range orderRes.Transaction.TxOutputs {
+		if !bytes.Equal(output.PublicKey, transactorPublicKey) {
+			nodeFeeNanos += output.AmountNanos
+		}
+	}
+
+	networkFeeNanos, err := fes.simulateSubmitTransaction(utxoView, orderRes.Transaction)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: Problem simulating order: %v", err))
+		return
+	}
+	totalFeeNanos := networkFeeNanos + nodeFeeNanos
+
+	buyingCoinEndingBalance, err := fes.getTransactorDesoOrDaoCoinBalance(
+		utxoView, requestData.TransactorPublicKeyBase58Check, requestData.BuyingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+	sellingCoinEndingBalance, err := fes.getTransactorDesoOrDaoCoinBalance(
+		utxoView, requestData.TransactorPublicKeyBase58Check, requestData.SellingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+
+	// The all-in quantities are the actual balance changes. For the $DESO side of the trade, this includes fees.
+	effectiveBuyingQuantity, err := lib.SafeUint256().Sub(buyingCoinEndingBalance, buyingCoinStartingBalance)
+	if err != nil {
+		_AddBadRequestError(ww, "GetEffectiveDAOCoinPrice: Fees exceed the quantity of the buying coin that would be filled")
+		return
+	}
+	effectiveSellingQuantity, err := lib.SafeUint256().Sub(sellingCoinStartingBalance, sellingCoinEndingBalance)
+	if err != nil {
+		_AddInternalServerError(ww, "GetEffectiveDAOCoinPrice: Selling coin balance cannot increase as a result of a DAO coin limit order execution")
+		return
+	}
+
+	// The nominal quantities exclude fees, so we back them out of the $DESO side of the trade.
+	totalFeeNanosUint256 := uint256.NewInt().SetUint64(totalFeeNanos)
+	buyingQuantity := uint256.NewInt().Set(effectiveBuyingQuantity)
+	sellingQuantity := uint256.NewInt().Set(effectiveSellingQuantity)
+	if isDESOCoinIdentifier(requestData.BuyingDAOCoinCreatorPublicKeyBase58Check) {
+		buyingQuantity.Add(buyingQuantity, totalFeeNanosUint256)
+	} else if isDESOCoinIdentifier(requestData.SellingDAOCoinCreatorPublicKeyBase58Check) {
+		if sellingQuantity.Lt(totalFeeNanosUint256) {
+			_AddInternalServerError(ww, "GetEffectiveDAOCoinPrice: Selling coin quantity filled is less than fees")
+			return
+		}
+		sellingQuantity.Sub(sellingQuantity, totalFeeNanosUint256)
+	}
+
+	if buyingQuantity.IsZero() || sellingQuantity.IsZero() {
+		_AddBadRequestError(ww, "GetEffectiveDAOCoinPrice: There are no orders on the book that can fill this order")
+		return
+	}
+
+	price, err := calculateDAOCoinPriceStringFromQuantities(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		buyingQuantity,
+		sellingQuantity,
+		requestData.OperationType,
+	)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+	effectivePrice, err := calculateDAOCoinPriceStringFromQuantities(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		effectiveBuyingQuantity,
+		effectiveSellingQuantity,
+		requestData.OperationType,
+	)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: %v", err))
+		return
+	}
+
+	res := GetEffectiveDAOCoinPriceResponse{
+		BuyingCoinQuantityFilled:  CalculateDisplayUnitsFromBaseUnits(requestData.BuyingDAOCoinCreatorPublicKeyBase58Check, buyingQuantity),
+		SellingCoinQuantityFilled: CalculateDisplayUnitsFromBaseUnits(requestData.SellingDAOCoinCreatorPublicKeyBase58Check, sellingQuantity),
+		Price:                     price,
+		EffectivePrice:            effectivePrice,
+		NetworkFeeNanos:           networkFeeNanos,
+		NodeFeeNanos:              nodeFeeNanos,
+		TotalFeeNanos:             totalFeeNanos,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetEffectiveDAOCoinPrice: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// calculateDAOCoinPriceStringFromQuantities given the base unit quantities of the buying and selling coins exchanged,
+// this calculates the average price as a decimal string. If operation type = BID, then price is the number of selling
+// coins per buying coin. If operation type = ASK, then price is the number of buying coins per selling coin
+func calculateDAOCoinPriceStringFromQuantities(
+	buyingCoinPublicKeyBase58Check string,
+	sellingCoinPublicKeyBase58Check string,
+	buyingQuantityInBaseUnits *uint256.Int,
+	sellingQuantityInBaseUnits *uint256.Int,
+	operationTypeString DAOCoinLimitOrderOperationTypeString,
+) (string, error) {
+	numeratorCoin, numeratorQuantity := sellingCoinPublicKeyBase58Check, sellingQuantityInBaseUnits
+	denominatorCoin, denominatorQuantity := buyingCoinPublicKeyBase58Check, buyingQuantityInBaseUnits
+	if operationTypeString == DAOCoinLimitOrderOperationTypeStringASK {
+		numeratorCoin, numeratorQuantity = buyingCoinPublicKeyBase58Check, buyingQuantityInBaseUnits
+		denominatorCoin, denominatorQuantity = sellingCoinPublicKeyBase58Check, sellingQuantityInBaseUnits
+	}
+	if denominatorQuantity.IsZero() {
+		return "", errors.Errorf("Cannot calculate a price from a zero quantity")
+	}
+
+	// price = (numeratorQuantity / numeratorScalingFactor) / (denominatorQuantity / denominatorScalingFactor), which
+	// we scale up by 1e38 so that it can be formatted as a decimal string
+	numerator := big.NewInt(0).Mul(numeratorQuantity.ToBig(), getScalingFactorForCoin(denominatorCoin).ToBig())
+	numerator.Mul(numerator, lib.OneE38.ToBig())
+	denominator := big.NewInt(0).Mul(denominatorQuantity.ToBig(), getScalingFactorForCoin(numeratorCoin).ToBig())
+
+	return lib.FormatScaledUint256AsDecimalString(big.NewInt(0).Div(numerator, denominator), lib.OneE38.ToBig()), nil
+}
+
+// MaxDAOCoinArbitrageCycleLength is the maximum number of coins that can be included in a GetDAOCoinArbitrageOpportunity
+// request. Each coin adds a leg, and each leg requires pulling one side of that pair's book.
+const MaxDAOCoinArbitrageCycleLength = 10
+
+// estimatedDAOCoinLimitOrderTxnSizeBytes is a conservative estimate of the size of a signed DAO coin limit order
+// transaction that fills against a handful of orders. It's used to estimate network fees without constructing
+// the transaction.
+const estimatedDAOCoinLimitOrderTxnSizeBytes = 1000
+
+type GetDAOCoinArbitrageOpportunityRequest struct {
+	// The coins that make up the cycle, in the order they are traded. Each entry is either DESOCoinIdentifierString or
+	// the public key of a DAO coin's creator. The first coin must be DESOCoinIdentifierString, and the cycle implicitly
+	// closes by trading the last coin back into $DESO. Ex: ["DESO", CoinA, CoinB] evaluates DESO→CoinA→CoinB→DESO.
+	Coins []string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) for the quantity of $DESO that starts the cycle
+	StartingQuantity string `safeForLogging:"true"`
+
+	// Optional. If provided, node-level fees are omitted when this public key is exempt from them.
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	MinFeeRateNanosPerKB uint64 `safeForLogging:"true"`
+}
+
+type DAOCoinArbitrageLegResponse struct {
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	BuyingDAOCoinCreatorPublicKeyBase58Check  string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) for the best available price on the book, as the number of buying coins received
+	// per selling coin sold. This is "0" if there are no orders on the book to fill this leg.
+	Price string
+
+	// A decimal string for the product of the prices of this leg and all of the legs before it
+	CumulativeMultiplier string
+
+	// A decimal string for the quantity of the buying coin this leg would produce at Price, excluding fees
+	BuyingCoinQuantity string
+
+	EstimatedFeeNanos uint64
+}
+
+type GetDAOCoinArbitrageOpportunityResponse struct {
+	Legs []DAOCoinArbitrageLegResponse
+
+	// A decimal string for the product of the prices of all legs. A value greater than 1 means the cycle returns
+	// more $DESO than it started with, before fees.
+	NetMultiplier string
+
+	StartingQuantity         string
+	EndingQuantityBeforeFees string
+
+	TotalEstimatedFeeNanos uint64
+	// The $DESO that the cycle would return in excess of StartingQuantity, after fees. Negative if the cycle loses
+	// $DESO.
+	NetProfitNanos int64
+	IsProfitable   bool
+}
+
+// GetDAOCoinArbitrageOpportunity evaluates a cycle of coin trades that starts and ends in $DESO using the best
+// available price on the book for each leg, and returns whether the cycle would be profitable after estimated fees.
+// Because it only considers the top of each book, the result is an upper bound on what a trader would actually
+// receive for quantities larger than the best order on any leg.
+func (fes *APIServer) GetDAOCoinArbitrageOpportunity(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinArbitrageOpportunityRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.Coins) < 2 || len(requestData.Coins) > MaxDAOCoinArbitrageCycleLength {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinArbitrageOpportunity: Coins must contain between 2 and %d coins", MaxDAOCoinArbitrageCycleLength))
+		return
+	}
+	if !isDESOCoinIdentifier(requestData.Coins[0]) {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinArbitrageOpportunity: The first coin in the cycle must be %s", DESOCoinIdentifierString))
+		return
+	}
+
+	startingQuantityNanos, err := calculateQuantityToFillAsDESONanos(requestData.StartingQuantity)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Invalid StartingQuantity: %v", err))
+		return
+	}
+	if startingQuantityNanos.IsZero() || !startingQuantityNanos.IsUint64() ||
+		startingQuantityNanos.Uint64() > uint64(math.MaxInt64) {
+		_AddBadRequestError(ww, "GetDAOCoinArbitrageOpportunity: StartingQuantity must be greater than zero and fit in an int64 of nanos")
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	// Resolve every coin to a PKID up front so we fail fast on invalid input and duplicate coins.
+	coinPKIDs := make([]*lib.PKID, len(requestData.Coins))
+	seenCoins := make(map[lib.PKID]bool)
+	for ii, coin := range requestData.Coins {
+		coinPKIDs[ii], err = fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Invalid coin %s: %v", coin, err))
+			return
+		}
+		if seenCoins[*coinPKIDs[ii]] {
+			_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Coin %s is included more than once", coin))
+			return
+		}
+		seenCoins[*coinPKIDs[ii]] = true
+	}
+
+	// Every leg is a separate DAO coin limit order transaction, so every leg pays node-level fees and a network fee.
+	isTransactorExempt := false
+	if requestData.TransactorPublicKeyBase58Check != "" {
+		if _, err = GetPubKeyBytesFromBase58Check(requestData.TransactorPublicKeyBase58Check); err != nil {
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Invalid TransactorPublicKeyBase58Check: %v", err))
+			return
+		}
+		_, isTransactorExempt = fes.ExemptPublicKeyMap[requestData.TransactorPublicKeyBase58Check]
+	}
+	feeRateNanosPerKB := requestData.MinFeeRateNanosPerKB
+	if feeRateNanosPerKB == 0 {
+		feeRateNanosPerKB = fes.MinFeeRateNanosPerKB
+		if utxoView.GlobalParamsEntry != nil && utxoView.GlobalParamsEntry.MinimumNetworkFeeNanosPerKB > 0 {
+			feeRateNanosPerKB = utxoView.GlobalParamsEntry.MinimumNetworkFeeNanosPerKB
+		}
+	}
+	feeNanosPerLeg := feeRateNanosPerKB * estimatedDAOCoinLimitOrderTxnSizeBytes / 1000
+	if !isTransactorExempt {
+		for _, output := range fes.TransactionFeeMap[lib.TxnTypeDAOCoinLimitOrder] {
+			feeNanosPerLeg += output.AmountNanos
+		}
+	}
+
+	res := GetDAOCoinArbitrageOpportunityResponse{
+		Legs:             []DAOCoinArbitrageLegResponse{},
+		StartingQuantity: CalculateDisplayUnitsFromBaseUnits(DESOCoinIdentifierString, startingQuantityNanos),
+	}
+	cumulativeMultiplier := big.NewFloat(1)
+	quantityInBaseUnits := uint256.NewInt().Set(startingQuantityNanos)
+	for ii := range requestData.Coins {
+		// The last leg closes the cycle by trading back into the first coin.
+		nextIndex := (ii + 1) % len(requestData.Coins)
+		sellingCoin, buyingCoin := requestData.Coins[ii], requestData.Coins[nextIndex]
+
+		scaledExchangeRate, err := getBestScaledExchangeRateForSellingCoin(utxoView, coinPKIDs[nextIndex], coinPKIDs[ii])
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Error getting limit orders: %v", err))
+			return
+		}
+
+		price := "0"
+		if !scaledExchangeRate.IsZero() {
+			// The counterparty orders are buying the selling coin, so the counterparty's exchange rate is the number of
+			// coins received per selling coin.
+			price, err = CalculatePriceStringFromScaledExchangeRate(
+				sellingCoin, buyingCoin, scaledExchangeRate, DAOCoinLimitOrderOperationTypeStringBID)
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: %v", err))
+				return
+			}
+		}
+		priceAsFloat, ok := big.NewFloat(0).SetString(price)
+		if !ok {
+			_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Problem parsing price %s", price))
+			return
+		}
+		cumulativeMultiplier.Mul(cumulativeMultiplier, priceAsFloat)
+
+		// Convert the quantity from the previous leg using the counterparty's exchange rate, which is scaled by 1e38.
+		quantityAsBigInt := big.NewInt(0).Mul(quantityInBaseUnits.ToBig(), scaledExchangeRate.ToBig())
+		quantityAsBigInt.Div(quantityAsBigInt, lib.OneE38.ToBig())
+		var overflow bool
+		quantityInBaseUnits, overflow = uint256.FromBig(quantityAsBigInt)
+		if overflow {
+			_AddBadRequestError(ww, "GetDAOCoinArbitrageOpportunity: Overflow computing leg quantity")
+			return
+		}
+
+		res.Legs = append(res.Legs, DAOCoinArbitrageLegResponse{
+			SellingDAOCoinCreatorPublicKeyBase58Check: sellingCoin,
+			BuyingDAOCoinCreatorPublicKeyBase58Check:  buyingCoin,
+			Price:                                     price,
+			CumulativeMultiplier:                      cumulativeMultiplier.Text('f', 18),
+			BuyingCoinQuantity:                        CalculateDisplayUnitsFromBaseUnits(buyingCoin, quantityInBaseUnits),
+			EstimatedFeeNanos:                         feeNanosPerLeg,
+		})
+		res.TotalEstimatedFeeNanos += feeNanosPerLeg
+	}
+
+	res.NetMultiplier = cumulativeMultiplier.Text('f', 18)
+	res.EndingQuantityBeforeFees = CalculateDisplayUnitsFromBaseUnits(DESOCoinIdentifierString, quantityInBaseUnits)
+
+	// Net profit = ending quantity - starting quantity - fees, computed with big ints so that it may be negative
+	netProfitNanos := big.NewInt(0).Sub(quantityInBaseUnits.ToBig(), startingQuantityNanos.ToBig())
+	netProfitNanos.Sub(netProfitNanos, big.NewInt(0).SetUint64(res.TotalEstimatedFeeNanos))
+	if !netProfitNanos.IsInt64() {
+		_AddBadRequestError(ww, "GetDAOCoinArbitrageOpportunity: Net profit does not fit in an int64 of nanos")
+		return
+	}
+	res.NetProfitNanos = netProfitNanos.Int64()
+	res.IsProfitable = res.NetProfitNanos > 0
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinArbitrageOpportunity: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// getBestScaledExchangeRateForSellingCoin returns the best scaled exchange rate available to a transactor selling
+// sellingCoinPKID for buyingCoinPKID, as the number of buying coin base units received per selling coin base unit
+// scaled by 1e38. The counterparties for such an order are orders buying the selling coin with the buying coin, and
+// the best of those is the one offering the most buying coins per selling coin. Returns zero if the book is empty.
+func getBestScaledExchangeRateForSellingCoin(
+	utxoView *lib.UtxoView,
+	buyingCoinPKID *lib.PKID,
+	sellingCoinPKID *lib.PKID,
+) (*uint256.Int, error) {
+	orders, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(sellingCoinPKID, buyingCoinPKID)
+	if err != nil {
+		return nil, err
+	}
+	return getBestScaledExchangeRateForOrders(orders), nil
+}
+
+type GetDAOCoinFillPreviewRequest struct {
+	// Optional. If provided, fills against this transactor's own orders are flagged, since the order would be
+	// rejected if it matched against them.
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Either DESOCoinIdentifierString or the public key of the DAO coin's creator
+	BuyingDAOCoinCreatorPublicKeyBase58Check  string `safeForLogging:"true"`
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) that represents the quantity of coins being bought or sold. If operation type is BID,
+	// then this quantity refers to the coin being bought. If operation type is ASK, then it refers to the coin being sold
+	Quantity string `safeForLogging:"true"`
+
+	OperationType DAOCoinLimitOrderOperationTypeString `safeForLogging:"true"`
+}
+
+type DAOCoinFillPreviewOrderResponse struct {
+	// The resting order that would be matched
+	Order DAOCoinLimitOrderEntryResponse
+
+	// Decimal strings (ex: 1.23) for the quantities of each coin exchanged with this order, from the perspective of the
+	// market order being previewed
+	BuyingCoinQuantityFilled  string
+	SellingCoinQuantityFilled string
+
+	// A decimal string for the price of this fill, in the same terms as the market order's operation type
+	Price string
+
+	// True if this fill consumes the rest of the resting order
+	IsOrderFullyConsumed bool
+	// True if the resting order belongs to TransactorPublicKeyBase58Check
+	IsTransactorOrder bool
+}
+
+type GetDAOCoinFillPreviewResponse struct {
+	// The resting orders that would be matched, in the order they would be matched
+	Fills []DAOCoinFillPreviewOrderResponse
+
+	BuyingCoinQuantityFilled  string
+	SellingCoinQuantityFilled string
+
+	// A decimal string for the average price across all fills. If operation type is BID, then the denominator is the
+	// coin being bought. If operation type is ASK, then the denominator is the coin being sold. Empty if nothing fills.
+	AveragePrice string
+
+	// True if the book has enough liquidity to fill the entire quantity
+	IsFullyFilled bool
+}
+
+// GetDAOCoinFillPreview walks the book for a market order and returns each resting order it would match against,
+// in matching order, along with the quantities consumed from each. This mirrors the matching order used by the core
+// protocol, but doesn't simulate the transaction, so rounding on each fill may differ slightly from what the
+// protocol produces.
+func (fes *APIServer) GetDAOCoinFillPreview(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinFillPreviewRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinFillPreview: Problem parsing request body: %v", err))
+		return
+	}
+
+	requestData.OperationType = normalizeOrderOperationTypeString(requestData.OperationType)
+	if _, err := orderOperationTypeToUint64(requestData.OperationType); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinFillPreview: %v", err))
+		return
+	}
+	if _, _, err := fes.getBuyingAndSellingDAOCoinPublicKeys(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+	); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinFillPreview: %v", err))
+		return
+	}
+
+	quantityToFillInBaseUnits, err := CalculateQuantityToFillAsBaseUnits(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.OperationType,
+		requestData.Quantity,
+	)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinFillPreview: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinFillPreview: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	buyingCoinPKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(
+		utxoView, requestData.BuyingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinFillPreview: Invalid BuyingDAOCoinCreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	sellingCoinPKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(
+		utxoView, requestData.SellingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinFillPreview: Invalid SellingDAOCoinCreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	var transactorPKID *lib.PKID
+	if requestData.TransactorPublicKeyBase58Check != "" {
+		transactorPKID, err = fes.getPKIDFromPublicKeyBase58Check(utxoView, requestData.TransactorPublicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinFillPreview: Invalid TransactorPublicKeyBase58Check: %v", err))
+			return
+		}
+	}
+
+	// The resting orders that can fill this order are the ones buying the coin this order sells, and selling the
+	// coin this order buys. The protocol matches them best price first, then oldest first.
+	matchingOrders, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(sellingCoinPKID, buyingCoinPKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinFillPreview: Error getting limit orders: %v", err))
+		return
+	}
+	sort.Slice(matchingOrders, func(ii, jj int) bool {
+		return matchingOrders[ii].IsBetterMatchingOrderThan(matchingOrders[jj])
+	})
+
+	res := GetDAOCoinFillPreviewResponse{
+		Fills: []DAOCoinFillPreviewOrderResponse{},
+	}
+	remainingQuantity := uint256.NewInt().Set(quantityToFillInBaseUnits)
+	totalBuyingQuantity := uint256.NewInt()
+	totalSellingQuantity := uint256.NewInt()
+	for _, matchingOrder := range matchingOrders {
+		if remainingQuantity.IsZero() {
+			break
+		}
+
+		buyingQuantity, sellingQuantity, isOrderFullyConsumed, err := calculateDAOCoinQuantitiesFilledByMatchingOrder(
+			matchingOrder, requestData.OperationType, remainingQuantity)
+		if err != nil {
+			logRequestErrorf(req.Context(), "GetDAOCoinFillPreview: Skipping limit order with OrderID %v: %v", matchingOrder.OrderID, err)
+			continue
+		}
+
+		orderResponse, err := buildDAOCoinLimitOrderResponse(
+			fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, matchingOrder.TransactorPKID),
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			matchingOrder,
+		)
+		if err != nil {
+			logRequestErrorf(req.Context(), "GetDAOCoinFillPreview: Skipping limit order with OrderID %v: %v", matchingOrder.OrderID, err)
+			continue
+		}
+
+		fillPrice := ""
+		if !buyingQuantity.IsZero() && !sellingQuantity.IsZero() {
+			fillPrice, err = calculateDAOCoinPriceStringFromQuantities(
+				requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+				requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+				buyingQuantity,
+				sellingQuantity,
+				requestData.OperationType,
+			)
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinFillPreview: %v", err))
+				return
+			}
+		}
+
+		res.Fills = append(res.Fills, DAOCoinFillPreviewOrderResponse{
+			Order: *orderResponse,
+			BuyingCoinQuantityFilled: CalculateDisplayUnitsFromBaseUnits(
+				requestData.BuyingDAOCoinCreatorPublicKeyBase58Check, buyingQuantity),
+			SellingCoinQuantityFilled: CalculateDisplayUnitsFromBaseUnits(
+				requestData.SellingDAOCoinCreatorPublicKeyBase58Check, sellingQuantity),
+			Price:                fillPrice,
+			IsOrderFullyConsumed: isOrderFullyConsumed,
+			IsTransactorOrder:    transactorPKID != nil && transactorPKID.Eq(matchingOrder.TransactorPKID),
+		})
+		totalBuyingQuantity.Add(totalBuyingQuantity, buyingQuantity)
+		totalSellingQuantity.Add(totalSellingQuantity, sellingQuantity)
+	}
+
+	res.BuyingCoinQuantityFilled = CalculateDisplayUnitsFromBaseUnits(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check, totalBuyingQuantity)
+	res.SellingCoinQuantityFilled = CalculateDisplayUnitsFromBaseUnits(
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check, totalSellingQuantity)
+	res.IsFullyFilled = remainingQuantity.IsZero()
+	if !totalBuyingQuantity.IsZero() && !totalSellingQuantity.IsZero() {
+		res.AveragePrice, err = calculateDAOCoinPriceStringFromQuantities(
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			totalBuyingQuantity,
+			totalSellingQuantity,
+			requestData.OperationType,
+		)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinFillPreview: %v", err))
+			return
+		}
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinFillPreview: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// calculateDAOCoinQuantitiesFilledByMatchingOrder returns the base units of the buying and selling coins exchanged
+// when an order with the given operation type and remaining quantity to fill matches against a resting order, along
+// with whether the resting order is fully consumed. The remaining quantity is decremented in place by the quantity
+// filled, in the same coin it's denominated in.
+func calculateDAOCoinQuantitiesFilledByMatchingOrder(
+	matchingOrder *lib.DAOCoinLimitOrderEntry,
+	operationTypeString DAOCoinLimitOrderOperationTypeString,
+	remainingQuantity *uint256.Int,
+) (_buyingQuantity *uint256.Int, _sellingQuantity *uint256.Int, _isOrderFullyConsumed bool, _err error) {
+	// From the resting order's perspective, it sells the coin this order buys, and buys the coin this order sells.
+	var buyingQuantity, sellingQuantity *uint256.Int
+	isOrderFullyConsumed := false
+	if operationTypeString == DAOCoinLimitOrderOperationTypeStringBID {
+		// The remaining quantity is denominated in the buying coin
+		orderSellingBaseUnits, err := matchingOrder.BaseUnitsToSellUint256()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		buyingQuantity = uint256.NewInt().Set(remainingQuantity)
+		if !orderSellingBaseUnits.Gt(remainingQuantity) {
+			buyingQuantity = orderSellingBaseUnits
+			isOrderFullyConsumed = true
+		}
+		sellingQuantity, err = lib.ComputeBaseUnitsToBuyUint256(
+			matchingOrder.ScaledExchangeRateCoinsToSellPerCoinToBuy, buyingQuantity)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		remainingQuantity.Sub(remainingQuantity, buyingQuantity)
+	} else {
+		// The remaining quantity is denominated in the selling coin
+		orderBuyingBaseUnits, err := matchingOrder.BaseUnitsToBuyUint256()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		sellingQuantity = uint256.NewInt().Set(remainingQuantity)
+		if !orderBuyingBaseUnits.Gt(remainingQuantity) {
+			sellingQuantity = orderBuyingBaseUnits
+			isOrderFullyConsumed = true
+		}
+		buyingQuantity, err = lib.ComputeBaseUnitsToSellUint256(
+			matchingOrder.ScaledExchangeRateCoinsToSellPerCoinToBuy, sellingQuantity)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		remainingQuantity.Sub(remainingQuantity, sellingQuantity)
+	}
+	return buyingQuantity, sellingQuantity, isOrderFullyConsumed, nil
+}
+
+type GetDAOCoinMarketsForCreatorRequest struct {
+	// Either the public key or the username of the DAO coin's creator must be provided.
+	CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	CreatorUsername             string `safeForLogging:"true"`
+}
+
+type DAOCoinMarketResponse struct {
+	// The coin the creator's DAO coin trades against. Either a DAO coin creator's public key or DESO.
+	CounterpartCoinPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Orders buying the creator's coin with the counterpart coin, and orders selling it for the counterpart coin.
+	NumBidOrders int `safeForLogging:"true"`
+	NumAskOrders int `safeForLogging:"true"`
+
+	// Decimal strings priced in counterpart coins per creator coin. Empty if that side of the book has no orders.
+	BestBidPrice string `safeForLogging:"true"`
+	BestAskPrice string `safeForLogging:"true"`
+}
+
+type GetDAOCoinMarketsForCreatorResponse struct {
+	CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Sorted by total number of open orders, descending.
+	Markets []DAOCoinMarketResponse `safeForLogging:"true"`
+}
+
+// GetDAOCoinMarketsForCreator returns every coin that a creator's DAO coin has open orders against, on either side
+// of the book, along with the top of book for each market.
+func (fes *APIServer) GetDAOCoinMarketsForCreator(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinMarketsForCreatorRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem parsing request body: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	var creatorPublicKeyBytes []byte
+	if requestData.CreatorPublicKeyBase58Check != "" {
+		creatorPublicKeyBytes, err = GetPubKeyBytesFromBase58Check(requestData.CreatorPublicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinMarketsForCreator: Invalid CreatorPublicKeyBase58Check: %v", err))
+			return
+		}
+	} else if requestData.CreatorUsername != "" {
+		profileEntry := utxoView.GetProfileEntryForUsername([]byte(requestData.CreatorUsername))
+		if profileEntry == nil {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"GetDAOCoinMarketsForCreator: No profile found for username %s", requestData.CreatorUsername))
+			return
+		}
+		creatorPublicKeyBytes = profileEntry.PublicKey
+	} else {
+		_AddBadRequestError(ww, "GetDAOCoinMarketsForCreator: Must provide a CreatorPublicKeyBase58Check or CreatorUsername")
+		return
+	}
+	creatorPKIDEntry := utxoView.GetPKIDForPublicKey(creatorPublicKeyBytes)
+	if creatorPKIDEntry == nil || creatorPKIDEntry.PKID == nil {
+		_AddBadRequestError(ww, "GetDAOCoinMarketsForCreator: No PKID found for creator")
+		return
+	}
+	creatorPKID := creatorPKIDEntry.PKID
+	creatorPublicKeyBase58Check := lib.PkToString(creatorPublicKeyBytes, fes.Params)
+
+	// There is no index by coin on either side of the book, so we scan the db for the counterpart coins, and include
+	// any counterparts that only appear in the mempool. The orders for each counterpart are then re-fetched through
+	// the view, which filters out orders that have since been cancelled or filled.
+	dbOrders, err := utxoView.GetDbAdapter().GetAllDAOCoinLimitOrders()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem fetching orders: %v", err))
+		return
+	}
+	counterpartPKIDs := make(map[lib.PKID]bool)
+	addCounterpart := func(order *lib.DAOCoinLimitOrderEntry) {
+		if order.BuyingDAOCoinCreatorPKID.Eq(creatorPKID) {
+			counterpartPKIDs[*order.SellingDAOCoinCreatorPKID] = true
+		} else if order.SellingDAOCoinCreatorPKID.Eq(creatorPKID) {
+			counterpartPKIDs[*order.BuyingDAOCoinCreatorPKID] = true
+		}
+	}
+	for _, order := range dbOrders {
+		addCounterpart(order)
+	}
+	for _, order := range utxoView.DAOCoinLimitOrderMapKeyToDAOCoinLimitOrderEntry {
+		addCounterpart(order)
+	}
+
+	markets := []DAOCoinMarketResponse{}
+	for counterpartPKIDIter := range counterpartPKIDs {
+		counterpartPKID := counterpartPKIDIter
+		counterpartPublicKeyBase58Check := fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, &counterpartPKID)
+
+		bidOrders, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(creatorPKID, &counterpartPKID)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem fetching bids: %v", err))
+			return
+		}
+		askOrders, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(&counterpartPKID, creatorPKID)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem fetching asks: %v", err))
+			return
+		}
+		if len(bidOrders) == 0 && len(askOrders) == 0 {
+			continue
+		}
+
+		market := DAOCoinMarketResponse{
+			CounterpartCoinPublicKeyBase58Check: counterpartPublicKeyBase58Check,
+			NumBidOrders:                        len(bidOrders),
+			NumAskOrders:                        len(askOrders),
+		}
+		// For both sides, the best order has the highest scaled exchange rate: bids pay the most counterpart coins
+		// per creator coin, and asks give the most creator coins per counterpart coin.
+		if bestBid := getBestScaledExchangeRateForOrders(bidOrders); !bestBid.IsZero() {
+			market.BestBidPrice, err = CalculatePriceStringFromScaledExchangeRate(
+				creatorPublicKeyBase58Check,
+				counterpartPublicKeyBase58Check,
+				bestBid,
+				DAOCoinLimitOrderOperationTypeStringBID,
+			)
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem calculating bid price: %v", err))
+				return
+			}
+		}
+		if bestAsk := getBestScaledExchangeRateForOrders(askOrders); !bestAsk.IsZero() {
+			market.BestAskPrice, err = CalculatePriceStringFromScaledExchangeRate(
+				counterpartPublicKeyBase58Check,
+				creatorPublicKeyBase58Check,
+				bestAsk,
+				DAOCoinLimitOrderOperationTypeStringASK,
+			)
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem calculating ask price: %v", err))
+				return
+			}
+		}
+		markets = append(markets, market)
+	}
+	sort.Slice(markets, func(ii, jj int) bool {
+		numOrdersII := markets[ii].NumBidOrders + markets[ii].NumAskOrders
+		numOrdersJJ := markets[jj].NumBidOrders + markets[jj].NumAskOrders
+		if numOrdersII != numOrdersJJ {
+			return numOrdersII > numOrdersJJ
+		}
+		return markets[ii].CounterpartCoinPublicKeyBase58Check < markets[jj].CounterpartCoinPublicKeyBase58Check
+	})
+
+	res := GetDAOCoinMarketsForCreatorResponse{
+		CreatorPublicKeyBase58Check: creatorPublicKeyBase58Check,
+		Markets:                     markets,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketsForCreator: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// getBestScaledExchangeRateForOrders returns the highest ScaledExchangeRateCoinsToSellPerCoinToBuy among orders, or
+// zero if there are none.
+func getBestScaledExchangeRateForOrders(orders []*lib.DAOCoinLimitOrderEntry) *uint256.Int {
+	bestExchangeRate := uint256.NewInt()
+	for _, order := range orders {
+		if order.ScaledExchangeRateCoinsToSellPerCoinToBuy.Gt(bestExchangeRate) {
+			bestExchangeRate = order.ScaledExchangeRateCoinsToSellPerCoinToBuy
+		}
+	}
+	return bestExchangeRate
+}
+
+// MaxDAOCoinLimitOrdersByIDs is the maximum number of order IDs that can be looked up in a single
+// GetDAOCoinLimitOrdersByIDs request.
+const MaxDAOCoinLimitOrdersByIDs = 100
+
+type GetDAOCoinLimitOrdersByIDsRequest struct {
+	// Hex encoded order IDs.
+	OrderIDs []string `safeForLogging:"true"`
+}
+
+type GetDAOCoinLimitOrdersByIDsResponse struct {
+	// The requested orders that are still open, with their remaining quantities.
+	Orders []DAOCoinLimitOrderEntryResponse `safeForLogging:"true"`
+	// The requested orders that are no longer on the book, because they were either filled or cancelled.
+	NotFoundOrderIDs []string `safeForLogging:"true"`
+}
+
+// GetDAOCoinLimitOrdersByIDs returns the current state of each of a list of orders, so clients tracking several
+// orders can poll them all at once.
+func (fes *APIServer) GetDAOCoinLimitOrdersByIDs(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinLimitOrdersByIDsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersByIDs: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.OrderIDs) == 0 {
+		_AddBadRequestError(ww, "GetDAOCoinLimitOrdersByIDs: Must provide at least one OrderID")
+		return
+	}
+	if len(requestData.OrderIDs) > MaxDAOCoinLimitOrdersByIDs {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinLimitOrdersByIDs: Cannot look up more than %d orders at once", MaxDAOCoinLimitOrdersByIDs))
+		return
+	}
+	orderIDs := make([]*lib.BlockHash, len(requestData.OrderIDs))
+	for ii, orderIDHex := range requestData.OrderIDs {
+		orderID, err := decodeBlockHashFromHex(orderIDHex)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersByIDs: Invalid OrderID %s: %v", orderIDHex, err))
+			return
+		}
+		orderIDs[ii] = orderID
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersByIDs: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	// There's no exported way to look up a single order with the mempool applied, so we find each order's
+	// transactor and then look the order up in the transactor's open orders, which filters out orders that were
+	// filled or cancelled in the mempool. Transactors' open orders are cached since a client's orders usually
+	// share a transactor.
+	openOrdersByTransactor := make(map[lib.PKID]map[lib.BlockHash]*lib.DAOCoinLimitOrderEntry)
+	res := GetDAOCoinLimitOrdersByIDsResponse{
+		Orders:           []DAOCoinLimitOrderEntryResponse{},
+		NotFoundOrderIDs: []string{},
+	}
+	for ii, orderID := range orderIDs {
+		order := utxoView.DAOCoinLimitOrderMapKeyToDAOCoinLimitOrderEntry[lib.DAOCoinLimitOrderMapKey{OrderID: *orderID}]
+		if order == nil {
+			order, err = utxoView.GetDbAdapter().GetDAOCoinLimitOrder(orderID)
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersByIDs: Problem fetching order: %v", err))
+				return
+			}
+		}
+		if order == nil {
+			res.NotFoundOrderIDs = append(res.NotFoundOrderIDs, requestData.OrderIDs[ii])
+			continue
+		}
+
+		openOrders, exists := openOrdersByTransactor[*order.TransactorPKID]
+		if !exists {
+			transactorOrders, err := utxoView.GetAllDAOCoinLimitOrdersForThisTransactor(order.TransactorPKID)
+			if err != nil {
+				_AddInternalServerError(ww, fmt.Sprintf(
+					"GetDAOCoinLimitOrdersByIDs: Problem fetching transactor's orders: %v", err))
+				return
+			}
+			openOrders = make(map[lib.BlockHash]*lib.DAOCoinLimitOrderEntry)
+			for _, transactorOrder := range transactorOrders {
+				openOrders[*transactorOrder.OrderID] = transactorOrder
+			}
+			openOrdersByTransactor[*order.TransactorPKID] = openOrders
+		}
+		openOrder, isOpen := openOrders[*orderID]
+		if !isOpen {
+			res.NotFoundOrderIDs = append(res.NotFoundOrderIDs, requestData.OrderIDs[ii])
+			continue
+		}
+
+		orderResponse, err := buildDAOCoinLimitOrderResponse(
+			lib.Base58CheckEncode(utxoView.GetPublicKeyForPKID(openOrder.TransactorPKID), false, fes.Params),
+			fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, openOrder.BuyingDAOCoinCreatorPKID),
+			fes.getPublicKeyBase58CheckOrCoinIdentifierForPKID(utxoView, openOrder.SellingDAOCoinCreatorPKID),
+			openOrder,
+		)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"GetDAOCoinLimitOrdersByIDs: Problem building response for order %s: %v", requestData.OrderIDs[ii], err))
+			return
+		}
+		res.Orders = append(res.Orders, *orderResponse)
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrdersByIDs: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type GetDAOCoinLimitOrderBookRequest struct {
+	// Bids are orders buying DAOCoin1 with DAOCoin2 and asks are orders selling DAOCoin1 for DAOCoin2. Either coin
+	// can be "DESO", but not both.
+	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+}
+
+type DAOCoinLimitOrderBookLevel struct {
+	// A decimal string (ex: 1.23) for the number of DAOCoin2 coins per DAOCoin1 coin at this price level
+	Price string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) for the total quantity of DAOCoin1 coins resting at this price level
+	Quantity       string  `safeForLogging:"true"`
+	QuantityToFill float64 `safeForLogging:"true"`
+
+	NumOrders int `safeForLogging:"true"`
+}
+
+type GetDAOCoinLimitOrderBookResponse struct {
+	// Bids are sorted from highest to lowest price and asks from lowest to highest, so the best price on each side
+	// comes first.
+	Bids []DAOCoinLimitOrderBookLevel
+	Asks []DAOCoinLimitOrderBookLevel
+
+	// Decimal strings for the total quantity of DAOCoin1 coins on each side of the book
+	TotalBidQuantity string
+	TotalAskQuantity string
+}
+
+// GetDAOCoinLimitOrderBook returns the open orders for a coin pair grouped into price levels
+func (fes *APIServer) GetDAOCoinLimitOrderBook(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinLimitOrderBookRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Problem parsing request body: %v", err))
+		return
+	}
+
+	coin1 := requestData.DAOCoin1CreatorPublicKeyBase58Check
+	coin2 := requestData.DAOCoin2CreatorPublicKeyBase58Check
+	if normalizeCoinIdentifier(coin1) == normalizeCoinIdentifier(coin2) {
+		_AddBadRequestError(ww, "GetDAOCoinLimitOrderBook: Must provide two different coins "+
+			"for DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check")
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	coin1PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin1)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinLimitOrderBook: Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	coin2PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin2)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinLimitOrderBook: Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+
+	ordersBuyingCoin1, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(coin1PKID, coin2PKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Error getting limit orders: %v", err))
+		return
+	}
+	ordersSellingCoin1, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(coin2PKID, coin1PKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Error getting limit orders: %v", err))
+		return
+	}
+
+	bids, totalBidBaseUnits, err := buildDAOCoinLimitOrderBookLevels(coin1, coin2, ordersBuyingCoin1, true)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Problem building bids: %v", err))
+		return
+	}
+	asks, totalAskBaseUnits, err := buildDAOCoinLimitOrderBookLevels(coin1, coin2, ordersSellingCoin1, false)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Problem building asks: %v", err))
+		return
+	}
+
+	res := GetDAOCoinLimitOrderBookResponse{
+		Bids:             bids,
+		Asks:             asks,
+		TotalBidQuantity: CalculateDisplayUnitsFromBaseUnits(coin1, totalBidBaseUnits),
+		TotalAskQuantity: CalculateDisplayUnitsFromBaseUnits(coin1, totalAskBaseUnits),
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinLimitOrderBook: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// buildDAOCoinLimitOrderBookLevels groups orders on one side of the book by exchange rate, summing the quantity of
+// coin1 at each level. If isBid, the orders are buying coin1 with coin2, otherwise they are selling coin1 for coin2.
+// Orders are summed in coin1 base units regardless of their operation type, and each level is then converted to a
+// price and quantity using buildDAOCoinLimitOrderResponse. Returns the levels, best price first, and the total
+// quantity in coin1 base units.
+func buildDAOCoinLimitOrderBookLevels(
+	coin1PublicKeyBase58Check string,
+	coin2PublicKeyBase58Check string,
+	orders []*lib.DAOCoinLimitOrderEntry,
+	isBid bool,
+) (_levels []DAOCoinLimitOrderBookLevel, _totalBaseUnits *uint256.Int, _err error) {
+	type priceLevel struct {
+		scaledExchangeRate *uint256.Int
+		coin1BaseUnits     *uint256.Int
+		numOrders          int
+	}
+	levelsByExchangeRate := make(map[uint256.Int]*priceLevel)
+	totalBaseUnits := uint256.NewInt()
+	for _, order := range orders {
+		var coin1BaseUnits *uint256.Int
+		var err error
+		if isBid {
+			coin1BaseUnits, err = order.BaseUnitsToBuyUint256()
+		} else {
+			coin1BaseUnits, err = order.BaseUnitsToSellUint256()
+		}
+		if err != nil {
+			glog.Errorf("buildDAOCoinLimitOrderBookLevels: Skipping order %v: %v", order.OrderID, err)
+			continue
+		}
+
+		level, exists := levelsByExchangeRate[*order.ScaledExchangeRateCoinsToSellPerCoinToBuy]
+		if !exists {
+			level = &priceLevel{
+				scaledExchangeRate: order.ScaledExchangeRateCoinsToSellPerCoinToBuy,
+				coin1BaseUnits:     uint256.NewInt(),
+			}
+			levelsByExchangeRate[*order.ScaledExchangeRateCoinsToSellPerCoinToBuy] = level
+		}
+		if level.coin1BaseUnits.AddOverflow(level.coin1BaseUnits, coin1BaseUnits) ||
+			totalBaseUnits.AddOverflow(totalBaseUnits, coin1BaseUnits) {
+			return nil, nil, errors.Errorf("Overflow summing quantity for order %v", order.OrderID)
+		}
+		level.numOrders++
+	}
+
+	priceLevels := make([]*priceLevel, 0, len(levelsByExchangeRate))
+	for _, level := range levelsByExchangeRate {
+		priceLevels = append(priceLevels, level)
+	}
+	// Bid exchange rates are coin2 per coin1 and ask exchange rates are coin1 per coin2, so on both sides the best
+	// price has the highest exchange rate.
+	sort.Slice(priceLevels, func(ii, jj int) bool {
+		return priceLevels[ii].scaledExchangeRate.Gt(priceLevels[jj].scaledExchangeRate)
+	})
+
+	// Bids are expressed as BID orders buying coin1 and asks as ASK orders selling coin1, so that the quantity is
+	// always in coin1 and the price is always coin2 per coin1.
+	buyingCoin, sellingCoin := coin1PublicKeyBase58Check, coin2PublicKeyBase58Check
+	operationType := lib.DAOCoinLimitOrderOperationTypeBID
+	if !isBid {
+		buyingCoin, sellingCoin = coin2PublicKeyBase58Check, coin1PublicKeyBase58Check
+		operationType = lib.DAOCoinLimitOrderOperationTypeASK
+	}
+	levels := make([]DAOCoinLimitOrderBookLevel, 0, len(priceLevels))
+	for _, level := range priceLevels {
+		levelResponse, err := buildDAOCoinLimitOrderResponse("", buyingCoin, sellingCoin, &lib.DAOCoinLimitOrderEntry{
+			ScaledExchangeRateCoinsToSellPerCoinToBuy: level.scaledExchangeRate,
+			QuantityToFillInBaseUnits:                 level.coin1BaseUnits,
+			OperationType:                             operationType,
+			OrderID:                                   &lib.ZeroBlockHash,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		levels = append(levels, DAOCoinLimitOrderBookLevel{
+			Price:          levelResponse.Price,
+			Quantity:       levelResponse.Quantity,
+			QuantityToFill: levelResponse.QuantityToFill,
+			NumOrders:      level.numOrders,
+		})
+	}
+	return levels, totalBaseUnits, nil
+}
+
+type GetDAOCoinBestBidAskRequest struct {
+	// The best bid is the highest price at which someone is buying DAOCoin1 with DAOCoin2 and the best ask is the
+	// lowest price at which someone is selling DAOCoin1 for DAOCoin2. Either coin can be "DESO", but not both.
+	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+}
+
+type GetDAOCoinBestBidAskResponse struct {
+	// Decimal strings (ex: 1.23) for the number of DAOCoin2 coins per DAOCoin1 coin and the quantity of DAOCoin1
+	// coins available at that price. All fields for a side are empty if there are no orders on that side.
+	BestBidPrice    string `safeForLogging:"true"`
+	BestBidQuantity string `safeForLogging:"true"`
+	BestAskPrice    string `safeForLogging:"true"`
+	BestAskQuantity string `safeForLogging:"true"`
+}
+
+// GetDAOCoinBestBidAsk returns the top of the order book for a coin pair
+func (fes *APIServer) GetDAOCoinBestBidAsk(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinBestBidAskRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Problem parsing request body: %v", err))
+		return
+	}
+
+	coin1 := requestData.DAOCoin1CreatorPublicKeyBase58Check
+	coin2 := requestData.DAOCoin2CreatorPublicKeyBase58Check
+	if normalizeCoinIdentifier(coin1) == normalizeCoinIdentifier(coin2) {
+		_AddBadRequestError(ww, "GetDAOCoinBestBidAsk: Must provide two different coins "+
+			"for DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check")
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	coin1PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin1)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinBestBidAsk: Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	coin2PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin2)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinBestBidAsk: Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+
+	ordersBuyingCoin1, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(coin1PKID, coin2PKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Error getting limit orders: %v", err))
+		return
+	}
+	ordersSellingCoin1, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(coin2PKID, coin1PKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Error getting limit orders: %v", err))
+		return
+	}
+
+	// Orders at the same price are aggregated so that the quantity returned is everything available at the best
+	// price, not just the quantity of a single order.
+	bids, _, err := buildDAOCoinLimitOrderBookLevels(coin1, coin2, ordersBuyingCoin1, true)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Problem building bids: %v", err))
+		return
+	}
+	asks, _, err := buildDAOCoinLimitOrderBookLevels(coin1, coin2, ordersSellingCoin1, false)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Problem building asks: %v", err))
+		return
+	}
+
+	res := GetDAOCoinBestBidAskResponse{}
+	if len(bids) > 0 {
+		res.BestBidPrice = bids[0].Price
+		res.BestBidQuantity = bids[0].Quantity
+	}
+	if len(asks) > 0 {
+		res.BestAskPrice = asks[0].Price
+		res.BestAskQuantity = asks[0].Quantity
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinBestBidAsk: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type SimulateDAOCoinLimitOrderFillRequest struct {
+	// Either DESOCoinIdentifierString or the public key of the DAO coin's creator
+	BuyingDAOCoinCreatorPublicKeyBase58Check  string `safeForLogging:"true"`
+	SellingDAOCoinCreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) that represents the exchange rate between the two coins. If operation type is BID,
+	// then the denominator represents the coin being bought. If operation type is ASK, then the denominator represents
+	// the coin being sold. Optional; if empty, the order is simulated as a market order that accepts any price.
+	Price string `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) that represents the quantity of coins being bought or sold. If operation type is BID,
+	// then this quantity refers to the coin being bought. If operation type is ASK, then it refers to the coin being sold
+	Quantity string `safeForLogging:"true"`
+
+	OperationType DAOCoinLimitOrderOperationTypeString `safeForLogging:"true"`
+	FillType      DAOCoinLimitOrderFillTypeString      `safeForLogging:"true"`
+}
+
+type SimulateDAOCoinLimitOrderFillResponse struct {
+	// Decimal strings (ex: 1.23) for the quantities of each coin the order would exchange
+	BuyingCoinQuantityFilled  string `safeForLogging:"true"`
+	SellingCoinQuantityFilled string `safeForLogging:"true"`
+
+	// Decimal strings for how much of the requested quantity would be filled and how much would be left over, in the
+	// same coin as the requested quantity
+	FilledQuantity    string `safeForLogging:"true"`
+	RemainingQuantity string `safeForLogging:"true"`
+
+	// A decimal string for the weighted average exchange rate across all fills, in the same terms as Price. Empty if
+	// nothing fills.
+	AverageExchangeRate string `safeForLogging:"true"`
+
+	// True if the book has enough liquidity at an acceptable price to fill the entire quantity
+	IsFullyFilled bool `safeForLogging:"true"`
+	// True if the order is GOOD_TILL_CANCELLED and the remaining quantity would be left resting on the book
+	RemainingQuantityRestsOnBook bool `safeForLogging:"true"`
+	// True if the order is FILL_OR_KILL and would be rejected because it can't be fully filled. Nothing would be
+	// exchanged in that case, so the filled quantities are zero.
+	WouldBeRejected bool `safeForLogging:"true"`
+}
+
+// SimulateDAOCoinLimitOrderFill walks the resting orders for a proposed order, best price first, and returns how much
+// of it would fill and at what average exchange rate. Nothing is submitted. The per-fill math matches
+// GetDAOCoinFillPreview, but resting orders priced worse than the order's price are not matched.
+func (fes *APIServer) SimulateDAOCoinLimitOrderFill(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := SimulateDAOCoinLimitOrderFillRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: Problem parsing request body: %v", err))
+		return
+	}
+
+	requestData.OperationType = normalizeOrderOperationTypeString(requestData.OperationType)
+	operationType, err := orderOperationTypeToUint64(requestData.OperationType)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: %v", err))
+		return
+	}
+	requestData.FillType = normalizeOrderFillTypeString(requestData.FillType)
+	fillType, err := orderFillTypeToUint64(requestData.FillType)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: %v", err))
+		return
+	}
+	if _, _, err = fes.getBuyingAndSellingDAOCoinPublicKeys(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+	); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: %v", err))
+		return
+	}
+
+	// A zero exchange rate is how the protocol represents a market order
+	scaledExchangeRate := uint256.NewInt()
+	if requestData.Price != "" {
+		scaledExchangeRate, err = CalculateScaledExchangeRateFromPriceString(
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.Price,
+			operationType,
+		)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: %v", err))
+			return
+		}
+	} else if fillType == lib.DAOCoinLimitOrderFillTypeGoodTillCancelled {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"SimulateDAOCoinLimitOrderFill: %v fill type not supported for market orders", requestData.FillType))
+		return
+	}
+
+	quantityToFillInBaseUnits, err := CalculateQuantityToFillAsBaseUnits(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.OperationType,
+		requestData.Quantity,
+	)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	buyingCoinPKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(
+		utxoView, requestData.BuyingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"SimulateDAOCoinLimitOrderFill: Invalid BuyingDAOCoinCreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	sellingCoinPKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(
+		utxoView, requestData.SellingDAOCoinCreatorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"SimulateDAOCoinLimitOrderFill: Invalid SellingDAOCoinCreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+
+	transactorOrder := &lib.DAOCoinLimitOrderEntry{
+		BuyingDAOCoinCreatorPKID:                  buyingCoinPKID,
+		SellingDAOCoinCreatorPKID:                 sellingCoinPKID,
+		ScaledExchangeRateCoinsToSellPerCoinToBuy: scaledExchangeRate,
+		QuantityToFillInBaseUnits:                 quantityToFillInBaseUnits,
+		OperationType:                             operationType,
+		FillType:                                  fillType,
+	}
+
+	matchingOrders, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(sellingCoinPKID, buyingCoinPKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: Error getting limit orders: %v", err))
+		return
+	}
+	sort.Slice(matchingOrders, func(ii, jj int) bool {
+		return matchingOrders[ii].IsBetterMatchingOrderThan(matchingOrders[jj])
+	})
+
+	remainingQuantity := uint256.NewInt().Set(quantityToFillInBaseUnits)
+	totalBuyingQuantity := uint256.NewInt()
+	totalSellingQuantity := uint256.NewInt()
+	for _, matchingOrder := range matchingOrders {
+		// The orders are sorted best price first, so once one is priced out the rest are too.
+		if remainingQuantity.IsZero() || !transactorOrder.IsValidMatchingOrderPrice(matchingOrder) {
+			break
+		}
+
+		buyingQuantity, sellingQuantity, _, err := calculateDAOCoinQuantitiesFilledByMatchingOrder(
+			matchingOrder, requestData.OperationType, remainingQuantity)
+		if err != nil {
+			logRequestErrorf(req.Context(), "SimulateDAOCoinLimitOrderFill: Skipping limit order with OrderID %v: %v", matchingOrder.OrderID, err)
+			continue
+		}
+		totalBuyingQuantity.Add(totalBuyingQuantity, buyingQuantity)
+		totalSellingQuantity.Add(totalSellingQuantity, sellingQuantity)
+	}
+
+	res := SimulateDAOCoinLimitOrderFillResponse{
+		IsFullyFilled: remainingQuantity.IsZero(),
+	}
+	if !res.IsFullyFilled {
+		res.RemainingQuantityRestsOnBook = fillType == lib.DAOCoinLimitOrderFillTypeGoodTillCancelled
+		res.WouldBeRejected = fillType == lib.DAOCoinLimitOrderFillTypeFillOrKill
+	}
+	if res.WouldBeRejected {
+		totalBuyingQuantity = uint256.NewInt()
+		totalSellingQuantity = uint256.NewInt()
+		remainingQuantity = quantityToFillInBaseUnits
+	}
+
+	// The requested quantity is in the buying coin for a BID and in the selling coin for an ASK
+	quantityCoin := requestData.BuyingDAOCoinCreatorPublicKeyBase58Check
+	if requestData.OperationType == DAOCoinLimitOrderOperationTypeStringASK {
+		quantityCoin = requestData.SellingDAOCoinCreatorPublicKeyBase58Check
+	}
+	res.FilledQuantity = CalculateDisplayUnitsFromBaseUnits(
+		quantityCoin, uint256.NewInt().Sub(quantityToFillInBaseUnits, remainingQuantity))
+	res.RemainingQuantity = CalculateDisplayUnitsFromBaseUnits(quantityCoin, remainingQuantity)
+	res.BuyingCoinQuantityFilled = CalculateDisplayUnitsFromBaseUnits(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check, totalBuyingQuantity)
+	res.SellingCoinQuantityFilled = CalculateDisplayUnitsFromBaseUnits(
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check, totalSellingQuantity)
+	if !totalBuyingQuantity.IsZero() && !totalSellingQuantity.IsZero() {
+		res.AverageExchangeRate, err = calculateDAOCoinPriceStringFromQuantities(
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			totalBuyingQuantity,
+			totalSellingQuantity,
+			requestData.OperationType,
+		)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: %v", err))
+			return
+		}
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("SimulateDAOCoinLimitOrderFill: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// daoCoinTradesLookbackBlocks is how far back GetDAOCoinTrades scans for trades, which is roughly a day of blocks
+// at 5min/block. Every DAO coin limit order txn in this window is read from the txindex, so it shouldn't be raised
+// without adding an index of fills by coin pair.
+const daoCoinTradesLookbackBlocks = 288
+
+type GetDAOCoinTradesRequest struct {
+	// Either coin can be "DESO", but not both. Prices and quantities are quoted the same way as in
+	// GetDAOCoinLimitOrderBook: the price is the number of DAOCoin2 coins per DAOCoin1 coin, and the quantity is in
+	// DAOCoin1 coins.
+	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Optional. Defaults to the server's default page size and is capped at its max page size.
+	Limit int `safeForLogging:"true"`
+
+	// Optional. When set, only trades with a TstampNanos greater than this are returned, and if there are more than
+	// Limit of them, the earliest ones are returned. Passing the TstampNanos of the first trade in the previous
+	// response lets a client poll for new trades without missing any.
+	AfterTStampNanos uint64 `safeForLogging:"true"`
+}
+
+type DAOCoinTradeResponse struct {
+	TxnHashHex string `safeForLogging:"true"`
+
+	// The public key of the transactor whose order crossed the book and that of the resting order it matched
+	TakerPublicKeyBase58Check string `safeForLogging:"true"`
+	MakerPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// BID if the taker bought DAOCoin1 and ASK if the taker sold it
+	TakerSide DAOCoinLimitOrderOperationTypeString `safeForLogging:"true"`
+
+	// A decimal string (ex: 1.23) for the number of DAOCoin2 coins per DAOCoin1 coin this trade executed at
+	Price string `safeForLogging:"true"`
+	// A decimal string (ex: 1.23) for the number of DAOCoin1 coins exchanged
+	Quantity string `safeForLogging:"true"`
+
+	// The block's timestamp for mined trades, or the time the txn was added to the mempool otherwise
+	TstampNanos uint64 `safeForLogging:"true"`
+	// Zero for trades that are still in the mempool
+	BlockHeight uint32 `safeForLogging:"true"`
+}
+
+type GetDAOCoinTradesResponse struct {
+	// Sorted from newest to oldest
+	Trades []DAOCoinTradeResponse
+}
+
+// GetDAOCoinTrades returns the most recent fills for a coin pair found in the mempool and the last
+// daoCoinTradesLookbackBlocks blocks. Trades in the mempool are reported again with their block's timestamp once
+// mined, so clients that poll should dedupe by TxnHashHex.
+func (fes *APIServer) GetDAOCoinTrades(ww http.ResponseWriter, req *http.Request) {
+	if fes.TXIndex == nil {
+		_AddBadRequestError(ww, "GetDAOCoinTrades: Cannot be called when TXIndexChain is nil. This error occurs "+
+			"when --txindex was not passed to the program on startup")
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinTradesRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinTrades: Problem parsing request body: %v", err))
+		return
+	}
+
+	coin1 := requestData.DAOCoin1CreatorPublicKeyBase58Check
+	coin2 := requestData.DAOCoin2CreatorPublicKeyBase58Check
+	if normalizeCoinIdentifier(coin1) == normalizeCoinIdentifier(coin2) {
+		_AddBadRequestError(ww, "GetDAOCoinTrades: Must provide two different coins "+
+			"for DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check")
+		return
+	}
+	if requestData.Limit < 0 {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinTrades: Limit must not be negative: %v", requestData.Limit))
+		return
+	}
+	limit := fes.getPageSize(uint64(requestData.Limit))
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinTrades: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	coin1PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin1)
+	if err != nil {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinTrades: Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	coin2PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin2)
+	if err != nil {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinTrades: Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+
+	// Without a cursor we can stop as soon as we have enough trades, but with one we need every trade after it so
+	// that we can return the earliest.
+	maxTrades := limit
+	if requestData.AfterTStampNanos != 0 {
+		maxTrades = 0
+	}
+	trades, err := fes.getDAOCoinTrades(utxoView, coin1, coin2, coin1PKID, coin2PKID,
+		requestData.AfterTStampNanos, daoCoinTradesLookbackBlocks, maxTrades)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinTrades: %v", err))
+		return
+	}
+	if len(trades) > limit {
+		if requestData.AfterTStampNanos != 0 {
+			trades = trades[len(trades)-limit:]
+		} else {
+			trades = trades[:limit]
+		}
+	}
+
+	res := GetDAOCoinTradesResponse{Trades: []DAOCoinTradeResponse{}}
+	for _, trade := range trades {
+		res.Trades = append(res.Trades, trade.DAOCoinTradeResponse)
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinTrades: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// daoCoinTrade is a trade along with the exact quantities exchanged, so that prices can be compared and volumes
+// summed without rounding.
+type daoCoinTrade struct {
+	DAOCoinTradeResponse
+
+	coin1QuantityInBaseUnits *uint256.Int
+	coin2QuantityInBaseUnits *uint256.Int
+}
+
+// getDAOCoinTrades returns the trades for a coin pair with a timestamp greater than afterTStampNanos that are in the
+// mempool or the last lookbackBlocks blocks, newest first. If maxTrades is non-zero, it stops once it has found at
+// least that many.
+func (fes *APIServer) getDAOCoinTrades(
+	utxoView *lib.UtxoView,
+	coin1PublicKeyBase58Check string,
+	coin2PublicKeyBase58Check string,
+	coin1PKID *lib.PKID,
+	coin2PKID *lib.PKID,
+	afterTStampNanos uint64,
+	lookbackBlocks int,
+	maxTrades int,
+) ([]*daoCoinTrade, error) {
+	query := &daoCoinTradesQuery{
+		coin1PublicKeyBase58Check: coin1PublicKeyBase58Check,
+		coin2PublicKeyBase58Check: coin2PublicKeyBase58Check,
+		coin1PKID:                 coin1PKID,
+		coin2PKID:                 coin2PKID,
+	}
+	if err := fes.scanDAOCoinTrades(
+		utxoView, []*daoCoinTradesQuery{query}, afterTStampNanos, lookbackBlocks, maxTrades); err != nil {
+		return nil, err
+	}
+	return query.trades, nil
+}
+
+// daoCoinTradesQuery is a coin pair that scanDAOCoinTrades collects trades for.
+type daoCoinTradesQuery struct {
+	coin1PublicKeyBase58Check string
+	coin2PublicKeyBase58Check string
+	coin1PKID                 *lib.PKID
+	coin2PKID                 *lib.PKID
+
+	// Newest first once the scan finishes.
+	trades []*daoCoinTrade
+}
+
+// scanDAOCoinTrades is getDAOCoinTrades for several coin pairs at once, so that the mempool and blocks are only read
+// once however many pairs are asked for. Each pair's trades are added to its query. If maxTrades is non-zero, it
+// stops once every pair has at least that many.
+func (fes *APIServer) scanDAOCoinTrades(
+	utxoView *lib.UtxoView,
+	queries []*daoCoinTradesQuery,
+	afterTStampNanos uint64,
+	lookbackBlocks int,
+	maxTrades int,
+) error {
+	hasEnoughTrades := func() bool {
+		if maxTrades == 0 {
+			return false
+		}
+		for _, query := range queries {
+			if len(query.trades) < maxTrades {
+				return false
+			}
+		}
+		return true
+	}
+	addTrades := func(
+		txnHash *lib.BlockHash, txnMeta *lib.TransactionMetadata, tstampNanos uint64, blockHeight uint32) error {
+
+		if tstampNanos <= afterTStampNanos || txnMeta == nil || txnMeta.DAOCoinLimitOrderTxindexMetadata == nil {
+			return nil
+		}
+		for _, query := range queries {
+			txnTrades, err := fes.buildDAOCoinTradesForTxn(utxoView, query.coin1PublicKeyBase58Check,
+				query.coin2PublicKeyBase58Check, query.coin1PKID, query.coin2PKID,
+				txnMeta.DAOCoinLimitOrderTxindexMetadata)
+			if err != nil {
+				return errors.Wrapf(err, "Problem building trades for txn %v", txnHash)
+			}
+			for _, trade := range txnTrades {
+				trade.TxnHashHex = hex.EncodeToString(txnHash[:])
+				trade.TstampNanos = tstampNanos
+				trade.BlockHeight = blockHeight
+			}
+			query.trades = append(query.trades, txnTrades...)
+		}
+		return nil
+	}
+
+	poolTxns, _, err := fes.mempool.GetTransactionsOrderedByTimeAdded()
+	if err != nil {
+		return errors.Wrapf(err, "Problem getting mempool txns")
+	}
+	for ii := len(poolTxns) - 1; ii >= 0 && !hasEnoughTrades(); ii-- {
+		poolTx := poolTxns[ii]
+		if poolTx.Tx.TxnMeta.GetTxnType() != lib.TxnTypeDAOCoinLimitOrder {
+			continue
+		}
+		if err = addTrades(poolTx.Hash, poolTx.TxMeta, uint64(poolTx.Added.UnixNano()), 0); err != nil {
+			return err
+		}
+	}
+
+	bestChain := fes.blockchain.BestChain()
+	for ii := len(bestChain) - 1; ii >= 0 && ii >= len(bestChain)-lookbackBlocks && !hasEnoughTrades(); ii-- {
+		blockNode := bestChain[ii]
+		blockTstampNanos := uint64(blockNode.Header.TstampSecs) * 1e9
+		if blockTstampNanos <= afterTStampNanos {
+			break
+		}
+		blockTxns, err := fes.getDAOCoinLimitOrderTxnsForBlock(blockNode, bestChain[len(bestChain)-1].Height)
+		if err != nil {
+			return err
+		}
+		// Walk the block backwards so trades stay sorted newest first.
+		for jj := len(blockTxns) - 1; jj >= 0; jj-- {
+			blockTxn := blockTxns[jj]
+			if err = addTrades(blockTxn.TxnHash, blockTxn.TxnMeta, blockTstampNanos, blockNode.Height); err != nil {
+				return err
+			}
+		}
+	}
+	// Block timestamps aren't strictly increasing, and a mempool txn can have been added before the tip was mined,
+	// so the scan order is only roughly newest first. The sort is stable so trades in the same txn keep their order.
+	for _, query := range queries {
+		trades := query.trades
+		sort.SliceStable(trades, func(ii, jj int) bool {
+			return trades[ii].TstampNanos > trades[jj].TstampNanos
+		})
+	}
+	return nil
+}
+
+// getDAOCoinLimitOrderTxnsForBlock returns the DAO coin limit order txns in the block, in block order, along with
+// their txindex metadata. Blocks are read from DAOCoinLimitOrderTxnsCache when possible. A block whose txns aren't
+// all in the txindex yet isn't cached, so that it's read again once the txindex catches up.
+func (fes *APIServer) getDAOCoinLimitOrderTxnsForBlock(
+	blockNode *lib.BlockNode, tipHeight uint32) ([]daoCoinLimitOrderTxn, error) {
+
+	if fes.DAOCoinLimitOrderTxnsCache != nil {
+		if txns, found := fes.DAOCoinLimitOrderTxnsCache.Get(blockNode.Hash); found {
+			return txns, nil
+		}
+	}
+
+	blockMsg, err := lib.GetBlock(blockNode.Hash, fes.blockchain.DB(), fes.blockchain.Snapshot())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Problem fetching block %v", blockNode.Hash)
+	}
+	txns := []daoCoinLimitOrderTxn{}
+	isTxindexComplete := true
+	for _, txn := range blockMsg.Txns {
+		if txn.TxnMeta.GetTxnType() != lib.TxnTypeDAOCoinLimitOrder {
+			continue
+		}
+		txnHash := txn.Hash()
+		txnMeta := lib.DbGetTxindexTransactionRefByTxID(fes.TXIndex.TXIndexChain.DB(), nil, txnHash)
+		if txnMeta == nil {
+			isTxindexComplete = false
+		}
+		txns = append(txns, daoCoinLimitOrderTxn{TxnHash: txnHash, TxnMeta: txnMeta})
+	}
+
+	if fes.DAOCoinLimitOrderTxnsCache != nil && isTxindexComplete {
+		minBlockHeight := uint32(0)
+		if tipHeight > daoCoinCandlesLookbackBlocks {
+			minBlockHeight = tipHeight - daoCoinCandlesLookbackBlocks
+		}
+		fes.DAOCoinLimitOrderTxnsCache.Put(blockNode.Hash, blockNode.Height, txns, minBlockHeight)
+	}
+	return txns, nil
+}
+
+// buildDAOCoinTradesForTxn returns the trades in a DAO coin limit order txn if it's for the given coin pair, most
+// recent first. The filled orders come in pairs: the transactor's order followed by the resting order it matched.
+func (fes *APIServer) buildDAOCoinTradesForTxn(
+	utxoView *lib.UtxoView,
+	coin1PublicKeyBase58Check string,
+	coin2PublicKeyBase58Check string,
+	coin1PKID *lib.PKID,
+	coin2PKID *lib.PKID,
+	txnMeta *lib.DAOCoinLimitOrderTxindexMetadata,
+) ([]*daoCoinTrade, error) {
+	trades := []*daoCoinTrade{}
+	filledOrders := txnMeta.FilledDAOCoinLimitOrdersMetadata
+	for ii := len(filledOrders) - 2; ii >= 0; ii -= 2 {
+		takerOrder, makerOrder := filledOrders[ii], filledOrders[ii+1]
+
+		buyingPKID, err := fes.getPKIDFromPublicKeyBase58Check(utxoView, takerOrder.BuyingDAOCoinCreatorPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		sellingPKID, err := fes.getPKIDFromPublicKeyBase58Check(utxoView, takerOrder.SellingDAOCoinCreatorPublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		var takerSide DAOCoinLimitOrderOperationTypeString
+		var coin1Quantity, coin2Quantity *uint256.Int
+		if buyingPKID.Eq(coin1PKID) && sellingPKID.Eq(coin2PKID) {
+			takerSide = DAOCoinLimitOrderOperationTypeStringBID
+			coin1Quantity, coin2Quantity = takerOrder.CoinQuantityInBaseUnitsBought, takerOrder.CoinQuantityInBaseUnitsSold
+		} else if buyingPKID.Eq(coin2PKID) && sellingPKID.Eq(coin1PKID) {
+			takerSide = DAOCoinLimitOrderOperationTypeStringASK
+			coin1Quantity, coin2Quantity = takerOrder.CoinQuantityInBaseUnitsSold, takerOrder.CoinQuantityInBaseUnitsBought
+		} else {
+			// Every fill in a txn is for the same pair, so this txn is for some other market.
+			return trades, nil
+		}
+		if coin1Quantity.IsZero() {
+			continue
+		}
+
+		// Quoting the trade as a bid for coin1 with coin2 gives the number of coin2 coins per coin1 coin.
+		price, err := calculateDAOCoinPriceStringFromQuantities(
+			coin1PublicKeyBase58Check,
+			coin2PublicKeyBase58Check,
+			coin1Quantity,
+			coin2Quantity,
+			DAOCoinLimitOrderOperationTypeStringBID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		trades = append(trades, &daoCoinTrade{
+			DAOCoinTradeResponse: DAOCoinTradeResponse{
+				TakerPublicKeyBase58Check: takerOrder.TransactorPublicKeyBase58Check,
+				MakerPublicKeyBase58Check: makerOrder.TransactorPublicKeyBase58Check,
+				TakerSide:                 takerSide,
+				Price:                     price,
+				Quantity:                  CalculateDisplayUnitsFromBaseUnits(coin1PublicKeyBase58Check, coin1Quantity),
+			},
+			coin1QuantityInBaseUnits: coin1Quantity,
+			coin2QuantityInBaseUnits: coin2Quantity,
+		})
+	}
+	return trades, nil
+}
+
+// MaxGetDAOCoinCandles is the maximum number of intervals a GetDAOCoinCandles request can span.
+const MaxGetDAOCoinCandles = 500
+
+// daoCoinCandlesLookbackBlocks is how far back GetDAOCoinCandles scans for trades, which is roughly a week of blocks
+// at 5min/block. Candles for intervals before then are empty.
+const daoCoinCandlesLookbackBlocks = 7 * 288
+
+var daoCoinCandleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+type GetDAOCoinCandlesRequest struct {
+	// Prices and volumes are quoted the same way as in GetDAOCoinTrades.
+	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// One of 1m, 5m, 1h, or 1d
+	Interval string `safeForLogging:"true"`
+
+	// The time range to return candles for. EndTStampNanos defaults to now and StartTStampNanos defaults to
+	// MaxGetDAOCoinCandles intervals before EndTStampNanos. The range can't span more than MaxGetDAOCoinCandles
+	// intervals.
+	StartTStampNanos uint64 `safeForLogging:"true"`
+	EndTStampNanos   uint64 `safeForLogging:"true"`
+}
+
+type DAOCoinCandleResponse struct {
+	// The start of the interval. Intervals are aligned to multiples of their length since the unix epoch.
+	TStampNanos uint64 `safeForLogging:"true"`
+
+	// Decimal strings for the number of DAOCoin2 coins per DAOCoin1 coin
+	Open  string `safeForLogging:"true"`
+	High  string `safeForLogging:"true"`
+	Low   string `safeForLogging:"true"`
+	Close string `safeForLogging:"true"`
+
+	// A decimal string for the number of DAOCoin1 coins traded during the interval
+	Volume string `safeForLogging:"true"`
+
+	NumTrades int `safeForLogging:"true"`
+}
+
+type GetDAOCoinCandlesResponse struct {
+	// Sorted from oldest to newest. Intervals with no trades are omitted.
+	Candles []DAOCoinCandleResponse
+}
+
+// GetDAOCoinCandles returns OHLCV candles for a coin pair built from the trades GetDAOCoinTrades would return. At most
+// MaxGetDAOCoinCandles candles are returned, and only trades from the last daoCoinCandlesLookbackBlocks blocks and
+// the mempool are included.
+func (fes *APIServer) GetDAOCoinCandles(ww http.ResponseWriter, req *http.Request) {
+	if fes.TXIndex == nil {
+		_AddBadRequestError(ww, "GetDAOCoinCandles: Cannot be called when TXIndexChain is nil. This error occurs "+
+			"when --txindex was not passed to the program on startup")
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinCandlesRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinCandles: Problem parsing request body: %v", err))
+		return
+	}
+
+	coin1 := requestData.DAOCoin1CreatorPublicKeyBase58Check
+	coin2 := requestData.DAOCoin2CreatorPublicKeyBase58Check
+	if normalizeCoinIdentifier(coin1) == normalizeCoinIdentifier(coin2) {
+		_AddBadRequestError(ww, "GetDAOCoinCandles: Must provide two different coins "+
+			"for DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check")
+		return
+	}
+
+	interval, exists := daoCoinCandleIntervals[requestData.Interval]
+	if !exists {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinCandles: Invalid Interval %v: must be one of 1m, 5m, 1h, "+
+			"or 1d", requestData.Interval))
+		return
+	}
+	intervalNanos := uint64(interval.Nanoseconds())
+	endTstampNanos := requestData.EndTStampNanos
+	if endTstampNanos == 0 {
+		endTstampNanos = uint64(time.Now().UnixNano())
+	}
+	startTstampNanos := requestData.StartTStampNanos
+	if startTstampNanos == 0 && endTstampNanos > MaxGetDAOCoinCandles*intervalNanos {
+		startTstampNanos = endTstampNanos - MaxGetDAOCoinCandles*intervalNanos
+	}
+	// Round the start down to the beginning of its interval so the first candle is complete.
+	startTstampNanos -= startTstampNanos % intervalNanos
+	if startTstampNanos >= endTstampNanos {
+		_AddBadRequestError(ww, "GetDAOCoinCandles: StartTStampNanos must be before EndTStampNanos")
+		return
+	}
+	if (endTstampNanos-startTstampNanos)/intervalNanos > MaxGetDAOCoinCandles {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinCandles: Time range cannot span more than %v intervals",
+			MaxGetDAOCoinCandles))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinCandles: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	coin1PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin1)
+	if err != nil {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinCandles: Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+	coin2PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin2)
+	if err != nil {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDAOCoinCandles: Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err))
+		return
+	}
+
+	// Trades exactly at the start of the range belong to the first candle, so look for trades after the instant before.
+	afterTstampNanos := uint64(0)
+	if startTstampNanos > 0 {
+		afterTstampNanos = startTstampNanos - 1
+	}
+	trades, err := fes.getDAOCoinTrades(utxoView, coin1, coin2, coin1PKID, coin2PKID,
+		afterTstampNanos, daoCoinCandlesLookbackBlocks, 0)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinCandles: %v", err))
+		return
+	}
+
+	candles, err := buildDAOCoinCandles(coin1, coin2, trades, startTstampNanos, endTstampNanos, intervalNanos)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinCandles: Problem building candles: %v", err))
+		return
+	}
+
+	res := GetDAOCoinCandlesResponse{Candles: candles}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinCandles: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// buildDAOCoinCandles buckets trades, which must be sorted newest first as getDAOCoinTrades returns them, into candles for the intervals in
+// [startTstampNanos, endTstampNanos), with the first interval starting at startTstampNanos. Returns the candles oldest
+// first, skipping intervals with no trades.
+func buildDAOCoinCandles(
+	coin1PublicKeyBase58Check string,
+	coin2PublicKeyBase58Check string,
+	trades []*daoCoinTrade,
+	startTstampNanos uint64,
+	endTstampNanos uint64,
+	intervalNanos uint64,
+) ([]DAOCoinCandleResponse, error) {
+	// a has a higher price than b if a.coin2 / a.coin1 > b.coin2 / b.coin1.
+	isHigherPrice := func(a *daoCoinTrade, b *daoCoinTrade) bool {
+		aScaled := big.NewInt(0).Mul(a.coin2QuantityInBaseUnits.ToBig(), b.coin1QuantityInBaseUnits.ToBig())
+		bScaled := big.NewInt(0).Mul(b.coin2QuantityInBaseUnits.ToBig(), a.coin1QuantityInBaseUnits.ToBig())
+		return aScaled.Cmp(bScaled) > 0
+	}
+
+	candles := []DAOCoinCandleResponse{}
+	// Walk the trades oldest first, closing out a candle whenever a trade falls in a later interval.
+	var candle *DAOCoinCandleResponse
+	var high, low *daoCoinTrade
+	volume := uint256.NewInt()
+	closeCandle := func() {
+		if candle == nil {
+			return
+		}
+		candle.High = high.Price
+		candle.Low = low.Price
+		candle.Volume = CalculateDisplayUnitsFromBaseUnits(coin1PublicKeyBase58Check, volume)
+		candles = append(candles, *candle)
+	}
+	for ii := len(trades) - 1; ii >= 0; ii-- {
+		trade := trades[ii]
+		if trade.TstampNanos < startTstampNanos || trade.TstampNanos >= endTstampNanos {
+			continue
+		}
+		candleTstampNanos := startTstampNanos + (trade.TstampNanos-startTstampNanos)/intervalNanos*intervalNanos
+		if candle == nil || candle.TStampNanos != candleTstampNanos {
+			closeCandle()
+			candle = &DAOCoinCandleResponse{TStampNanos: candleTstampNanos, Open: trade.Price}
+			high, low = trade, trade
+			volume = uint256.NewInt()
+		}
+		if isHigherPrice(trade, high) {
+			high = trade
+		}
+		if isHigherPrice(low, trade) {
+			low = trade
+		}
+		candle.Close = trade.Price
+		candle.NumTrades++
+		if volume.AddOverflow(volume, trade.coin1QuantityInBaseUnits) {
+			return nil, errors.Errorf("Volume overflows uint256 in interval starting at %v", candleTstampNanos)
+		}
+	}
+	closeCandle()
+	return candles, nil
+}
+
+// MaxGetDAOCoinMarketStatsPairs is the max number of coin pairs in a GetDAOCoinMarketStats request
+const MaxGetDAOCoinMarketStatsPairs = 50
+
+// daoCoinMarketStatsWindow is the window GetDAOCoinMarketStats aggregates trades over
+const daoCoinMarketStatsWindow = 24 * time.Hour
+
+type GetDAOCoinMarketStatsRequest struct {
+	// Only DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check are used. Prices and volumes
+	// are quoted the same way as in GetDAOCoinTrades.
+	CoinPairs []GetDAOCoinLimitOrdersRequest `safeForLogging:"true"`
+}
+
+type DAOCoinMarketStatsResponse struct {
+	// Decimal strings for the number of DAOCoin2 coins per DAOCoin1 coin. Empty if there were no trades in the last
+	// 24h.
+	LastPrice string `safeForLogging:"true"`
+	High24h   string `safeForLogging:"true"`
+	Low24h    string `safeForLogging:"true"`
+
+	// The change from the first trade in the last 24h to the last one, as a percent. Zero if there were no trades.
+	PriceChangePercent24h float64 `safeForLogging:"true"`
+
+	// A decimal string for the number of DAOCoin1 coins traded in the last 24h
+	Volume24h    string `safeForLogging:"true"`
+	NumTrades24h int    `safeForLogging:"true"`
+
+	// Set if this pair's stats couldn't be computed, in which case the other fields are empty
+	Error string `safeForLogging:"true"`
+}
+
+type GetDAOCoinMarketStatsResponse struct {
+	// Keyed by "<DAOCoin1CreatorPublicKeyBase58Check>/<DAOCoin2CreatorPublicKeyBase58Check>" for each requested pair
+	StatsByCoinPair map[string]DAOCoinMarketStatsResponse
+}
+
+// GetDAOCoinMarketStats returns 24h stats for several coin pairs, computed from the same trades GetDAOCoinTrades and
+// GetDAOCoinCandles return. Results are cached for --dao-coin-market-stats-cache-ttl-seconds. A pair that fails has
+// its error reported inline rather than failing the whole request.
+func (fes *APIServer) GetDAOCoinMarketStats(ww http.ResponseWriter, req *http.Request) {
+	if fes.TXIndex == nil {
+		_AddBadRequestError(ww, "GetDAOCoinMarketStats: Cannot be called when TXIndexChain is nil. This error occurs "+
+			"when --txindex was not passed to the program on startup")
+		return
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetDAOCoinMarketStatsRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetDAOCoinMarketStats: Problem parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.CoinPairs) == 0 || len(requestData.CoinPairs) > MaxGetDAOCoinMarketStatsPairs {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetDAOCoinMarketStats: Must provide between 1 and %v CoinPairs", MaxGetDAOCoinMarketStatsPairs))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketStats: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	res := GetDAOCoinMarketStatsResponse{
+		StatsByCoinPair: make(map[string]DAOCoinMarketStatsResponse, len(requestData.CoinPairs)),
+	}
+	// Pairs are resolved to PKIDs first, so that the same market spelled different ways is only computed and cached
+	// once. Every pair that isn't cached is then computed from a single scan.
+	queriesByPKIDPair := make(map[daoCoinPKIDPair]*daoCoinTradesQuery)
+	batchKeysByPKIDPair := make(map[daoCoinPKIDPair][]string)
+	for ii := range requestData.CoinPairs {
+		coinPair := &requestData.CoinPairs[ii]
+		batchKey := getDAOCoinLimitOrdersBatchKey(coinPair)
+		query, err := fes.newDAOCoinMarketStatsQuery(
+			utxoView, coinPair.DAOCoin1CreatorPublicKeyBase58Check, coinPair.DAOCoin2CreatorPublicKeyBase58Check)
+		if err != nil {
+			res.StatsByCoinPair[batchKey] = DAOCoinMarketStatsResponse{Error: err.Error()}
+			continue
+		}
+		pkidPair := daoCoinPKIDPair{Coin1PKID: *query.coin1PKID, Coin2PKID: *query.coin2PKID}
+		if fes.DAOCoinMarketStatsCache != nil {
+			if stats, found := fes.DAOCoinMarketStatsCache.Get(pkidPair); found {
+				res.StatsByCoinPair[batchKey] = stats
+				continue
+			}
+		}
+		if _, exists := queriesByPKIDPair[pkidPair]; !exists {
+			queriesByPKIDPair[pkidPair] = query
+		}
+		batchKeysByPKIDPair[pkidPair] = append(batchKeysByPKIDPair[pkidPair], batchKey)
+	}
+
+	if len(queriesByPKIDPair) > 0 {
+		statsByPKIDPair, err := fes.getDAOCoinMarketStatsForQueries(utxoView, queriesByPKIDPair)
+		for pkidPair, batchKeys := range batchKeysByPKIDPair {
+			stats := statsByPKIDPair[pkidPair]
+			if err != nil {
+				stats = DAOCoinMarketStatsResponse{Error: err.Error()}
+			}
+			for _, batchKey := range batchKeys {
+				res.StatsByCoinPair[batchKey] = stats
+			}
+		}
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetDAOCoinMarketStats: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+// newDAOCoinMarketStatsQuery validates a coin pair and resolves its coins to PKIDs.
+func (fes *APIServer) newDAOCoinMarketStatsQuery(utxoView *lib.UtxoView, coin1 string, coin2 string) (
+	*daoCoinTradesQuery, error) {
+
+	if normalizeCoinIdentifier(coin1) == normalizeCoinIdentifier(coin2) {
+		return nil, errors.Errorf("Must provide two different coins " +
+			"for DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check")
+	}
+	coin1PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin1)
+	if err != nil {
+		return nil, errors.Errorf("Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err)
+	}
+	coin2PKID, err := fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin2)
+	if err != nil {
+		return nil, errors.Errorf("Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err)
+	}
+	return &daoCoinTradesQuery{
+		coin1PublicKeyBase58Check: coin1,
+		coin2PublicKeyBase58Check: coin2,
+		coin1PKID:                 coin1PKID,
+		coin2PKID:                 coin2PKID,
+	}, nil
+}
+
+// getDAOCoinMarketStatsForQueries computes the stats for every pair from a single scan of the last
+// daoCoinMarketStatsWindow, and caches them. Concurrent requests for the same set of pairs share one scan rather than
+// each doing their own when the cache expires.
+func (fes *APIServer) getDAOCoinMarketStatsForQueries(
+	utxoView *lib.UtxoView,
+	queriesByPKIDPair map[daoCoinPKIDPair]*daoCoinTradesQuery,
+) (map[daoCoinPKIDPair]DAOCoinMarketStatsResponse, error) {
+	computeStats := func() (map[daoCoinPKIDPair]DAOCoinMarketStatsResponse, error) {
+		queries := make([]*daoCoinTradesQuery, 0, len(queriesByPKIDPair))
+		for _, query := range queriesByPKIDPair {
+			queries = append(queries, query)
+		}
+		windowNanos := uint64(daoCoinMarketStatsWindow.Nanoseconds())
+		startTstampNanos := uint64(time.Now().UnixNano()) - windowNanos
+		// The lookback is only an upper bound. The scan stops at the first block before the window.
+		if err := fes.scanDAOCoinTrades(
+			utxoView, queries, startTstampNanos-1, daoCoinCandlesLookbackBlocks, 0); err != nil {
+			return nil, err
+		}
+
+		statsByPKIDPair := make(map[daoCoinPKIDPair]DAOCoinMarketStatsResponse, len(queriesByPKIDPair))
+		for pkidPair, query := range queriesByPKIDPair {
+			stats, err := buildDAOCoinMarketStats(
+				query.coin1PublicKeyBase58Check, query.coin2PublicKeyBase58Check, query.trades, startTstampNanos)
+			if err != nil {
+				stats = DAOCoinMarketStatsResponse{Error: err.Error()}
+			} else if fes.DAOCoinMarketStatsCache != nil {
+				fes.DAOCoinMarketStatsCache.Put(pkidPair, stats)
+			}
+			statsByPKIDPair[pkidPair] = stats
+		}
+		return statsByPKIDPair, nil
+	}
+	if fes.DAOCoinMarketStatsCache == nil {
+		return computeStats()
+	}
+
+	pkidPairKeys := make([]string, 0, len(queriesByPKIDPair))
+	for pkidPair := range queriesByPKIDPair {
+		pkidPairKeys = append(pkidPairKeys, string(pkidPair.Coin1PKID[:])+string(pkidPair.Coin2PKID[:]))
+	}
+	sort.Strings(pkidPairKeys)
+	statsByPKIDPair, err, _ := fes.DAOCoinMarketStatsCache.computeGroup.Do(
+		strings.Join(pkidPairKeys, ""), func() (interface{}, error) {
+			return computeStats()
+		})
+	if err != nil {
+		return nil, err
+	}
+	return statsByPKIDPair.(map[daoCoinPKIDPair]DAOCoinMarketStatsResponse), nil
+}
+
+// buildDAOCoinMarketStats builds a single candle from the trades, which must be sorted newest first, at or after
+// startTstampNanos and derives the stats from it.
+func buildDAOCoinMarketStats(
+	coin1 string,
+	coin2 string,
+	trades []*daoCoinTrade,
+	startTstampNanos uint64,
+) (DAOCoinMarketStatsResponse, error) {
+	// Trades in the mempool can be slightly newer than now, so extend the end of the window to include them.
+	candles, err := buildDAOCoinCandles(coin1, coin2, trades, startTstampNanos, math.MaxUint64, math.MaxUint64)
+	if err != nil {
+		return DAOCoinMarketStatsResponse{}, err
+	}
+
+	stats := DAOCoinMarketStatsResponse{Volume24h: CalculateDisplayUnitsFromBaseUnits(coin1, uint256.NewInt())}
+	if len(candles) == 0 {
+		return stats, nil
+	}
+	candle := candles[0]
+	stats.LastPrice = candle.Close
+	stats.High24h = candle.High
+	stats.Low24h = candle.Low
+	stats.Volume24h = candle.Volume
+	stats.NumTrades24h = candle.NumTrades
+
+	openPrice, ok := big.NewRat(0, 1).SetString(candle.Open)
+	if !ok {
+		return DAOCoinMarketStatsResponse{}, errors.Errorf("Problem parsing open price %v", candle.Open)
+	}
+	closePrice, ok := big.NewRat(0, 1).SetString(candle.Close)
+	if !ok {
+		return DAOCoinMarketStatsResponse{}, errors.Errorf("Problem parsing close price %v", candle.Close)
+	}
+	if openPrice.Sign() > 0 {
+		priceChange := big.NewRat(0, 1).Sub(closePrice, openPrice)
+		priceChange.Quo(priceChange, openPrice).Mul(priceChange, big.NewRat(100, 1))
+		stats.PriceChangePercent24h, _ = priceChange.Float64()
+	}
+	return stats, nil
+}
+
+type ConvertDAOCoinPriceRequest struct {
+	BuyingDAOCoinCreatorPublicKeyBase58Check  string                               `safeForLogging:"true"`
+	SellingDAOCoinCreatorPublicKeyBase58Check string                               `safeForLogging:"true"`
+	OperationType                             DAOCoinLimitOrderOperationTypeString `safeForLogging:"true"`
+
+	// Exactly one of these must be set. Price is a decimal string (ex: 1.23) whose denominator is determined by the
+	// operation type, as in CreateDAOCoinLimitOrder. ScaledExchangeRateCoinsToSellPerCoinToBuy is a base-10 integer
+	// string, as stored on chain in an order's ScaledExchangeRateCoinsToSellPerCoinToBuy.
+	Price                                     string  `safeForLogging:"true"`
+	ExchangeRateCoinsToSellPerCoinToBuy       float64 `safeForLogging:"true"`
+	ScaledExchangeRateCoinsToSellPerCoinToBuy string  `safeForLogging:"true"`
+}
+
+type ConvertDAOCoinPriceResponse struct {
+	Price                                     string
+	ExchangeRateCoinsToSellPerCoinToBuy       float64
+	ScaledExchangeRateCoinsToSellPerCoinToBuy string
+}
+
+// ConvertDAOCoinPrice converts a price for a coin pair between the decimal price string, float exchange rate, and
+// scaled base unit exchange rate representations, using the same functions order construction does. It doesn't read
+// any chain state, so clients can use it to check their own conversions without constructing a transaction.
+func (fes *APIServer) ConvertDAOCoinPrice(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := ConvertDAOCoinPriceRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertDAOCoinPrice: Problem parsing request body: %v", err))
+		return
+	}
+
+	requestData.OperationType = normalizeOrderOperationTypeString(requestData.OperationType)
+	operationType, err := orderOperationTypeToUint64(requestData.OperationType)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertDAOCoinPrice: %v", err))
+		return
+	}
+
+	if _, _, err = fes.getBuyingAndSellingDAOCoinPublicKeys(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+	); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertDAOCoinPrice: %v", err))
+		return
+	}
+
+	numInputs := 0
+	for _, isSet := range []bool{
+		requestData.Price != "",
+		requestData.ExchangeRateCoinsToSellPerCoinToBuy != 0,
+		requestData.ScaledExchangeRateCoinsToSellPerCoinToBuy != "",
+	} {
+		if isSet {
+			numInputs++
+		}
+	}
+	if numInputs != 1 {
+		_AddBadRequestError(ww, "ConvertDAOCoinPrice: Must provide exactly one of Price, "+
+			"ExchangeRateCoinsToSellPerCoinToBuy, or ScaledExchangeRateCoinsToSellPerCoinToBuy")
+		return
+	}
+
+	var scaledExchangeRate *uint256.Int
+	if requestData.Price != "" {
+		scaledExchangeRate, err = CalculateScaledExchangeRateFromPriceString(
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.Price,
+			operationType,
+		)
+	} else if requestData.ExchangeRateCoinsToSellPerCoinToBuy != 0 {
+		scaledExchangeRate, err = CalculateScaledExchangeRateFromFloat(
+			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			requestData.ExchangeRateCoinsToSellPerCoinToBuy,
+		)
+	} else {
+		scaledExchangeRate, err = parseBaseUnitsString(requestData.ScaledExchangeRateCoinsToSellPerCoinToBuy)
+		if err == nil && scaledExchangeRate.IsZero() {
+			err = errors.Errorf("ScaledExchangeRateCoinsToSellPerCoinToBuy must be greater than 0")
+		}
+	}
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertDAOCoinPrice: %v", err))
+		return
+	}
+
+	price, err := CalculatePriceStringFromScaledExchangeRate(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		scaledExchangeRate,
+		requestData.OperationType,
+	)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertDAOCoinPrice: %v", err))
+		return
+	}
+	exchangeRate, err := CalculateFloatFromScaledExchangeRate(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		scaledExchangeRate,
+	)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConvertDAOCoinPrice: %v", err))
+		return
+	}
+
+	res := ConvertDAOCoinPriceResponse{
+		Price:                               price,
+		ExchangeRateCoinsToSellPerCoinToBuy: exchangeRate,
+		ScaledExchangeRateCoinsToSellPerCoinToBuy: scaledExchangeRate.ToBig().String(),
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("ConvertDAOCoinPrice: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
@@ -1,11 +1,21 @@
 package routes
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/deso-smart/deso-backend/v3/config"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -551,3 +561,776 @@ func TestCalculateStringQuantityFromBaseUnits(t *testing.T) {
 		require.Error(t, err)
 	}
 }
+
+func TestCalculateCoinUnitConversions(t *testing.T) {
+	// DESO uses 1e9 base units per coin
+	{
+		displayUnits := CalculateDisplayUnitsFromBaseUnits(desoPubKeyBase58Check, uint256.NewInt().SetUint64(1500000000))
+		require.Equal(t, "1.5", displayUnits)
+
+		baseUnits, err := CalculateBaseUnitsFromDisplayUnits(desoPubKeyBase58Check, "1.5")
+		require.NoError(t, err)
+		require.Equal(t, uint256.NewInt().SetUint64(1500000000), baseUnits)
+	}
+
+	// DAO coins use 1e18 base units per coin
+	{
+		expectedBaseUnits := uint256.NewInt().Mul(uint256.NewInt().SetUint64(15), uint256.NewInt().SetUint64(1e17))
+		displayUnits := CalculateDisplayUnitsFromBaseUnits(daoCoinPubKeyBase58Check, expectedBaseUnits)
+		require.Equal(t, "1.5", displayUnits)
+
+		baseUnits, err := CalculateBaseUnitsFromDisplayUnits(daoCoinPubKeyBase58Check, "1.5")
+		require.NoError(t, err)
+		require.Equal(t, expectedBaseUnits, baseUnits)
+	}
+
+	// invalid inputs
+	{
+		_, err := CalculateBaseUnitsFromDisplayUnits(desoPubKeyBase58Check, "-1")
+		require.Error(t, err)
+
+		_, err = parseBaseUnitsString("-1")
+		require.Error(t, err)
+
+		_, err = parseBaseUnitsString("1.5")
+		require.Error(t, err)
+	}
+}
+
+func TestCalculateDAOCoinPriceStringFromQuantities(t *testing.T) {
+	// 2 DAO coins bought for 3 DESO
+	buyingQuantity := uint256.NewInt().Mul(uint256.NewInt().SetUint64(2), lib.BaseUnitsPerCoin)
+	sellingQuantity := uint256.NewInt().SetUint64(3 * lib.NanosPerUnit)
+
+	// BID: price is the number of selling coins per buying coin
+	{
+		price, err := calculateDAOCoinPriceStringFromQuantities(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			buyingQuantity,
+			sellingQuantity,
+			DAOCoinLimitOrderOperationTypeStringBID,
+		)
+		require.NoError(t, err)
+		require.Equal(t, "1.5", price)
+	}
+
+	// ASK: price is the number of buying coins per selling coin
+	{
+		price, err := calculateDAOCoinPriceStringFromQuantities(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			buyingQuantity,
+			sellingQuantity,
+			DAOCoinLimitOrderOperationTypeStringASK,
+		)
+		require.NoError(t, err)
+		require.Equal(t, "0.66666666666666666666666666666666666666", price)
+	}
+
+	// zero quantity
+	{
+		_, err := calculateDAOCoinPriceStringFromQuantities(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			uint256.NewInt(),
+			sellingQuantity,
+			DAOCoinLimitOrderOperationTypeStringBID,
+		)
+		require.Error(t, err)
+	}
+}
+
+func TestCalculateDAOCoinMarketOrderParams(t *testing.T) {
+	// Bid order to buy a DAO coin using $DESO defaults to immediate or cancel with a zero exchange rate
+	{
+		params, err := CalculateDAOCoinMarketOrderParams(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			"1",
+			DAOCoinLimitOrderOperationTypeStringBID,
+			"",
+		)
+		require.NoError(t, err)
+		require.True(t, params.ScaledExchangeRateCoinsToSellPerCoinToBuy.IsZero())
+		require.Equal(t, &(*lib.BaseUnitsPerCoin), params.QuantityToFillInBaseUnits)
+		require.Equal(t, lib.DAOCoinLimitOrderOperationTypeBID, params.OperationType)
+		require.Equal(t, lib.DAOCoinLimitOrderFillTypeImmediateOrCancel, params.FillType)
+	}
+
+	// Ask order to sell $DESO for a DAO coin with fill or kill
+	{
+		params, err := CalculateDAOCoinMarketOrderParams(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			"1",
+			DAOCoinLimitOrderOperationTypeStringASK,
+			DAOCoinLimitOrderFillTypeFillOrKill,
+		)
+		require.NoError(t, err)
+		require.True(t, params.ScaledExchangeRateCoinsToSellPerCoinToBuy.IsZero())
+		require.Equal(t, uint256.NewInt().SetUint64(lib.NanosPerUnit), params.QuantityToFillInBaseUnits)
+		require.Equal(t, lib.DAOCoinLimitOrderOperationTypeASK, params.OperationType)
+		require.Equal(t, lib.DAOCoinLimitOrderFillTypeFillOrKill, params.FillType)
+	}
+
+	// Lowercase operation and fill types are normalized, including when determining which coin the quantity is in
+	{
+		params, err := CalculateDAOCoinMarketOrderParams(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			"1",
+			"ask",
+			"fill_or_kill",
+		)
+		require.NoError(t, err)
+		require.Equal(t, uint256.NewInt().SetUint64(lib.NanosPerUnit), params.QuantityToFillInBaseUnits)
+		require.Equal(t, lib.DAOCoinLimitOrderOperationTypeASK, params.OperationType)
+		require.Equal(t, lib.DAOCoinLimitOrderFillTypeFillOrKill, params.FillType)
+	}
+
+	// Good till cancelled market orders are rejected
+	for _, fillType := range []DAOCoinLimitOrderFillTypeString{DAOCoinLimitOrderFillTypeGoodTillCancelled, "good_till_cancelled"} {
+		_, err := CalculateDAOCoinMarketOrderParams(
+			daoCoinPubKeyBase58Check,
+			desoPubKeyBase58Check,
+			"1",
+			DAOCoinLimitOrderOperationTypeStringBID,
+			fillType,
+		)
+		require.Error(t, err)
+	}
+}
+
+func TestOrderOperationAndFillTypeStrings(t *testing.T) {
+	for _, operationType := range []DAOCoinLimitOrderOperationTypeString{"BID", "bid", "Bid", "bId"} {
+		parsedOperationType, err := orderOperationTypeToUint64(operationType)
+		require.NoError(t, err)
+		require.Equal(t, lib.DAOCoinLimitOrderOperationTypeBID, parsedOperationType)
+		require.Equal(t, DAOCoinLimitOrderOperationTypeStringBID, normalizeOrderOperationTypeString(operationType))
+	}
+	for _, operationType := range []DAOCoinLimitOrderOperationTypeString{"ASK", "ask", "Ask"} {
+		parsedOperationType, err := orderOperationTypeToUint64(operationType)
+		require.NoError(t, err)
+		require.Equal(t, lib.DAOCoinLimitOrderOperationTypeASK, parsedOperationType)
+	}
+	for _, operationType := range []DAOCoinLimitOrderOperationTypeString{"", " BID", "BUY"} {
+		_, err := orderOperationTypeToUint64(operationType)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must be one of ASK or BID")
+	}
+
+	fillTypeTestCases := map[DAOCoinLimitOrderFillTypeString]lib.DAOCoinLimitOrderFillType{
+		"GOOD_TILL_CANCELLED": lib.DAOCoinLimitOrderFillTypeGoodTillCancelled,
+		"good_till_cancelled": lib.DAOCoinLimitOrderFillTypeGoodTillCancelled,
+		"Fill_Or_Kill":        lib.DAOCoinLimitOrderFillTypeFillOrKill,
+		"immediate_or_CANCEL": lib.DAOCoinLimitOrderFillTypeImmediateOrCancel,
+	}
+	for fillType, expectedFillType := range fillTypeTestCases {
+		parsedFillType, err := orderFillTypeToUint64(fillType)
+		require.NoError(t, err)
+		require.Equal(t, expectedFillType, parsedFillType)
+	}
+	for _, fillType := range []DAOCoinLimitOrderFillTypeString{"", "FILL OR KILL", "GTC"} {
+		_, err := orderFillTypeToUint64(fillType)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must be one of GOOD_TILL_CANCELLED, FILL_OR_KILL, or IMMEDIATE_OR_CANCEL")
+	}
+}
+
+func TestSortDAOCoinLimitOrderResponses(t *testing.T) {
+	// These prices are identical as float64s, so they can only be ordered correctly by comparing the strings exactly
+	responses := []DAOCoinLimitOrderEntryResponse{
+		{OrderID: "c", Price: "1.0000000000000000002", Quantity: "2"},
+		{OrderID: "a", Price: "1.0000000000000000003", Quantity: "1"},
+		{OrderID: "b", Price: "1.0000000000000000001", Quantity: "2"},
+	}
+	getOrderIDs := func() []string {
+		var orderIDs []string
+		for _, response := range responses {
+			orderIDs = append(orderIDs, response.OrderID)
+		}
+		return orderIDs
+	}
+
+	sortDAOCoinLimitOrderResponses(responses, TransactorDAOCoinLimitOrdersSortByPrice, false)
+	require.Equal(t, []string{"b", "c", "a"}, getOrderIDs())
+
+	sortDAOCoinLimitOrderResponses(responses, TransactorDAOCoinLimitOrdersSortByPrice, true)
+	require.Equal(t, []string{"a", "c", "b"}, getOrderIDs())
+
+	// Ties are broken by OrderID
+	sortDAOCoinLimitOrderResponses(responses, TransactorDAOCoinLimitOrdersSortByQuantity, true)
+	require.Equal(t, []string{"b", "c", "a"}, getOrderIDs())
+}
+
+func TestBuildDAOCoinCandles(t *testing.T) {
+	// Builds a trade of 1 DAO coin for priceInNanos $DESO nanos
+	newTrade := func(tstampNanos uint64, priceInNanos uint64) *daoCoinTrade {
+		return &daoCoinTrade{
+			DAOCoinTradeResponse: DAOCoinTradeResponse{
+				Price:       CalculateDisplayUnitsFromBaseUnits(desoPubKeyBase58Check, uint256.NewInt().SetUint64(priceInNanos)),
+				TstampNanos: tstampNanos,
+			},
+			coin1QuantityInBaseUnits: uint256.NewInt().Set(lib.BaseUnitsPerCoin),
+			coin2QuantityInBaseUnits: uint256.NewInt().SetUint64(priceInNanos),
+		}
+	}
+
+	// Trades are passed newest first. The first and last trades fall outside the range.
+	trades := []*daoCoinTrade{
+		newTrade(35, 1e9),
+		newTrade(25, 4e9),
+		newTrade(14, 2e9),
+		newTrade(12, 5e9),
+		newTrade(11, 1e9),
+		newTrade(10, 3e9),
+		newTrade(9, 1e9),
+	}
+	candles, err := buildDAOCoinCandles(daoCoinPubKeyBase58Check, desoPubKeyBase58Check, trades, 10, 30, 10)
+	require.NoError(t, err)
+	require.Equal(t, []DAOCoinCandleResponse{
+		{TStampNanos: 10, Open: "3.0", High: "5.0", Low: "1.0", Close: "2.0", Volume: "4.0", NumTrades: 4},
+		{TStampNanos: 20, Open: "4.0", High: "4.0", Low: "4.0", Close: "4.0", Volume: "1.0", NumTrades: 1},
+	}, candles)
+}
+
+func TestBuildDAOCoinMarketStats(t *testing.T) {
+	require := require.New(t)
+
+	// Builds a trade of 1 DAO coin for priceInNanos $DESO nanos
+	newTrade := func(tstampNanos uint64, priceInNanos uint64) *daoCoinTrade {
+		return &daoCoinTrade{
+			DAOCoinTradeResponse: DAOCoinTradeResponse{
+				Price:       CalculateDisplayUnitsFromBaseUnits(desoPubKeyBase58Check, uint256.NewInt().SetUint64(priceInNanos)),
+				TstampNanos: tstampNanos,
+			},
+			coin1QuantityInBaseUnits: uint256.NewInt().Set(lib.BaseUnitsPerCoin),
+			coin2QuantityInBaseUnits: uint256.NewInt().SetUint64(priceInNanos),
+		}
+	}
+
+	// Trades are passed newest first. The oldest trade is before the window.
+	stats, err := buildDAOCoinMarketStats(daoCoinPubKeyBase58Check, desoPubKeyBase58Check, []*daoCoinTrade{
+		newTrade(40, 3e9),
+		newTrade(30, 5e9),
+		newTrade(20, 1e9),
+		newTrade(10, 2e9),
+		newTrade(9, 9e9),
+	}, 10)
+	require.NoError(err)
+	require.Equal(DAOCoinMarketStatsResponse{
+		LastPrice:             "3.0",
+		High24h:               "5.0",
+		Low24h:                "1.0",
+		PriceChangePercent24h: 50,
+		Volume24h:             "4.0",
+		NumTrades24h:          4,
+	}, stats)
+
+	// A price drop is a negative change.
+	stats, err = buildDAOCoinMarketStats(daoCoinPubKeyBase58Check, desoPubKeyBase58Check, []*daoCoinTrade{
+		newTrade(20, 1e9),
+		newTrade(10, 4e9),
+	}, 10)
+	require.NoError(err)
+	require.Equal(float64(-75), stats.PriceChangePercent24h)
+
+	// With no trades in the window the prices are empty and the volume is zero.
+	stats, err = buildDAOCoinMarketStats(daoCoinPubKeyBase58Check, desoPubKeyBase58Check, []*daoCoinTrade{
+		newTrade(9, 9e9),
+	}, 10)
+	require.NoError(err)
+	require.Equal(DAOCoinMarketStatsResponse{Volume24h: "0.0"}, stats)
+}
+
+func TestDAOCoinLimitOrderTxnsCache(t *testing.T) {
+	require := require.New(t)
+
+	cache := NewDAOCoinLimitOrderTxnsCache()
+	oldBlockHash := &lib.BlockHash{1}
+	newBlockHash := &lib.BlockHash{2}
+	txns := []daoCoinLimitOrderTxn{{TxnHash: &lib.BlockHash{3}}}
+
+	_, found := cache.Get(oldBlockHash)
+	require.False(found)
+	cache.Put(oldBlockHash, 10, txns, 0)
+	cachedTxns, found := cache.Get(oldBlockHash)
+	require.True(found)
+	require.Equal(txns, cachedTxns)
+
+	// Blocks below the min height are evicted, and aren't cached in the first place.
+	cache.Put(newBlockHash, 20, []daoCoinLimitOrderTxn{}, 15)
+	_, found = cache.Get(oldBlockHash)
+	require.False(found)
+	cachedTxns, found = cache.Get(newBlockHash)
+	require.True(found)
+	require.Empty(cachedTxns)
+	cache.Put(oldBlockHash, 10, txns, 15)
+	_, found = cache.Get(oldBlockHash)
+	require.False(found)
+}
+
+func TestDAOCoinLimitOrdersCache(t *testing.T) {
+	require := require.New(t)
+
+	cache := NewDAOCoinLimitOrdersCache(time.Hour)
+	coin1PKID := &lib.PKID{1}
+	coin2PKID := &lib.PKID{2}
+	coin3PKID := &lib.PKID{3}
+	orders := []*lib.DAOCoinLimitOrderEntry{{OrderID: &lib.BlockHash{4}}}
+
+	_, found := cache.Get(coin1PKID, coin2PKID)
+	require.False(found)
+	cache.Put(coin1PKID, coin2PKID, orders)
+	cache.Put(coin2PKID, coin1PKID, orders)
+	cache.Put(coin1PKID, coin3PKID, orders)
+	cachedOrders, found := cache.Get(coin1PKID, coin2PKID)
+	require.True(found)
+	require.Equal(orders, cachedOrders)
+
+	// Deleting a pair removes both directions and nothing else.
+	cache.DeletePair(coin2PKID, coin1PKID)
+	_, found = cache.Get(coin1PKID, coin2PKID)
+	require.False(found)
+	_, found = cache.Get(coin2PKID, coin1PKID)
+	require.False(found)
+	_, found = cache.Get(coin1PKID, coin3PKID)
+	require.True(found)
+
+	// Cancels don't say which pair they're for, so they clear everything. Other txns are ignored.
+	fes := &APIServer{DAOCoinLimitOrdersCache: cache}
+	fes.invalidateDAOCoinLimitOrdersCacheForTxns([]*lib.MsgDeSoTxn{{TxnMeta: &lib.BasicTransferMetadata{}}})
+	_, found = cache.Get(coin1PKID, coin3PKID)
+	require.True(found)
+	fes.invalidateDAOCoinLimitOrdersCacheForTxn(&lib.MsgDeSoTxn{
+		TxnMeta: &lib.DAOCoinLimitOrderMetadata{CancelOrderID: &lib.BlockHash{4}}})
+	_, found = cache.Get(coin1PKID, coin3PKID)
+	require.False(found)
+
+	// Entries expire after the TTL.
+	expiringCache := NewDAOCoinLimitOrdersCache(time.Millisecond)
+	expiringCache.Put(coin1PKID, coin2PKID, orders)
+	time.Sleep(2 * time.Millisecond)
+	_, found = expiringCache.Get(coin1PKID, coin2PKID)
+	require.False(found)
+
+	// Only the limit order txns added since the last check are returned.
+	start := time.Now()
+	limitOrderTxn := &lib.MsgDeSoTxn{TxnMeta: &lib.DAOCoinLimitOrderMetadata{}}
+	poolTxns := []*lib.MempoolTx{
+		{Tx: limitOrderTxn, Added: start},
+		{Tx: &lib.MsgDeSoTxn{TxnMeta: &lib.BasicTransferMetadata{}}, Added: start.Add(time.Second)},
+		{Tx: limitOrderTxn, Added: start.Add(2 * time.Second)},
+	}
+	txns, lastAdded := getDAOCoinLimitOrderTxnsAddedAfter(poolTxns, start)
+	require.Equal([]*lib.MsgDeSoTxn{limitOrderTxn}, txns)
+	require.Equal(start.Add(2*time.Second), lastAdded)
+	txns, lastAdded = getDAOCoinLimitOrderTxnsAddedAfter(poolTxns, lastAdded)
+	require.Empty(txns)
+	require.Equal(start.Add(2*time.Second), lastAdded)
+}
+
+func TestCalculateUSDPricePerCoinToBuy(t *testing.T) {
+	// Buying a DAO coin at 2 $DESO per coin with $DESO at $10
+	require.Equal(t, "20.0000000000000",
+		calculateUSDPricePerCoinToBuy(daoCoinPubKeyBase58Check, desoPubKeyBase58Check, 2, 10))
+	// Selling a DAO coin at 0.5 coins per $DESO with $DESO at $10 prices the DAO coin being sold
+	require.Equal(t, "20.0000000000000",
+		calculateUSDPricePerCoinToBuy(desoPubKeyBase58Check, daoCoinPubKeyBase58Check, 0.5, 10))
+	// An order buying $DESO without an exchange rate has no implied DAO coin price
+	require.Equal(t, "", calculateUSDPricePerCoinToBuy(desoPubKeyBase58Check, daoCoinPubKeyBase58Check, 0, 10))
+	// There's no USD price for DAO coin <> DAO coin orders
+	require.Equal(t, "", calculateUSDPricePerCoinToBuy(daoCoinPubKeyBase58Check, daoCoinPubKeyBase58Check, 2, 10))
+}
+
+func TestBuildDAOCoinLimitOrderResponseExactFields(t *testing.T) {
+	type testCaseType struct {
+		OperationType lib.DAOCoinLimitOrderOperationType
+		Price         string
+		// Whether the response's Price string maps back to exactly the same scaled exchange rate. Prices whose
+		// exchange rate is irrational are rounded, so only the scaled exchange rate string preserves them.
+		PriceRoundTrips bool
+	}
+	testCases := []testCaseType{
+		{lib.DAOCoinLimitOrderOperationTypeBID, "3", true},
+		{lib.DAOCoinLimitOrderOperationTypeBID, "0.005", true},
+		{lib.DAOCoinLimitOrderOperationTypeASK, "20", true},
+		{lib.DAOCoinLimitOrderOperationTypeASK, "3", false},
+	}
+
+	for _, testCase := range testCases {
+		scaledExchangeRate, err := CalculateScaledExchangeRateFromPriceString(
+			daoCoinPubKeyBase58Check, desoPubKeyBase58Check, testCase.Price, testCase.OperationType)
+		require.NoError(t, err)
+		quantityToFillInBaseUnits := uint256.NewInt().SetUint64(123456789012345678)
+
+		order := &lib.DAOCoinLimitOrderEntry{
+			OrderID: &lib.BlockHash{},
+			ScaledExchangeRateCoinsToSellPerCoinToBuy: scaledExchangeRate,
+			QuantityToFillInBaseUnits:                 quantityToFillInBaseUnits,
+			OperationType:                             testCase.OperationType,
+		}
+		response, err := buildDAOCoinLimitOrderResponse("", daoCoinPubKeyBase58Check, desoPubKeyBase58Check, order)
+		require.NoError(t, err)
+
+		// The string fields parse back to the exact values on the order
+		scaledExchangeRateFromResponse, err := parseBaseUnitsString(response.ScaledExchangeRateString)
+		require.NoError(t, err)
+		require.Equal(t, scaledExchangeRate, scaledExchangeRateFromResponse)
+		quantityToFillFromResponse, err := parseBaseUnitsString(response.QuantityToFillBaseUnits)
+		require.NoError(t, err)
+		require.Equal(t, quantityToFillInBaseUnits, quantityToFillFromResponse)
+
+		scaledExchangeRateFromPrice, err := CalculateScaledExchangeRateFromPriceString(
+			daoCoinPubKeyBase58Check, desoPubKeyBase58Check, response.Price, testCase.OperationType)
+		require.NoError(t, err)
+		require.Equal(t, testCase.PriceRoundTrips,
+			response.ScaledExchangeRateString == scaledExchangeRateFromPrice.ToBig().String(), testCase)
+	}
+}
+
+func TestGetDAOCoinLimitOrdersForCoinPairRejectsSameCoin(t *testing.T) {
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{Params: &lib.DeSoTestnetParams}
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+	creatorPubKey := privKey.PubKey().SerializeCompressed()
+	creatorPubKeyBase58Check := lib.PkToString(creatorPubKey, &lib.DeSoTestnetParams)
+	zeroPubKeyBase58Check := lib.PkToString(lib.ZeroPublicKey.ToBytes(), &lib.DeSoTestnetParams)
+
+	// Give the creator a profile so that their coin can also be referred to by username.
+	require.NoError(t, lib.DBPutProfileEntryMappings(db, nil, 0, &lib.ProfileEntry{
+		PublicKey: creatorPubKey,
+		Username:  []byte("creator"),
+	}, lib.PublicKeyToPKID(creatorPubKey), &lib.DeSoTestnetParams))
+	utxoView, err := lib.NewUtxoView(db, &lib.DeSoTestnetParams, nil, nil)
+	require.NoError(t, err)
+
+	for _, coinPair := range [][2]string{
+		// The same creator public key on both sides
+		{creatorPubKeyBase58Check, creatorPubKeyBase58Check},
+		// The same creator username on both sides
+		{"creator", "creator"},
+		// The creator's username on one side and their public key on the other
+		{"creator", creatorPubKeyBase58Check},
+		{creatorPubKeyBase58Check, "creator"},
+		// $DESO passed as the zero public key on one side and DESOCoinIdentifierString on the other
+		{zeroPubKeyBase58Check, desoPubKeyBase58Check},
+		{desoPubKeyBase58Check, zeroPubKeyBase58Check},
+	} {
+		_, err = fes.getDAOCoinLimitOrdersForCoinPair(utxoView, &GetDAOCoinLimitOrdersRequest{
+			DAOCoin1CreatorPublicKeyBase58Check: coinPair[0],
+			DAOCoin2CreatorPublicKeyBase58Check: coinPair[1],
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "refer to the same coin")
+	}
+
+	// A username is accepted for a pair of different coins.
+	res, err := fes.getDAOCoinLimitOrdersForCoinPair(utxoView, &GetDAOCoinLimitOrdersRequest{
+		DAOCoin1CreatorPublicKeyBase58Check: "creator",
+		DAOCoin2CreatorPublicKeyBase58Check: desoPubKeyBase58Check,
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.Orders)
+
+	// A username without a profile isn't mistaken for the same coin.
+	_, err = fes.getDAOCoinLimitOrdersForCoinPair(utxoView, &GetDAOCoinLimitOrdersRequest{
+		DAOCoin1CreatorPublicKeyBase58Check: "nobody",
+		DAOCoin2CreatorPublicKeyBase58Check: "nobody",
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid DAOCoin1CreatorPublicKeyBase58Check")
+}
+
+func TestConvertDAOCoinPrice(t *testing.T) {
+	fes := &APIServer{}
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+	creatorPubKeyBase58Check := lib.PkToString(privKey.PubKey().SerializeCompressed(), &lib.DeSoTestnetParams)
+
+	convertDAOCoinPrice := func(requestData ConvertDAOCoinPriceRequest) (*httptest.ResponseRecorder, ConvertDAOCoinPriceResponse) {
+		bodyBytes, err := json.Marshal(requestData)
+		require.NoError(t, err)
+		request := httptest.NewRequest("POST", RoutePathConvertDAOCoinPrice, bytes.NewReader(bodyBytes))
+		recorder := httptest.NewRecorder()
+		fes.ConvertDAOCoinPrice(recorder, request)
+		res := ConvertDAOCoinPriceResponse{}
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		}
+		return recorder, res
+	}
+
+	type testCaseType struct {
+		SellingCoin                 string
+		OperationType               DAOCoinLimitOrderOperationTypeString
+		Price                       string
+		ExpectedScaledExchangeRate  string
+		ExpectedPriceFromScaledRate string
+	}
+
+	// The same DAO coin <-> DAO coin cases as TestCalculateScaledExchangeRateFromPriceString, plus a couple with $DESO
+	// as the coin to sell. Converting the scaled exchange rate back gives the same price, except for an ASK at an
+	// irrational exchange rate, whose scaled exchange rate is rounded up.
+	testCases := []testCaseType{
+		{creatorPubKeyBase58Check, "BID", "1", "100000000000000000000000000000000000000", "1.0"},
+		{creatorPubKeyBase58Check, "ASK", "1", "100000000000000000000000000000000000000", "1.0"},
+		{creatorPubKeyBase58Check, "BID", "20", "2000000000000000000000000000000000000000", "20.0"},
+		{creatorPubKeyBase58Check, "ASK", "20", "5000000000000000000000000000000000000", "20.0"},
+		{creatorPubKeyBase58Check, "BID", "3", "300000000000000000000000000000000000000", "3.0"},
+		{
+			creatorPubKeyBase58Check, "ASK", "3", "33333333333333333333333333333333333334",
+			"2.99999999999999999999999999999999999994",
+		},
+		{creatorPubKeyBase58Check, "BID", "0.005", "500000000000000000000000000000000000", "0.005"},
+		{creatorPubKeyBase58Check, "ASK", ".005", "20000000000000000000000000000000000000000", "0.005"},
+		{creatorPubKeyBase58Check, "BID", "0.00000000000000000000000000000000000001", "1", "0.00000000000000000000000000000000000001"},
+		{desoPubKeyBase58Check, "BID", "1", "100000000000000000000000000000", "1.0"},
+		{desoPubKeyBase58Check, "ASK", "20", "5000000000000000000000000000", "20.0"},
+	}
+
+	for _, testCase := range testCases {
+		requestData := ConvertDAOCoinPriceRequest{
+			BuyingDAOCoinCreatorPublicKeyBase58Check:  creatorPubKeyBase58Check,
+			SellingDAOCoinCreatorPublicKeyBase58Check: testCase.SellingCoin,
+			OperationType: testCase.OperationType,
+			Price:         testCase.Price,
+		}
+		recorder, res := convertDAOCoinPrice(requestData)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		require.Equal(t, testCase.ExpectedScaledExchangeRate, res.ScaledExchangeRateCoinsToSellPerCoinToBuy)
+		require.Equal(t, testCase.ExpectedPriceFromScaledRate, res.Price)
+
+		// Converting from the scaled exchange rate and from the float should give the same representations back
+		requestData.Price = ""
+		requestData.ScaledExchangeRateCoinsToSellPerCoinToBuy = res.ScaledExchangeRateCoinsToSellPerCoinToBuy
+		recorder, resFromScaled := convertDAOCoinPrice(requestData)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		require.Equal(t, res, resFromScaled)
+	}
+
+	// A float exchange rate is always coins to sell per coin to buy, regardless of operation type
+	recorder, res := convertDAOCoinPrice(ConvertDAOCoinPriceRequest{
+		BuyingDAOCoinCreatorPublicKeyBase58Check:  creatorPubKeyBase58Check,
+		SellingDAOCoinCreatorPublicKeyBase58Check: desoPubKeyBase58Check,
+		OperationType:                       "ASK",
+		ExchangeRateCoinsToSellPerCoinToBuy: 0.05,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, "5000000000000000000000000000", res.ScaledExchangeRateCoinsToSellPerCoinToBuy)
+	require.Equal(t, "20.0", res.Price)
+	require.Equal(t, 0.05, res.ExchangeRateCoinsToSellPerCoinToBuy)
+
+	errorTestCases := []ConvertDAOCoinPriceRequest{
+		// Invalid prices, as in TestCalculateScaledExchangeRateFromPriceString
+		{Price: "0.000000000000000000000000000000000000001"},
+		{Price: "10000000000000000000000000000000000000000"},
+		{Price: "-1"},
+		{Price: "a.2"},
+		// Invalid scaled exchange rates
+		{ScaledExchangeRateCoinsToSellPerCoinToBuy: "0"},
+		{ScaledExchangeRateCoinsToSellPerCoinToBuy: "-1"},
+		{ScaledExchangeRateCoinsToSellPerCoinToBuy: "1.5"},
+		{ScaledExchangeRateCoinsToSellPerCoinToBuy: "1000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		// Zero or more than one input
+		{},
+		{Price: "1", ExchangeRateCoinsToSellPerCoinToBuy: 1},
+		{Price: "1", ScaledExchangeRateCoinsToSellPerCoinToBuy: "1"},
+	}
+	for _, requestData := range errorTestCases {
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check = creatorPubKeyBase58Check
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check = desoPubKeyBase58Check
+		requestData.OperationType = "BID"
+		recorder, _ = convertDAOCoinPrice(requestData)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, requestData)
+	}
+
+	// Invalid coin pairs and operation types
+	for _, requestData := range []ConvertDAOCoinPriceRequest{
+		{BuyingDAOCoinCreatorPublicKeyBase58Check: desoPubKeyBase58Check, SellingDAOCoinCreatorPublicKeyBase58Check: desoPubKeyBase58Check, OperationType: "BID"},
+		{BuyingDAOCoinCreatorPublicKeyBase58Check: daoCoinPubKeyBase58Check, SellingDAOCoinCreatorPublicKeyBase58Check: desoPubKeyBase58Check, OperationType: "BID"},
+		{BuyingDAOCoinCreatorPublicKeyBase58Check: creatorPubKeyBase58Check, SellingDAOCoinCreatorPublicKeyBase58Check: desoPubKeyBase58Check, OperationType: "BUY"},
+	} {
+		requestData.Price = "1"
+		recorder, _ = convertDAOCoinPrice(requestData)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, requestData)
+	}
+}
+
+func TestNormalizeCoinIdentifier(t *testing.T) {
+	desoAliases := []string{DESOCoinIdentifierString, "$DESO", ""}
+	for _, desoAlias := range desoAliases {
+		require.Equal(t, "", normalizeCoinIdentifier(desoAlias))
+		require.True(t, isDESOCoinIdentifier(desoAlias))
+
+		// Every alias picks the $DESO side of the pair when scaling exchange rates and quantities
+		require.True(t, isCoinToFillDESO(desoAlias, daoCoinPubKeyBase58Check, DAOCoinLimitOrderOperationTypeStringBID))
+		require.False(t, isCoinToFillDESO(desoAlias, daoCoinPubKeyBase58Check, DAOCoinLimitOrderOperationTypeStringASK))
+		require.True(t, isCoinToFillDESO(daoCoinPubKeyBase58Check, desoAlias, DAOCoinLimitOrderOperationTypeStringASK))
+
+		scaledExchangeRate, err := CalculateScaledExchangeRateFromPriceString(
+			daoCoinPubKeyBase58Check, desoAlias, "1", lib.DAOCoinLimitOrderOperationTypeBID)
+		require.NoError(t, err)
+		require.Equal(t, "100000000000000000000000000000", scaledExchangeRate.ToBig().String())
+
+		quantityInBaseUnits, err := CalculateQuantityToFillAsBaseUnits(
+			daoCoinPubKeyBase58Check, desoAlias, DAOCoinLimitOrderOperationTypeStringASK, "1")
+		require.NoError(t, err)
+		require.Equal(t, uint256.NewInt().SetUint64(lib.NanosPerUnit), quantityInBaseUnits)
+	}
+
+	for _, coinIdentifier := range []string{daoCoinPubKeyBase58Check, "deso", "$deso", " DESO"} {
+		require.Equal(t, coinIdentifier, normalizeCoinIdentifier(coinIdentifier))
+		require.False(t, isDESOCoinIdentifier(coinIdentifier))
+	}
+
+	// The aliases are all the same coin, so they can't be traded for each other
+	fes := &APIServer{}
+	for _, desoAlias := range desoAliases {
+		_, _, err := fes.getBuyingAndSellingDAOCoinPublicKeys(DESOCoinIdentifierString, desoAlias)
+		require.Error(t, err)
+	}
+}
+
+func TestGetDAOCoinLimitOrdersBatchResultKey(t *testing.T) {
+	require := require.New(t)
+
+	coinPair := &GetDAOCoinLimitOrdersRequest{
+		DAOCoin1CreatorPublicKeyBase58Check: "coin1",
+		DAOCoin2CreatorPublicKeyBase58Check: DESOCoinIdentifierString,
+	}
+	require.Equal("coin1/DESO", getDAOCoinLimitOrdersBatchResultKey(coinPair))
+
+	// Pages of the same pair get different keys.
+	coinPair.Limit = 10
+	require.Equal("coin1/DESO?Limit=10", getDAOCoinLimitOrdersBatchResultKey(coinPair))
+	coinPair.Offset = "order1"
+	coinPair.IncludeUSDPrices = true
+	require.Equal("coin1/DESO?Limit=10&Offset=order1&IncludeUSDPrices=true",
+		getDAOCoinLimitOrdersBatchResultKey(coinPair))
+}
+
+func TestDAOCoinLimitOrdersOffset(t *testing.T) {
+	require := require.New(t)
+
+	newOrder := func(scaledExchangeRate uint64, orderIDByte byte) *lib.DAOCoinLimitOrderEntry {
+		return &lib.DAOCoinLimitOrderEntry{
+			OrderID: &lib.BlockHash{orderIDByte},
+			ScaledExchangeRateCoinsToSellPerCoinToBuy: uint256.NewInt().SetUint64(scaledExchangeRate),
+		}
+	}
+	orders := []*lib.DAOCoinLimitOrderEntry{newOrder(1, 3), newOrder(2, 2), newOrder(2, 1), newOrder(1, 4)}
+	sortDAOCoinLimitOrdersForPagination(orders)
+	require.Equal(&lib.BlockHash{1}, orders[0].OrderID)
+	require.Equal(&lib.BlockHash{2}, orders[1].OrderID)
+	require.Equal(&lib.BlockHash{3}, orders[2].OrderID)
+
+	offset := &daoCoinLimitOrdersOffset{
+		isBuyingCoin2:      true,
+		scaledExchangeRate: orders[1].ScaledExchangeRateCoinsToSellPerCoinToBuy,
+		orderID:            orders[1].OrderID,
+	}
+	parsedOffset, err := parseDAOCoinLimitOrdersOffset(offset.String())
+	require.NoError(err)
+	require.Equal(offset, parsedOffset)
+
+	// The offset is before the orders after it on its side, but not the order it was taken from, even if that
+	// order has since been removed.
+	require.False(parsedOffset.isBefore(true, orders[0]))
+	require.False(parsedOffset.isBefore(true, orders[1]))
+	require.True(parsedOffset.isBefore(true, orders[2]))
+	require.True(parsedOffset.isBefore(true, orders[3]))
+	// Orders buying DAOCoin1 all come before orders buying DAOCoin2.
+	require.False(parsedOffset.isBefore(false, orders[3]))
+
+	for _, invalidOffset := range []string{"", "order1", "3:1:" + orders[0].OrderID.String(), "1:-1:00", "1:1:zz"} {
+		_, err = parseDAOCoinLimitOrdersOffset(invalidOffset)
+		require.Error(err)
+	}
+}
+
+func TestDAOCoinOrderBookFeedLimits(t *testing.T) {
+	require := require.New(t)
+
+	chain, params, _ := NewLowDifficultyBlockchain()
+	mempool, _ := NewTestMiner(t, chain, params, true /*isSender*/)
+	fes := &APIServer{Config: &config.Config{}, Params: params, mempool: mempool}
+	fes.daoCoinOrderBookFeed = newDAOCoinOrderBookFeed(fes)
+	server := httptest.NewServer(http.HandlerFunc(fes.GetDAOCoinOrderBookFeed))
+	defer server.Close()
+
+	dial := func() *websocket.Conn {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+		require.NoError(err)
+		return ws
+	}
+	receive := func(ws *websocket.Conn) *DAOCoinOrderBookFeedMessage {
+		require.NoError(ws.SetReadDeadline(time.Now().Add(5 * time.Second)))
+		msg := &DAOCoinOrderBookFeedMessage{}
+		require.NoError(websocket.JSON.Receive(ws, msg))
+		return msg
+	}
+	newPubKeyBase58Check := func() string {
+		privKey, err := btcec.NewPrivateKey(btcec.S256())
+		require.NoError(err)
+		return lib.PkToString(privKey.PubKey().SerializeCompressed(), params)
+	}
+	newSubscribe := func(daoCoinPubKeyBase58Check string) *DAOCoinOrderBookFeedControlMessage {
+		return &DAOCoinOrderBookFeedControlMessage{
+			Type:                                DAOCoinOrderBookFeedControlSubscribe,
+			DAOCoin1CreatorPublicKeyBase58Check: daoCoinPubKeyBase58Check,
+			DAOCoin2CreatorPublicKeyBase58Check: desoPubKeyBase58Check,
+		}
+	}
+
+	// Connections over the cap get an error and are closed.
+	fes.daoCoinOrderBookFeed.mtx.Lock()
+	fes.daoCoinOrderBookFeed.numConns = MaxDAOCoinOrderBookFeedConnections
+	fes.daoCoinOrderBookFeed.mtx.Unlock()
+	ws := dial()
+	msg := receive(ws)
+	require.Equal(DAOCoinOrderBookFeedMessageError, msg.Type)
+	require.Contains(msg.Error, "max of")
+	require.Error(websocket.JSON.Receive(ws, &DAOCoinOrderBookFeedMessage{}))
+	ws.Close()
+	fes.daoCoinOrderBookFeed.mtx.Lock()
+	fes.daoCoinOrderBookFeed.numConns = 0
+	fes.daoCoinOrderBookFeed.mtx.Unlock()
+
+	// Once the feed is watching the max number of pairs, only pairs it's already watching can be subscribed to.
+	ws = dial()
+	defer ws.Close()
+	watchedPubKeyBase58Check := newPubKeyBase58Check()
+	require.NoError(websocket.JSON.Send(ws, newSubscribe(watchedPubKeyBase58Check)))
+	require.Equal(DAOCoinOrderBookFeedMessageSnapshot, receive(ws).Type)
+	fes.daoCoinOrderBookFeed.mtx.Lock()
+	for ii := 1; ii < MaxDAOCoinOrderBookFeedPairs; ii++ {
+		fes.daoCoinOrderBookFeed.orderBooks[newDAOCoinPair(fmt.Sprintf("pair%d", ii), "")] = &daoCoinOrderBookSnapshot{}
+	}
+	fes.daoCoinOrderBookFeed.mtx.Unlock()
+	require.NoError(websocket.JSON.Send(ws, newSubscribe(newPubKeyBase58Check())))
+	msg = receive(ws)
+	require.Equal(DAOCoinOrderBookFeedMessageError, msg.Type)
+	require.Contains(msg.Error, "already watching")
+	require.NoError(websocket.JSON.Send(ws, newSubscribe(watchedPubKeyBase58Check)))
+	require.Equal(DAOCoinOrderBookFeedMessageSnapshot, receive(ws).Type)
+
+	// Control messages over the per-connection rate limit get an error. Unsubscribes don't get a reply otherwise.
+	time.Sleep(daoCoinOrderBookFeedControlMessageWindow)
+	for ii := 0; ii <= daoCoinOrderBookFeedMaxControlMessagesPerWindow; ii++ {
+		require.NoError(websocket.JSON.Send(ws, &DAOCoinOrderBookFeedControlMessage{
+			Type:                                DAOCoinOrderBookFeedControlUnsubscribe,
+			DAOCoin1CreatorPublicKeyBase58Check: newPubKeyBase58Check(),
+			DAOCoin2CreatorPublicKeyBase58Check: desoPubKeyBase58Check,
+		}))
+	}
+	msg = receive(ws)
+	require.Equal(DAOCoinOrderBookFeedMessageError, msg.Type)
+	require.Contains(msg.Error, "control messages per")
+}
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"sync"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+)
+
+// DAOCoinLimitOrderTxnsCache caches the DAO coin limit order txns in each block that getDAOCoinTrades has scanned,
+// along with their txindex metadata. Trades, candles, and market stats all rescan up to a week of blocks, and reading
+// each block and its txindex entries is most of that work. A block's txns never change once it's mined, so entries
+// don't expire. Instead, blocks more than daoCoinCandlesLookbackBlocks below the tip are evicted since no scan reaches
+// them.
+type DAOCoinLimitOrderTxnsCache struct {
+	mtx     sync.RWMutex
+	entries map[lib.BlockHash]*daoCoinLimitOrderTxnsCacheEntry
+}
+
+// daoCoinLimitOrderTxn is a DAO coin limit order txn and its txindex metadata.
+type daoCoinLimitOrderTxn struct {
+	TxnHash *lib.BlockHash
+	TxnMeta *lib.TransactionMetadata
+}
+
+type daoCoinLimitOrderTxnsCacheEntry struct {
+	BlockHeight uint32
+	Txns        []daoCoinLimitOrderTxn
+}
+
+func NewDAOCoinLimitOrderTxnsCache() *DAOCoinLimitOrderTxnsCache {
+	return &DAOCoinLimitOrderTxnsCache{
+		entries: make(map[lib.BlockHash]*daoCoinLimitOrderTxnsCacheEntry),
+	}
+}
+
+// Get returns the cached txns for the block. The slice is shared and must not be modified.
+func (cache *DAOCoinLimitOrderTxnsCache) Get(blockHash *lib.BlockHash) (_txns []daoCoinLimitOrderTxn, _found bool) {
+	cache.mtx.RLock()
+	defer cache.mtx.RUnlock()
+
+	entry, exists := cache.entries[*blockHash]
+	if !exists {
+		return nil, false
+	}
+	return entry.Txns, true
+}
+
+// Put caches the txns for the block, and evicts every block below minBlockHeight.
+func (cache *DAOCoinLimitOrderTxnsCache) Put(
+	blockHash *lib.BlockHash, blockHeight uint32, txns []daoCoinLimitOrderTxn, minBlockHeight uint32) {
+
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	for key, entry := range cache.entries {
+		if entry.BlockHeight < minBlockHeight {
+			delete(cache.entries, key)
+		}
+	}
+	if blockHeight < minBlockHeight {
+		return
+	}
+	cache.entries[*blockHash] = &daoCoinLimitOrderTxnsCacheEntry{
+		BlockHeight: blockHeight,
+		Txns:        txns,
+	}
+}
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/golang/glog"
+)
+
+// DAOCoinLimitOrdersCache is a short-lived, in-memory cache of the open orders buying one coin with another, as
+// returned by GetAllDAOCoinLimitOrdersForThisDAOCoinPair. Popular pairs are read on nearly every order book request,
+// so caching them for a few seconds takes a lot of load off the view. Entries are considered stale after the
+// configured TTL, and both directions of a pair are invalidated whenever this node broadcasts a limit order for it,
+// and within daoCoinLimitOrdersCacheInvalidationInterval of a limit order for it relayed by a peer reaching the
+// mempool or a new block being mined.
+type DAOCoinLimitOrdersCache struct {
+	mtx     sync.RWMutex
+	ttl     time.Duration
+	entries map[daoCoinLimitOrdersCacheKey]*daoCoinLimitOrdersCacheEntry
+}
+
+type daoCoinLimitOrdersCacheKey struct {
+	BuyingDAOCoinCreatorPKID  lib.PKID
+	SellingDAOCoinCreatorPKID lib.PKID
+}
+
+type daoCoinLimitOrdersCacheEntry struct {
+	Orders          []*lib.DAOCoinLimitOrderEntry
+	ExpiresAtTstamp time.Time
+}
+
+func NewDAOCoinLimitOrdersCache(ttl time.Duration) *DAOCoinLimitOrdersCache {
+	return &DAOCoinLimitOrdersCache{
+		ttl:     ttl,
+		entries: make(map[daoCoinLimitOrdersCacheKey]*daoCoinLimitOrdersCacheEntry),
+	}
+}
+
+// Get returns the cached orders buying buyingPKID with sellingPKID, if there is an entry that has not expired yet.
+// The returned slice is a copy so callers can sort it, but the orders themselves are shared and must not be modified.
+func (cache *DAOCoinLimitOrdersCache) Get(
+	buyingPKID *lib.PKID, sellingPKID *lib.PKID) (_orders []*lib.DAOCoinLimitOrderEntry, _found bool) {
+
+	cache.mtx.RLock()
+	defer cache.mtx.RUnlock()
+
+	entry, exists := cache.entries[daoCoinLimitOrdersCacheKey{*buyingPKID, *sellingPKID}]
+	if !exists || time.Now().After(entry.ExpiresAtTstamp) {
+		return nil, false
+	}
+	return append([]*lib.DAOCoinLimitOrderEntry{}, entry.Orders...), true
+}
+
+func (cache *DAOCoinLimitOrdersCache) Put(
+	buyingPKID *lib.PKID, sellingPKID *lib.PKID, orders []*lib.DAOCoinLimitOrderEntry) {
+
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	// Sweep out expired entries on write so the map doesn't grow without bound.
+	now := time.Now()
+	for key, entry := range cache.entries {
+		if now.After(entry.ExpiresAtTstamp) {
+			delete(cache.entries, key)
+		}
+	}
+
+	cache.entries[daoCoinLimitOrdersCacheKey{*buyingPKID, *sellingPKID}] = &daoCoinLimitOrdersCacheEntry{
+		Orders:          append([]*lib.DAOCoinLimitOrderEntry{}, orders...),
+		ExpiresAtTstamp: now.Add(cache.ttl),
+	}
+}
+
+// DeletePair removes both directions of a pair, since an order for one side can fill orders on the other.
+func (cache *DAOCoinLimitOrdersCache) DeletePair(coin1PKID *lib.PKID, coin2PKID *lib.PKID) {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	delete(cache.entries, daoCoinLimitOrdersCacheKey{*coin1PKID, *coin2PKID})
+	delete(cache.entries, daoCoinLimitOrdersCacheKey{*coin2PKID, *coin1PKID})
+}
+
+// Clear removes all entries from the cache.
+func (cache *DAOCoinLimitOrdersCache) Clear() {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	cache.entries = make(map[daoCoinLimitOrdersCacheKey]*daoCoinLimitOrdersCacheEntry)
+}
+
+// How often the mempool and block tip are checked for changes that invalidate the DAOCoinLimitOrdersCache.
+const daoCoinLimitOrdersCacheInvalidationInterval = 1 * time.Second
+
+// invalidateDAOCoinLimitOrdersCacheForTxn drops the cached orders a DAO coin limit order txn broadcast by this node
+// can change.
+func (fes *APIServer) invalidateDAOCoinLimitOrdersCacheForTxn(txn *lib.MsgDeSoTxn) {
+	fes.invalidateDAOCoinLimitOrdersCacheForTxns([]*lib.MsgDeSoTxn{txn})
+}
+
+// invalidateDAOCoinLimitOrdersCacheForTxns drops the cached orders the DAO coin limit order txns in txns can change.
+// The pair a cancelled order belongs to isn't in the txn, so cancels clear the whole cache.
+func (fes *APIServer) invalidateDAOCoinLimitOrdersCacheForTxns(txns []*lib.MsgDeSoTxn) {
+	if fes.DAOCoinLimitOrdersCache == nil {
+		return
+	}
+	var txnMetas []*lib.DAOCoinLimitOrderMetadata
+	for _, txn := range txns {
+		if txn.TxnMeta.GetTxnType() != lib.TxnTypeDAOCoinLimitOrder {
+			continue
+		}
+		txnMeta := txn.TxnMeta.(*lib.DAOCoinLimitOrderMetadata)
+		if txnMeta.CancelOrderID != nil {
+			fes.DAOCoinLimitOrdersCache.Clear()
+			return
+		}
+		txnMetas = append(txnMetas, txnMeta)
+	}
+	if len(txnMetas) == 0 {
+		return
+	}
+
+	utxoView, err := fes.mempool.GetAugmentedUniversalView()
+	if err != nil {
+		glog.Errorf("invalidateDAOCoinLimitOrdersCacheForTxns: Problem fetching utxoView, clearing cache: %v", err)
+		fes.DAOCoinLimitOrdersCache.Clear()
+		return
+	}
+	for _, txnMeta := range txnMetas {
+		buyingPKID := utxoView.GetPKIDForPublicKey(txnMeta.BuyingDAOCoinCreatorPublicKey.ToBytes()).PKID
+		sellingPKID := utxoView.GetPKIDForPublicKey(txnMeta.SellingDAOCoinCreatorPublicKey.ToBytes()).PKID
+		fes.DAOCoinLimitOrdersCache.DeletePair(buyingPKID, sellingPKID)
+	}
+}
+
+// getDAOCoinLimitOrderTxnsAddedAfter returns the DAO coin limit order txns in poolTxns, which must be ordered by time
+// added, that were added after addedAfter, along with the time the newest txn in poolTxns was added.
+func getDAOCoinLimitOrderTxnsAddedAfter(poolTxns []*lib.MempoolTx, addedAfter time.Time) (
+	_txns []*lib.MsgDeSoTxn, _lastAdded time.Time) {
+
+	lastAdded := addedAfter
+	var txns []*lib.MsgDeSoTxn
+	for ii := len(poolTxns) - 1; ii >= 0 && poolTxns[ii].Added.After(addedAfter); ii-- {
+		if poolTxns[ii].Added.After(lastAdded) {
+			lastAdded = poolTxns[ii].Added
+		}
+		if poolTxns[ii].Tx.TxnMeta.GetTxnType() == lib.TxnTypeDAOCoinLimitOrder {
+			txns = append(txns, poolTxns[ii].Tx)
+		}
+	}
+	return txns, lastAdded
+}
+
+// StartDAOCoinLimitOrdersCacheInvalidation watches for DAO coin limit order txns that reach the mempool without
+// being broadcast by this node, e.g. ones relayed by peers, and drops the cached orders they can change. A new block
+// can include orders this node never saw in its mempool and evicts mempool txns, so it clears the whole cache.
+func (fes *APIServer) StartDAOCoinLimitOrdersCacheInvalidation() {
+	go func() {
+		lastAdded := time.Now()
+		lastTipHash := fes.blockchain.BlockTip().Hash
+	out:
+		for {
+			select {
+			case <-time.After(daoCoinLimitOrdersCacheInvalidationInterval):
+				if tipHash := fes.blockchain.BlockTip().Hash; *tipHash != *lastTipHash {
+					lastTipHash = tipHash
+					fes.DAOCoinLimitOrdersCache.Clear()
+				}
+
+				poolTxns, _, err := fes.mempool.GetTransactionsOrderedByTimeAdded()
+				if err != nil {
+					glog.Errorf("StartDAOCoinLimitOrdersCacheInvalidation: Problem getting mempool txns, "+
+						"clearing cache: %v", err)
+					fes.DAOCoinLimitOrdersCache.Clear()
+					continue
+				}
+				var txns []*lib.MsgDeSoTxn
+				txns, lastAdded = getDAOCoinLimitOrderTxnsAddedAfter(poolTxns, lastAdded)
+				fes.invalidateDAOCoinLimitOrdersCacheForTxns(txns)
+			case <-fes.quit:
+				break out
+			}
+		}
+	}()
+}
+
+// getAllDAOCoinLimitOrdersForThisDAOCoinPair wraps the view's GetAllDAOCoinLimitOrdersForThisDAOCoinPair with the
+// DAOCoinLimitOrdersCache, if it's enabled.
+func (fes *APIServer) getAllDAOCoinLimitOrdersForThisDAOCoinPair(
+	utxoView *lib.UtxoView, buyingPKID *lib.PKID, sellingPKID *lib.PKID) ([]*lib.DAOCoinLimitOrderEntry, error) {
+
+	if fes.DAOCoinLimitOrdersCache == nil {
+		return utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(buyingPKID, sellingPKID)
+	}
+	if orders, found := fes.DAOCoinLimitOrdersCache.Get(buyingPKID, sellingPKID); found {
+		return orders, nil
+	}
+	orders, err := utxoView.GetAllDAOCoinLimitOrdersForThisDAOCoinPair(buyingPKID, sellingPKID)
+	if err != nil {
+		return nil, err
+	}
+	fes.DAOCoinLimitOrdersCache.Put(buyingPKID, sellingPKID, orders)
+	return orders, nil
+}
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+	"golang.org/x/sync/singleflight"
+)
+
+// DAOCoinMarketStatsCache is a short-lived, in-memory cache of the stats GetDAOCoinMarketStats computes for each coin
+// pair. Computing them means scanning a day of blocks for trades, so a markets page that polls many pairs would
+// otherwise redo that scan on every request. Entries are keyed by the pair's PKIDs and are considered stale after the
+// configured TTL.
+type DAOCoinMarketStatsCache struct {
+	mtx     sync.RWMutex
+	ttl     time.Duration
+	entries map[daoCoinPKIDPair]*daoCoinMarketStatsCacheEntry
+
+	// Collapses concurrent scans for the same set of pairs into one. See getDAOCoinMarketStatsForQueries.
+	computeGroup singleflight.Group
+}
+
+// daoCoinPKIDPair identifies a market by its coins' PKIDs, with lib.ZeroPKID for $DESO.
+type daoCoinPKIDPair struct {
+	Coin1PKID lib.PKID
+	Coin2PKID lib.PKID
+}
+
+type daoCoinMarketStatsCacheEntry struct {
+	Stats           DAOCoinMarketStatsResponse
+	ExpiresAtTstamp time.Time
+}
+
+func NewDAOCoinMarketStatsCache(ttl time.Duration) *DAOCoinMarketStatsCache {
+	return &DAOCoinMarketStatsCache{
+		ttl:     ttl,
+		entries: make(map[daoCoinPKIDPair]*daoCoinMarketStatsCacheEntry),
+	}
+}
+
+// Get returns the cached stats for the pair, if there is an entry that has not expired yet.
+func (cache *DAOCoinMarketStatsCache) Get(pair daoCoinPKIDPair) (_stats DAOCoinMarketStatsResponse, _found bool) {
+	cache.mtx.RLock()
+	defer cache.mtx.RUnlock()
+
+	entry, exists := cache.entries[pair]
+	if !exists || time.Now().After(entry.ExpiresAtTstamp) {
+		return DAOCoinMarketStatsResponse{}, false
+	}
+	return entry.Stats, true
+}
+
+func (cache *DAOCoinMarketStatsCache) Put(pair daoCoinPKIDPair, stats DAOCoinMarketStatsResponse) {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	// Sweep out expired entries on write so the map doesn't grow without bound.
+	now := time.Now()
+	for key, entry := range cache.entries {
+		if now.After(entry.ExpiresAtTstamp) {
+			delete(cache.entries, key)
+		}
+	}
+
+	cache.entries[pair] = &daoCoinMarketStatsCacheEntry{
+		Stats:           stats,
+		ExpiresAtTstamp: now.Add(cache.ttl),
+	}
+}
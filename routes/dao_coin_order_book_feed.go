@@ -0,0 +1,422 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/golang/glog"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// MaxDAOCoinOrderBookFeedSubscriptions is the max number of coin pairs a single feed connection can subscribe to.
+	MaxDAOCoinOrderBookFeedSubscriptions = 20
+	// MaxDAOCoinOrderBookFeedConnections is the max number of feed connections open at once across all clients.
+	MaxDAOCoinOrderBookFeedConnections = 1000
+	// MaxDAOCoinOrderBookFeedPairs is the max number of distinct coin pairs watched at once across all connections,
+	// since every one of them is re-read on each poll.
+	MaxDAOCoinOrderBookFeedPairs = 500
+
+	// How often subscribed order books are re-read from the mempool's augmented view and diffed.
+	daoCoinOrderBookFeedPollInterval = 2 * time.Second
+	// The number of messages buffered for a connection. A client that falls this far behind is disconnected
+	// rather than holding up updates for everyone else.
+	daoCoinOrderBookFeedSendBufferSize = 64
+	// Control messages are tiny, so there's no reason to accept large frames.
+	daoCoinOrderBookFeedMaxMessageBytes = 4096
+	// Each subscribe reads a whole order book, so a connection can only send this many control messages per
+	// daoCoinOrderBookFeedControlMessageWindow. Messages over the limit get an error and are otherwise ignored.
+	daoCoinOrderBookFeedMaxControlMessagesPerWindow = 10
+	daoCoinOrderBookFeedControlMessageWindow        = time.Second
+)
+
+const (
+	DAOCoinOrderBookFeedControlSubscribe   = "subscribe"
+	DAOCoinOrderBookFeedControlUnsubscribe = "unsubscribe"
+
+	DAOCoinOrderBookFeedMessageSnapshot = "snapshot"
+	DAOCoinOrderBookFeedMessageUpdate   = "update"
+	DAOCoinOrderBookFeedMessageError    = "error"
+)
+
+// DAOCoinOrderBookFeedControlMessage is sent by the client to subscribe to or unsubscribe from a coin pair. The coins
+// are identified the same way as in GetDAOCoinLimitOrdersRequest. Every way of referring to $DESO is treated as the
+// same coin, and is reported back as an empty string in DAOCoinOrderBookFeedMessage.
+type DAOCoinOrderBookFeedControlMessage struct {
+	// Either "subscribe" or "unsubscribe".
+	Type string `safeForLogging:"true"`
+
+	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+}
+
+// DAOCoinOrderBookFeedMessage is sent by the server. After subscribing, the client gets a "snapshot" with every open
+// order for the pair in AddedOrders, followed by an "update" whenever the order book changes. Clients should upsert
+// AddedOrders by OrderID, since partially filled orders are re-sent with their remaining quantity. An "error" reports
+// a control message that couldn't be applied; the connection stays open.
+type DAOCoinOrderBookFeedMessage struct {
+	Type string
+
+	DAOCoin1CreatorPublicKeyBase58Check string
+	DAOCoin2CreatorPublicKeyBase58Check string
+
+	// New orders and orders that were partially filled, in the same order GetDAOCoinLimitOrders returns them.
+	AddedOrders []DAOCoinLimitOrderEntryResponse
+	// The OrderIDs of orders that were filled or cancelled.
+	RemovedOrderIDs []string
+
+	Error string
+}
+
+type daoCoinPair struct {
+	DAOCoin1CreatorPublicKeyBase58Check string
+	DAOCoin2CreatorPublicKeyBase58Check string
+}
+
+type daoCoinOrderBookSnapshot struct {
+	orders     []DAOCoinLimitOrderEntryResponse
+	ordersByID map[string]DAOCoinLimitOrderEntryResponse
+}
+
+type daoCoinOrderBookFeedConn struct {
+	send chan *DAOCoinOrderBookFeedMessage
+	// Closed when the connection should be torn down.
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// Guarded by the feed's mtx.
+	subscriptions map[daoCoinPair]struct{}
+}
+
+func (conn *daoCoinOrderBookFeedConn) close() {
+	conn.closeOnce.Do(func() { close(conn.done) })
+}
+
+// enqueue never blocks. If the connection's buffer is full it's closed instead.
+func (conn *daoCoinOrderBookFeedConn) enqueue(msg *DAOCoinOrderBookFeedMessage) {
+	select {
+	case conn.send <- msg:
+	default:
+		conn.close()
+	}
+}
+
+// daoCoinOrderBookFeed tracks the order books that feed connections are subscribed to. Every subscribed pair is
+// read once per poll regardless of how many connections are watching it, and the diff is broadcast to all of them.
+type daoCoinOrderBookFeed struct {
+	fes *APIServer
+
+	mtx         sync.Mutex
+	numConns    int
+	subscribers map[daoCoinPair]map[*daoCoinOrderBookFeedConn]struct{}
+	// The last order book broadcast for each subscribed pair.
+	orderBooks map[daoCoinPair]*daoCoinOrderBookSnapshot
+}
+
+func newDAOCoinOrderBookFeed(fes *APIServer) *daoCoinOrderBookFeed {
+	return &daoCoinOrderBookFeed{
+		fes:         fes,
+		subscribers: make(map[daoCoinPair]map[*daoCoinOrderBookFeedConn]struct{}),
+		orderBooks:  make(map[daoCoinPair]*daoCoinOrderBookSnapshot),
+	}
+}
+
+// StartDAOCoinOrderBookFeed polls the order books that feed connections are subscribed to until the server stops.
+func (fes *APIServer) StartDAOCoinOrderBookFeed() {
+	go func() {
+	out:
+		for {
+			select {
+			case <-time.After(daoCoinOrderBookFeedPollInterval):
+				fes.daoCoinOrderBookFeed.poll()
+			case <-fes.quit:
+				break out
+			}
+		}
+	}()
+}
+
+// newDAOCoinPair keys a pair by its normalized coin identifiers so that subscribers spelling $DESO differently share
+// one order book.
+func newDAOCoinPair(daoCoin1CreatorPublicKeyBase58Check string, daoCoin2CreatorPublicKeyBase58Check string) daoCoinPair {
+	return daoCoinPair{
+		DAOCoin1CreatorPublicKeyBase58Check: normalizeCoinIdentifier(daoCoin1CreatorPublicKeyBase58Check),
+		DAOCoin2CreatorPublicKeyBase58Check: normalizeCoinIdentifier(daoCoin2CreatorPublicKeyBase58Check),
+	}
+}
+
+func (feed *daoCoinOrderBookFeed) getOrderBookSnapshot(utxoView *lib.UtxoView, pair daoCoinPair) (
+	*daoCoinOrderBookSnapshot, error) {
+	res, err := feed.fes.getDAOCoinLimitOrdersForCoinPair(utxoView, &GetDAOCoinLimitOrdersRequest{
+		DAOCoin1CreatorPublicKeyBase58Check: pair.DAOCoin1CreatorPublicKeyBase58Check,
+		DAOCoin2CreatorPublicKeyBase58Check: pair.DAOCoin2CreatorPublicKeyBase58Check,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &daoCoinOrderBookSnapshot{
+		orders:     res.Orders,
+		ordersByID: make(map[string]DAOCoinLimitOrderEntryResponse, len(res.Orders)),
+	}
+	for _, order := range res.Orders {
+		snapshot.ordersByID[order.OrderID] = order
+	}
+	return snapshot, nil
+}
+
+func (feed *daoCoinOrderBookFeed) subscribe(conn *daoCoinOrderBookFeedConn, pair daoCoinPair) error {
+	feed.mtx.Lock()
+	_, alreadySubscribed := conn.subscriptions[pair]
+	numSubscriptions := len(conn.subscriptions)
+	feed.mtx.Unlock()
+	if !alreadySubscribed && numSubscriptions >= MaxDAOCoinOrderBookFeedSubscriptions {
+		return fmt.Errorf("Cannot subscribe to more than %d coin pairs", MaxDAOCoinOrderBookFeedSubscriptions)
+	}
+
+	// Read the order book before taking the lock since it can be slow. This also validates the pair.
+	utxoView, err := feed.fes.mempool.GetAugmentedUniversalView()
+	if err != nil {
+		return fmt.Errorf("Problem fetching utxoView: %v", err)
+	}
+	snapshot, err := feed.getOrderBookSnapshot(utxoView, pair)
+	if err != nil {
+		return err
+	}
+
+	feed.mtx.Lock()
+	defer feed.mtx.Unlock()
+
+	if _, watched := feed.orderBooks[pair]; !watched && len(feed.orderBooks) >= MaxDAOCoinOrderBookFeedPairs {
+		return fmt.Errorf("The feed is already watching the max of %d coin pairs", MaxDAOCoinOrderBookFeedPairs)
+	}
+
+	// If the pair is already being watched, send the snapshot the next update will be diffed against so that the
+	// new subscriber doesn't miss anything.
+	if existingSnapshot, exists := feed.orderBooks[pair]; exists {
+		snapshot = existingSnapshot
+	} else {
+		feed.orderBooks[pair] = snapshot
+	}
+	if feed.subscribers[pair] == nil {
+		feed.subscribers[pair] = make(map[*daoCoinOrderBookFeedConn]struct{})
+	}
+	feed.subscribers[pair][conn] = struct{}{}
+	conn.subscriptions[pair] = struct{}{}
+
+	conn.enqueue(&DAOCoinOrderBookFeedMessage{
+		Type:                                DAOCoinOrderBookFeedMessageSnapshot,
+		DAOCoin1CreatorPublicKeyBase58Check: pair.DAOCoin1CreatorPublicKeyBase58Check,
+		DAOCoin2CreatorPublicKeyBase58Check: pair.DAOCoin2CreatorPublicKeyBase58Check,
+		AddedOrders:                         snapshot.orders,
+		RemovedOrderIDs:                     []string{},
+	})
+	return nil
+}
+
+func (feed *daoCoinOrderBookFeed) unsubscribe(conn *daoCoinOrderBookFeedConn, pair daoCoinPair) {
+	feed.mtx.Lock()
+	defer feed.mtx.Unlock()
+
+	feed._unsubscribeWithLock(conn, pair)
+}
+
+// addConn returns false if the feed already has MaxDAOCoinOrderBookFeedConnections connections. Otherwise the
+// connection must be removed with removeConn once it closes.
+func (feed *daoCoinOrderBookFeed) addConn() bool {
+	feed.mtx.Lock()
+	defer feed.mtx.Unlock()
+
+	if feed.numConns >= MaxDAOCoinOrderBookFeedConnections {
+		return false
+	}
+	feed.numConns++
+	return true
+}
+
+// removeConn unsubscribes the connection from every pair.
+func (feed *daoCoinOrderBookFeed) removeConn(conn *daoCoinOrderBookFeedConn) {
+	feed.mtx.Lock()
+	defer feed.mtx.Unlock()
+
+	feed.numConns--
+	for pair := range conn.subscriptions {
+		feed._unsubscribeWithLock(conn, pair)
+	}
+}
+
+func (feed *daoCoinOrderBookFeed) _unsubscribeWithLock(conn *daoCoinOrderBookFeedConn, pair daoCoinPair) {
+	delete(conn.subscriptions, pair)
+	delete(feed.subscribers[pair], conn)
+	// Stop reading pairs no one is watching.
+	if len(feed.subscribers[pair]) == 0 {
+		delete(feed.subscribers, pair)
+		delete(feed.orderBooks, pair)
+	}
+}
+
+// poll re-reads every subscribed order book from a single augmented view and broadcasts what changed since the
+// last poll.
+func (feed *daoCoinOrderBookFeed) poll() {
+	feed.mtx.Lock()
+	pairs := make([]daoCoinPair, 0, len(feed.subscribers))
+	for pair := range feed.subscribers {
+		pairs = append(pairs, pair)
+	}
+	feed.mtx.Unlock()
+	if len(pairs) == 0 {
+		return
+	}
+
+	utxoView, err := feed.fes.mempool.GetAugmentedUniversalView()
+	if err != nil {
+		glog.Errorf("daoCoinOrderBookFeed.poll: Problem fetching utxoView: %v", err)
+		return
+	}
+	for _, pair := range pairs {
+		snapshot, err := feed.getOrderBookSnapshot(utxoView, pair)
+		if err != nil {
+			glog.Errorf("daoCoinOrderBookFeed.poll: Problem getting order book for %v: %v", pair, err)
+			continue
+		}
+
+		feed.mtx.Lock()
+		// Skip pairs that lost their last subscriber while we were reading.
+		previousSnapshot, exists := feed.orderBooks[pair]
+		if !exists {
+			feed.mtx.Unlock()
+			continue
+		}
+		feed.orderBooks[pair] = snapshot
+
+		addedOrders := []DAOCoinLimitOrderEntryResponse{}
+		for _, order := range snapshot.orders {
+			if previousOrder, existed := previousSnapshot.ordersByID[order.OrderID]; !existed || previousOrder != order {
+				addedOrders = append(addedOrders, order)
+			}
+		}
+		removedOrderIDs := []string{}
+		for _, previousOrder := range previousSnapshot.orders {
+			if _, stillExists := snapshot.ordersByID[previousOrder.OrderID]; !stillExists {
+				removedOrderIDs = append(removedOrderIDs, previousOrder.OrderID)
+			}
+		}
+		if len(addedOrders) > 0 || len(removedOrderIDs) > 0 {
+			msg := &DAOCoinOrderBookFeedMessage{
+				Type:                                DAOCoinOrderBookFeedMessageUpdate,
+				DAOCoin1CreatorPublicKeyBase58Check: pair.DAOCoin1CreatorPublicKeyBase58Check,
+				DAOCoin2CreatorPublicKeyBase58Check: pair.DAOCoin2CreatorPublicKeyBase58Check,
+				AddedOrders:                         addedOrders,
+				RemovedOrderIDs:                     removedOrderIDs,
+			}
+			for conn := range feed.subscribers[pair] {
+				conn.enqueue(msg)
+			}
+		}
+		feed.mtx.Unlock()
+	}
+}
+
+// GetDAOCoinOrderBookFeed upgrades the request to a WebSocket that pushes order book updates for the coin pairs the
+// client subscribes to. See DAOCoinOrderBookFeedControlMessage and DAOCoinOrderBookFeedMessage. Updates are found by
+// polling the mempool's augmented view, so they can lag the mempool by up to daoCoinOrderBookFeedPollInterval.
+func (fes *APIServer) GetDAOCoinOrderBookFeed(ww http.ResponseWriter, req *http.Request) {
+	// The feed is read-only public data, so we accept connections from any origin, including clients that don't
+	// send one.
+	websocket.Server{Handler: fes.serveDAOCoinOrderBookFeed}.ServeHTTP(ww, req)
+}
+
+func (fes *APIServer) serveDAOCoinOrderBookFeed(ws *websocket.Conn) {
+	ws.MaxPayloadBytes = daoCoinOrderBookFeedMaxMessageBytes
+	if !fes.daoCoinOrderBookFeed.addConn() {
+		websocket.JSON.Send(ws, &DAOCoinOrderBookFeedMessage{
+			Type: DAOCoinOrderBookFeedMessageError,
+			Error: fmt.Sprintf("GetDAOCoinOrderBookFeed: The feed already has the max of %d connections",
+				MaxDAOCoinOrderBookFeedConnections),
+		})
+		ws.Close()
+		return
+	}
+	conn := &daoCoinOrderBookFeedConn{
+		send:          make(chan *DAOCoinOrderBookFeedMessage, daoCoinOrderBookFeedSendBufferSize),
+		done:          make(chan struct{}),
+		subscriptions: make(map[daoCoinPair]struct{}),
+	}
+	defer fes.daoCoinOrderBookFeed.removeConn(conn)
+	defer conn.close()
+
+	// Messages are written from their own goroutine so that a slow client never blocks the feed. Closing the socket
+	// also unblocks the read loop below.
+	go func() {
+		defer ws.Close()
+		for {
+			select {
+			case msg := <-conn.send:
+				if err := websocket.JSON.Send(ws, msg); err != nil {
+					conn.close()
+					return
+				}
+			case <-conn.done:
+				return
+			}
+		}
+	}()
+
+	windowStart := time.Now()
+	numControlMessagesInWindow := 0
+	for {
+		var rawMessage string
+		if err := websocket.Message.Receive(ws, &rawMessage); err != nil {
+			return
+		}
+
+		if time.Since(windowStart) >= daoCoinOrderBookFeedControlMessageWindow {
+			windowStart = time.Now()
+			numControlMessagesInWindow = 0
+		}
+		numControlMessagesInWindow++
+		if numControlMessagesInWindow > daoCoinOrderBookFeedMaxControlMessagesPerWindow {
+			conn.enqueue(&DAOCoinOrderBookFeedMessage{
+				Type: DAOCoinOrderBookFeedMessageError,
+				Error: fmt.Sprintf("GetDAOCoinOrderBookFeed: Cannot send more than %d control messages per %v",
+					daoCoinOrderBookFeedMaxControlMessagesPerWindow, daoCoinOrderBookFeedControlMessageWindow),
+			})
+			continue
+		}
+
+		controlMessage := DAOCoinOrderBookFeedControlMessage{}
+		if err := json.Unmarshal([]byte(rawMessage), &controlMessage); err != nil {
+			conn.enqueue(&DAOCoinOrderBookFeedMessage{
+				Type:  DAOCoinOrderBookFeedMessageError,
+				Error: fmt.Sprintf("GetDAOCoinOrderBookFeed: Problem parsing control message: %v", err),
+			})
+			continue
+		}
+		pair := newDAOCoinPair(
+			controlMessage.DAOCoin1CreatorPublicKeyBase58Check, controlMessage.DAOCoin2CreatorPublicKeyBase58Check)
+
+		var err error
+		switch strings.ToLower(controlMessage.Type) {
+		case DAOCoinOrderBookFeedControlSubscribe:
+			err = fes.daoCoinOrderBookFeed.subscribe(conn, pair)
+		case DAOCoinOrderBookFeedControlUnsubscribe:
+			fes.daoCoinOrderBookFeed.unsubscribe(conn, pair)
+		default:
+			err = fmt.Errorf("Type must be either %s or %s",
+				DAOCoinOrderBookFeedControlSubscribe, DAOCoinOrderBookFeedControlUnsubscribe)
+		}
+		if err != nil {
+			conn.enqueue(&DAOCoinOrderBookFeedMessage{
+				Type:                                DAOCoinOrderBookFeedMessageError,
+				DAOCoin1CreatorPublicKeyBase58Check: pair.DAOCoin1CreatorPublicKeyBase58Check,
+				DAOCoin2CreatorPublicKeyBase58Check: pair.DAOCoin2CreatorPublicKeyBase58Check,
+				Error:                               fmt.Sprintf("GetDAOCoinOrderBookFeed: %v", err),
+			})
+		}
+	}
+}
@@ -66,7 +66,7 @@ func (fes *APIServer) validateETHDepositAddress(depositAddress string) error {
 }
 
 func (fes *APIServer) SubmitETHTx(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SubmitETHTxRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SubmitETHTx: Problem parsing request body: %v", err))
@@ -255,7 +255,7 @@ type AdminProcessETHTxResponse struct {
 }
 
 func (fes *APIServer) AdminProcessETHTx(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminProcessETHTxRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminProcessETHTx: Problem parsing request body: %v", err))
@@ -354,7 +354,7 @@ type QueryETHRPCRequest struct {
 
 // QueryETHRPC is an endpoint used to execute queries through Infura
 func (fes *APIServer) QueryETHRPC(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := QueryETHRPCRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("QueryETHRPC: Problem parsing request body: %v", err))
@@ -385,7 +385,7 @@ type MetamaskSignInResponse struct {
 func (fes *APIServer) MetamaskSignIn(ww http.ResponseWriter, req *http.Request) {
 	// Give the user starter deso if this is their first time signing in with through metamask and if they don't have Deso
 	DEFAULT_ERROR := "MetamaskSignin: something went wrong with processing your airdrop: %v"
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	// Validate the  request object
 	requestData := MetamaskSignInRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
@@ -254,7 +254,7 @@ type APIKeyPairResponse struct {
 // public/private key pairs generated line up with what is shown by the tool.
 func (fes *APIServer) APIKeyPair(ww http.ResponseWriter, rr *http.Request) {
 	// Decode the request data.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	apiKeyPairRequest := APIKeyPairRequest{}
 	if err := decoder.Decode(&apiKeyPairRequest); err != nil {
 		APIAddError(ww, fmt.Sprintf("APIKeyPair: Problem parsing request body: %v", err))
@@ -345,7 +345,7 @@ type APIBalanceResponse struct {
 // APITransactionInfo endpoint instead.
 func (fes *APIServer) APIBalance(ww http.ResponseWriter, rr *http.Request) {
 	// Decode the request data.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	balanceRequest := APIBalanceRequest{}
 	if err := decoder.Decode(&balanceRequest); err != nil {
 		APIAddError(ww, fmt.Sprintf("APIBalanceRequest: Problem parsing request body: %v", err))
@@ -590,7 +590,7 @@ func APITransactionToResponse(
 //
 // TODO: This function is redundant with the APITransferDeSo function in frontend_utils
 func (fes *APIServer) APITransferDeSo(ww http.ResponseWriter, rr *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	transferDeSoRequest := APITransferDeSoRequest{}
 	if err := decoder.Decode(&transferDeSoRequest); err != nil {
 		APIAddError(ww, fmt.Sprintf("APITransferDeSo: Problem parsing request body: %v", err))
@@ -829,7 +829,7 @@ func (fes *APIServer) APITransactionInfo(ww http.ResponseWriter, rr *http.Reques
 	}
 
 	// Decode the request
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	transactionInfoRequest := APITransactionInfoRequest{}
 	if err := decoder.Decode(&transactionInfoRequest); err != nil {
 		APIAddError(ww, fmt.Sprintf("APITransactionInfo: Problem parsing request body: %v", err))
@@ -1198,7 +1198,7 @@ type APIBlockResponse struct {
 // and hash can be obtained using the /info endpoint.
 func (fes *APIServer) APIBlock(ww http.ResponseWriter, rr *http.Request) {
 	// Decode the request
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	blockRequest := APIBlockRequest{}
 	if err := decoder.Decode(&blockRequest); err != nil {
 		APIAddError(ww, fmt.Sprintf("APIBlockRequest: Problem parsing request body: %v", err))
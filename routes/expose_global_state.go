@@ -32,6 +32,34 @@ func (fes *APIServer) GetGlobalFeed(ww http.ResponseWriter, req *http.Request) {
 	fes.WriteGlobalStateDataToResponse(fes.GlobalFeedPostHashes, "GetGlobalFeed", ww)
 }
 
+type GetGlobalStateModeResponse struct {
+	// True if this node serves its global state to other nodes.
+	ExposesGlobalState bool
+	// True if this node fetches global state data from GlobalStateAPIUrl instead of only using its own.
+	UsesExternalGlobalState bool
+	// The URL this node fetches global state data from. Only populated if the node is configured to expose it.
+	GlobalStateAPIUrl string
+	// True if this node reads and writes its global state through a remote node rather than a local db.
+	UsesRemoteGlobalStateNode bool
+}
+
+// GetGlobalStateMode returns how this node is configured to serve and fetch global state. This reflects config
+// only, and never includes the global state remote secret.
+func (fes *APIServer) GetGlobalStateMode(ww http.ResponseWriter, req *http.Request) {
+	res := GetGlobalStateModeResponse{
+		ExposesGlobalState:        fes.Config.ExposeGlobalState,
+		UsesExternalGlobalState:   !fes.Config.ExposeGlobalState && fes.Config.GlobalStateAPIUrl != "",
+		UsesRemoteGlobalStateNode: fes.Config.GlobalStateRemoteNode != "",
+	}
+	if res.UsesExternalGlobalState && fes.Config.ExposeGlobalStateAPIUrl {
+		res.GlobalStateAPIUrl = fes.Config.GlobalStateAPIUrl
+	}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetGlobalStateMode: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
 // WriteGlobalStateDataToResponse is a helper to encode the response.
 func (fes *APIServer) WriteGlobalStateDataToResponse(data interface{}, functionName string, ww http.ResponseWriter) {
 	if !fes.Config.ExposeGlobalState {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -21,6 +22,7 @@ const (
 	RoutePathGlobalStatePutRemote      = "/api/v1/global-state/put"
 	RoutePathGlobalStateGetRemote      = "/api/v1/global-state/get"
 	RoutePathGlobalStateBatchGetRemote = "/api/v1/global-state/batch-get"
+	RoutePathGlobalStateBatchPutRemote = "/api/v1/global-state/batch-put"
 	RoutePathGlobalStateDeleteRemote   = "/api/v1/global-state/delete"
 	RoutePathGlobalStateSeekRemote     = "/api/v1/global-state/seek"
 )
@@ -29,6 +31,13 @@ type GlobalState struct {
 	GlobalStateRemoteNode   string
 	GlobalStateRemoteSecret string
 	GlobalStateDB           *badger.DB
+
+	// Optional. Counts Get/Put/Seek calls when --enable-metrics is set.
+	Metrics *Metrics
+
+	// Set when --global-state-redis-addr is set, in which case global state is stored in redis instead of a db
+	// or remote node.
+	RedisClient *redisGlobalStateClient
 }
 
 // GlobalStateRoutes returns the routes for managing global state.
@@ -56,6 +65,13 @@ func (gs *GlobalState) GlobalStateRoutes() []Route {
 			gs.BatchGetRemote,
 			AdminAccess, // CheckSecret
 		},
+		{
+			"BatchPutRemote",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGlobalStateBatchPutRemote,
+			gs.BatchPutRemote,
+			AdminAccess, // CheckSecret
+		},
 		{
 			"DeleteRemote",
 			[]string{"POST", "OPTIONS"},
@@ -174,7 +190,8 @@ var (
 	_GlobalStatePrefixReferralHashToReferralInfo = []byte{24}
 	// 	- <prefix, PKID, referral hash (8 bytes)> -> <IsActive bool>
 	_GlobalStatePrefixPKIDReferralHashToIsActive = []byte{25}
-	// - <prefix, PKID, referral hash (8 bytes), Referred PKID
+	// - <prefix, PKID, referral hash (8 bytes), Referred PKID> -> <RefereePayoutInfo>
+	//   Entries written before payouts were recorded have a value of []byte{1}.
 	_GlobalStatePrefixPKIDReferralHashRefereePKID = []byte{26}
 	// - <prefix, TimestampNanos, PKID, referral hash (8 bytes), Referred PKID
 	_GlobalStatePrefixTimestampPKIDReferralHashRefereePKID = []byte{37}
@@ -227,12 +244,24 @@ var (
 
 	_GlobalStatePrefixMetamaskAirdrop = []byte{45}
 
+	// Index used for case-insensitive referral hash lookups.
+	// <prefix, lowercase ReferralHash> -> <ReferralHash>
+	_GlobalStatePrefixLowercaseReferralHashToReferralHash = []byte{46}
+
+	// Responses saved for requests made with an Idempotency-Key header. See HandleIdempotencyKey.
+	// <prefix, public key, idempotency key> -> <IdempotencyRecord>
+	_GlobalStatePrefixPublicKeyIdempotencyKeyToIdempotencyRecord = []byte{47}
+
+	// Index of the referral hash each referee was credited to, so it can be found without knowing the referrer.
+	// <prefix, referee PKID> -> <ReferralHash>
+	_GlobalStatePrefixRefereePKIDToReferralHash = []byte{48}
+
 	// TODO: This process is a bit error-prone. We should come up with a test or
 	// something to at least catch cases where people have two prefixes with the
 	// same ID.
 	//
 
-	// NEXT_TAG: 46
+	// NEXT_TAG: 49
 
 )
 
@@ -262,13 +291,39 @@ type ReferralInfo struct {
 	TotalReferrerDeSoNanos uint64
 	TotalRefereeDeSoNanos  uint64
 	DateCreatedTStampNanos uint64
+
+	// The public key of the admin who created this referral hash. Empty for referral hashes created before this
+	// was recorded.
+	CreatedByAdminPublicKey string
+
+	// The link is treated as inactive at or after this time, regardless of its IsActive status. Zero means the
+	// link never expires.
+	ExpiresAtTStampNanos uint64
 }
 
+// SimpleReferralInfo is the referee-facing subset of a ReferralInfo. It is returned by public endpoints so it must
+// not include the referrer or the referrer's payout.
 type SimpleReferralInfo struct {
 	ReferralHashBase58    string
 	RefereeAmountUSDCents uint64
+	RequiresJumio         bool
 	MaxReferrals          uint64 // If set to zero, there is no cap on referrals.
 	TotalReferrals        uint64
+	RemainingReferrals    uint64 // Always zero if there is no cap on referrals.
+}
+
+// A RefereePayoutInfo records what was paid out when a referee was credited to a referral hash.
+type RefereePayoutInfo struct {
+	// The USD cents per DeSo exchange rate used to convert the referral amounts to nanos.
+	UsdCentsPerDeSoExchangeRate uint64
+	RefereeDeSoNanos            uint64
+	ReferrerDeSoNanos           uint64
+	TstampNanos                 uint64
+
+	// The hashes of the transactions that paid each side. Empty if that side wasn't paid or the payout was
+	// recorded before these were tracked.
+	RefereeTxnHashHex  string
+	ReferrerTxnHashHex string
 }
 
 type NFTDropEntry struct {
@@ -377,6 +432,10 @@ type UserMetadata struct {
 	// Txn hash in which the referrer was paid
 	ReferrerDeSoTxnHash string
 
+	// Starter DeSo paid from the referral link's referee amount when the user verified their phone number. This
+	// payout is deducted from the referee bonus paid after Jumio verification.
+	PhoneVerificationReferralDeSoNanos uint64
+
 	// The number of unread notifications stored in the db.
 	UnreadNotifications uint64
 	// The most recently scanned notification transaction index in the database. Stored in order to prevent unnecessary re-scanning.
@@ -519,6 +578,29 @@ func GlobalStateKeyForReferralHashToReferralInfo(referralHashBytes []byte) []byt
 	return key
 }
 
+// Key for resolving a referral hash regardless of case. The referral hash is lowercased.
+func GlobalStateKeyForLowercaseReferralHashToReferralHash(referralHashBase58 string) []byte {
+	prefixCopy := append([]byte{}, _GlobalStatePrefixLowercaseReferralHashToReferralHash...)
+	key := append(prefixCopy, []byte(strings.ToLower(referralHashBase58))...)
+	return key
+}
+
+// Key for looking up the referral hash a referee was credited to.
+func GlobalStateKeyForRefereePKIDToReferralHash(refereePKID *lib.PKID) []byte {
+	prefixCopy := append([]byte{}, _GlobalStatePrefixRefereePKIDToReferralHash...)
+	key := append(prefixCopy, refereePKID[:]...)
+	return key
+}
+
+// Key for the response saved for a public key's idempotency key. Public keys are a fixed length, so the idempotency
+// key can follow directly.
+func GlobalStateKeyForPublicKeyIdempotencyKeyToIdempotencyRecord(publicKeyBytes []byte, idempotencyKey string) []byte {
+	prefixCopy := append([]byte{}, _GlobalStatePrefixPublicKeyIdempotencyKeyToIdempotencyRecord...)
+	key := append(prefixCopy, publicKeyBytes...)
+	key = append(key, []byte(idempotencyKey)...)
+	return key
+}
+
 // Key for getting a pub key's referral hashes and "IsActive" status.
 func GlobalStateKeyForPKIDReferralHashToIsActive(pkid *lib.PKID, referralHashBytes []byte) []byte {
 	prefixCopy := append([]byte{}, _GlobalStatePrefixPKIDReferralHashToIsActive...)
@@ -785,7 +867,7 @@ type PutRemoteResponse struct {
 
 func (gs *GlobalState) PutRemote(ww http.ResponseWriter, rr *http.Request) {
 	// Parse the request.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := PutRemoteRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("PutRemote: Problem parsing request body: %v", err))
@@ -826,7 +908,14 @@ func (gs *GlobalState) CreatePutRequest(key []byte, value []byte) (
 	return url, json_data, nil
 }
 
-func (gs *GlobalState) Put(key []byte, value []byte) error {
+func (gs *GlobalState) Put(key []byte, value []byte) (_err error) {
+	defer func() { gs.Metrics.RecordGlobalStateCall("put", _err) }()
+
+	// If we have a redis server then it holds all of global state.
+	if gs.RedisClient != nil {
+		return gs.RedisClient.Put(key, value)
+	}
+
 	// If we have a remote node then use that node to fulfill this request.
 	if gs.GlobalStateRemoteNode != "" {
 		// TODO: This codepath is hard to exercise in a test.
@@ -868,7 +957,7 @@ type GetRemoteResponse struct {
 
 func (gs *GlobalState) GetRemote(ww http.ResponseWriter, rr *http.Request) {
 	// Parse the request.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := GetRemoteRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetRemote: Problem parsing request body: %v", err))
@@ -912,6 +1001,13 @@ func (gs *GlobalState) CreateGetRequest(key []byte) (
 }
 
 func (gs *GlobalState) Get(key []byte) (value []byte, _err error) {
+	defer func() { gs.Metrics.RecordGlobalStateCall("get", _err) }()
+
+	// If we have a redis server then it holds all of global state.
+	if gs.RedisClient != nil {
+		return gs.RedisClient.Get(key)
+	}
+
 	// If we have a remote node then use that node to fulfill this request.
 	if gs.GlobalStateRemoteNode != "" {
 		// TODO: This codepath is currently annoying to test.
@@ -969,7 +1065,7 @@ type BatchGetRemoteResponse struct {
 
 func (gs *GlobalState) BatchGetRemote(ww http.ResponseWriter, rr *http.Request) {
 	// Parse the request.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := BatchGetRemoteRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("BatchGetRemote: Problem parsing request body: %v", err))
@@ -1013,6 +1109,13 @@ func (gs *GlobalState) CreateBatchGetRequest(keyList [][]byte) (
 }
 
 func (gs *GlobalState) BatchGet(keyList [][]byte) (value [][]byte, _err error) {
+	defer func() { gs.Metrics.RecordGlobalStateCall("batch_get", _err) }()
+
+	// If we have a redis server then it holds all of global state.
+	if gs.RedisClient != nil {
+		return gs.RedisClient.BatchGet(keyList)
+	}
+
 	// If we have a remote node then use that node to fulfill this request.
 	if gs.GlobalStateRemoteNode != "" {
 		// TODO: This codepath is currently annoying to test.
@@ -1065,6 +1168,112 @@ func (gs *GlobalState) BatchGet(keyList [][]byte) (value [][]byte, _err error) {
 	return retValueList, nil
 }
 
+// KVPair is a single key-value write for BatchPut.
+type KVPair struct {
+	Key   []byte
+	Value []byte
+}
+
+type BatchPutRemoteRequest struct {
+	KVPairs []KVPair
+}
+
+type BatchPutRemoteResponse struct {
+}
+
+func (gs *GlobalState) BatchPutRemote(ww http.ResponseWriter, rr *http.Request) {
+	// Parse the request.
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
+	requestData := BatchPutRemoteRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("BatchPutRemote: Problem parsing request body: %v", err))
+		return
+	}
+
+	// Call the BatchPut function. Note that this may also proxy to another node.
+	if err := gs.BatchPut(requestData.KVPairs); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"BatchPutRemote: Error processing BatchPut: %v", err))
+		return
+	}
+
+	// Return
+	res := BatchPutRemoteResponse{}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("BatchPutRemote: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+func (gs *GlobalState) CreateBatchPutRequest(kvPairs []KVPair) (
+	_url string, _json_data []byte, _err error) {
+
+	req := BatchPutRemoteRequest{
+		KVPairs: kvPairs,
+	}
+	json_data, err := json.Marshal(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("BatchPut: Could not marshal JSON: %v", err)
+	}
+
+	url := fmt.Sprintf("%s%s?%s=%s",
+		gs.GlobalStateRemoteNode, RoutePathGlobalStateBatchPutRemote,
+		GlobalStateSharedSecretParam, gs.GlobalStateRemoteSecret)
+
+	return url, json_data, nil
+}
+
+// BatchPut writes every pair in a single request to the remote node, or a single transaction against the local
+// db. Callers with a large number of writes should split them up, since badger limits the size of a transaction.
+func (gs *GlobalState) BatchPut(kvPairs []KVPair) (_err error) {
+	defer func() { gs.Metrics.RecordGlobalStateCall("batch_put", _err) }()
+
+	if len(kvPairs) == 0 {
+		return nil
+	}
+
+	// If we have a redis server then it holds all of global state.
+	if gs.RedisClient != nil {
+		return gs.RedisClient.BatchPut(kvPairs)
+	}
+
+	// If we have a remote node then use that node to fulfill this request.
+	if gs.GlobalStateRemoteNode != "" {
+		url, json_data, err := gs.CreateBatchPutRequest(kvPairs)
+		if err != nil {
+			return fmt.Errorf("BatchPut: Error constructing request: %v", err)
+		}
+
+		res, err := http.Post(
+			url,
+			"application/json", /*contentType*/
+			bytes.NewBuffer(json_data))
+		if err != nil {
+			return fmt.Errorf("BatchPut: Error processing remote request")
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			bodyBytes, _ := ioutil.ReadAll(res.Body)
+			return fmt.Errorf("BatchPut: Remote node returned status %d: %s", res.StatusCode, string(bodyBytes))
+		}
+
+		// No error means nothing to return.
+		return nil
+	}
+
+	// If we get here, it means we don't have a remote node so store the
+	// data in our local db.
+	return gs.GlobalStateDB.Update(func(txn *badger.Txn) error {
+		for _, kvPair := range kvPairs {
+			if err := txn.Set(kvPair.Key, kvPair.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 type DeleteRemoteRequest struct {
 	Key []byte
 }
@@ -1092,7 +1301,7 @@ func (gs *GlobalState) CreateDeleteRequest(key []byte) (
 
 func (gs *GlobalState) DeleteRemote(ww http.ResponseWriter, rr *http.Request) {
 	// Parse the request.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := DeleteRemoteRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("DeleteRemote: Problem parsing request body: %v", err))
@@ -1114,7 +1323,14 @@ func (gs *GlobalState) DeleteRemote(ww http.ResponseWriter, rr *http.Request) {
 	}
 }
 
-func (gs *GlobalState) Delete(key []byte) error {
+func (gs *GlobalState) Delete(key []byte) (_err error) {
+	defer func() { gs.Metrics.RecordGlobalStateCall("delete", _err) }()
+
+	// If we have a redis server then it holds all of global state.
+	if gs.RedisClient != nil {
+		return gs.RedisClient.Delete(key)
+	}
+
 	// If we have a remote node then use that node to fulfill this request.
 	if gs.GlobalStateRemoteNode != "" {
 		// TODO: This codepath is currently annoying to test.
@@ -1131,10 +1347,14 @@ func (gs *GlobalState) Delete(key []byte) error {
 		if err != nil {
 			return fmt.Errorf("Delete: Error processing remote request")
 		}
+		defer res.Body.Close()
 
-		res.Body.Close()
-		//res := DeleteRemoteResponse{}
-		//json.NewDecoder(resReturned.Body).Decode(&res)
+		// The remote node rejects requests with the wrong shared secret, so make sure a failed delete,
+		// including one that was never authorized, isn't reported as a success.
+		if res.StatusCode != http.StatusOK {
+			bodyBytes, _ := ioutil.ReadAll(res.Body)
+			return fmt.Errorf("Delete: Remote node returned status %d: %s", res.StatusCode, string(bodyBytes))
+		}
 
 		// No error means nothing to return.
 		return nil
@@ -1186,7 +1406,7 @@ func (gs *GlobalState) CreateSeekRequest(startPrefix []byte, validForPrefix []by
 
 func (gs *GlobalState) GlobalStateSeekRemote(ww http.ResponseWriter, rr *http.Request) {
 	// Parse the request.
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := SeekRemoteRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GlobalStateSeekRemote: Problem parsing request body: %v", err))
@@ -1222,6 +1442,12 @@ func (gs *GlobalState) GlobalStateSeekRemote(ww http.ResponseWriter, rr *http.Re
 func (gs *GlobalState) Seek(startPrefix []byte, validForPrefix []byte,
 	maxKeyLen int, numToFetch int, reverse bool, fetchValues bool) (
 	_keysFound [][]byte, _valsFound [][]byte, _err error) {
+	defer func() { gs.Metrics.RecordGlobalStateCall("seek", _err) }()
+
+	// If we have a redis server then it holds all of global state.
+	if gs.RedisClient != nil {
+		return gs.RedisClient.Seek(startPrefix, validForPrefix, maxKeyLen, numToFetch, reverse, fetchValues)
+	}
 
 	// If we have a remote node then use that node to fulfill this request.
 	if gs.GlobalStateRemoteNode != "" {
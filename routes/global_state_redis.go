@@ -0,0 +1,227 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisDialTimeout = 5 * time.Second
+	// How long reading or writing a single command's reply may take.
+	redisCommandTimeout = 30 * time.Second
+
+	// Every global state key is also added to this sorted set, with a score of zero, so that Seek can page
+	// through keys in byte order with ZRANGEBYLEX. Global state keys all start with a small prefix byte, so
+	// they can't collide with this name.
+	redisGlobalStateKeyIndex = "deso-backend:global-state-keys"
+)
+
+// redisGlobalStateClient stores global state in redis so that several nodes behind a load balancer can share it
+// without designating one of them as the remote node.
+type redisGlobalStateClient struct {
+	client *redis.Client
+}
+
+// newRedisGlobalStateClient connects to the redis server at addr. The username and password are sent with AUTH
+// when the password is set, and db is passed to SELECT.
+func newRedisGlobalStateClient(
+	addr string, username string, password string, db int, useTLS bool) *redisGlobalStateClient {
+	options := &redis.Options{
+		Addr:         addr,
+		Username:     username,
+		Password:     password,
+		DB:           db,
+		DialTimeout:  redisDialTimeout,
+		ReadTimeout:  redisCommandTimeout,
+		WriteTimeout: redisCommandTimeout,
+	}
+	if useTLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return &redisGlobalStateClient{client: redis.NewClient(options)}
+}
+
+func (client *redisGlobalStateClient) Get(key []byte) ([]byte, error) {
+	value, err := client.client.Get(context.Background(), string(key)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Get: %v", err)
+	}
+	return value, nil
+}
+
+// Put sets the key and adds it to the key index in one transaction.
+func (client *redisGlobalStateClient) Put(key []byte, value []byte) error {
+	ctx := context.Background()
+	_, err := client.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, string(key), value, 0)
+		pipe.ZAdd(ctx, redisGlobalStateKeyIndex, redis.Z{Member: string(key)})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Put: %v", err)
+	}
+	return nil
+}
+
+// BatchGet returns an empty value for missing keys, matching the local db.
+func (client *redisGlobalStateClient) BatchGet(keyList [][]byte) ([][]byte, error) {
+	if len(keyList) == 0 {
+		return nil, nil
+	}
+	values, err := client.mget(keyList)
+	if err != nil {
+		return nil, fmt.Errorf("BatchGet: %v", err)
+	}
+	for ii := range values {
+		if values[ii] == nil {
+			values[ii] = []byte{}
+		}
+	}
+	return values, nil
+}
+
+// BatchPut sets every pair and adds their keys to the key index in one transaction.
+func (client *redisGlobalStateClient) BatchPut(kvPairs []KVPair) error {
+	if len(kvPairs) == 0 {
+		return nil
+	}
+	pairs := make([]interface{}, 0, 2*len(kvPairs))
+	members := make([]redis.Z, 0, len(kvPairs))
+	for _, kvPair := range kvPairs {
+		pairs = append(pairs, string(kvPair.Key), kvPair.Value)
+		members = append(members, redis.Z{Member: string(kvPair.Key)})
+	}
+	ctx := context.Background()
+	_, err := client.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.MSet(ctx, pairs...)
+		pipe.ZAdd(ctx, redisGlobalStateKeyIndex, members...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("BatchPut: %v", err)
+	}
+	return nil
+}
+
+// Delete removes the key and its entry in the key index in one transaction.
+func (client *redisGlobalStateClient) Delete(key []byte) error {
+	ctx := context.Background()
+	_, err := client.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, string(key))
+		pipe.ZRem(ctx, redisGlobalStateKeyIndex, string(key))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Delete: %v", err)
+	}
+	return nil
+}
+
+// mget returns the value of each key, or nil for keys that aren't set.
+func (client *redisGlobalStateClient) mget(keyList [][]byte) ([][]byte, error) {
+	keys := make([]string, len(keyList))
+	for ii, key := range keyList {
+		keys[ii] = string(key)
+	}
+	replies, err := client.client.MGet(context.Background(), keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, len(replies))
+	for ii, reply := range replies {
+		if reply == nil {
+			continue
+		}
+		value, ok := reply.(string)
+		if !ok {
+			return nil, fmt.Errorf("mget: Unexpected value type %T", reply)
+		}
+		values[ii] = []byte(value)
+	}
+	return values, nil
+}
+
+// prefixUpperBound returns the smallest key that sorts after every key with the given prefix, or nil if there
+// isn't one because the prefix is empty or all 0xFF.
+func prefixUpperBound(prefix []byte) []byte {
+	upperBound := append([]byte{}, prefix...)
+	for ii := len(upperBound) - 1; ii >= 0; ii-- {
+		if upperBound[ii] != 0xFF {
+			upperBound[ii]++
+			return upperBound[:ii+1]
+		}
+	}
+	return nil
+}
+
+// Seek mirrors lib.DBGetPaginatedKeysAndValuesForPrefix. Keys are read in byte order from the key index with
+// ZRANGEBYLEX (or ZREVRANGEBYLEX when reverse is set), so a page costs O(log(N) + numToFetch) like a badger
+// iterator rather than a walk over the whole keyspace.
+func (client *redisGlobalStateClient) Seek(startPrefix []byte, validForPrefix []byte,
+	maxKeyLen int, numToFetch int, reverse bool, fetchValues bool) (
+	_keysFound [][]byte, _valsFound [][]byte, _err error) {
+
+	// The range covers the keys with validForPrefix that a badger iterator seeking to startPrefix would visit.
+	prefixEnd := prefixUpperBound(validForPrefix)
+	upperBound := "+"
+	if prefixEnd != nil {
+		upperBound = "(" + string(prefixEnd)
+	}
+	rangeBy := &redis.ZRangeBy{Count: int64(numToFetch)}
+	ctx := context.Background()
+	var keys []string
+	var err error
+	if !reverse {
+		start := startPrefix
+		if bytes.Compare(start, validForPrefix) < 0 {
+			start = validForPrefix
+		}
+		rangeBy.Min = "[" + string(start)
+		rangeBy.Max = upperBound
+		keys, err = client.client.ZRangeByLex(ctx, redisGlobalStateKeyIndex, rangeBy).Result()
+	} else {
+		// Pad the start the same way DBGetPaginatedKeysAndValuesForPrefixWithTxn does.
+		paddedPrefix := make([]byte, maxKeyLen)
+		for ii := 0; ii < maxKeyLen; ii++ {
+			if ii < len(startPrefix) {
+				paddedPrefix[ii] = startPrefix[ii]
+			} else {
+				paddedPrefix[ii] = 0xFF
+			}
+		}
+		rangeBy.Min = "[" + string(validForPrefix)
+		rangeBy.Max = "[" + string(paddedPrefix)
+		if prefixEnd != nil && bytes.Compare(paddedPrefix, prefixEnd) >= 0 {
+			rangeBy.Max = upperBound
+		}
+		keys, err = client.client.ZRevRangeByLex(ctx, redisGlobalStateKeyIndex, rangeBy).Result()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Seek: %v", err)
+	}
+
+	keysFound := make([][]byte, len(keys))
+	for ii, key := range keys {
+		if maxKeyLen != 0 && len(key) != maxKeyLen {
+			return nil, nil, fmt.Errorf("Seek: Invalid key length %v != %v", len(key), maxKeyLen)
+		}
+		keysFound[ii] = []byte(key)
+	}
+
+	valsFound := make([][]byte, len(keysFound))
+	if fetchValues && len(keysFound) > 0 {
+		if valsFound, err = client.mget(keysFound); err != nil {
+			return nil, nil, fmt.Errorf("Seek: %v", err)
+		}
+	}
+
+	return keysFound, valsFound, nil
+}
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisGlobalState(t *testing.T) {
+	require := require.New(t)
+
+	redisServer := miniredis.RunT(t)
+	redisServer.RequireUserAuth("user", "pass")
+	gs := &GlobalState{RedisClient: newRedisGlobalStateClient(redisServer.Addr(), "user", "pass", 2, false)}
+
+	// Getting when no value is present should return nil without an error.
+	val, err := gs.Get([]byte("woo"))
+	require.NoError(err)
+	require.Nil(val)
+
+	// Put then Delete then Get should return nil.
+	require.NoError(gs.Put([]byte("woo"), []byte("hoo")))
+	val, err = gs.Get([]byte("woo"))
+	require.NoError(err)
+	require.Equal([]byte("hoo"), val)
+	require.NoError(gs.Delete([]byte("woo")))
+	val, err = gs.Get([]byte("woo"))
+	require.NoError(err)
+	require.Nil(val)
+
+	// BatchGet returns an empty value for missing keys, like the local db.
+	require.NoError(gs.BatchPut([]KVPair{
+		{Key: []byte("fan"), Value: []byte("tastic")},
+		{Key: []byte("great"), Value: []byte("scott")},
+	}))
+	valueList, err := gs.BatchGet([][]byte{[]byte("fan"), []byte("woo"), []byte("great")})
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("tastic"), {}, []byte("scott")}, valueList)
+
+	// Keys should be stored in the selected db.
+	redisServer.Select(2)
+	require.True(redisServer.Exists("fan"))
+
+	// A client with the wrong password can't read anything.
+	badClient := newRedisGlobalStateClient(redisServer.Addr(), "user", "wrong", 2, false)
+	_, err = badClient.Get([]byte("fan"))
+	require.Error(err)
+
+	// Seek should visit keys in order, respecting the start key, limit, and direction. Keys under a
+	// neighbouring prefix and a deleted key shouldn't be returned.
+	prefix := []byte{'*', 7}
+	for _, suffix := range []byte{3, 1, 4, 2} {
+		require.NoError(gs.Put(append(append([]byte{}, prefix...), suffix), []byte{suffix}))
+	}
+	require.NoError(gs.Put([]byte{'*', 8, 1}, []byte{1}))
+	require.NoError(gs.Put([]byte{'*', 7, 5}, []byte{5}))
+	require.NoError(gs.Delete([]byte{'*', 7, 5}))
+
+	keys, vals, err := gs.Seek(prefix, prefix, 0, 0, false, true)
+	require.NoError(err)
+	require.Equal([][]byte{{'*', 7, 1}, {'*', 7, 2}, {'*', 7, 3}, {'*', 7, 4}}, keys)
+	require.Equal([][]byte{{1}, {2}, {3}, {4}}, vals)
+
+	keys, _, err = gs.Seek([]byte{'*', 7, 2}, prefix, 0, 2, false, false)
+	require.NoError(err)
+	require.Equal([][]byte{{'*', 7, 2}, {'*', 7, 3}}, keys)
+
+	keys, _, err = gs.Seek(prefix, prefix, 3, 3, true, false)
+	require.NoError(err)
+	require.Equal([][]byte{{'*', 7, 4}, {'*', 7, 3}, {'*', 7, 2}}, keys)
+
+	keys, _, err = gs.Seek([]byte{'*', 7, 2}, prefix, 3, 0, true, false)
+	require.NoError(err)
+	require.Equal([][]byte{{'*', 7, 2}, {'*', 7, 1}}, keys)
+}
@@ -213,3 +213,58 @@ func TestGlobalStateServiceURLCreation(t *testing.T) {
 		assert.Equal("https://deso.com:17001/api/v1/global-state/delete?shared_secret=abcdef", url)
 	}
 }
+
+// newTestRemoteGlobalState returns a GlobalState that proxies to a test server whose remote routes are backed by a
+// local db. The shared secret check that the APIServer router would normally apply is skipped.
+func newTestRemoteGlobalState(t *testing.T) *GlobalState {
+	globalStateDB, _ := GetTestBadgerDb()
+	t.Cleanup(func() { globalStateDB.Close() })
+	localGlobalState := &GlobalState{GlobalStateDB: globalStateDB}
+
+	mux := http.NewServeMux()
+	for _, route := range localGlobalState.GlobalStateRoutes() {
+		mux.HandleFunc(route.Pattern, route.HandlerFunc)
+	}
+	remoteNode := httptest.NewServer(mux)
+	t.Cleanup(remoteNode.Close)
+
+	return &GlobalState{GlobalStateRemoteNode: remoteNode.URL}
+}
+
+func TestGlobalStateRemoteDelete(t *testing.T) {
+	require := require.New(t)
+
+	remoteGlobalState := newTestRemoteGlobalState(t)
+
+	// Put then Delete then Get should return nil.
+	require.NoError(remoteGlobalState.Put([]byte("woo"), []byte("hoo")))
+	val, err := remoteGlobalState.Get([]byte("woo"))
+	require.NoError(err)
+	require.Equal([]byte("hoo"), val)
+	require.NoError(remoteGlobalState.Delete([]byte("woo")))
+	val, err = remoteGlobalState.Get([]byte("woo"))
+	require.NoError(err)
+	require.Nil(val)
+
+	// A remote node that rejects the request should surface an error rather than silently succeeding.
+	rejectingNode := httptest.NewServer(http.HandlerFunc(func(ww http.ResponseWriter, rr *http.Request) {
+		_AddForbiddenError(ww, "Invalid shared secret")
+	}))
+	defer rejectingNode.Close()
+	rejectedGlobalState := &GlobalState{GlobalStateRemoteNode: rejectingNode.URL}
+	require.Error(rejectedGlobalState.Delete([]byte("woo")))
+}
+
+func TestGlobalStateRemoteBatchPut(t *testing.T) {
+	require := require.New(t)
+
+	remoteGlobalState := newTestRemoteGlobalState(t)
+
+	require.NoError(remoteGlobalState.BatchPut([]KVPair{
+		{Key: []byte("woo"), Value: []byte("hoo")},
+		{Key: []byte("fan"), Value: []byte("tastic")},
+	}))
+	valueList, err := remoteGlobalState.BatchGet([][]byte{[]byte("woo"), []byte("fan")})
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("hoo"), []byte("tastic")}, valueList)
+}
@@ -971,7 +971,7 @@ func (fes *APIServer) HandleHotFeedPageRequest(
 	approvedPostsOnly bool,
 	addMultiplierBool bool,
 ) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := HotFeedPageRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("HandleHotFeedPageRequest: Problem parsing request body: %v", err))
@@ -983,7 +983,7 @@ func (fes *APIServer) HandleHotFeedPageRequest(
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("HandleHotFeedPageRequest: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("HandleHotFeedPageRequest: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -1109,6 +1109,143 @@ func (fes *APIServer) HandleHotFeedPageRequest(
 	}
 }
 
+type GetHotFeedScoresRequest struct {
+	// If provided, posts from creators this reader has blocked can be excluded.
+	ReaderPublicKeyBase58Check string
+	// If defined, only get scores for posts tagged with this tag.
+	Tag string
+	// If true, sort by new instead of by hotness. Only applies to queries where "Tag" is defined.
+	SortByNew bool
+	// Index into the ordered hot feed at which to start the page.
+	Offset int
+	// Number of entries to return. Defaults to DefaultHotFeedScoresLimit.
+	Limit int
+	// If true, skip posts whose poster has been blocked by the reader or is blacklisted on this node.
+	ExcludeBlockedCreators bool
+}
+
+type HotFeedScoreEntryResponse struct {
+	PostHashHex                string
+	HotnessScore               uint64
+	PosterPublicKeyBase58Check string
+}
+
+type GetHotFeedScoresResponse struct {
+	HotFeedScores []HotFeedScoreEntryResponse
+	// The total number of entries in the ordered hot feed being paged through.
+	TotalEntries int
+	// Offset to pass in the next request, or -1 if there are no more entries.
+	NextOffset int
+}
+
+const (
+	DefaultHotFeedScoresLimit = 50
+	MaxHotFeedScoresLimit     = 1000
+)
+
+// GetHotFeedScores returns the raw ranked post hashes and hotness scores computed by the hot feed routine. Unlike
+// GetHotFeed, it does not build full PostEntryResponses, which makes it cheap for clients that only need the ranking.
+func (fes *APIServer) GetHotFeedScores(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetHotFeedScoresRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetHotFeedScores: Problem parsing request body: %v", err))
+		return
+	}
+
+	if !fes.Config.RunHotFeedRoutine {
+		_AddBadRequestError(ww, fmt.Sprintf("GetHotFeedScores: Hot feed routine is not enabled on this node"))
+		return
+	}
+
+	if requestData.Offset < 0 {
+		_AddBadRequestError(ww, fmt.Sprintf("GetHotFeedScores: Offset must be non-negative: %d", requestData.Offset))
+		return
+	}
+	limit := requestData.Limit
+	if limit <= 0 {
+		limit = DefaultHotFeedScoresLimit
+	}
+	if limit > MaxHotFeedScoresLimit {
+		limit = MaxHotFeedScoresLimit
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetHotFeedScores: Error getting utxoView: %v", err))
+		return
+	}
+
+	// Get the map of public keys the reader has blocked, if we've been asked to filter them out.
+	blockedPublicKeys := make(map[string]struct{})
+	if requestData.ExcludeBlockedCreators && requestData.ReaderPublicKeyBase58Check != "" {
+		readerPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetHotFeedScores: Problem decoding reader public key: %v", err))
+			return
+		}
+		blockedPublicKeys, err = fes.GetBlockedPubKeysForUser(readerPublicKeyBytes)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("GetHotFeedScores: Problem getting blocked public keys: %v", err))
+			return
+		}
+	}
+
+	// Choose the ordered list to page through, the same way HandleHotFeedPageRequest does.
+	var hotFeedOrderedList []*HotFeedEntry
+	if requestData.Tag != "" {
+		var tagMap map[string][]*HotFeedEntry
+		if requestData.SortByNew {
+			tagMap = fes.PostTagToOrderedNewestEntries
+		} else {
+			tagMap = fes.PostTagToOrderedHotFeedEntries
+		}
+		hotFeedOrderedList = tagMap[requestData.Tag]
+	} else {
+		hotFeedOrderedList = fes.HotFeedOrderedList
+	}
+
+	hotFeedScores := []HotFeedScoreEntryResponse{}
+	nextOffset := -1
+	for ii := requestData.Offset; ii < len(hotFeedOrderedList); ii++ {
+		if len(hotFeedScores) >= limit {
+			nextOffset = ii
+			break
+		}
+		hotFeedEntry := hotFeedOrderedList[ii]
+		postEntry := utxoView.GetPostEntryForPostHash(hotFeedEntry.PostHash)
+		if postEntry == nil || postEntry.IsHidden {
+			continue
+		}
+		posterPublicKeyBase58Check := lib.PkToString(postEntry.PosterPublicKey, fes.Params)
+		if requestData.ExcludeBlockedCreators {
+			if _, isBlocked := blockedPublicKeys[posterPublicKeyBase58Check]; isBlocked {
+				continue
+			}
+			posterPKID := utxoView.GetPKIDForPublicKey(postEntry.PosterPublicKey)
+			if posterPKID != nil && IsRestrictedPubKey(
+				fes.GetGraylistState(posterPKID.PKID), fes.GetBlacklistState(posterPKID.PKID), "") {
+				continue
+			}
+		}
+		hotFeedScores = append(hotFeedScores, HotFeedScoreEntryResponse{
+			PostHashHex:                hotFeedEntry.PostHashHex,
+			HotnessScore:               hotFeedEntry.HotnessScore,
+			PosterPublicKeyBase58Check: posterPublicKeyBase58Check,
+		})
+	}
+
+	res := GetHotFeedScoresResponse{
+		HotFeedScores: hotFeedScores,
+		TotalEntries:  len(hotFeedOrderedList),
+		NextOffset:    nextOffset,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetHotFeedScores: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
 type AdminUpdateHotFeedAlgorithmRequest struct {
 	// Maximum score amount that any individual PKID can contribute to the global hot feed score
 	// before time decay. Ignored if set to zero.
@@ -1127,7 +1264,7 @@ type AdminUpdateHotFeedAlgorithmRequest struct {
 type AdminUpdateHotFeedAlgorithmResponse struct{}
 
 func (fes *APIServer) AdminUpdateHotFeedAlgorithm(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateHotFeedAlgorithmRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateHotFeedAlgorithm: Problem parsing request body: %v", err))
@@ -1206,7 +1343,7 @@ type AdminGetHotFeedAlgorithmResponse struct {
 }
 
 func (fes *APIServer) AdminGetHotFeedAlgorithm(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetHotFeedAlgorithmRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetHotFeedAlgorithm: Problem parsing request body: %v", err))
@@ -1240,7 +1377,7 @@ type AdminUpdateHotFeedPostMultiplierRequest struct {
 type AdminUpdateHotFeedPostMultiplierResponse struct{}
 
 func (fes *APIServer) AdminUpdateHotFeedPostMultiplier(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateHotFeedPostMultiplierRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateHotFeedPostMultiplier: Problem parsing request body: %v", err))
@@ -1299,7 +1436,7 @@ type AdminUpdateHotFeedUserMultiplierRequest struct {
 type AdminUpdateHotFeedUserMultiplierResponse struct{}
 
 func (fes *APIServer) AdminUpdateHotFeedUserMultiplier(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminUpdateHotFeedUserMultiplierRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminUpdateHotFeedUserMultiplier: Problem parsing request body: %v", err))
@@ -1368,7 +1505,7 @@ type AdminGetHotFeedUserMultiplierResponse struct {
 }
 
 func (fes *APIServer) AdminGetHotFeedUserMultiplier(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AdminGetHotFeedUserMultiplierRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AdminGetHotFeedUserMultiplier: Problem parsing request body: %v", err))
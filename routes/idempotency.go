@@ -0,0 +1,327 @@
+package routes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/golang/glog"
+)
+
+const (
+	// IdempotencyKeyHeader is an optional header on requests to idempotentRoutes. Retrying a request with the same
+	// key, for the same public key, returns the transaction constructed the first time instead of a new one.
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+
+	// Requests are serialized on one of a fixed number of locks, picked by hashing the global state key, so the
+	// locks don't grow with the number of keys ever used.
+	numIdempotencyKeyLocks = 256
+
+	// How often expired records are deleted from global state, and how many are looked at per seek.
+	idempotencyRecordSweepInterval  = 10 * time.Minute
+	idempotencyRecordSweepBatchSize = 1000
+)
+
+// idempotentRoutes construct transactions. A client that retries one of these after a network blip would otherwise
+// get back a second transaction spending different inputs, and could end up broadcasting both.
+var idempotentRoutes = map[string]bool{
+	RoutePathUpdateGlobalParams:       true,
+	RoutePathSwapIdentity:             true,
+	RoutePathCreateDAOCoinLimitOrder:  true,
+	RoutePathCreateDAOCoinMarketOrder: true,
+	RoutePathCancelDAOCoinLimitOrder:  true,
+}
+
+// IdempotencyRecord is the response to the first request made with an idempotency key.
+type IdempotencyRecord struct {
+	// Keys are scoped to a public key rather than a route, so this is used to reject reusing a key on another route.
+	RoutePath string
+	// The SHA-256 hash of the request body without its JWT, used to reject reusing a key for a different request.
+	// See getIdempotencyRequestHashHex.
+	RequestHashHex string
+	// The hash of the transaction in ResponseBytes, for debugging. Empty if the response couldn't be parsed.
+	TxnHashHex           string
+	ResponseBytes        []byte
+	ExpiresAtTStampNanos uint64
+}
+
+// idempotencyRequestPublicKeys holds the public key fields idempotentRoutes identify their transactor with, and the
+// JWT that public routes must send to use an idempotency key.
+type idempotencyRequestPublicKeys struct {
+	UpdaterPublicKeyBase58Check    string
+	TransactorPublicKeyBase58Check string
+	JWT                            string
+}
+
+// idempotencyResponseRecorder buffers a response so it can be saved before it's sent.
+type idempotencyResponseRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (recorder *idempotencyResponseRecorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *idempotencyResponseRecorder) Write(data []byte) (int, error) {
+	return recorder.body.Write(data)
+}
+
+func (recorder *idempotencyResponseRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+}
+
+// lockIdempotencyKey serializes requests that share a global state key, so a retry that arrives while the first
+// request is still being served waits for its response rather than constructing a second transaction.
+func (fes *APIServer) lockIdempotencyKey(dbKey []byte) (_unlock func()) {
+	hasher := fnv.New32a()
+	hasher.Write(dbKey)
+	mtx := &fes.mtxIdempotencyKeys[hasher.Sum32()%numIdempotencyKeyLocks]
+	mtx.Lock()
+	return mtx.Unlock
+}
+
+// HandleIdempotencyKey wraps a route in idempotentRoutes. Requests without an Idempotency-Key header pass straight
+// through. Otherwise, the first successful response for the key and the request's public key is saved in global
+// state for --idempotency-key-ttl-seconds, and returned as is to any repeat of the request during that time.
+// Otherwise anyone could claim a key for someone else's public key, so on routes that aren't admin-only the
+// request must also carry a JWT for the transactor.
+//
+// This only dedupes construction, and only on nodes that share global state. It does nothing to stop both a
+// transaction and a different one built later from being included on chain, or the same transaction from being
+// broadcast twice (which the mempool rejects anyway).
+func (fes *APIServer) HandleIdempotencyKey(inner http.Handler, routePath string, accessLevel AccessLevel) http.Handler {
+	return http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		idempotencyKey := req.Header.Get(IdempotencyKeyHeader)
+		if idempotencyKey == "" || fes.Config.IdempotencyKeyTTLSeconds == 0 {
+			inner.ServeHTTP(ww, req)
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			_AddBadRequestError(ww, fmt.Sprintf("HandleIdempotencyKey: %s header must be at most %d characters",
+				IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			return
+		}
+
+		// Peek at the body for the transactor's public key, then put it back for the handler.
+		bodyBytes, err := ioutil.ReadAll(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("HandleIdempotencyKey: Problem reading request body: %v", err))
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+		publicKeys := idempotencyRequestPublicKeys{}
+		if err = json.Unmarshal(bodyBytes, &publicKeys); err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("HandleIdempotencyKey: Problem parsing request body: %v", err))
+			return
+		}
+		publicKeyBase58Check := publicKeys.TransactorPublicKeyBase58Check
+		if publicKeyBase58Check == "" {
+			publicKeyBase58Check = publicKeys.UpdaterPublicKeyBase58Check
+		}
+		publicKeyBytes, err := GetPubKeyBytesFromBase58Check(publicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("HandleIdempotencyKey: %v", err))
+			return
+		}
+		// Admin routes have already checked the caller by the time they get here.
+		if accessLevel == PublicAccess {
+			isValid, err := fes.ValidateJWT(publicKeyBase58Check, publicKeys.JWT)
+			if err != nil || !isValid {
+				_AddForbiddenError(ww, fmt.Sprintf(
+					"HandleIdempotencyKey: %s header requires a valid JWT for %s", IdempotencyKeyHeader,
+					publicKeyBase58Check))
+				return
+			}
+		}
+		requestHashHex, err := getIdempotencyRequestHashHex(bodyBytes)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("HandleIdempotencyKey: %v", err))
+			return
+		}
+
+		dbKey := GlobalStateKeyForPublicKeyIdempotencyKeyToIdempotencyRecord(publicKeyBytes, idempotencyKey)
+		defer fes.lockIdempotencyKey(dbKey)()
+
+		record, err := fes.getIdempotencyRecord(dbKey)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf("HandleIdempotencyKey: %v", err))
+			return
+		}
+		if record != nil {
+			if record.RoutePath != routePath {
+				_AddBadRequestErrorWithCode(ww, ErrorCodeIdempotencyKeyReused, fmt.Sprintf(
+					"HandleIdempotencyKey: %s %s was already used for %s", IdempotencyKeyHeader, idempotencyKey,
+					record.RoutePath))
+				return
+			}
+			if record.RequestHashHex != requestHashHex {
+				_AddBadRequestErrorWithCode(ww, ErrorCodeIdempotencyKeyReused, fmt.Sprintf(
+					"HandleIdempotencyKey: %s %s was already used for a different request", IdempotencyKeyHeader,
+					idempotencyKey))
+				return
+			}
+			ww.Header().Set("Idempotent-Replayed", "true")
+			ww.Write(record.ResponseBytes)
+			return
+		}
+
+		// Error responses aren't saved, so that a request that failed can be retried with the same key. The handler
+		// gets a copy of the context AddErrorContext attached to ww, since that's where _AddHttpErrorWithCode looks.
+		recorder := &idempotencyResponseRecorder{header: ww.Header(), statusCode: http.StatusOK}
+		handlerWW := http.ResponseWriter(recorder)
+		if errorContext, ok := ww.(*errorContextResponseWriter); ok {
+			recorderErrorContext := *errorContext
+			recorderErrorContext.ResponseWriter = recorder
+			handlerWW = &recorderErrorContext
+		}
+		inner.ServeHTTP(handlerWW, req)
+
+		if recorder.statusCode == http.StatusOK {
+			record = &IdempotencyRecord{
+				RoutePath:      routePath,
+				RequestHashHex: requestHashHex,
+				TxnHashHex:     getTxnHashHexFromResponseBytes(recorder.body.Bytes()),
+				ResponseBytes:  recorder.body.Bytes(),
+				ExpiresAtTStampNanos: uint64(time.Now().Add(
+					time.Duration(fes.Config.IdempotencyKeyTTLSeconds) * time.Second).UnixNano()),
+			}
+			if err = fes.putIdempotencyRecord(dbKey, record); err != nil {
+				// The transaction was still constructed, so return it. A retry just won't be deduped.
+				logRequestErrorf(req.Context(), "HandleIdempotencyKey: %v", err)
+			}
+		}
+		ww.WriteHeader(recorder.statusCode)
+		ww.Write(recorder.body.Bytes())
+	})
+}
+
+// getIdempotencyRequestHashHex hashes a request body with its JWT field removed. A client retrying after its JWT
+// expired sends a fresh one, and that shouldn't make the retry look like a different request. The remaining fields
+// are re-encoded in sorted order, so reordering them doesn't change the hash either.
+func getIdempotencyRequestHashHex(bodyBytes []byte) (string, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(bodyBytes, &fields); err != nil {
+		return "", fmt.Errorf("getIdempotencyRequestHashHex: Problem parsing request body: %v", err)
+	}
+	delete(fields, "JWT")
+	canonicalBytes, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("getIdempotencyRequestHashHex: Problem encoding request body: %v", err)
+	}
+	requestHash := sha256.Sum256(canonicalBytes)
+	return hex.EncodeToString(requestHash[:]), nil
+}
+
+// getIdempotencyRecord returns nil if there's no record for dbKey or it has expired.
+func (fes *APIServer) getIdempotencyRecord(dbKey []byte) (*IdempotencyRecord, error) {
+	recordBytes, err := fes.GlobalState.Get(dbKey)
+	if err != nil {
+		return nil, fmt.Errorf("getIdempotencyRecord: Problem getting record: %v", err)
+	}
+	if recordBytes == nil {
+		return nil, nil
+	}
+	record := &IdempotencyRecord{}
+	if err = json.Unmarshal(recordBytes, record); err != nil {
+		return nil, fmt.Errorf("getIdempotencyRecord: Problem decoding record: %v", err)
+	}
+	if uint64(time.Now().UnixNano()) >= record.ExpiresAtTStampNanos {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// putIdempotencyRecord overwrites any existing record. Expired records are deleted by the sweeper started with
+// StartIdempotencyRecordSweeper, or replaced if their key is used again first.
+func (fes *APIServer) putIdempotencyRecord(dbKey []byte, record *IdempotencyRecord) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("putIdempotencyRecord: Problem encoding record: %v", err)
+	}
+	if err = fes.GlobalState.Put(dbKey, recordBytes); err != nil {
+		return fmt.Errorf("putIdempotencyRecord: Problem putting record: %v", err)
+	}
+	return nil
+}
+
+// getTxnHashHexFromResponseBytes returns the hash of the transaction in the TransactionHex field of a response, or
+// an empty string if there isn't a valid one.
+func getTxnHashHexFromResponseBytes(responseBytes []byte) string {
+	response := struct{ TransactionHex string }{}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return ""
+	}
+	txnBytes, err := hex.DecodeString(response.TransactionHex)
+	if err != nil {
+		return ""
+	}
+	txn := &lib.MsgDeSoTxn{}
+	if err = txn.FromBytes(txnBytes); err != nil {
+		return ""
+	}
+	return txn.Hash().String()
+}
+
+// StartIdempotencyRecordSweeper periodically deletes expired idempotency records from global state, since most keys
+// are never used again and their records would otherwise be kept forever.
+func (fes *APIServer) StartIdempotencyRecordSweeper() {
+	go func() {
+	out:
+		for {
+			select {
+			case <-time.After(idempotencyRecordSweepInterval):
+				fes.deleteExpiredIdempotencyRecords()
+			case <-fes.quit:
+				break out
+			}
+		}
+	}()
+}
+
+// deleteExpiredIdempotencyRecords walks every idempotency record and deletes the ones that have expired, or that
+// can't be decoded. It returns the number of records deleted.
+func (fes *APIServer) deleteExpiredIdempotencyRecords() (_numDeleted int) {
+	prefix := _GlobalStatePrefixPublicKeyIdempotencyKeyToIdempotencyRecord
+	nowNanos := uint64(time.Now().UnixNano())
+	numDeleted := 0
+	startKey := prefix
+	for {
+		// Idempotency keys vary in length, so the key length isn't checked.
+		keys, vals, err := fes.GlobalState.Seek(startKey, prefix, 0, idempotencyRecordSweepBatchSize,
+			false, true)
+		if err != nil {
+			glog.Errorf("deleteExpiredIdempotencyRecords: Problem seeking records: %v", err)
+			return numDeleted
+		}
+		for ii, key := range keys {
+			// The start key is inclusive, so skip the last key of the previous batch.
+			if bytes.Equal(key, startKey) {
+				continue
+			}
+			record := &IdempotencyRecord{}
+			if err = json.Unmarshal(vals[ii], record); err == nil && nowNanos < record.ExpiresAtTStampNanos {
+				continue
+			}
+			if err = fes.GlobalState.Delete(key); err != nil {
+				glog.Errorf("deleteExpiredIdempotencyRecords: Problem deleting record: %v", err)
+				continue
+			}
+			numDeleted++
+		}
+		if len(keys) < idempotencyRecordSweepBatchSize {
+			return numDeleted
+		}
+		startKey = keys[len(keys)-1]
+	}
+}
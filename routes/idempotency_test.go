@@ -0,0 +1,165 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/deso-smart/deso-backend/v3/config"
+	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleIdempotencyKey(t *testing.T) {
+	require := require.New(t)
+
+	db, dir := GetTestBadgerDb()
+	defer os.RemoveAll(dir)
+	defer db.Close()
+	fes := &APIServer{
+		GlobalState: &GlobalState{GlobalStateDB: db},
+		Config:      &config.Config{IdempotencyKeyTTLSeconds: 60},
+	}
+
+	// Each call constructs a "new" transaction, unless the request asks for an error.
+	numCalls := 0
+	inner := http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		numCalls++
+		requestData := struct {
+			Fail  bool
+			Nonce int
+		}{}
+		require.NoError(json.NewDecoder(req.Body).Decode(&requestData))
+		if requestData.Fail {
+			_AddBadRequestError(ww, "TestEndpoint: Failed")
+			return
+		}
+		json.NewEncoder(ww).Encode(struct{ Call int }{numCalls})
+	})
+	limitOrderHandler := AddErrorContext(fes.HandleIdempotencyKey(
+		inner, RoutePathCreateDAOCoinLimitOrder, PublicAccess), "CreateDAOCoinLimitOrder")
+	cancelHandler := AddErrorContext(fes.HandleIdempotencyKey(
+		inner, RoutePathCancelDAOCoinLimitOrder, PublicAccess), "CancelDAOCoinLimitOrder")
+	swapIdentityHandler := AddErrorContext(fes.HandleIdempotencyKey(
+		inner, RoutePathSwapIdentity, SuperAdminAccess), "SwapIdentity")
+
+	jwtsByPublicKey := make(map[string]string)
+	privKeysByPublicKey := make(map[string]*btcec.PrivateKey)
+	signJWT := func(privKey *btcec.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		signedToken, err := token.SignedString(privKey.ToECDSA())
+		require.NoError(err)
+		return signedToken
+	}
+	newPublicKey := func() string {
+		privKey, err := btcec.NewPrivateKey(btcec.S256())
+		require.NoError(err)
+		publicKey := lib.PkToString(privKey.PubKey().SerializeCompressed(), &lib.DeSoTestnetParams)
+		privKeysByPublicKey[publicKey] = privKey
+		jwtsByPublicKey[publicKey] = signJWT(privKey)
+		return publicKey
+	}
+	publicKey := newPublicKey()
+	nonce := 0
+	serveRequest := func(handler http.Handler, publicKey string, jwtToken string, idempotencyKey string, fail bool,
+	) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(fmt.Sprintf(
+			`{"TransactorPublicKeyBase58Check": "%s", "JWT": "%s", "Fail": %v, "Nonce": %d}`,
+			publicKey, jwtToken, fail, nonce))))
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	serve := func(handler http.Handler, publicKey string, idempotencyKey string, fail bool) *httptest.ResponseRecorder {
+		return serveRequest(handler, publicKey, jwtsByPublicKey[publicKey], idempotencyKey, fail)
+	}
+
+	// Without a key every request constructs a new transaction.
+	require.Equal("{\"Call\":1}\n", serve(limitOrderHandler, publicKey, "", false).Body.String())
+	require.Equal("{\"Call\":2}\n", serve(limitOrderHandler, publicKey, "", false).Body.String())
+
+	// Repeating a key returns the first response.
+	require.Equal("{\"Call\":3}\n", serve(limitOrderHandler, publicKey, "key1", false).Body.String())
+	rr := serve(limitOrderHandler, publicKey, "key1", false)
+	require.Equal(http.StatusOK, rr.Code)
+	require.Equal("{\"Call\":3}\n", rr.Body.String())
+	require.Equal("true", rr.Header().Get("Idempotent-Replayed"))
+	require.Equal(3, numCalls)
+
+	// A retry with a fresh JWT is still the same request.
+	freshJWT := signJWT(privKeysByPublicKey[publicKey])
+	require.NotEqual(jwtsByPublicKey[publicKey], freshJWT)
+	rr = serveRequest(limitOrderHandler, publicKey, freshJWT, "key1", false)
+	require.Equal(http.StatusOK, rr.Code)
+	require.Equal("{\"Call\":3}\n", rr.Body.String())
+
+	// Keys are scoped per public key.
+	require.Equal("{\"Call\":4}\n", serve(limitOrderHandler, newPublicKey(), "key1", false).Body.String())
+
+	// Reusing a key on another route is an error.
+	rr = serve(cancelHandler, publicKey, "key1", false)
+	require.Equal(http.StatusBadRequest, rr.Code)
+	errorResponse := ErrorResponse{}
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+	require.Equal(ErrorCodeIdempotencyKeyReused, errorResponse.Code)
+	require.Equal("CancelDAOCoinLimitOrder", errorResponse.Endpoint)
+
+	// Reusing a key for a different request is an error.
+	nonce++
+	rr = serve(limitOrderHandler, publicKey, "key1", false)
+	require.Equal(http.StatusBadRequest, rr.Code)
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+	require.Equal(ErrorCodeIdempotencyKeyReused, errorResponse.Code)
+	nonce--
+
+	// Public routes need a JWT for the transactor to use a key, but admin routes don't.
+	require.Equal(http.StatusForbidden, serveRequest(limitOrderHandler, publicKey, "", "key3", false).Code)
+	require.Equal(http.StatusForbidden, serveRequest(
+		limitOrderHandler, publicKey, jwtsByPublicKey[newPublicKey()], "key3", false).Code)
+	require.Equal(http.StatusOK, serveRequest(limitOrderHandler, publicKey, "", "", false).Code)
+	require.Equal(5, numCalls)
+	require.Equal("{\"Call\":6}\n", serveRequest(swapIdentityHandler, publicKey, "", "key4", false).Body.String())
+	require.Equal("{\"Call\":6}\n", serveRequest(swapIdentityHandler, publicKey, "", "key4", false).Body.String())
+
+	// Errors aren't saved, so the request can be retried with the same key.
+	rr = serve(limitOrderHandler, publicKey, "key2", true)
+	require.Equal(http.StatusBadRequest, rr.Code)
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+	require.Equal("CreateDAOCoinLimitOrder", errorResponse.Endpoint)
+	require.Equal("{\"Call\":8}\n", serve(limitOrderHandler, publicKey, "key2", false).Body.String())
+
+	// Expired records are ignored.
+	dbKey := GlobalStateKeyForPublicKeyIdempotencyKeyToIdempotencyRecord(
+		lib.MustBase58CheckDecode(publicKey), "key1")
+	record, err := fes.getIdempotencyRecord(dbKey)
+	require.NoError(err)
+	record.ExpiresAtTStampNanos = 1
+	require.NoError(fes.putIdempotencyRecord(dbKey, record))
+	require.Equal("{\"Call\":9}\n", serve(limitOrderHandler, publicKey, "key1", false).Body.String())
+
+	// The sweeper deletes expired records and leaves the rest.
+	dbKey = GlobalStateKeyForPublicKeyIdempotencyKeyToIdempotencyRecord(
+		lib.MustBase58CheckDecode(publicKey), "key2")
+	record, err = fes.getIdempotencyRecord(dbKey)
+	require.NoError(err)
+	record.ExpiresAtTStampNanos = 1
+	require.NoError(fes.putIdempotencyRecord(dbKey, record))
+	require.Equal(1, fes.deleteExpiredIdempotencyRecords())
+	recordBytes, err := fes.GlobalState.Get(dbKey)
+	require.NoError(err)
+	require.Nil(recordBytes)
+	require.Equal(0, fes.deleteExpiredIdempotencyRecords())
+	require.Equal("{\"Call\":9}\n", serve(limitOrderHandler, publicKey, "key1", false).Body.String())
+}
@@ -480,7 +480,7 @@ func (fes *APIServer) getOtherPartyInThread(messageEntry *lib.MessageEntry,
 
 // GetMessagesStateless ...
 func (fes *APIServer) GetMessagesStateless(ww http.ResponseWriter, rr *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	getMessagesRequest := GetMessagesStatelessRequest{}
 	if err := decoder.Decode(&getMessagesRequest); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetMessagesStateless: Error parsing request body: %v", err))
@@ -490,7 +490,7 @@ func (fes *APIServer) GetMessagesStateless(ww http.ResponseWriter, rr *http.Requ
 	// Decode the public key into bytes.
 	publicKeyBytes, _, err := lib.Base58CheckDecode(getMessagesRequest.PublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetMessagesStateless: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetMessagesStateless: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -498,7 +498,7 @@ func (fes *APIServer) GetMessagesStateless(ww http.ResponseWriter, rr *http.Requ
 	if getMessagesRequest.FetchAfterPublicKeyBase58Check != "" {
 		fetchAfterPublicKeyBytes, _, err = lib.Base58CheckDecode(getMessagesRequest.FetchAfterPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetMessagesStateless: Problem decoding fetch after public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetMessagesStateless: Problem decoding fetch after public key: %v", err))
 			return
 		}
 	}
@@ -580,7 +580,7 @@ type SendMessageStatelessResponse struct {
 // SendMessageStateless ...
 func (fes *APIServer) SendMessageStateless(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SendMessageStatelessRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SendMessageStateless: Problem parsing request body: %v", err))
@@ -673,7 +673,7 @@ func (fes *APIServer) SendMessageStateless(ww http.ResponseWriter, req *http.Req
 	if len(requestData.SenderMessagingGroupKeyName) > 0 {
 		senderMessagingPublicKey, _, err = lib.Base58CheckDecode(checkPartyMessagingKeysResponse.SenderMessagingPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("SendMessageStateless: Problem decoding sender messaging public key "+
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("SendMessageStateless: Problem decoding sender messaging public key "+
 				"(public key: %v, key name: %v)", checkPartyMessagingKeysResponse.SenderMessagingPublicKeyBase58Check,
 				senderMessagingGroupKeyNameBytes))
 			return
@@ -686,7 +686,7 @@ func (fes *APIServer) SendMessageStateless(ww http.ResponseWriter, req *http.Req
 	if len(requestData.RecipientMessagingGroupKeyName) > 0 {
 		recipientMessagingPublicKey, _, err = lib.Base58CheckDecode(checkPartyMessagingKeysResponse.RecipientMessagingPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("SendMessageStateless: Problem decoding recipient messaging public key "+
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("SendMessageStateless: Problem decoding recipient messaging public key "+
 				"(public key: %v, key name: %v)", checkPartyMessagingKeysResponse.RecipientMessagingPublicKeyBase58Check,
 				recipientMessagingGroupKeyNameBytes))
 			return
@@ -752,7 +752,7 @@ type MarkContactMessagesReadRequest struct {
 }
 
 func (fes *APIServer) MarkContactMessagesRead(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := MarkContactMessagesReadRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("MarkUserContactMessagesRead: Problem parsing request body: %v", err))
@@ -767,13 +767,13 @@ func (fes *APIServer) MarkContactMessagesRead(ww http.ResponseWriter, req *http.
 
 	userPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("MarkUserContactMessagesRead: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("MarkUserContactMessagesRead: Problem decoding user public key: %v", err))
 		return
 	}
 
 	contactPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.ContactPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("MarkUserContactMessagesRead: Problem decoding contact public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("MarkUserContactMessagesRead: Problem decoding contact public key: %v", err))
 		return
 	}
 
@@ -790,7 +790,7 @@ type MarkAllMessagesReadRequest struct {
 }
 
 func (fes *APIServer) MarkAllMessagesRead(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := MarkAllMessagesReadRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("MarkUserContactMessagesRead: Problem parsing request body: %v", err))
@@ -805,7 +805,7 @@ func (fes *APIServer) MarkAllMessagesRead(ww http.ResponseWriter, req *http.Requ
 
 	userPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("MarkUserContactMessagesRead: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("MarkUserContactMessagesRead: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -918,7 +918,7 @@ type RegisterMessagingGroupKeyResponse struct {
 // RegisterMessagingGroupKey ...
 func (fes *APIServer) RegisterMessagingGroupKey(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := RegisterMessagingGroupKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("RegisterMessagingGroupKey: Problem parsing request body: %v", err))
@@ -1040,7 +1040,7 @@ type GetAllMessagingGroupKeysResponse struct {
 
 // GetAllMessagingGroupKeys ...
 func (fes *APIServer) GetAllMessagingGroupKeys(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetAllMessagingGroupKeysRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetAllMessagingGroupKeys: Problem parsing request body: %v", err))
@@ -1173,7 +1173,7 @@ func (fes *APIServer) CreateCheckPartyMessagingKeysResponse(senderPublicKey *lib
 func (fes *APIServer) CheckPartyMessagingKeys(ww http.ResponseWriter, req *http.Request) {
 
 	// Decode the request.
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := CheckPartyMessagingKeysRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("CheckPartyMessagingKeys: Problem parsing request body: %v", err))
@@ -1183,7 +1183,7 @@ func (fes *APIServer) CheckPartyMessagingKeys(ww http.ResponseWriter, req *http.
 	// Decode the sender public key.
 	senderPublicKey, _, err := lib.Base58CheckDecode(requestData.SenderPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CheckPartyMessagingKeys: Problem decoding sender public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("CheckPartyMessagingKeys: Problem decoding sender public key: %v", err))
 		return
 	}
 	// Parse the sender's messaging key name from string to a byte array.
@@ -1197,7 +1197,7 @@ func (fes *APIServer) CheckPartyMessagingKeys(ww http.ResponseWriter, req *http.
 	// Decode the recipient public key.
 	recipientPublicKey, _, err := lib.Base58CheckDecode(requestData.RecipientPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CheckPartyMessagingKeys: Problem decoding recipient public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("CheckPartyMessagingKeys: Problem decoding recipient public key: %v", err))
 		return
 	}
 	// Parse the recipient's messaging key name from string to a byte array.
@@ -1242,7 +1242,7 @@ type GetBulkMessagingPublicKeysResponse struct {
 // identified by <GroupOwnerPublicKeysBase58Check, MessagingGroupKeyNames>. If all the groups exist, it will return
 // the messaging public keys of the groups.
 func (fes *APIServer) GetBulkMessagingPublicKeys(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetBulkMessagingPublicKeysRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetBulkMessagingPublicKeys: Problem parsing request body: %v", err))
@@ -1259,7 +1259,7 @@ func (fes *APIServer) GetBulkMessagingPublicKeys(ww http.ResponseWriter, req *ht
 	for _, groupOwnerPublicKeyBase58Check := range requestData.GroupOwnerPublicKeysBase58Check {
 		groupOwnerPublicKeyBytes, _, err := lib.Base58CheckDecode(groupOwnerPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetBulkMessagingPublicKeys: Problem decoding group owner public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetBulkMessagingPublicKeys: Problem decoding group owner public key: %v", err))
 			return
 		}
 		groupOwnerPublicKey := lib.NewPublicKey(groupOwnerPublicKeyBytes)
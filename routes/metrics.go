@@ -0,0 +1,212 @@
+package routes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RoutePathMetrics is unprefixed since that's where Prometheus scrapers look by default.
+	RoutePathMetrics = "/metrics"
+)
+
+// metricsLatencyBucketsSeconds are the upper bounds of the request latency histogram buckets. They match the
+// Prometheus client defaults so dashboards built for other services work unchanged.
+var metricsLatencyBucketsSeconds = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type routeStatusKey struct {
+	Route      string
+	StatusCode int
+}
+
+type latencyHistogram struct {
+	// BucketCounts[ii] is the number of observations <= metricsLatencyBucketsSeconds[ii]. Buckets are cumulative
+	// when rendered, not when stored.
+	BucketCounts []uint64
+	Count        uint64
+	SumSeconds   float64
+}
+
+type globalStateOpKey struct {
+	Op      string
+	IsError bool
+}
+
+// Metrics collects per-route request counts and latencies along with global state call counts, and renders
+// them in the Prometheus text exposition format. A nil *Metrics is valid and records nothing, so callers
+// don't need to check whether metrics are enabled.
+type Metrics struct {
+	mtx sync.Mutex
+
+	requestCounts    map[routeStatusKey]uint64
+	requestLatencies map[string]*latencyHistogram
+	globalStateCalls map[globalStateOpKey]uint64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requestCounts:    make(map[routeStatusKey]uint64),
+		requestLatencies: make(map[string]*latencyHistogram),
+		globalStateCalls: make(map[globalStateOpKey]uint64),
+	}
+}
+
+// RecordRequest records a single completed request to route.
+func (metrics *Metrics) RecordRequest(route string, statusCode int, duration time.Duration) {
+	if metrics == nil {
+		return
+	}
+	metrics.mtx.Lock()
+	defer metrics.mtx.Unlock()
+
+	metrics.requestCounts[routeStatusKey{Route: route, StatusCode: statusCode}]++
+
+	histogram, exists := metrics.requestLatencies[route]
+	if !exists {
+		histogram = &latencyHistogram{BucketCounts: make([]uint64, len(metricsLatencyBucketsSeconds))}
+		metrics.requestLatencies[route] = histogram
+	}
+	seconds := duration.Seconds()
+	for ii, upperBound := range metricsLatencyBucketsSeconds {
+		if seconds <= upperBound {
+			histogram.BucketCounts[ii]++
+			break
+		}
+	}
+	histogram.Count++
+	histogram.SumSeconds += seconds
+}
+
+// RecordGlobalStateCall records a single global state operation such as "get", "put", or "seek".
+func (metrics *Metrics) RecordGlobalStateCall(op string, err error) {
+	if metrics == nil {
+		return
+	}
+	metrics.mtx.Lock()
+	defer metrics.mtx.Unlock()
+
+	metrics.globalStateCalls[globalStateOpKey{Op: op, IsError: err != nil}]++
+}
+
+// WritePrometheusText renders every metric in the Prometheus text exposition format.
+func (metrics *Metrics) WritePrometheusText(ww io.Writer) {
+	metrics.mtx.Lock()
+	defer metrics.mtx.Unlock()
+
+	requestCountKeys := make([]routeStatusKey, 0, len(metrics.requestCounts))
+	for key := range metrics.requestCounts {
+		requestCountKeys = append(requestCountKeys, key)
+	}
+	sort.Slice(requestCountKeys, func(ii, jj int) bool {
+		if requestCountKeys[ii].Route != requestCountKeys[jj].Route {
+			return requestCountKeys[ii].Route < requestCountKeys[jj].Route
+		}
+		return requestCountKeys[ii].StatusCode < requestCountKeys[jj].StatusCode
+	})
+	fmt.Fprintln(ww, "# HELP deso_api_requests_total Number of API requests by route and status code.")
+	fmt.Fprintln(ww, "# TYPE deso_api_requests_total counter")
+	for _, key := range requestCountKeys {
+		fmt.Fprintf(ww, "deso_api_requests_total{route=%s,code=\"%d\"} %d\n",
+			quoteMetricsLabel(key.Route), key.StatusCode, metrics.requestCounts[key])
+	}
+
+	routes := make([]string, 0, len(metrics.requestLatencies))
+	for route := range metrics.requestLatencies {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	fmt.Fprintln(ww, "# HELP deso_api_request_duration_seconds API request latency by route.")
+	fmt.Fprintln(ww, "# TYPE deso_api_request_duration_seconds histogram")
+	for _, route := range routes {
+		histogram := metrics.requestLatencies[route]
+		routeLabel := quoteMetricsLabel(route)
+		cumulativeCount := uint64(0)
+		for ii, upperBound := range metricsLatencyBucketsSeconds {
+			cumulativeCount += histogram.BucketCounts[ii]
+			fmt.Fprintf(ww, "deso_api_request_duration_seconds_bucket{route=%s,le=\"%s\"} %d\n",
+				routeLabel, strconv.FormatFloat(upperBound, 'g', -1, 64), cumulativeCount)
+		}
+		fmt.Fprintf(ww, "deso_api_request_duration_seconds_bucket{route=%s,le=\"+Inf\"} %d\n",
+			routeLabel, histogram.Count)
+		fmt.Fprintf(ww, "deso_api_request_duration_seconds_sum{route=%s} %s\n",
+			routeLabel, strconv.FormatFloat(histogram.SumSeconds, 'g', -1, 64))
+		fmt.Fprintf(ww, "deso_api_request_duration_seconds_count{route=%s} %d\n", routeLabel, histogram.Count)
+	}
+
+	globalStateKeys := make([]globalStateOpKey, 0, len(metrics.globalStateCalls))
+	for key := range metrics.globalStateCalls {
+		globalStateKeys = append(globalStateKeys, key)
+	}
+	sort.Slice(globalStateKeys, func(ii, jj int) bool {
+		if globalStateKeys[ii].Op != globalStateKeys[jj].Op {
+			return globalStateKeys[ii].Op < globalStateKeys[jj].Op
+		}
+		return !globalStateKeys[ii].IsError && globalStateKeys[jj].IsError
+	})
+	fmt.Fprintln(ww, "# HELP deso_global_state_calls_total Number of global state calls by operation and result.")
+	fmt.Fprintln(ww, "# TYPE deso_global_state_calls_total counter")
+	for _, key := range globalStateKeys {
+		result := "ok"
+		if key.IsError {
+			result = "error"
+		}
+		fmt.Fprintf(ww, "deso_global_state_calls_total{op=%s,result=\"%s\"} %d\n",
+			quoteMetricsLabel(key.Op), result, metrics.globalStateCalls[key])
+	}
+}
+
+// quoteMetricsLabel escapes a label value as required by the Prometheus text format.
+func quoteMetricsLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+// GetMetrics serves the collected metrics to a Prometheus scraper. It's only routed when --enable-metrics is set.
+func (fes *APIServer) GetMetrics(ww http.ResponseWriter, rr *http.Request) {
+	ww.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fes.Metrics.WritePrometheusText(ww)
+}
+
+// statusRecordingResponseWriter remembers the status code written by a handler. Handlers that never call
+// WriteHeader implicitly return 200.
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (ww *statusRecordingResponseWriter) WriteHeader(statusCode int) {
+	ww.statusCode = statusCode
+	ww.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Hijack lets WebSocket handlers such as GetDAOCoinOrderBookFeed take over the connection. The handshake is written
+// to the hijacked connection directly, so WebSocket requests are recorded as 200s.
+func (ww *statusRecordingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := ww.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Hijack: Underlying ResponseWriter does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// RecordMetrics records the latency and status code of every request to the wrapped route.
+func RecordMetrics(inner http.Handler, name string, metrics *Metrics) http.Handler {
+	return http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := &statusRecordingResponseWriter{ResponseWriter: ww, statusCode: http.StatusOK}
+
+		inner.ServeHTTP(recorder, req)
+
+		metrics.RecordRequest(name, recorder.statusCode, time.Since(start))
+	})
+}
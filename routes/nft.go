@@ -100,7 +100,7 @@ type CreateNFTResponse struct {
 }
 
 func (fes *APIServer) CreateNFT(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := CreateNFTRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("CreateNFT: Error parsing request body: %v", err))
@@ -225,7 +225,7 @@ func (fes *APIServer) CreateNFT(ww http.ResponseWriter, req *http.Request) {
 	// Get the updater's public key.
 	updaterPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UpdaterPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateNFT: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("CreateNFT: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -318,7 +318,7 @@ type UpdateNFTResponse struct {
 }
 
 func (fes *APIServer) UpdateNFT(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := UpdateNFTRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("UpdateNFT: Error parsing request body: %v", err))
@@ -373,7 +373,7 @@ func (fes *APIServer) UpdateNFT(ww http.ResponseWriter, req *http.Request) {
 	// Get the updater's public key.
 	updaterPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UpdaterPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("UpdateNFT: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("UpdateNFT: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -468,7 +468,7 @@ type CreateNFTBidResponse struct {
 }
 
 func (fes *APIServer) CreateNFTBid(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := CreateNFTBidRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("CreateNFTBid: Error parsing request body: %v", err))
@@ -516,7 +516,7 @@ func (fes *APIServer) CreateNFTBid(ww http.ResponseWriter, req *http.Request) {
 	// Get the updater's public key.
 	updaterPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UpdaterPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateNFTBid: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("CreateNFTBid: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -629,7 +629,7 @@ type AcceptNFTBidResponse struct {
 }
 
 func (fes *APIServer) AcceptNFTBid(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AcceptNFTBidRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AcceptNFTBid: Error parsing request body: %v", err))
@@ -678,7 +678,7 @@ func (fes *APIServer) AcceptNFTBid(ww http.ResponseWriter, req *http.Request) {
 	// Get the updater's public key.
 	updaterPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UpdaterPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("AcceptNFTBid: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AcceptNFTBid: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -692,7 +692,7 @@ func (fes *APIServer) AcceptNFTBid(ww http.ResponseWriter, req *http.Request) {
 	// Get the bidder's public key.
 	bidderPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.BidderPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("AcceptNFTBid: Problem decoding bidder public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AcceptNFTBid: Problem decoding bidder public key: %v", err))
 		return
 	}
 
@@ -773,7 +773,7 @@ type GetNFTShowcaseResponse struct {
 }
 
 func (fes *APIServer) GetNFTShowcase(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTShowcaseRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTShowcase: Error parsing request body: %v", err))
@@ -785,7 +785,7 @@ func (fes *APIServer) GetNFTShowcase(ww http.ResponseWriter, req *http.Request)
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTShowcase: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTShowcase: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -871,7 +871,7 @@ type GetNextNFTShowcaseResponse struct {
 }
 
 func (fes *APIServer) GetNextNFTShowcase(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNextNFTShowcaseRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNextNFTShowcase: Error parsing request body: %v", err))
@@ -921,7 +921,7 @@ type GetNFTsForUserResponse struct {
 }
 
 func (fes *APIServer) GetNFTsForUser(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTsForUserRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTsForUser: Error parsing request body: %v", err))
@@ -934,7 +934,7 @@ func (fes *APIServer) GetNFTsForUser(ww http.ResponseWriter, req *http.Request)
 	}
 	userPublicKey, _, err := lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetNFTsForUser: Problem decoding reader public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTsForUser: Problem decoding reader public key: %v", err))
 		return
 	}
 
@@ -942,7 +942,7 @@ func (fes *APIServer) GetNFTsForUser(ww http.ResponseWriter, req *http.Request)
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTsForUser: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTsForUser: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -1037,7 +1037,7 @@ type GetNFTBidsForUserResponse struct {
 }
 
 func (fes *APIServer) GetNFTBidsForUser(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTBidsForUserRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTBidsForUser: Error parsing request body: %v", err))
@@ -1049,7 +1049,7 @@ func (fes *APIServer) GetNFTBidsForUser(ww http.ResponseWriter, req *http.Reques
 	if requestData.UserPublicKeyBase58Check != "" {
 		userPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.UserPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTBidsForUser: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTBidsForUser: Problem decoding reader public key: %v", err))
 			return
 		}
 	} else {
@@ -1061,7 +1061,7 @@ func (fes *APIServer) GetNFTBidsForUser(ww http.ResponseWriter, req *http.Reques
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTBidsForUser: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTBidsForUser: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -1126,7 +1126,7 @@ type GetNFTBidsForNFTPostResponse struct {
 }
 
 func (fes *APIServer) GetNFTBidsForNFTPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTBidsForNFTPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTBidsForNFTPost: Error parsing request body: %v", err))
@@ -1138,7 +1138,7 @@ func (fes *APIServer) GetNFTBidsForNFTPost(ww http.ResponseWriter, req *http.Req
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTBidsForNFTPost: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTBidsForNFTPost: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -1208,7 +1208,7 @@ type GetNFTCollectionSummaryResponse struct {
 }
 
 func (fes *APIServer) GetNFTCollectionSummary(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTCollectionSummaryRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTCollectionSummary: Error parsing request body: %v", err))
@@ -1246,7 +1246,7 @@ func (fes *APIServer) GetNFTCollectionSummary(ww http.ResponseWriter, req *http.
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTCollectionSummary: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTCollectionSummary: Problem decoding reader public key: %v", err))
 			return
 		}
 		readerPKID = utxoView.GetPKIDForPublicKey(readerPublicKeyBytes).PKID
@@ -1300,7 +1300,7 @@ type GetNFTEntriesForPostHashResponse struct {
 }
 
 func (fes *APIServer) GetNFTEntriesForPostHash(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTEntriesForPostHashRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTEntriesForPostHash: Error parsing request body: %v", err))
@@ -1342,7 +1342,7 @@ func (fes *APIServer) GetNFTEntriesForPostHash(ww http.ResponseWriter, req *http
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTEntriesForPostHash: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTEntriesForPostHash: Problem decoding reader public key: %v", err))
 			return
 		}
 		readerPKID = utxoView.GetPKIDForPublicKey(readerPublicKeyBytes).PKID
@@ -1545,7 +1545,7 @@ type TransferNFTResponse struct {
 }
 
 func (fes *APIServer) TransferNFT(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := TransferNFTRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("TransferNFT: Error parsing request body: %v", err))
@@ -1593,7 +1593,7 @@ func (fes *APIServer) TransferNFT(ww http.ResponseWriter, req *http.Request) {
 	// Get the sender's public key.
 	senderPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.SenderPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferNFT: Problem decoding sender public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferNFT: Problem decoding sender public key: %v", err))
 		return
 	}
 
@@ -1607,7 +1607,7 @@ func (fes *APIServer) TransferNFT(ww http.ResponseWriter, req *http.Request) {
 	// Get the receiver's public key.
 	receiverPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.ReceiverPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferNFT: Problem decoding receiver public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferNFT: Problem decoding receiver public key: %v", err))
 		return
 	}
 
@@ -1706,7 +1706,7 @@ type AcceptNFTTransferResponse struct {
 }
 
 func (fes *APIServer) AcceptNFTTransfer(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AcceptNFTTransferRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AcceptNFTTransfer: Error parsing request body: %v", err))
@@ -1750,7 +1750,7 @@ func (fes *APIServer) AcceptNFTTransfer(ww http.ResponseWriter, req *http.Reques
 	// Get the updater's public key.
 	updaterPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UpdaterPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("AcceptNFTTransfer: Problem decoding updater public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("AcceptNFTTransfer: Problem decoding updater public key: %v", err))
 		return
 	}
 
@@ -1845,7 +1845,7 @@ type BurnNFTResponse struct {
 }
 
 func (fes *APIServer) BurnNFT(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := BurnNFTRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("BurnNFT: Error parsing request body: %v", err))
@@ -1889,7 +1889,7 @@ func (fes *APIServer) BurnNFT(ww http.ResponseWriter, req *http.Request) {
 	// Get the updater's public key.
 	updaterPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.UpdaterPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("BurnNFT: Problem decoding updater public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("BurnNFT: Problem decoding updater public key: %v", err))
 		return
 	}
 
@@ -1988,7 +1988,7 @@ type GetNFTsCreatedByPublicKeyResponse struct {
 
 // GetNFTsCreatedByPublicKey gets paginated NFTs for a public key or username.
 func (fes *APIServer) GetNFTsCreatedByPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNFTsCreatedByPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetNFTsCreatedByPublicKey: Error parsing request body: %v", err))
@@ -2007,7 +2007,7 @@ func (fes *APIServer) GetNFTsCreatedByPublicKey(ww http.ResponseWriter, req *htt
 	if requestData.PublicKeyBase58Check != "" {
 		publicKeyBytes, _, err = lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTsCreatedByPublicKey: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTsCreatedByPublicKey: Problem decoding user public key: %v", err))
 			return
 		}
 	} else {
@@ -2027,7 +2027,7 @@ func (fes *APIServer) GetNFTsCreatedByPublicKey(ww http.ResponseWriter, req *htt
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPk, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetNFTsCreatedByPublicKey: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetNFTsCreatedByPublicKey: Problem decoding reader public key: %v", err))
 			return
 		}
 		readerPKID = utxoView.GetPKIDForPublicKey(readerPk).PKID
@@ -776,7 +776,7 @@ func (fes *APIServer) GetGlobalFeedPostHashesForLastWeek() (_postHashes []*lib.B
 
 // GetPostsStateless ...
 func (fes *APIServer) GetPostsStateless(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetPostsStatelessRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetPostsStateless: Problem parsing request body: %v", err))
@@ -790,7 +790,7 @@ func (fes *APIServer) GetPostsStateless(ww http.ResponseWriter, req *http.Reques
 
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetPostsStateless: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetPostsStateless: Problem decoding user public key: %v", err))
 			return
 		}
 	}
@@ -981,7 +981,7 @@ type GetSinglePostResponse struct {
 }
 
 func (fes *APIServer) GetSinglePost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetSinglePostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetSinglePost: Problem parsing request body: %v", err))
@@ -1447,7 +1447,7 @@ type GetPostsForPublicKeyResponse struct {
 
 // GetPostsForPublicKey gets paginated posts for a public key or username.
 func (fes *APIServer) GetPostsForPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetPostsForPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetPostsForPublicKey: Error parsing request body: %v", err))
@@ -1466,7 +1466,7 @@ func (fes *APIServer) GetPostsForPublicKey(ww http.ResponseWriter, req *http.Req
 	if requestData.PublicKeyBase58Check != "" {
 		publicKeyBytes, _, err = lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetPostsForPublicKey: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetPostsForPublicKey: Problem decoding user public key: %v", err))
 			return
 		}
 	} else {
@@ -1485,7 +1485,7 @@ func (fes *APIServer) GetPostsForPublicKey(ww http.ResponseWriter, req *http.Req
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPk, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetPostsForPublicKey: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetPostsForPublicKey: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -1592,7 +1592,7 @@ type GetPostsDiamondedBySenderForReceiverResponse struct {
 }
 
 func (fes *APIServer) GetDiamondedPosts(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetPostsDiamondedBySenderForReceiverRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -1613,7 +1613,7 @@ func (fes *APIServer) GetDiamondedPosts(ww http.ResponseWriter, req *http.Reques
 		// Decode the receiver public key for which we are fetching posts that were diamonded.
 		receiverPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReceiverPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetDiamondedPosts: Problem decoding receiver public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDiamondedPosts: Problem decoding receiver public key: %v", err))
 			return
 		}
 		receiverProfileEntry = utxoView.GetProfileEntryForPublicKey(receiverPublicKeyBytes)
@@ -1635,7 +1635,7 @@ func (fes *APIServer) GetDiamondedPosts(ww http.ResponseWriter, req *http.Reques
 		// Decode the sender public key for which we are fetching posts that were diamonded.
 		senderPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.SenderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetDiamondedPosts: Problem decoding sender public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDiamondedPosts: Problem decoding sender public key: %v", err))
 			return
 		}
 		senderProfileEntry = utxoView.GetProfileEntryForPublicKey(senderPublicKeyBytes)
@@ -1656,7 +1656,7 @@ func (fes *APIServer) GetDiamondedPosts(ww http.ResponseWriter, req *http.Reques
 		// Decode the reader public key.
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetDiamondedPosts: Problem decoding reader public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDiamondedPosts: Problem decoding reader public key: %v", err))
 			return
 		}
 	}
@@ -1758,7 +1758,7 @@ type GetLikesForPostResponse struct {
 }
 
 func (fes *APIServer) GetLikesForPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetLikesForPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww,
@@ -1777,7 +1777,7 @@ func (fes *APIServer) GetLikesForPost(ww http.ResponseWriter, req *http.Request)
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetLikesForPost: Problem decoding user public key: %v : %s", err,
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetLikesForPost: Problem decoding user public key: %v : %s", err,
 				requestData.ReaderPublicKeyBase58Check))
 			return
 		}
@@ -1879,7 +1879,7 @@ type DiamondSenderResponse struct {
 }
 
 func (fes *APIServer) GetDiamondsForPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetDiamondsForPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetDiamondsForPost: Problem parsing request body: %v", err))
@@ -2014,7 +2014,7 @@ type GetRepostsForPostResponse struct {
 }
 
 func (fes *APIServer) GetRepostsForPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetRepostsForPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetRepostsForPost: Problem parsing request body: %v", err))
@@ -2032,7 +2032,7 @@ func (fes *APIServer) GetRepostsForPost(ww http.ResponseWriter, req *http.Reques
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetRepostsForPost: Problem decoding user public key: %v : %s", err, requestData.ReaderPublicKeyBase58Check))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetRepostsForPost: Problem decoding user public key: %v : %s", err, requestData.ReaderPublicKeyBase58Check))
 			return
 		}
 	}
@@ -2132,7 +2132,7 @@ type GetQuoteRepostsForPostResponse struct {
 }
 
 func (fes *APIServer) GetQuoteRepostsForPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetQuoteRepostsForPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetQuoteRepostsForPost: Problem parsing request body: %v", err))
@@ -2150,7 +2150,7 @@ func (fes *APIServer) GetQuoteRepostsForPost(ww http.ResponseWriter, req *http.R
 	if requestData.ReaderPublicKeyBase58Check != "" {
 		readerPublicKeyBytes, _, err = lib.Base58CheckDecode(requestData.ReaderPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetQuoteRepostsForPost: Problem decoding user public key: %v : %s",
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetQuoteRepostsForPost: Problem decoding user public key: %v : %s",
 				err, requestData.ReaderPublicKeyBase58Check))
 			return
 		}
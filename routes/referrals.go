@@ -8,6 +8,7 @@ import (
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/pkg/errors"
 )
 
 type GetReferralInfoForUserRequest struct {
@@ -21,7 +22,10 @@ type GetReferralInfoForUserResponse struct {
 }
 
 func (fes *APIServer) GetReferralInfoForUser(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetReferralInfoForUserRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -50,7 +54,8 @@ func (fes *APIServer) GetReferralInfoForUser(ww http.ResponseWriter, req *http.R
 	}
 
 	// Get the referral link info structs.
-	referralInfoResponses, err := fes.getReferralInfoResponsesForPubKey(publicKeyBytes, false /*includeReferredUsers*/)
+	referralInfoResponses, _, err := fes.getReferralInfoResponsesForPubKey(publicKeyBytes, false, /*includeReferredUsers*/
+		0 /*refereeOffset*/, 0 /*refereeLimit*/, "" /*startReferralHash*/, 0 /*limit*/, false /*reverse*/)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetReferralInfoForUser: Problem putting new referral hash and info: %v", err))
 		return
@@ -67,7 +72,7 @@ func (fes *APIServer) GetReferralInfoForUser(ww http.ResponseWriter, req *http.R
 }
 
 type GetReferralInfoForReferralHashRequest struct {
-	ReferralHash string
+	ReferralHash string `safeForLogging:"true"`
 }
 
 type GetReferralInfoForReferralHashResponse struct {
@@ -76,7 +81,10 @@ type GetReferralInfoForReferralHashResponse struct {
 }
 
 func (fes *APIServer) GetReferralInfoForReferralHash(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetReferralInfoForReferralHashRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -84,29 +92,125 @@ func (fes *APIServer) GetReferralInfoForReferralHash(ww http.ResponseWriter, req
 		return
 	}
 
+	if requestData.ReferralHash == "" {
+		_AddBadRequestError(ww, "GetReferralInfoForReferralHash: Must provide a ReferralHash")
+		return
+	}
+
 	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHash)
+	if errors.Cause(err) == ErrReferralHashNotFound {
+		_AddNotFoundErrorWithCode(ww, ErrorCodeReferralHashNotFound, fmt.Sprintf(
+			"GetReferralInfoForReferralHash: Referral hash %s not found", requestData.ReferralHash))
+		return
+	}
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetReferralInfoForReferralHash: Error getting referral info for referral hash: %v", err))
+		_AddInternalServerError(ww, fmt.Sprintf("GetReferralInfoForReferralHash: Error getting referral info for referral hash: %v", err))
 		return
 	}
 
+	// Only the referee-facing fields are returned so that we don't leak who the referrer is or what they're paid.
 	simpleReferralInfo := SimpleReferralInfo{
 		ReferralHashBase58:    referralInfo.ReferralHashBase58,
 		RefereeAmountUSDCents: referralInfo.RefereeAmountUSDCents,
+		RequiresJumio:         referralInfo.RequiresJumio,
 		MaxReferrals:          referralInfo.MaxReferrals,
 		TotalReferrals:        referralInfo.TotalReferrals,
+		RemainingReferrals:    getRemainingReferrals(referralInfo.MaxReferrals, referralInfo.TotalReferrals),
 	}
 
 	res := GetReferralInfoForReferralHashResponse{
 		ReferralInfoResponse: &SimpleReferralInfoResponse{
 			Info:     simpleReferralInfo,
-			IsActive: fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58),
+			IsActive: fes.isReferralHashActive(referralInfo),
 		},
 		CountrySignUpBonus: fes.GetCountryLevelSignUpBonusFromHeader(req),
 	}
 
 	if err = json.NewEncoder(ww).Encode(res); err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetReferralInfoForUser: Problem encoding response as JSON: %v", err))
+		_AddBadRequestError(ww, fmt.Sprintf("GetReferralInfoForReferralHash: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
+type VerifyReferralOwnershipRequest struct {
+	ReferralHashBase58                  string `safeForLogging:"true"`
+	ClaimedReferrerPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Optional. If a valid admin public key and JWT are provided, the response will include the actual owner of
+	// the referral hash.
+	AdminPublicKey string `safeForLogging:"true"`
+	JWT            string
+}
+
+type VerifyReferralOwnershipResponse struct {
+	IsOwner bool
+
+	// Only populated for admins.
+	ReferrerPublicKeyBase58Check string `safeForLogging:"true"`
+}
+
+func (fes *APIServer) VerifyReferralOwnership(ww http.ResponseWriter, req *http.Request) {
+	if !fes.requireReferralsEnabled(ww) {
+		return
+	}
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := VerifyReferralOwnershipRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"VerifyReferralOwnership: Problem parsing request body: %v", err))
+		return
+	}
+
+	// Check whether the caller is an admin. Only admins are allowed to see who actually owns the referral hash.
+	isAdmin := false
+	if requestData.AdminPublicKey != "" {
+		isValid, err := fes.ValidateJWT(requestData.AdminPublicKey, requestData.JWT)
+		if err != nil || !isValid {
+			_AddBadRequestError(ww, fmt.Sprintf("VerifyReferralOwnership: Invalid token: %v", err))
+			return
+		}
+		isAdmin, _ = fes.UserAdminStatus(requestData.AdminPublicKey)
+	}
+
+	claimedPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.ClaimedReferrerPublicKeyBase58Check)
+	if err != nil || len(claimedPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"VerifyReferralOwnership: Problem decoding claimed referrer public key %s: %v",
+			requestData.ClaimedReferrerPublicKeyBase58Check, err))
+		return
+	}
+
+	referralInfo, err := fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("VerifyReferralOwnership: Error getting referral info for referral hash: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("VerifyReferralOwnership: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	// Resolve the claimed public key to a PKID so that referrers who have swapped keys are still matched correctly.
+	claimedPKIDEntry := utxoView.GetPKIDForPublicKey(claimedPublicKeyBytes)
+	if claimedPKIDEntry == nil || claimedPKIDEntry.PKID == nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"VerifyReferralOwnership: No PKID found for claimed referrer public key %s",
+			requestData.ClaimedReferrerPublicKeyBase58Check))
+		return
+	}
+
+	res := VerifyReferralOwnershipResponse{
+		IsOwner: referralInfo.ReferrerPKID != nil && claimedPKIDEntry.PKID.Eq(referralInfo.ReferrerPKID),
+	}
+	if isAdmin && referralInfo.ReferrerPKID != nil {
+		res.ReferrerPublicKeyBase58Check = lib.PkToString(
+			utxoView.GetPublicKeyForPKID(referralInfo.ReferrerPKID), fes.Params)
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("VerifyReferralOwnership: Problem encoding response as JSON: %v", err))
 		return
 	}
 }
@@ -1,12 +1,18 @@
 package routes
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	fmt "fmt"
 	"github.com/pkg/errors"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -26,7 +32,9 @@ import (
 
 const (
 	// MaxRequestBodySizeBytes is the maximum size of a request body we will
-	// generally be willing to process.
+	// generally be willing to process. It is the default for the
+	// --max-request-body-size-bytes flag, which routes can override individually
+	// with --route-max-request-body-size-bytes.
 	MaxRequestBodySizeBytes        = 10 * 1e6 // 10M
 	SeedInfoCookieKey              = "seed_info_cookie_key"
 	TwilioVoipCarrierType          = "voip"
@@ -41,36 +49,42 @@ const (
 	RoutePathGetQuoteRecloutsForPost = "/api/v0/get-quote-reclouts-for-post" // Deprecated
 
 	// base.go
-	RoutePathHealthCheck      = "/api/v0/health-check"
-	RoutePathGetExchangeRate  = "/api/v0/get-exchange-rate"
-	RoutePathGetAppState      = "/api/v0/get-app-state"
-	RoutePathGetIngressCookie = "/api/v0/get-ingress-cookie"
+	RoutePathHealthCheck                  = "/api/v0/health-check"
+	RoutePathGetHealthCheck               = "/api/v0/health"
+	RoutePathGetReadyCheck                = "/api/v0/ready"
+	RoutePathGetExchangeRate              = "/api/v0/get-exchange-rate"
+	RoutePathGetAppState                  = "/api/v0/get-app-state"
+	RoutePathGetIngressCookie             = "/api/v0/get-ingress-cookie"
+	RoutePathGetSupportedTransactionTypes = "/api/v0/get-supported-transaction-types"
 
 	// transaction.go
-	RoutePathGetTxn                   = "/api/v0/get-txn"
-	RoutePathSubmitTransaction        = "/api/v0/submit-transaction"
-	RoutePathUpdateProfile            = "/api/v0/update-profile"
-	RoutePathExchangeBitcoin          = "/api/v0/exchange-bitcoin"
-	RoutePathSendDeSo                 = "/api/v0/send-deso"
-	RoutePathSubmitPost               = "/api/v0/submit-post"
-	RoutePathCreateFollowTxnStateless = "/api/v0/create-follow-txn-stateless"
-	RoutePathCreateLikeStateless      = "/api/v0/create-like-stateless"
-	RoutePathBuyOrSellCreatorCoin     = "/api/v0/buy-or-sell-creator-coin"
-	RoutePathTransferCreatorCoin      = "/api/v0/transfer-creator-coin"
-	RoutePathSendDiamonds             = "/api/v0/send-diamonds"
-	RoutePathAuthorizeDerivedKey      = "/api/v0/authorize-derived-key"
-	RoutePathDAOCoin                  = "/api/v0/dao-coin"
-	RoutePathTransferDAOCoin          = "/api/v0/transfer-dao-coin"
-	RoutePathCreateDAOCoinLimitOrder  = "/api/v0/create-dao-coin-limit-order"
-	RoutePathCreateDAOCoinMarketOrder = "/api/v0/create-dao-coin-market-order"
-	RoutePathCancelDAOCoinLimitOrder  = "/api/v0/cancel-dao-coin-limit-order"
-	RoutePathAppendExtraData          = "/api/v0/append-extra-data"
-	RoutePathGetTransactionSpending   = "/api/v0/get-transaction-spending"
+	RoutePathGetTxn                               = "/api/v0/get-txn"
+	RoutePathSubmitTransaction                    = "/api/v0/submit-transaction"
+	RoutePathUpdateProfile                        = "/api/v0/update-profile"
+	RoutePathExchangeBitcoin                      = "/api/v0/exchange-bitcoin"
+	RoutePathSendDeSo                             = "/api/v0/send-deso"
+	RoutePathSubmitPost                           = "/api/v0/submit-post"
+	RoutePathCreateFollowTxnStateless             = "/api/v0/create-follow-txn-stateless"
+	RoutePathCreateLikeStateless                  = "/api/v0/create-like-stateless"
+	RoutePathBuyOrSellCreatorCoin                 = "/api/v0/buy-or-sell-creator-coin"
+	RoutePathTransferCreatorCoin                  = "/api/v0/transfer-creator-coin"
+	RoutePathSendDiamonds                         = "/api/v0/send-diamonds"
+	RoutePathAuthorizeDerivedKey                  = "/api/v0/authorize-derived-key"
+	RoutePathDAOCoin                              = "/api/v0/dao-coin"
+	RoutePathTransferDAOCoin                      = "/api/v0/transfer-dao-coin"
+	RoutePathCreateDAOCoinLimitOrder              = "/api/v0/create-dao-coin-limit-order"
+	RoutePathCreateDAOCoinMarketOrder             = "/api/v0/create-dao-coin-market-order"
+	RoutePathCancelDAOCoinLimitOrder              = "/api/v0/cancel-dao-coin-limit-order"
+	RoutePathConstructCancelAllDAOCoinLimitOrders = "/api/v0/construct-cancel-all-dao-coin-limit-orders"
+	RoutePathAppendExtraData                      = "/api/v0/append-extra-data"
+	RoutePathGetTransactionSpending               = "/api/v0/get-transaction-spending"
+	RoutePathDecodeTransactionHex                 = "/api/v0/decode-transaction-hex"
 
 	RoutePathGetUsersStateless                          = "/api/v0/get-users-stateless"
 	RoutePathDeleteIdentities                           = "/api/v0/delete-identities"
 	RoutePathGetProfiles                                = "/api/v0/get-profiles"
 	RoutePathGetSingleProfile                           = "/api/v0/get-single-profile"
+	RoutePathGetProfilesBatch                           = "/api/v0/get-profiles-batch"
 	RoutePathGetSingleProfilePicture                    = "/api/v0/get-single-profile-picture"
 	RoutePathGetHodlersForPublicKey                     = "/api/v0/get-hodlers-for-public-key"
 	RoutePathGetHodlersCountForPublicKeys               = "/api/v0/get-hodlers-count-for-public-keys"
@@ -96,7 +110,25 @@ const (
 
 	// dao_coin_exchange.go
 	RoutePathGetDaoCoinLimitOrders           = "/api/v0/get-dao-coin-limit-orders"
+	RoutePathGetDaoCoinLimitOrdersBatch      = "/api/v0/get-dao-coin-limit-orders-batch"
 	RoutePathGetTransactorDaoCoinLimitOrders = "/api/v0/get-transactor-dao-coin-limit-orders"
+	RoutePathConvertCoinUnits                = "/api/v0/convert-coin-units"
+	RoutePathGetEffectiveDAOCoinPrice        = "/api/v0/get-effective-dao-coin-price"
+	RoutePathGetDAOCoinMinOrderSize          = "/api/v0/get-dao-coin-min-order-size"
+	RoutePathGetExchangeFeeSchedule          = "/api/v0/get-exchange-fee-schedule"
+	RoutePathGetDAOCoinArbitrageOpportunity  = "/api/v0/get-dao-coin-arbitrage-opportunity"
+	RoutePathGetTransactorExchangeExposure   = "/api/v0/get-transactor-exchange-exposure"
+	RoutePathGetDAOCoinFillPreview           = "/api/v0/get-dao-coin-fill-preview"
+	RoutePathGetDAOCoinMarketsForCreator     = "/api/v0/get-dao-coin-markets-for-creator"
+	RoutePathGetDAOCoinLimitOrdersByIDs      = "/api/v0/get-dao-coin-limit-orders-by-ids"
+	RoutePathGetDAOCoinLimitOrderBook        = "/api/v0/get-dao-coin-limit-order-book"
+	RoutePathGetDAOCoinBestBidAsk            = "/api/v0/get-dao-coin-best-bid-ask"
+	RoutePathSimulateDAOCoinLimitOrderFill   = "/api/v0/simulate-dao-coin-limit-order-fill"
+	RoutePathGetDAOCoinOrderBookFeed         = "/api/v0/get-dao-coin-order-book-feed"
+	RoutePathGetDAOCoinTrades                = "/api/v0/get-dao-coin-trades"
+	RoutePathGetDAOCoinCandles               = "/api/v0/get-dao-coin-candles"
+	RoutePathGetDAOCoinMarketStats           = "/api/v0/get-dao-coin-market-stats"
+	RoutePathConvertDAOCoinPrice             = "/api/v0/convert-dao-coin-price"
 
 	// post.go
 	RoutePathGetPostsStateless      = "/api/v0/get-posts-stateless"
@@ -109,7 +141,8 @@ const (
 	RoutePathGetDiamondedPosts      = "/api/v0/get-diamonded-posts"
 
 	// hot_feed.go
-	RoutePathGetHotFeed = "/api/v0/get-hot-feed"
+	RoutePathGetHotFeed       = "/api/v0/get-hot-feed"
+	RoutePathGetHotFeedScores = "/api/v0/get-hot-feed-scores"
 
 	// nft.go
 	RoutePathCreateNFT                 = "/api/v0/create-nft"
@@ -150,6 +183,7 @@ const (
 	RoutePathSubmitPhoneNumberVerificationCode = "/api/v0/submit-phone-number-verification-code"
 	RoutePathResendVerifyEmail                 = "/api/v0/resend-verify-email"
 	RoutePathVerifyEmail                       = "/api/v0/verify-email"
+	RoutePathGetStarterDeSoForPrefix           = "/api/v0/get-starter-deso-for-prefix"
 	RoutePathJumioBegin                        = "/api/v0/jumio-begin"
 	RoutePathJumioCallback                     = "/api/v0/jumio-callback"
 	RoutePathJumioFlowFinished                 = "/api/v0/jumio-flow-finished"
@@ -182,6 +216,10 @@ const (
 	RoutePathNodeControl          = "/api/v0/admin/node-control"
 	RoutePathAdminGetMempoolStats = "/api/v0/admin/get-mempool-stats"
 
+	// verify.go
+	RoutePathAdminSendTestEmail    = "/api/v0/admin/send-test-email"
+	RoutePathAdminTestTwilioConfig = "/api/v0/admin/test-twilio-config"
+
 	// admin_buy_deso.go
 	RoutePathSetUSDCentsToDeSoReserveExchangeRate = "/api/v0/admin/set-usd-cents-to-deso-reserve-exchange-rate"
 	RoutePathGetUSDCentsToDeSoReserveExchangeRate = "/api/v0/admin/get-usd-cents-to-deso-reserve-exchange-rate"
@@ -189,8 +227,9 @@ const (
 	RoutePathGetBuyDeSoFeeBasisPoints             = "/api/v0/admin/get-buy-deso-fee-basis-points"
 
 	// admin_transaction.go
-	RoutePathGetGlobalParams                   = "/api/v0/get-global-params"
-	RoutePathTestSignTransactionWithDerivedKey = "/api/v0/admin/test-sign-transaction-with-derived-key"
+	RoutePathGetGlobalParams                    = "/api/v0/get-global-params"
+	RoutePathTestSignTransactionWithDerivedKey  = "/api/v0/admin/test-sign-transaction-with-derived-key"
+	RoutePathTestSignTransactionsWithDerivedKey = "/api/v0/admin/test-sign-transactions-with-derived-key"
 
 	// Eventually we will deprecate the admin endpoint since it does not need to be protected.
 	RoutePathAdminGetGlobalParams = "/api/v0/admin/get-global-params"
@@ -242,16 +281,32 @@ const (
 	RoutePathAdminGetAllCountryLevelSignUpBonuses = "/api/v0/admin/get-all-country-level-sign-up-bonuses"
 
 	// admin_referrals.go
-	RoutePathAdminCreateReferralHash        = "/api/v0/admin/create-referral-hash"
-	RoutePathAdminGetAllReferralInfoForUser = "/api/v0/admin/get-all-referral-info-for-user"
-	RoutePathAdminUpdateReferralHash        = "/api/v0/admin/update-referral-hash"
-	RoutePathAdminUploadReferralCSV         = "/api/v0/admin/upload-referral-csv"
-	RoutePathAdminDownloadReferralCSV       = "/api/v0/admin/download-referral-csv"
-	RoutePathAdminDownloadRefereeCSV        = "/api/v0/admin/download-referee-csv"
+	RoutePathAdminCreateReferralHash             = "/api/v0/admin/create-referral-hash"
+	RoutePathAdminGetAllReferralInfoForUser      = "/api/v0/admin/get-all-referral-info-for-user"
+	RoutePathAdminUpdateReferralHash             = "/api/v0/admin/update-referral-hash"
+	RoutePathAdminUploadReferralCSV              = "/api/v0/admin/upload-referral-csv"
+	RoutePathAdminValidateReferralRows           = "/api/v0/admin/validate-referral-rows"
+	RoutePathAdminDownloadReferralCSV            = "/api/v0/admin/download-referral-csv"
+	RoutePathAdminGetAllReferralInfo             = "/api/v0/admin/get-all-referral-info"
+	RoutePathAdminDownloadRefereeCSV             = "/api/v0/admin/download-referee-csv"
+	RoutePathAdminDownloadReferralJoinedCSV      = "/api/v0/admin/download-referral-joined-csv"
+	RoutePathAdminCompactReferralInfos           = "/api/v0/admin/compact-referral-infos"
+	RoutePathAdminGetReferralsByCreatingAdmin    = "/api/v0/admin/get-referrals-by-creating-admin"
+	RoutePathAdminGetEligibleReferralsForReferee = "/api/v0/admin/get-eligible-referrals-for-referee"
+	RoutePathAdminHasUsedAnyReferral             = "/api/v0/admin/has-used-any-referral"
+	RoutePathAdminGetReferralPayoutDetail        = "/api/v0/admin/get-referral-payout-detail"
+	RoutePathAdminGetLowConversionJumioReferrals = "/api/v0/admin/get-low-conversion-jumio-referrals"
+	RoutePathAdminSwapReferralOwnership          = "/api/v0/admin/swap-referral-ownership"
+	RoutePathAdminDeleteReferralHash             = "/api/v0/admin/delete-referral-hash"
+	RoutePathAdminBatchCreateReferralHashes      = "/api/v0/admin/batch-create-referral-hashes"
+	RoutePathAdminGetReferralStats               = "/api/v0/admin/get-referral-stats"
+	RoutePathAdminGetRefereePayouts              = "/api/v0/admin/get-referee-payouts"
+	RoutePathAdminGetReferrerForReferralHash     = "/api/v0/admin/get-referrer-for-referral-hash"
 
 	// referrals.go
 	RoutePathGetReferralInfoForUser         = "/api/v0/get-referral-info-for-user"
 	RoutePathGetReferralInfoForReferralHash = "/api/v0/get-referral-info-for-referral-hash"
+	RoutePathVerifyReferralOwnership        = "/api/v0/verify-referral-ownership"
 
 	// admin_tutorial.go
 	RoutePathAdminUpdateTutorialCreators = "/api/v0/admin/update-tutorial-creators"
@@ -263,11 +318,13 @@ const (
 	RoutePathGetBlacklistedPublicKeys = "/api/v0/get-blacklisted-public-keys"
 	RoutePathGetGraylistedPublicKeys  = "/api/v0/get-graylisted-public-keys"
 	RoutePathGetGlobalFeed            = "/api/v0/get-global-feed"
+	RoutePathGetGlobalStateMode       = "/api/v0/get-global-state-mode"
 
 	// supply.go
 	RoutePathGetTotalSupply       = "/api/v0/total-supply"
 	RoutePathGetRichList          = "/api/v0/rich-list"
 	RoutePathGetCountKeysWithDESO = "/api/v0/count-keys-with-deso"
+	RoutePathGetSupplyStats       = "/api/v0/supply-stats"
 )
 
 // APIServer provides the interface between the blockchain and things like the
@@ -294,6 +351,10 @@ type APIServer struct {
 	// db. This makes it easy to run a local node in development.
 	GlobalState *GlobalState
 
+	// Optional. Only set when --enable-metrics is set, in which case every route records its latency and
+	// status code and the metrics are served on RoutePathMetrics.
+	Metrics *Metrics
+
 	// Optional, may be empty. Used for Twilio integration
 	Twilio *twilio.Client
 
@@ -306,6 +367,13 @@ type APIServer struct {
 	// causing one to error.
 	mtxSeedDeSo sync.RWMutex
 
+	// Map of referral hash to a *sync.Mutex that serializes read-modify-write updates to that referral hash's
+	// ReferralInfo. See updateReferralInfoForReferralHash.
+	mtxReferralInfoByHash sync.Map
+
+	// Locks for idempotency global state keys, picked by hash. See lockIdempotencyKey.
+	mtxIdempotencyKeys [numIdempotencyKeyLocks]sync.Mutex
+
 	UsdCentsPerDeSoExchangeRate    uint64
 	UsdCentsPerBitCoinExchangeRate float64
 	UsdCentsPerETHExchangeRate     uint64
@@ -394,10 +462,31 @@ type APIServer struct {
 	GlobalFeedPostEntries []*lib.PostEntry
 
 	// Cache of Total Supply and Rich List
-	TotalSupplyNanos  uint64
-	TotalSupplyDESO   float64
-	RichList          []RichListEntryResponse
-	CountKeysWithDESO uint64
+	TotalSupplyNanos                  uint64
+	TotalSupplyDESO                   float64
+	CirculatingSupplyNanos            uint64
+	RichList                          []RichListEntryResponse
+	CountKeysWithDESO                 uint64
+	SupplyStatsLastUpdatedTstampNanos uint64
+	// True while the top holders' share of the supply is above the configured alert threshold. This lets us alert
+	// only when the threshold is crossed rather than on every run of the supply monitoring routine.
+	SupplyAlertTriggered bool
+
+	// Optional cache of username to public key and PKID lookups. Nil unless --enable-username-to-pkid-cache is set.
+	UsernameToPKIDCache *UsernameToPKIDCache
+
+	// Optional cache of GetDAOCoinMarketStats results. Nil if --dao-coin-market-stats-cache-ttl-seconds is 0.
+	DAOCoinMarketStatsCache *DAOCoinMarketStatsCache
+
+	// Optional cache of the open orders for each side of a DAO coin pair. Nil if
+	// --dao-coin-limit-orders-cache-ttl-millis is 0.
+	DAOCoinLimitOrdersCache *DAOCoinLimitOrdersCache
+
+	// Cache of the DAO coin limit order txns in recently scanned blocks. See DAOCoinLimitOrderTxnsCache.
+	DAOCoinLimitOrderTxnsCache *DAOCoinLimitOrderTxnsCache
+
+	// Tracks the order books that connections to RoutePathGetDAOCoinOrderBookFeed are subscribed to.
+	daoCoinOrderBookFeed *daoCoinOrderBookFeed
 
 	// map of country name to sign up bonus data
 	AllCountryLevelSignUpBonuses map[string]CountrySignUpBonusResponse
@@ -435,15 +524,35 @@ func NewAPIServer(
 	blockCypherAPIKey string,
 ) (*APIServer, error) {
 
+	var metrics *Metrics
+	if config.EnableMetrics {
+		metrics = NewMetrics()
+	}
+
 	globalState := &GlobalState{
 		GlobalStateRemoteSecret: config.GlobalStateRemoteSecret,
 		GlobalStateRemoteNode:   config.GlobalStateRemoteNode,
 		GlobalStateDB:           globalStateDB,
+		Metrics:                 metrics,
+	}
+	if config.GlobalStateRedisAddr != "" {
+		if globalState.GlobalStateRemoteNode != "" {
+			return nil, fmt.Errorf(
+				"NewAPIServer: Error: Only one of globalStateRemoteNode and globalStateRedisAddr can be set")
+		}
+		globalState.RedisClient = newRedisGlobalStateClient(config.GlobalStateRedisAddr,
+			config.GlobalStateRedisUsername, config.GlobalStateRedisPassword, config.GlobalStateRedisDB,
+			config.GlobalStateRedisTLS)
 	}
 
-	if globalStateDB == nil && globalState.GlobalStateRemoteNode == "" {
+	if globalStateDB == nil && globalState.GlobalStateRemoteNode == "" && globalState.RedisClient == nil {
 		return nil, fmt.Errorf(
-			"NewAPIServer: Error: A globalStateDB or a globalStateRemoteNode is required")
+			"NewAPIServer: Error: A globalStateDB, globalStateRemoteNode, or globalStateRedisAddr is required")
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, fmt.Errorf(
+			"NewAPIServer: Error: --tls-cert-file and --tls-key-file must be set together to serve HTTPS")
 	}
 
 	publicKeyBase58Prefix := lib.Base58CheckEncode(make([]byte, btcec.PubKeyBytesLenCompressed), false, params)[0:3]
@@ -463,6 +572,7 @@ func NewAPIServer(
 		Twilio:                    twilio,
 		BlockCypherAPIKey:         blockCypherAPIKey,
 		GlobalState:               globalState,
+		Metrics:                   metrics,
 		LastTradeDeSoPriceHistory: []LastTradePriceHistoryItem{},
 		PublicKeyBase58Prefix:     publicKeyBase58Prefix,
 		// We consider last trade prices from the last hour when determining the current price of DeSo.
@@ -472,8 +582,51 @@ func NewAPIServer(
 		quit:                         make(chan struct{}),
 	}
 
+	if fes.Config.EnableUsernameToPKIDCache {
+		fes.UsernameToPKIDCache = NewUsernameToPKIDCache(
+			time.Duration(fes.Config.UsernameToPKIDCacheTTLSeconds) * time.Second)
+	}
+	if fes.Config.DAOCoinMarketStatsCacheTTLSeconds > 0 {
+		fes.DAOCoinMarketStatsCache = NewDAOCoinMarketStatsCache(
+			time.Duration(fes.Config.DAOCoinMarketStatsCacheTTLSeconds) * time.Second)
+	}
+	if fes.Config.DAOCoinLimitOrdersCacheTTLMillis > 0 {
+		fes.DAOCoinLimitOrdersCache = NewDAOCoinLimitOrdersCache(
+			time.Duration(fes.Config.DAOCoinLimitOrdersCacheTTLMillis) * time.Millisecond)
+		fes.StartDAOCoinLimitOrdersCacheInvalidation()
+	}
+
+	fes.DAOCoinLimitOrderTxnsCache = NewDAOCoinLimitOrderTxnsCache()
+
+	fes.daoCoinOrderBookFeed = newDAOCoinOrderBookFeed(fes)
+	fes.StartDAOCoinOrderBookFeed()
+
 	fes.StartSeedBalancesMonitoring()
 
+	if fes.Config.IdempotencyKeyTTLSeconds > 0 {
+		fes.StartIdempotencyRecordSweeper()
+	}
+
+	// Referral hashes created before the case-insensitive index existed need to be indexed before they resolve
+	// regardless of case.
+	if fes.Config.CaseInsensitiveReferralHashes {
+		go func() {
+			if err := fes.backfillReferralHashIndex(); err != nil {
+				glog.Errorf("NewAPIServer: Problem backfilling the referral hash index: %v", err)
+			}
+		}()
+	}
+
+	// Referees credited before the index of referral hashes by referee existed need to be indexed before
+	// AdminHasUsedAnyReferral can find them.
+	if fes.Config.EnableReferrals {
+		go func() {
+			if err := fes.backfillRefereeReferralHashIndex(); err != nil {
+				glog.Errorf("NewAPIServer: Problem backfilling the referee referral hash index: %v", err)
+			}
+		}()
+	}
+
 	// Call this once upon starting server to ensure we have a good initial value
 	fes.UpdateUSDCentsToDeSoExchangeRate()
 	fes.UpdateUSDToBTCPrice()
@@ -564,6 +717,20 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.HealthCheck,
 			PublicAccess,
 		},
+		{
+			"GetHealthCheck",
+			[]string{"GET"},
+			RoutePathGetHealthCheck,
+			fes.GetHealthCheck,
+			PublicAccess,
+		},
+		{
+			"GetReadyCheck",
+			[]string{"GET"},
+			RoutePathGetReadyCheck,
+			fes.GetReadyCheck,
+			PublicAccess,
+		},
 
 		// Routes for populating various UI elements.
 		{
@@ -682,6 +849,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetSingleProfile,
 			PublicAccess,
 		},
+		{
+			"GetProfilesBatch",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetProfilesBatch,
+			fes.GetProfilesBatch,
+			PublicAccess,
+		},
 		{
 			"GetSingleProfilePicture",
 			[]string{"GET"},
@@ -717,6 +891,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetHotFeed,
 			PublicAccess,
 		},
+		{
+			"GetHotFeedScores",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetHotFeedScores,
+			fes.GetHotFeedScores,
+			PublicAccess,
+		},
 		{
 			"CreateNFT",
 			[]string{"POST", "OPTIONS"},
@@ -927,6 +1108,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.CancelDAOCoinLimitOrder,
 			PublicAccess,
 		},
+		{
+			"ConstructCancelAllDAOCoinLimitOrders",
+			[]string{"POST", "OPTIONS"},
+			RoutePathConstructCancelAllDAOCoinLimitOrders,
+			fes.ConstructCancelAllDAOCoinLimitOrders,
+			PublicAccess,
+		},
 		{
 			"AppendExtraData",
 			[]string{"POST", "OPTIONS"},
@@ -941,6 +1129,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetTransactionSpending,
 			PublicAccess,
 		},
+		{
+			"DecodeTransactionHex",
+			[]string{"POST", "OPTIONS"},
+			RoutePathDecodeTransactionHex,
+			fes.DecodeTransactionHex,
+			PublicAccess,
+		},
 		{
 			"GetNotifications",
 			[]string{"POST", "OPTIONS"},
@@ -969,6 +1164,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetAppState,
 			PublicAccess,
 		},
+		{
+			"GetSupportedTransactionTypes",
+			[]string{"GET"},
+			RoutePathGetSupportedTransactionTypes,
+			fes.GetSupportedTransactionTypes,
+			PublicAccess,
+		},
 		{
 			"GetIngressCookie",
 			[]string{"GET"},
@@ -1039,6 +1241,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.ResendVerifyEmail,
 			PublicAccess,
 		},
+		{
+			"GetStarterDeSoForPrefix",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetStarterDeSoForPrefix,
+			fes.GetStarterDeSoForPrefix,
+			PublicAccess,
+		},
 		{
 			"VerifyEmail",
 			[]string{"POST", "OPTIONS"},
@@ -1116,6 +1325,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetDAOCoinLimitOrders,
 			PublicAccess,
 		},
+		{
+			"GetDAOCoinLimitOrdersBatch",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDaoCoinLimitOrdersBatch,
+			fes.GetDAOCoinLimitOrdersBatch,
+			PublicAccess,
+		},
 		{
 			"GetTransactorDAOCoinLimitOrders",
 			[]string{"POST", "OPTIONS"},
@@ -1123,6 +1339,125 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetTransactorDAOCoinLimitOrders,
 			PublicAccess,
 		},
+		{
+			"ConvertCoinUnits",
+			[]string{"POST", "OPTIONS"},
+			RoutePathConvertCoinUnits,
+			fes.ConvertCoinUnits,
+			PublicAccess,
+		},
+		{
+			"GetEffectiveDAOCoinPrice",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetEffectiveDAOCoinPrice,
+			fes.GetEffectiveDAOCoinPrice,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinMinOrderSize",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinMinOrderSize,
+			fes.GetDAOCoinMinOrderSize,
+			PublicAccess,
+		},
+		{
+			"GetExchangeFeeSchedule",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetExchangeFeeSchedule,
+			fes.GetExchangeFeeSchedule,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinArbitrageOpportunity",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinArbitrageOpportunity,
+			fes.GetDAOCoinArbitrageOpportunity,
+			PublicAccess,
+		},
+		{
+			"GetTransactorExchangeExposure",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetTransactorExchangeExposure,
+			fes.GetTransactorExchangeExposure,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinFillPreview",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinFillPreview,
+			fes.GetDAOCoinFillPreview,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinMarketsForCreator",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinMarketsForCreator,
+			fes.GetDAOCoinMarketsForCreator,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinLimitOrdersByIDs",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinLimitOrdersByIDs,
+			fes.GetDAOCoinLimitOrdersByIDs,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinLimitOrderBook",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinLimitOrderBook,
+			fes.GetDAOCoinLimitOrderBook,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinOrderBookFeed",
+			[]string{"GET"},
+			RoutePathGetDAOCoinOrderBookFeed,
+			fes.GetDAOCoinOrderBookFeed,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinTrades",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinTrades,
+			fes.GetDAOCoinTrades,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinCandles",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinCandles,
+			fes.GetDAOCoinCandles,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinMarketStats",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinMarketStats,
+			fes.GetDAOCoinMarketStats,
+			PublicAccess,
+		},
+		{
+			"ConvertDAOCoinPrice",
+			[]string{"POST", "OPTIONS"},
+			RoutePathConvertDAOCoinPrice,
+			fes.ConvertDAOCoinPrice,
+			PublicAccess,
+		},
+		{
+			"GetDAOCoinBestBidAsk",
+			[]string{"POST", "OPTIONS"},
+			RoutePathGetDAOCoinBestBidAsk,
+			fes.GetDAOCoinBestBidAsk,
+			PublicAccess,
+		},
+		{
+			"SimulateDAOCoinLimitOrderFill",
+			[]string{"POST", "OPTIONS"},
+			RoutePathSimulateDAOCoinLimitOrderFill,
+			fes.SimulateDAOCoinLimitOrderFill,
+			PublicAccess,
+		},
 		// Jumio Routes
 		{
 			"JumioBegin",
@@ -1166,6 +1501,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetReferralInfoForReferralHash,
 			PublicAccess,
 		},
+		{
+			"VerifyReferralOwnership",
+			[]string{"POST", "OPTIONS"},
+			RoutePathVerifyReferralOwnership,
+			fes.VerifyReferralOwnership,
+			PublicAccess,
+		},
 		// Tutorial Routes
 		{
 			"GetTutorialCreators",
@@ -1270,6 +1612,20 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.AdminGetMempoolStats,
 			AdminAccess,
 		},
+		{
+			"AdminSendTestEmail",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminSendTestEmail,
+			fes.AdminSendTestEmail,
+			SuperAdminAccess,
+		},
+		{
+			"AdminTestTwilioConfig",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminTestTwilioConfig,
+			fes.AdminTestTwilioConfig,
+			SuperAdminAccess,
+		},
 		{
 			"AdminGetGlobalParams",
 			[]string{"POST", "OPTIONS"},
@@ -1453,6 +1809,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.TestSignTransactionWithDerivedKey,
 			SuperAdminAccess,
 		},
+		{
+			"AdminTestSignTransactionsWithDerivedKey",
+			[]string{"POST", "OPTIONS"},
+			RoutePathTestSignTransactionsWithDerivedKey,
+			fes.TestSignTransactionsWithDerivedKey,
+			SuperAdminAccess,
+		},
 		{
 			"AdminJumioCallback",
 			[]string{"POST", "OPTIONS"},
@@ -1505,6 +1868,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			// content types.
 			PublicAccess,
 		},
+		{
+			"AdminValidateReferralRows",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminValidateReferralRows,
+			fes.AdminValidateReferralRows,
+			SuperAdminAccess,
+		},
 		{
 			"AdminDownloadReferralCSV",
 			[]string{"POST", "OPTIONS"},
@@ -1512,6 +1882,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.AdminDownloadReferralCSV,
 			SuperAdminAccess,
 		},
+		{
+			"AdminGetAllReferralInfo",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetAllReferralInfo,
+			fes.AdminGetAllReferralInfo,
+			SuperAdminAccess,
+		},
 		{
 			"AdminDownloadReferralCSV",
 			[]string{"POST", "OPTIONS"},
@@ -1519,6 +1896,97 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.AdminDownloadRefereeCSV,
 			SuperAdminAccess,
 		},
+		{
+			"AdminDownloadReferralJoinedCSV",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminDownloadReferralJoinedCSV,
+			fes.AdminDownloadReferralJoinedCSV,
+			SuperAdminAccess,
+		},
+		{
+			"AdminCompactReferralInfos",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminCompactReferralInfos,
+			fes.AdminCompactReferralInfos,
+			SuperAdminAccess,
+		},
+		{
+			"AdminGetReferralsByCreatingAdmin",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetReferralsByCreatingAdmin,
+			fes.AdminGetReferralsByCreatingAdmin,
+			SuperAdminAccess,
+		},
+		{
+			"AdminGetEligibleReferralsForReferee",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetEligibleReferralsForReferee,
+			fes.AdminGetEligibleReferralsForReferee,
+			AdminAccess,
+		},
+		{
+			"AdminHasUsedAnyReferral",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminHasUsedAnyReferral,
+			fes.AdminHasUsedAnyReferral,
+			AdminAccess,
+		},
+		{
+			"AdminGetReferralPayoutDetail",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetReferralPayoutDetail,
+			fes.AdminGetReferralPayoutDetail,
+			AdminAccess,
+		},
+		{
+			"AdminGetLowConversionJumioReferrals",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetLowConversionJumioReferrals,
+			fes.AdminGetLowConversionJumioReferrals,
+			AdminAccess,
+		},
+		{
+			"AdminSwapReferralOwnership",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminSwapReferralOwnership,
+			fes.AdminSwapReferralOwnership,
+			SuperAdminAccess,
+		},
+		{
+			"AdminDeleteReferralHash",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminDeleteReferralHash,
+			fes.AdminDeleteReferralHash,
+			SuperAdminAccess,
+		},
+		{
+			"AdminBatchCreateReferralHashes",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminBatchCreateReferralHashes,
+			fes.AdminBatchCreateReferralHashes,
+			SuperAdminAccess,
+		},
+		{
+			"AdminGetReferralStats",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetReferralStats,
+			fes.AdminGetReferralStats,
+			AdminAccess,
+		},
+		{
+			"AdminGetRefereePayouts",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetRefereePayouts,
+			fes.AdminGetRefereePayouts,
+			AdminAccess,
+		},
+		{
+			"AdminGetReferrerForReferralHash",
+			[]string{"POST", "OPTIONS"},
+			RoutePathAdminGetReferrerForReferralHash,
+			fes.AdminGetReferrerForReferralHash,
+			AdminAccess,
+		},
 		{
 			"AdminUpdateTutorialCreators",
 			[]string{"POST", "OPTIONS"},
@@ -1766,6 +2234,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetGlobalFeed,
 			PublicAccess,
 		},
+		{
+			"GetGlobalStateMode",
+			[]string{"GET"},
+			RoutePathGetGlobalStateMode,
+			fes.GetGlobalStateMode,
+			PublicAccess,
+		},
 		{
 			"GetTotalSupply",
 			[]string{"GET"},
@@ -1787,6 +2262,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 			fes.GetCountKeysWithDESO,
 			PublicAccess,
 		},
+		{
+			"GetSupplyStats",
+			[]string{"GET"},
+			RoutePathGetSupplyStats,
+			fes.GetSupplyStats,
+			PublicAccess,
+		},
 	}
 
 	router := muxtrace.NewRouter().StrictSlash(true)
@@ -1803,11 +2285,23 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 	fullRouteList := append([]Route{}, FrontendRoutes...)
 	fullRouteList = append(fullRouteList, fes.APIRoutes()...)
 	fullRouteList = append(fullRouteList, fes.GlobalState.GlobalStateRoutes()...)
+	if fes.Metrics != nil {
+		fullRouteList = append(fullRouteList, Route{
+			"GetMetrics",
+			[]string{"GET"},
+			RoutePathMetrics,
+			fes.GetMetrics,
+			PublicAccess,
+		})
+	}
 
 	for _, route := range fullRouteList {
 		var handler http.Handler
 
 		handler = route.HandlerFunc
+		if idempotentRoutes[route.Pattern] {
+			handler = fes.HandleIdempotencyKey(handler, route.Pattern, route.AccessLevel)
+		}
 		// Note that the wrapper that is applied last is actually called first. For
 		// example if you have:
 		// - handler = C(handler)
@@ -1821,7 +2315,13 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 		if route.AccessLevel != PublicAccess {
 			handler = fes.CheckAdminPublicKey(handler, route.AccessLevel)
 		}
+		handler = LimitRequestBodySize(handler, fes.getMaxRequestBodySizeBytes(route.Pattern))
+		handler = AddErrorContext(handler, route.Name)
+		if fes.Metrics != nil {
+			handler = RecordMetrics(handler, route.Name, fes.Metrics)
+		}
 		handler = Logger(handler, route.Name)
+		handler = AddRequestID(handler)
 		handler = AddHeaders(handler, fes.Config.AccessControlAllowOrigins)
 
 		router.
@@ -1843,6 +2343,135 @@ func (fes *APIServer) NewRouter() *muxtrace.Router {
 	return router
 }
 
+// defaultRouteMaxRequestBodySizeBytes raises the request body size limit for upload routes, which take files that
+// can be larger than a JSON request. The image limit leaves room for the multipart encoding around an image of up to
+// MaxRequestBodySizeBytes, which UploadImage checks itself.
+var defaultRouteMaxRequestBodySizeBytes = map[string]int64{
+	RoutePathUploadImage:            2 * MaxRequestBodySizeBytes,
+	RoutePathAdminUploadReferralCSV: maxDecompressedReferralCSVSizeBytes,
+}
+
+// getMaxRequestBodySizeBytes returns the request body size limit for routePath. A per-route override takes
+// precedence, then the larger of the route's default in defaultRouteMaxRequestBodySizeBytes and the node-wide limit,
+// and MaxRequestBodySizeBytes is used if none of them are set.
+func (fes *APIServer) getMaxRequestBodySizeBytes(routePath string) int64 {
+	maxBytes := int64(MaxRequestBodySizeBytes)
+	if fes.Config != nil {
+		if routeMaxBytes, exists := fes.Config.RouteMaxRequestBodySizeBytes[routePath]; exists && routeMaxBytes > 0 {
+			return int64(routeMaxBytes)
+		}
+		if fes.Config.MaxRequestBodySizeBytes > 0 {
+			maxBytes = int64(fes.Config.MaxRequestBodySizeBytes)
+		}
+	}
+	if defaultMaxBytes, exists := defaultRouteMaxRequestBodySizeBytes[routePath]; exists && defaultMaxBytes > maxBytes {
+		return defaultMaxBytes
+	}
+	return maxBytes
+}
+
+type maxRequestBodySizeBytesContextKey struct{}
+
+// LimitRequestBodySize fails any read of the request body past maxBytes, which handlers surface as a
+// problem parsing the request body. Handlers get maxBytes with getMaxRequestBodySizeBytesForRequest.
+func LimitRequestBodySize(inner http.Handler, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		req.Body = http.MaxBytesReader(ww, req.Body, maxBytes)
+		inner.ServeHTTP(ww, req.WithContext(context.WithValue(req.Context(), maxRequestBodySizeBytesContextKey{}, maxBytes)))
+	})
+}
+
+// getMaxRequestBodySizeBytesForRequest returns the limit LimitRequestBodySize applied to req, or
+// MaxRequestBodySizeBytes if req didn't go through it.
+func getMaxRequestBodySizeBytesForRequest(req *http.Request) int64 {
+	if maxBytes, ok := req.Context().Value(maxRequestBodySizeBytesContextKey{}).(int64); ok {
+		return maxBytes
+	}
+	return MaxRequestBodySizeBytes
+}
+
+// errorContextResponseWriter carries what _AddHttpErrorWithCode needs to know about the request being served.
+type errorContextResponseWriter struct {
+	http.ResponseWriter
+	endpoint  string
+	requestID string
+	// Set when the request only accepts text/plain, in which case errors are written as just their message.
+	plainText bool
+}
+
+func (ww *errorContextResponseWriter) Flush() {
+	if flusher, ok := ww.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (ww *errorContextResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := ww.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Hijack: Underlying ResponseWriter does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// AddErrorContext lets errors returned by the wrapped route name the route, and lets clients that predate
+// ErrorResponse keep getting plain-text errors by sending "Accept: text/plain".
+func AddErrorContext(inner http.Handler, name string) http.Handler {
+	return http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		accept := req.Header.Get("Accept")
+		inner.ServeHTTP(&errorContextResponseWriter{
+			ResponseWriter: ww,
+			endpoint:       name,
+			requestID:      GetRequestID(req.Context()),
+			plainText:      strings.Contains(accept, "text/plain") && !strings.Contains(accept, "json"),
+		}, req)
+	})
+}
+
+const (
+	// RequestIDHeader is set on every response. If a request already has one, say from a load balancer, its value
+	// is reused so the same ID shows up in every service's logs.
+	RequestIDHeader = "X-Request-ID"
+	// Incoming IDs end up in our logs, so they're limited in length and to characters that are safe to log.
+	maxRequestIDLength = 128
+)
+
+type requestIDContextKey struct{}
+
+// GetRequestID returns the ID AddRequestID assigned to the request ctx belongs to, or "" if there isn't one.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range requestID {
+		if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' ||
+			strings.ContainsRune("-_.:", char)) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddRequestID assigns every request an ID, which handlers can get with GetRequestID. The ID is returned in the
+// X-Request-ID response header and in error responses, and is attached to error logs, so that an error a client
+// reports can be matched to the server's log lines.
+func AddRequestID(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			randomBytes := make([]byte, 16)
+			rand.Read(randomBytes)
+			requestID = hex.EncodeToString(randomBytes)
+		}
+		ww.Header().Set(RequestIDHeader, requestID)
+		inner.ServeHTTP(ww, req.WithContext(context.WithValue(req.Context(), requestIDContextKey{}, requestID)))
+	})
+}
+
 // Logger ...
 func Logger(inner http.Handler, name string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1851,11 +2480,12 @@ func Logger(inner http.Handler, name string) http.Handler {
 		inner.ServeHTTP(w, r)
 
 		glog.V(2).Infof(
-			"%s\t%s\t%s\t%s",
+			"%s\t%s\t%s\t%s\t%s",
 			r.Method,
 			r.RequestURI,
 			name,
 			time.Since(start),
+			GetRequestID(r.Context()),
 		)
 	})
 }
@@ -1869,6 +2499,7 @@ var publicRoutes = map[string]interface{}{
 	RoutePathGetBlacklistedPublicKeys:       nil,
 	RoutePathGetGraylistedPublicKeys:        nil,
 	RoutePathGetGlobalFeed:                  nil,
+	RoutePathGetGlobalStateMode:             nil,
 	RoutePathDeletePII:                      nil,
 	RoutePathGetUserMetadata:                nil,
 	RoutePathSubmitTransaction:              nil,
@@ -1945,12 +2576,15 @@ func AddHeaders(inner http.Handler, allowedOrigins []string) http.Handler {
 
 			if r.RequestURI != RoutePathUploadVideo {
 				w.Header().Set("Access-Control-Allow-Origin", actualOrigin)
-				w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept")
+				w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, "+
+					RequestIDHeader+", "+IdempotencyKeyHeader)
 			} else {
 				w.Header().Set("Access-Control-Allow-Origin", "*")
 				w.Header().Set("Access-Control-Allow-Headers", "*")
 			}
 			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, DELETE, OPTIONS")
+			// Let frontends read the request ID so users can include it when reporting errors
+			w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
 		}
 
 		// Otherwise, don't add any headers. This should make a CORS request fail.
@@ -2001,7 +2635,7 @@ func (fes *APIServer) CheckAdminPublicKey(inner http.Handler, AccessLevel Access
 
 		// We read the entire body and then create a new ReadCloser Body object
 		// from the bytes we read because you can only read the body once
-		bodyBytes, err := ioutil.ReadAll(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+		bodyBytes, err := ioutil.ReadAll(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 		if err != nil {
 			_AddBadRequestError(ww, fmt.Sprintf("CheckAdminPublicKey: %v", err))
 			return
@@ -2114,10 +2748,38 @@ func (fes *APIServer) ValidateJWT(publicKey string, jwtToken string) (bool, erro
 func (fes *APIServer) Start() {
 	fes.initState()
 
+	if fes.Config.TLSCertFile != "" && fes.Config.TLSKeyFile != "" {
+		httpServer := &http.Server{
+			Addr:      fmt.Sprintf(":%d", fes.Config.APIPort),
+			Handler:   fes.router,
+			TLSConfig: newAPIServerTLSConfig(),
+		}
+		glog.Infof("Listening to SSL JSON API connections on port :%d", fes.Config.APIPort)
+		glog.Error(httpServer.ListenAndServeTLS(fes.Config.TLSCertFile, fes.Config.TLSKeyFile))
+		return
+	}
+
 	glog.Infof("Listening to NON-SSL JSON API connections on port :%d", fes.Config.APIPort)
 	glog.Error(http.ListenAndServe(fmt.Sprintf(":%d", fes.Config.APIPort), fes.router))
 }
 
+// newAPIServerTLSConfig requires TLS 1.2 or newer and, for TLS 1.2, restricts the cipher suites to ECDHE
+// key exchange with AEAD ciphers. TLS 1.3 suites aren't configurable and are all considered safe.
+func newAPIServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	}
+}
+
 // A helper function to initialize the APIServer. Useful for testing.
 func (fes *APIServer) initState() {
 	glog.Info("APIServer.Start: Starting APIServer")
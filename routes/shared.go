@@ -2,6 +2,7 @@ package routes
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
@@ -15,24 +16,114 @@ import (
 	"github.com/tyler-smith/go-bip39"
 )
 
+// ErrorCode is a stable identifier for the kind of error an endpoint returned. Clients should branch on
+// ErrorResponse.Code rather than Message, which is meant for humans and may change.
+type ErrorCode string
+
+const (
+	// Errors that don't have a more specific code get the one for their HTTP status.
+	ErrorCodeBadRequest          ErrorCode = "BadRequest"
+	ErrorCodeNotFound            ErrorCode = "NotFound"
+	ErrorCodeForbidden           ErrorCode = "Forbidden"
+	ErrorCodeInternalServerError ErrorCode = "InternalServerError"
+	ErrorCodeServiceUnavailable  ErrorCode = "ServiceUnavailable"
+
+	ErrorCodeInvalidPublicKey     ErrorCode = "InvalidPublicKey"
+	ErrorCodeReferralHashNotFound ErrorCode = "ReferralHashNotFound"
+	// An Idempotency-Key header was reused on a different route. See HandleIdempotencyKey.
+	ErrorCodeIdempotencyKeyReused ErrorCode = "IdempotencyKeyReused"
+)
+
+var errorCodesByStatusCode = map[int]ErrorCode{
+	http.StatusBadRequest:          ErrorCodeBadRequest,
+	http.StatusNotFound:            ErrorCodeNotFound,
+	http.StatusForbidden:           ErrorCodeForbidden,
+	http.StatusInternalServerError: ErrorCodeInternalServerError,
+	http.StatusServiceUnavailable:  ErrorCodeServiceUnavailable,
+}
+
+// ErrorResponse is the body of every error returned by _AddHttpError, unless the request asked for plain text.
+type ErrorResponse struct {
+	Code    ErrorCode
+	Message string
+	// The name of the route that returned the error, ex: GetDAOCoinLimitOrders.
+	Endpoint string
+	// Also returned in the X-Request-ID header. Include it when reporting an error so it can be found in the logs.
+	RequestID string
+
+	// Same as Message. Kept for clients that predate Code.
+	Error string `json:"error"`
+}
+
+// logRequestErrorf logs an error along with the ID of the request ctx belongs to, if it has one. Use this rather than
+// glog.Errorf for errors that happen while serving a request.
+func logRequestErrorf(ctx context.Context, format string, args ...interface{}) {
+	logErrorWithRequestID(GetRequestID(ctx), fmt.Sprintf(format, args...))
+}
+
+func logErrorWithRequestID(requestID string, errorString string) {
+	if requestID == "" {
+		glog.Error(errorString)
+		return
+	}
+	glog.Errorf("%s (RequestID: %s)", errorString, requestID)
+}
+
 func _AddBadRequestError(ww http.ResponseWriter, errorString string) {
 	_AddHttpError(ww, errorString, http.StatusBadRequest)
 }
 
+func _AddBadRequestErrorWithCode(ww http.ResponseWriter, code ErrorCode, errorString string) {
+	_AddHttpErrorWithCode(ww, errorString, http.StatusBadRequest, code)
+}
+
 func _AddNotFoundError(ww http.ResponseWriter, errorString string) {
 	_AddHttpError(ww, errorString, http.StatusNotFound)
 }
 
+func _AddNotFoundErrorWithCode(ww http.ResponseWriter, code ErrorCode, errorString string) {
+	_AddHttpErrorWithCode(ww, errorString, http.StatusNotFound, code)
+}
+
+func _AddForbiddenError(ww http.ResponseWriter, errorString string) {
+	_AddHttpError(ww, errorString, http.StatusForbidden)
+}
+
 func _AddInternalServerError(ww http.ResponseWriter, errorString string) {
 	_AddHttpError(ww, errorString, http.StatusInternalServerError)
 }
 
+func _AddServiceUnavailableError(ww http.ResponseWriter, errorString string) {
+	_AddHttpError(ww, errorString, http.StatusServiceUnavailable)
+}
+
 func _AddHttpError(ww http.ResponseWriter, errorString string, statusCode int) {
-	glog.Error(errorString)
+	_AddHttpErrorWithCode(ww, errorString, statusCode, errorCodesByStatusCode[statusCode])
+}
+
+// _AddHttpErrorWithCode writes an ErrorResponse. The Endpoint, RequestID, and whether to write plain text instead
+// come from AddErrorContext, so they're only set for requests served through the router.
+func _AddHttpErrorWithCode(ww http.ResponseWriter, errorString string, statusCode int, code ErrorCode) {
+	errorContext, _ := ww.(*errorContextResponseWriter)
+	if errorContext == nil {
+		errorContext = &errorContextResponseWriter{}
+	}
+	logErrorWithRequestID(errorContext.requestID, errorString)
+	if errorContext.plainText {
+		ww.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		ww.WriteHeader(statusCode)
+		fmt.Fprint(ww, errorString)
+		return
+	}
+
 	ww.WriteHeader(statusCode)
-	json.NewEncoder(ww).Encode(struct {
-		Error string `json:"error"`
-	}{Error: errorString})
+	json.NewEncoder(ww).Encode(ErrorResponse{
+		Code:      code,
+		Message:   errorString,
+		Endpoint:  errorContext.endpoint,
+		RequestID: errorContext.requestID,
+		Error:     errorString,
+	})
 }
 
 type TransactionInfo struct {
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deso-smart/deso-backend/v3/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponses(t *testing.T) {
+	require := require.New(t)
+
+	handler := AddErrorContext(http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, "TestEndpoint: Problem decoding public key")
+	}), "TestEndpoint")
+
+	// By default errors are structured, and still have the error field older clients read.
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/", nil))
+	require.Equal(http.StatusBadRequest, rr.Code)
+	errorResponse := ErrorResponse{}
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+	require.Equal(ErrorResponse{
+		Code:     ErrorCodeInvalidPublicKey,
+		Message:  "TestEndpoint: Problem decoding public key",
+		Endpoint: "TestEndpoint",
+		Error:    "TestEndpoint: Problem decoding public key",
+	}, errorResponse)
+
+	// Requests that only accept plain text get just the message.
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Accept", "text/plain")
+	handler.ServeHTTP(rr, req)
+	require.Equal(http.StatusBadRequest, rr.Code)
+	require.Equal("TestEndpoint: Problem decoding public key", rr.Body.String())
+
+	// Errors without a specific code get the one for their status, even outside the router.
+	rr = httptest.NewRecorder()
+	_AddNotFoundError(rr, "TestEndpoint: Not found")
+	errorResponse = ErrorResponse{}
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+	require.Equal(ErrorCodeNotFound, errorResponse.Code)
+	require.Equal("", errorResponse.Endpoint)
+}
+
+func TestAddRequestID(t *testing.T) {
+	require := require.New(t)
+
+	var handlerRequestID string
+	handler := AddRequestID(AddErrorContext(http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		handlerRequestID = GetRequestID(req.Context())
+		_AddInternalServerError(ww, "TestEndpoint: Something went wrong")
+	}), "TestEndpoint"))
+	serve := func(incomingRequestID string) (*httptest.ResponseRecorder, ErrorResponse) {
+		req := httptest.NewRequest("POST", "/", nil)
+		if incomingRequestID != "" {
+			req.Header.Set(RequestIDHeader, incomingRequestID)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		errorResponse := ErrorResponse{}
+		require.NoError(json.Unmarshal(rr.Body.Bytes(), &errorResponse))
+		return rr, errorResponse
+	}
+
+	// A valid incoming ID is passed through to the handler, the response header, and the error.
+	rr, errorResponse := serve("lb-1234.abcd")
+	require.Equal("lb-1234.abcd", handlerRequestID)
+	require.Equal("lb-1234.abcd", rr.Header().Get(RequestIDHeader))
+	require.Equal("lb-1234.abcd", errorResponse.RequestID)
+
+	// Missing or unsafe IDs are replaced with a new one.
+	for _, incomingRequestID := range []string{"", "bad id\n", strings.Repeat("a", maxRequestIDLength+1)} {
+		rr, errorResponse = serve(incomingRequestID)
+		require.Len(handlerRequestID, 32)
+		require.NotEqual(incomingRequestID, handlerRequestID)
+		require.Equal(handlerRequestID, rr.Header().Get(RequestIDHeader))
+		require.Equal(handlerRequestID, errorResponse.RequestID)
+	}
+}
+
+func TestLimitRequestBodySize(t *testing.T) {
+	require := require.New(t)
+
+	// Upload routes get a larger default, and per-route overrides win over everything.
+	fes := &APIServer{Config: &config.Config{}}
+	require.Equal(int64(MaxRequestBodySizeBytes), fes.getMaxRequestBodySizeBytes(RoutePathGetDAOCoinLimitOrdersByIDs))
+	require.Equal(int64(2*MaxRequestBodySizeBytes), fes.getMaxRequestBodySizeBytes(RoutePathUploadImage))
+	require.Equal(int64(maxDecompressedReferralCSVSizeBytes),
+		fes.getMaxRequestBodySizeBytes(RoutePathAdminUploadReferralCSV))
+	fes.Config.MaxRequestBodySizeBytes = 50 * 1e6
+	require.Equal(int64(50*1e6), fes.getMaxRequestBodySizeBytes(RoutePathGetDAOCoinLimitOrdersByIDs))
+	require.Equal(int64(50*1e6), fes.getMaxRequestBodySizeBytes(RoutePathUploadImage))
+	fes.Config.RouteMaxRequestBodySizeBytes = map[string]uint64{RoutePathUploadImage: 1000}
+	require.Equal(int64(1000), fes.getMaxRequestBodySizeBytes(RoutePathUploadImage))
+
+	// Handlers see the limit that was applied, and reads past it fail.
+	var handlerMaxBytes int64
+	var readErr error
+	handler := LimitRequestBodySize(http.HandlerFunc(func(ww http.ResponseWriter, req *http.Request) {
+		handlerMaxBytes = getMaxRequestBodySizeBytesForRequest(req)
+		_, readErr = ioutil.ReadAll(req.Body)
+	}), 10)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("0123456789")))
+	require.Equal(int64(10), handlerMaxBytes)
+	require.NoError(readErr)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("0123456789a")))
+	require.Error(readErr)
+	require.Equal(int64(MaxRequestBodySizeBytes),
+		getMaxRequestBodySizeBytesForRequest(httptest.NewRequest("POST", "/", nil)))
+}
@@ -1,16 +1,21 @@
 package routes
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/golang/glog"
 	"net/http"
 	"sort"
+	"strconv"
 	"time"
 )
 
-const richListLength = 1000
+const (
+	defaultRichListLength = 1000
+	maxRichListLength     = 10000
+)
 
 // Only keep balances in rich list if balance is greater than 100 DESO
 const richListMin = 100 * lib.NanosPerUnit
@@ -22,6 +27,7 @@ type RichListEntry struct {
 
 type RichListEntryResponse struct {
 	PublicKeyBase58Check string
+	Username             string
 	BalanceNanos         uint64
 	BalanceDESO          float64
 	Percentage           float64
@@ -43,6 +49,17 @@ func (fes *APIServer) StartSupplyMonitoring() {
 	}()
 }
 
+type GetSupplyStatsResponse struct {
+	TotalSupplyNanos uint64
+	TotalSupplyDESO  float64
+	// Circulating supply only counts DESO held directly by public keys, excluding DESO locked in creator coins.
+	CirculatingSupplyNanos uint64
+	CirculatingSupplyDESO  float64
+	CountKeysWithDESO      uint64
+	// Time at which the supply monitoring routine last computed these values.
+	LastUpdatedTstampNanos uint64
+}
+
 func (fes *APIServer) UpdateSupplyStats() {
 	totalSupply := uint64(0)
 	totalKeysWithDESO := uint64(0)
@@ -78,6 +95,7 @@ func (fes *APIServer) UpdateSupplyStats() {
 	}
 
 	fes.CountKeysWithDESO = totalKeysWithDESO
+	fes.CirculatingSupplyNanos = totalSupply
 
 	// Get all the keys for the Prefix that is ordered by DESO locked in creator coins
 	uint64BytesLen := 8
@@ -102,18 +120,34 @@ func (fes *APIServer) UpdateSupplyStats() {
 		return richList[ii].BalanceNanos > richList[jj].BalanceNanos
 	})
 
-	endIdx := richListLength
-	if len(richList) < richListLength {
+	endIdx := fes.getRichListLength()
+	if len(richList) < endIdx {
 		endIdx = len(richList)
 	}
 
 	richList = richList[:endIdx]
 
+	// We resolve usernames here rather than when serving the rich list so that requests stay cheap.
+	var utxoView *lib.UtxoView
+	if fes.backendServer != nil {
+		utxoView, err = fes.backendServer.GetMempool().GetAugmentedUniversalView()
+		if err != nil {
+			glog.Errorf("StartSupplyMonitoring: Error getting utxoView for rich list usernames: %v", err)
+		}
+	}
+
 	// Convert RichListEntries to RichListEntryResponses
 	var richListResponses []RichListEntryResponse
 	for _, item := range richList {
+		usernameStr := ""
+		if utxoView != nil {
+			if profileEntry := utxoView.GetProfileEntryForPublicKey(item.KeyBytes[1:]); profileEntry != nil {
+				usernameStr = string(profileEntry.Username)
+			}
+		}
 		richListResponses = append(richListResponses, RichListEntryResponse{
 			PublicKeyBase58Check: lib.PkToString(item.KeyBytes[1:], fes.Params),
+			Username:             usernameStr,
 			BalanceNanos:         item.BalanceNanos,
 			BalanceDESO:          float64(item.BalanceNanos) / float64(lib.NanosPerUnit),
 			Value:                fes.GetUSDFromNanos(item.BalanceNanos),
@@ -122,6 +156,92 @@ func (fes *APIServer) UpdateSupplyStats() {
 	}
 
 	fes.RichList = richListResponses
+	fes.SupplyStatsLastUpdatedTstampNanos = uint64(time.Now().UnixNano())
+
+	fes.checkSupplyAlertThresholds()
+}
+
+type SupplyAlert struct {
+	AlertType                       string
+	Message                         string
+	TopHoldersCount                 uint64
+	TopHoldersPercentage            float64
+	ThresholdPercentage             float64
+	TotalSupplyDESO                 float64
+	AlertTimestampNanos             uint64
+	TopHoldersPublicKeysBase58Check []string
+}
+
+const SupplyAlertTypeTopHoldersConcentration = "TopHoldersConcentration"
+
+// checkSupplyAlertThresholds compares the freshly computed rich list against the configured thresholds and POSTs an
+// alert to the configured webhook when a threshold is crossed. We only alert on the transition from below to above
+// the threshold so that operators aren't paged every time the routine runs.
+func (fes *APIServer) checkSupplyAlertThresholds() {
+	if fes.Config.SupplyAlertTopHoldersPercentage <= 0 || fes.TotalSupplyNanos == 0 {
+		return
+	}
+
+	numTopHolders := fes.Config.SupplyAlertTopHoldersCount
+	if numTopHolders == 0 {
+		numTopHolders = 1
+	}
+	if numTopHolders > uint64(len(fes.RichList)) {
+		numTopHolders = uint64(len(fes.RichList))
+	}
+
+	topHoldersBalanceNanos := uint64(0)
+	var topHoldersPublicKeys []string
+	for _, richListEntry := range fes.RichList[:numTopHolders] {
+		topHoldersBalanceNanos += richListEntry.BalanceNanos
+		topHoldersPublicKeys = append(topHoldersPublicKeys, richListEntry.PublicKeyBase58Check)
+	}
+	topHoldersPercentage := 100 * float64(topHoldersBalanceNanos) / float64(fes.TotalSupplyNanos)
+
+	if topHoldersPercentage <= fes.Config.SupplyAlertTopHoldersPercentage {
+		fes.SupplyAlertTriggered = false
+		return
+	}
+	if fes.SupplyAlertTriggered {
+		return
+	}
+	fes.SupplyAlertTriggered = true
+
+	alert := SupplyAlert{
+		AlertType: SupplyAlertTypeTopHoldersConcentration,
+		Message: fmt.Sprintf("Top %d holders own %.4f%% of the total supply, exceeding the threshold of %.4f%%",
+			numTopHolders, topHoldersPercentage, fes.Config.SupplyAlertTopHoldersPercentage),
+		TopHoldersCount:                 numTopHolders,
+		TopHoldersPercentage:            topHoldersPercentage,
+		ThresholdPercentage:             fes.Config.SupplyAlertTopHoldersPercentage,
+		TotalSupplyDESO:                 fes.TotalSupplyDESO,
+		AlertTimestampNanos:             uint64(time.Now().UnixNano()),
+		TopHoldersPublicKeysBase58Check: topHoldersPublicKeys,
+	}
+	glog.Warningf("checkSupplyAlertThresholds: %v", alert.Message)
+	if err := fes.sendSupplyAlert(&alert); err != nil {
+		glog.Errorf("checkSupplyAlertThresholds: Problem sending supply alert: %v", err)
+	}
+}
+
+func (fes *APIServer) sendSupplyAlert(alert *SupplyAlert) error {
+	if fes.Config.SupplyAlertWebhookURL == "" {
+		return nil
+	}
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("sendSupplyAlert: Problem marshaling alert: %v", err)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(fes.Config.SupplyAlertWebhookURL, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("sendSupplyAlert: Problem posting alert to webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sendSupplyAlert: Webhook returned non-2xx status: %d", resp.StatusCode)
+	}
+	return nil
 }
 
 func (fes *APIServer) GetTotalSupply(ww http.ResponseWriter, req *http.Request) {
@@ -135,12 +255,53 @@ func (fes *APIServer) GetTotalSupply(ww http.ResponseWriter, req *http.Request)
 	}
 }
 
+// getRichListLength returns the configured number of rich list entries to keep, capped at maxRichListLength to avoid
+// holding an unreasonably large list in memory.
+func (fes *APIServer) getRichListLength() int {
+	richListLength := fes.Config.RichListLength
+	if richListLength == 0 {
+		richListLength = defaultRichListLength
+	}
+	if richListLength > maxRichListLength {
+		richListLength = maxRichListLength
+	}
+	return int(richListLength)
+}
+
+// GetRichList serves the rich list cached by the supply monitoring routine. The optional "offset" and "limit" query
+// params can be used to page through the list. If neither is provided the full cached list is returned.
 func (fes *APIServer) GetRichList(ww http.ResponseWriter, req *http.Request) {
 	if !fes.Config.RunSupplyMonitoringRoutine {
 		_AddBadRequestError(ww, fmt.Sprintf("Supply Monitoring is not enabled on this node"))
 		return
 	}
-	if err := json.NewEncoder(ww).Encode(fes.RichList); err != nil {
+
+	richList := fes.RichList
+	offset := 0
+	if offsetStr := req.URL.Query().Get("offset"); offsetStr != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			_AddBadRequestError(ww, fmt.Sprintf("GetRichList: Invalid offset: %v", offsetStr))
+			return
+		}
+	}
+	if offset > len(richList) {
+		offset = len(richList)
+	}
+	richList = richList[offset:]
+	if limitStr := req.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			_AddBadRequestError(ww, fmt.Sprintf("GetRichList: Invalid limit: %v", limitStr))
+			return
+		}
+		if limit < len(richList) {
+			richList = richList[:limit]
+		}
+	}
+
+	if err := json.NewEncoder(ww).Encode(richList); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetRichList: Error encoding response: %v", err))
 		return
 	}
@@ -156,3 +317,24 @@ func (fes *APIServer) GetCountKeysWithDESO(ww http.ResponseWriter, req *http.Req
 		return
 	}
 }
+
+// GetSupplyStats serves the supply metrics cached by the supply monitoring routine. We never compute these inline
+// since doing so requires a full scan of all balances.
+func (fes *APIServer) GetSupplyStats(ww http.ResponseWriter, req *http.Request) {
+	if !fes.Config.RunSupplyMonitoringRoutine {
+		_AddBadRequestError(ww, fmt.Sprintf("Supply Monitoring is not enabled on this node"))
+		return
+	}
+	res := GetSupplyStatsResponse{
+		TotalSupplyNanos:       fes.TotalSupplyNanos,
+		TotalSupplyDESO:        fes.TotalSupplyDESO,
+		CirculatingSupplyNanos: fes.CirculatingSupplyNanos,
+		CirculatingSupplyDESO:  float64(fes.CirculatingSupplyNanos) / float64(lib.NanosPerUnit),
+		CountKeysWithDESO:      fes.CountKeysWithDESO,
+		LastUpdatedTstampNanos: fes.SupplyStatsLastUpdatedTstampNanos,
+	}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetSupplyStats: Error encoding response: %v", err))
+		return
+	}
+}
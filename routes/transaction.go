@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,7 +34,7 @@ type GetTxnResponse struct {
 }
 
 func (fes *APIServer) GetTxn(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetTxnRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetTxn: Problem parsing request body: %v", err))
@@ -84,7 +85,7 @@ type SubmitTransactionResponse struct {
 }
 
 func (fes *APIServer) SubmitTransaction(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SubmitTransactionRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SubmitTransactionRequest: Problem parsing request body: %v", err))
@@ -122,6 +123,13 @@ func (fes *APIServer) SubmitTransaction(ww http.ResponseWriter, req *http.Reques
 		}
 	}
 
+	// Swapping identities or updating a profile can change which PKID a username maps to.
+	if fes.UsernameToPKIDCache != nil && (txn.TxnMeta.GetTxnType() == lib.TxnTypeSwapIdentity ||
+		txn.TxnMeta.GetTxnType() == lib.TxnTypeUpdateProfile) {
+		fes.UsernameToPKIDCache.Clear()
+	}
+	fes.invalidateDAOCoinLimitOrdersCacheForTxn(txn)
+
 	if err := json.NewEncoder(ww).Encode(res); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SubmitTransactionResponse: Problem encoding response as JSON: %v", err))
 		return
@@ -252,7 +260,7 @@ type UpdateProfileResponse struct {
 
 // UpdateProfile ...
 func (fes *APIServer) UpdateProfile(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := UpdateProfileRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("UpdateProfile: Problem parsing request body: %v", err))
@@ -633,7 +641,7 @@ func (fes *APIServer) ExchangeBitcoinStateless(ww http.ResponseWriter, req *http
 		_AddBadRequestError(ww, "ExchangeBitcoinStateless: This node is not configured to sell DeSo for Bitcoin")
 		return
 	}
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := ExchangeBitcoinRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("ExchangeBitcoinStateless: Problem parsing request body: %v", err))
@@ -999,7 +1007,7 @@ type SendDeSoResponse struct {
 
 // SendDeSo ...
 func (fes *APIServer) SendDeSo(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SendDeSoRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SendDeSo: Problem parsing request body: %v", err))
@@ -1046,7 +1054,7 @@ func (fes *APIServer) SendDeSo(ww http.ResponseWriter, req *http.Request) {
 	// Decode the sender public key.
 	senderPkBytes, _, err := lib.Base58CheckDecode(requestData.SenderPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("SendDeSo: Problem decoding sender base58 public key %s: %v", requestData.SenderPublicKeyBase58Check, err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("SendDeSo: Problem decoding sender base58 public key %s: %v", requestData.SenderPublicKeyBase58Check, err))
 		return
 	}
 
@@ -1173,7 +1181,7 @@ type CreateLikeStatelessResponse struct {
 // CreateLikeStateless ...
 func (fes *APIServer) CreateLikeStateless(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := CreateLikeStatelessRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("CreateLikeStateless: Problem parsing request body: %v", err))
@@ -1285,7 +1293,7 @@ type SubmitPostResponse struct {
 
 // SubmitPost ...
 func (fes *APIServer) SubmitPost(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SubmitPostRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SubmitPost: Problem parsing request body: %v", err))
@@ -1571,7 +1579,7 @@ func (fes *APIServer) CreateFollowTxnStateless(ww http.ResponseWriter, req *http
 	//
 	// This isn't necessarily a bug, but just flagging in case it comes up.
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := CreateFollowTxnStatelessRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("CreateFollowTxnStateless: Problem parsing request body: %v", err))
@@ -1697,7 +1705,7 @@ type BuyOrSellCreatorCoinResponse struct {
 
 // BuyOrSellCreatorCoin ...
 func (fes *APIServer) BuyOrSellCreatorCoin(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := BuyOrSellCreatorCoinRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("BuyOrSellCreatorCoin: Problem parsing request body: %v", err))
@@ -1958,7 +1966,7 @@ type TransferCreatorCoinResponse struct {
 
 // TransferCreatorCoin ...
 func (fes *APIServer) TransferCreatorCoin(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := TransferCreatorCoinRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("TransferCreatorCoin: Problem parsing request body: %v", err))
@@ -1975,7 +1983,7 @@ func (fes *APIServer) TransferCreatorCoin(ww http.ResponseWriter, req *http.Requ
 	// Decode the updater public key
 	senderPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.SenderPublicKeyBase58Check)
 	if err != nil || len(senderPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferCreatorCoin: Problem decoding sender public key %s: %v",
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferCreatorCoin: Problem decoding sender public key %s: %v",
 			requestData.SenderPublicKeyBase58Check, err))
 		return
 	}
@@ -1990,7 +1998,7 @@ func (fes *APIServer) TransferCreatorCoin(ww http.ResponseWriter, req *http.Requ
 	// Decode the creator public key
 	creatorPublicKeyBytes, _, err := lib.Base58CheckDecode(requestData.CreatorPublicKeyBase58Check)
 	if err != nil || len(creatorPublicKeyBytes) != btcec.PubKeyBytesLenCompressed {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferCreatorCoin: Problem decoding creator public key %s: %v",
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferCreatorCoin: Problem decoding creator public key %s: %v",
 			requestData.CreatorPublicKeyBase58Check, err))
 		return
 	}
@@ -2108,7 +2116,7 @@ type SendDiamondsResponse struct {
 
 // SendDiamonds ...
 func (fes *APIServer) SendDiamonds(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SendDiamondsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SendDiamonds: Problem parsing request body: %v", err))
@@ -2308,7 +2316,7 @@ type DAOCoinResponse struct {
 
 // DAOCoin ...
 func (fes *APIServer) DAOCoin(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := DAOCoinRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("DAOCoin: Problem parsing request body: %v", err))
@@ -2485,7 +2493,7 @@ type TransferDAOCoinResponse struct {
 
 // TransferDAOCoin ...
 func (fes *APIServer) TransferDAOCoin(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := TransferDAOCoinRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("TransferDAOCoin: Problem parsing request body: %v", err))
@@ -2509,7 +2517,7 @@ func (fes *APIServer) TransferDAOCoin(ww http.ResponseWriter, req *http.Request)
 	senderPublicKeyBytes, _, err := fes.GetPubKeyAndProfileEntryForUsernameOrPublicKeyBase58Check(
 		requestData.SenderPublicKeyBase58Check, utxoView)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferDAOCoin: Problem decoding sender public key %s: %v",
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferDAOCoin: Problem decoding sender public key %s: %v",
 			requestData.SenderPublicKeyBase58Check, err))
 		return
 	}
@@ -2525,7 +2533,7 @@ func (fes *APIServer) TransferDAOCoin(ww http.ResponseWriter, req *http.Request)
 	creatorPublicKeyBytes, creatorProfileEntry, err := fes.GetPubKeyAndProfileEntryForUsernameOrPublicKeyBase58Check(
 		requestData.ProfilePublicKeyBase58CheckOrUsername, utxoView)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferDAOCoin: Problem decoding creator public key %s: %v",
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferDAOCoin: Problem decoding creator public key %s: %v",
 			requestData.ProfilePublicKeyBase58CheckOrUsername, err))
 		return
 	}
@@ -2540,7 +2548,7 @@ func (fes *APIServer) TransferDAOCoin(ww http.ResponseWriter, req *http.Request)
 	receiverPublicKeyBytes, _, err := fes.GetPubKeyAndProfileEntryForUsernameOrPublicKeyBase58Check(
 		requestData.ReceiverPublicKeyBase58CheckOrUsername, utxoView)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("TransferDAOCoin: Problem decoding reeceiver public key %s: %v",
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("TransferDAOCoin: Problem decoding reeceiver public key %s: %v",
 			requestData.ReceiverPublicKeyBase58CheckOrUsername, err))
 		return
 	}
@@ -2604,6 +2612,12 @@ type DAOCoinLimitOrderResponse struct {
 	TxnHashHex        string
 
 	SimulatedExecutionResult *DAOCoinLimitOrderSimulatedExecutionResult
+
+	// The exact price encoded in the transaction, as a decimal string in the same terms as the request's Price. This
+	// matches the request's Price when a Price string was provided. When the deprecated float
+	// ExchangeRateCoinsToSellPerCoinToBuy was used instead, it shows what that float was rounded to, since only its
+	// 15 most significant digits can be represented. Empty for market orders, which have no price.
+	Price string `safeForLogging:"true"`
 }
 
 // DAOCoinLimitOrderWithExchangeRateAndQuantityRequest alias type for backwards compatibility
@@ -2621,7 +2635,9 @@ type DAOCoinLimitOrderCreationRequest struct {
 
 	// A decimal string (ex: 1.23) that represents the exchange rate between the two coins. If operation type is BID
 	// then the denominator represents the coin being bought. If the operation type is ASK, then the denominator
-	// represents the coin being sold
+	// represents the coin being sold. This is the recommended way to pass a price: it's parsed exactly by
+	// CalculateScaledExchangeRateFromPriceString, whereas ExchangeRateCoinsToSellPerCoinToBuy goes through a float64
+	// and is truncated to 15 significant digits. If both are provided, Price is used.
 	Price string `safeForLogging:"true"`
 
 	// A decimal string (ex: 1.23) that represents the quantity of coins being bought or sold. If operation type is BID,
@@ -2631,6 +2647,11 @@ type DAOCoinLimitOrderCreationRequest struct {
 	OperationType DAOCoinLimitOrderOperationTypeString `safeForLogging:"true"`
 	FillType      DAOCoinLimitOrderFillTypeString      `safeForLogging:"true"`
 
+	// If true, the order is built as a market order that fills at whatever prices are resting on the book. Price and
+	// ExchangeRateCoinsToSellPerCoinToBuy are ignored, FillType defaults to IMMEDIATE_OR_CANCEL, and
+	// GOOD_TILL_CANCELLED is rejected.
+	IsMarketOrder bool `safeForLogging:"true"`
+
 	// The two fields ExchangeRateCoinsToSellPerCoinToBuy and QuantityToFill will be deprecated once the above Price
 	// and Quantity fields are deployed, and users have migrated to start using them. Until then, the API will continue
 	// to accept ExchangeRateCoinsToSellPerCoinToBuy and QuantityToFill in requests to this endpoint
@@ -2644,7 +2665,7 @@ type DAOCoinLimitOrderCreationRequest struct {
 // CreateDAOCoinLimitOrder Constructs a transaction that creates a DAO coin limit order for the specified
 // DAO coin pair, price, quantity, operation type, and fill type
 func (fes *APIServer) CreateDAOCoinLimitOrder(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := DAOCoinLimitOrderCreationRequest{}
 
 	if err := decoder.Decode(&requestData); err != nil {
@@ -2658,7 +2679,23 @@ func (fes *APIServer) CreateDAOCoinLimitOrder(ww http.ResponseWriter, req *http.
 		return
 	}
 
+	if requestData.IsMarketOrder {
+		fes.createDAOCoinMarketOrder(ww, "CreateDAOCoinLimitOrder", &DAOCoinMarketOrderCreationRequest{
+			TransactorPublicKeyBase58Check:            requestData.TransactorPublicKeyBase58Check,
+			BuyingDAOCoinCreatorPublicKeyBase58Check:  requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+			SellingDAOCoinCreatorPublicKeyBase58Check: requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+			Quantity:             requestData.Quantity,
+			OperationType:        requestData.OperationType,
+			FillType:             requestData.FillType,
+			QuantityToFill:       requestData.QuantityToFill,
+			MinFeeRateNanosPerKB: requestData.MinFeeRateNanosPerKB,
+			TransactionFees:      requestData.TransactionFees,
+		})
+		return
+	}
+
 	// Validate operation type
+	requestData.OperationType = normalizeOrderOperationTypeString(requestData.OperationType)
 	operationType, err := orderOperationTypeToUint64(requestData.OperationType)
 	if err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinLimitOrder: %v", err))
@@ -2668,6 +2705,7 @@ func (fes *APIServer) CreateDAOCoinLimitOrder(ww http.ResponseWriter, req *http.
 	// Parse and validate fill type; for backwards compatibility, default the empty string to GoodTillCancelled
 	fillType := lib.DAOCoinLimitOrderFillTypeGoodTillCancelled
 	if requestData.FillType != "" {
+		requestData.FillType = normalizeOrderFillTypeString(requestData.FillType)
 		fillType, err = orderFillTypeToUint64(requestData.FillType)
 		if err != nil {
 			_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinLimitOrder: %v", err))
@@ -2797,6 +2835,17 @@ func (fes *APIServer) CreateDAOCoinLimitOrder(ww http.ResponseWriter, req *http.
 		return
 	}
 
+	res.Price, err = CalculatePriceStringFromScaledExchangeRate(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		scaledExchangeRateCoinsToSellPerCoinToBuy,
+		requestData.OperationType,
+	)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("CreateDAOCoinLimitOrder: %v", err))
+		return
+	}
+
 	if err = json.NewEncoder(ww).Encode(res); err != nil {
 		_AddInternalServerError(ww, fmt.Sprintf("CreateDAOCoinLimitOrder: Problem encoding response as JSON: %v", err))
 		return
@@ -2832,7 +2881,7 @@ type DAOCoinMarketOrderCreationRequest struct {
 }
 
 func (fes *APIServer) CreateDAOCoinMarketOrder(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := DAOCoinMarketOrderCreationRequest{}
 
 	if err := decoder.Decode(&requestData); err != nil {
@@ -2846,53 +2895,40 @@ func (fes *APIServer) CreateDAOCoinMarketOrder(ww http.ResponseWriter, req *http
 		return
 	}
 
-	// Validate operation type
-	operationType, err := orderOperationTypeToUint64(requestData.OperationType)
-	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
-		return
-	}
-
-	// Validate and convert quantity to base units
-
-	// Parse and validated quantity
-	quantityToFillInBaseUnits := uint256.NewInt()
-	if requestData.Quantity == "" && requestData.QuantityToFill == 0 {
-		err = errors.Errorf("CreateDAOCoinMarketOrder: Quantity must be provided as a valid decimal string (ex: 1.23)")
-	} else if requestData.Quantity != "" {
-		quantityToFillInBaseUnits, err = CalculateQuantityToFillAsBaseUnits(
-			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
-			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
-			requestData.OperationType,
-			requestData.Quantity,
-		)
-	} else if requestData.QuantityToFill <= 0 {
-		err = errors.Errorf("CreateDAOCoinMarketOrder: Quantity must be greater than 0")
-	} else {
-		quantityToFillInBaseUnits, err = CalculateQuantityToFillAsBaseUnits(
-			requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
-			requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
-			requestData.OperationType,
-			formatFloatAsString(requestData.QuantityToFill),
-		)
-	}
+	fes.createDAOCoinMarketOrder(ww, "CreateDAOCoinMarketOrder", &requestData)
+}
 
-	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
-		return
+// createDAOCoinMarketOrder validates a market order request and writes the constructed transaction to ww. It backs
+// both CreateDAOCoinMarketOrder and CreateDAOCoinLimitOrder requests with IsMarketOrder set, and errors are prefixed
+// with endpointName.
+func (fes *APIServer) createDAOCoinMarketOrder(
+	ww http.ResponseWriter, endpointName string, requestData *DAOCoinMarketOrderCreationRequest,
+) {
+	// Prefer Quantity, falling back to the deprecated QuantityToFill.
+	quantity := requestData.Quantity
+	if quantity == "" {
+		if requestData.QuantityToFill == 0 {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"%s: Quantity must be provided as a valid decimal string (ex: 1.23)", endpointName))
+			return
+		}
+		if requestData.QuantityToFill < 0 {
+			_AddBadRequestError(ww, fmt.Sprintf("%s: Quantity must be greater than 0", endpointName))
+			return
+		}
+		quantity = formatFloatAsString(requestData.QuantityToFill)
 	}
 
-	// Validate fill type
-	fillType, err := orderFillTypeToUint64(requestData.FillType)
+	// Validate the operation type, fill type and quantity.
+	marketOrderParams, err := CalculateDAOCoinMarketOrderParams(
+		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check,
+		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
+		quantity,
+		requestData.OperationType,
+		requestData.FillType,
+	)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
-		return
-	}
-	if fillType == lib.DAOCoinLimitOrderFillTypeGoodTillCancelled {
-		_AddBadRequestError(
-			ww,
-			fmt.Sprintf("CreateDAOCoinMarketOrder: %v fill type not supported for market orders", requestData.FillType),
-		)
+		_AddBadRequestError(ww, fmt.Sprintf("%s: %v", endpointName, err))
 		return
 	}
 
@@ -2901,13 +2937,13 @@ func (fes *APIServer) CreateDAOCoinMarketOrder(ww http.ResponseWriter, req *http
 		requestData.TransactorPublicKeyBase58Check,
 		requestData.BuyingDAOCoinCreatorPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
+		_AddBadRequestError(ww, fmt.Sprintf("%s: %v", endpointName, err))
 		return
 	}
 
 	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
 	if err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: problem fetching utxoView: %v", err))
+		_AddInternalServerError(ww, fmt.Sprintf("%s: problem fetching utxoView: %v", endpointName, err))
 		return
 	}
 
@@ -2917,28 +2953,25 @@ func (fes *APIServer) CreateDAOCoinMarketOrder(ww http.ResponseWriter, req *http
 		requestData.SellingDAOCoinCreatorPublicKeyBase58Check,
 	)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
+		_AddBadRequestError(ww, fmt.Sprintf("%s: %v", endpointName, err))
 		return
 	}
 
-	// override the initial value and explicitly set to 0 for clarity
-	zeroUint256 := uint256.NewInt().SetUint64(0)
-
 	res, err := fes.createDAOCoinLimitOrderResponse(
 		utxoView,
 		requestData.TransactorPublicKeyBase58Check,
 		buyingCoinPublicKey,
 		sellingCoinPublicKey,
-		zeroUint256,
-		quantityToFillInBaseUnits,
-		operationType,
-		fillType,
+		marketOrderParams.ScaledExchangeRateCoinsToSellPerCoinToBuy,
+		marketOrderParams.QuantityToFillInBaseUnits,
+		marketOrderParams.OperationType,
+		marketOrderParams.FillType,
 		nil,
 		requestData.MinFeeRateNanosPerKB,
 		requestData.TransactionFees,
 	)
 	if err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
+		_AddInternalServerError(ww, fmt.Sprintf("%s: %v", endpointName, err))
 		return
 	}
 
@@ -2950,12 +2983,12 @@ func (fes *APIServer) CreateDAOCoinMarketOrder(ww http.ResponseWriter, req *http
 		res.Transaction,
 	)
 	if err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: %v", err))
+		_AddInternalServerError(ww, fmt.Sprintf("%s: %v", endpointName, err))
 		return
 	}
 
 	if err = json.NewEncoder(ww).Encode(res); err != nil {
-		_AddInternalServerError(ww, fmt.Sprintf("CreateDAOCoinMarketOrder: Problem encoding response as JSON: %v", err))
+		_AddInternalServerError(ww, fmt.Sprintf("%s: Problem encoding response as JSON: %v", endpointName, err))
 		return
 	}
 }
@@ -2968,8 +3001,8 @@ func (fes *APIServer) getBuyingAndSellingDAOCoinPublicKeys(
 	buyingDAOCoinCreatorPublicKeyBase58Check string,
 	sellingDAOCoinCreatorPublicKeyBase58Check string,
 ) ([]byte, []byte, error) {
-	if sellingDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString &&
-		buyingDAOCoinCreatorPublicKeyBase58Check == DESOCoinIdentifierString {
+	if isDESOCoinIdentifier(sellingDAOCoinCreatorPublicKeyBase58Check) &&
+		isDESOCoinIdentifier(buyingDAOCoinCreatorPublicKeyBase58Check) {
 		return nil, nil, errors.Errorf("'DESO' specified for both the " +
 			"coin to buy and the coin to sell. At least one must specify a valid DAO public key whose coin " +
 			"will be bought or sold")
@@ -2980,14 +3013,14 @@ func (fes *APIServer) getBuyingAndSellingDAOCoinPublicKeys(
 
 	var err error
 
-	if buyingDAOCoinCreatorPublicKeyBase58Check != DESOCoinIdentifierString {
+	if !isDESOCoinIdentifier(buyingDAOCoinCreatorPublicKeyBase58Check) {
 		buyingCoinPublicKey, err = GetPubKeyBytesFromBase58Check(buyingDAOCoinCreatorPublicKeyBase58Check)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
-	if sellingDAOCoinCreatorPublicKeyBase58Check != DESOCoinIdentifierString {
+	if !isDESOCoinIdentifier(sellingDAOCoinCreatorPublicKeyBase58Check) {
 		sellingCoinPublicKey, err = GetPubKeyBytesFromBase58Check(sellingDAOCoinCreatorPublicKeyBase58Check)
 		if err != nil {
 			return nil, nil, err
@@ -3010,7 +3043,7 @@ type DAOCoinLimitOrderWithCancelOrderIDRequest struct {
 // CancelDAOCoinLimitOrder Constructs a transaction that cancels an existing DAO coin limit order with the specified
 // order id
 func (fes *APIServer) CancelDAOCoinLimitOrder(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := DAOCoinLimitOrderWithCancelOrderIDRequest{}
 
 	if err := decoder.Decode(&requestData); err != nil {
@@ -3069,6 +3102,162 @@ func (fes *APIServer) CancelDAOCoinLimitOrder(ww http.ResponseWriter, req *http.
 	}
 }
 
+// MaxCancelAllDAOCoinLimitOrders is the maximum number of cancel txns ConstructCancelAllDAOCoinLimitOrders builds in
+// one call.
+const MaxCancelAllDAOCoinLimitOrders = 100
+
+type ConstructCancelAllDAOCoinLimitOrdersRequest struct {
+	// The public key of the user whose orders are being cancelled
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	// Optional. When both are set, only orders for this coin pair are cancelled, in either direction. Either coin
+	// can be "DESO".
+	DAOCoin1CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+	DAOCoin2CreatorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	MinFeeRateNanosPerKB uint64           `safeForLogging:"true"`
+	TransactionFees      []TransactionFee `safeForLogging:"true"`
+}
+
+type CancelDAOCoinLimitOrderTxnResponse struct {
+	CancelOrderID string `safeForLogging:"true"`
+
+	*DAOCoinLimitOrderResponse
+}
+
+type ConstructCancelAllDAOCoinLimitOrdersResponse struct {
+	// One unsigned txn per cancelled order, sorted by CancelOrderID
+	Transactions []CancelDAOCoinLimitOrderTxnResponse
+
+	// The sum of FeeNanos across Transactions
+	TotalFeeNanos uint64
+
+	// The number of open orders matching the request, which can be more than len(Transactions) when there are over
+	// MaxCancelAllDAOCoinLimitOrders of them.
+	NumOpenOrders int
+}
+
+// ConstructCancelAllDAOCoinLimitOrders constructs, but doesn't sign or submit, a txn cancelling each of the
+// transactor's open orders, optionally limited to one coin pair. Core can only cancel one order per txn. Each txn is
+// built independently against the mempool, so txns can spend the same inputs, in which case only the first of them
+// to be submitted is accepted. Callers should submit the txns in order and call this again to pick up any orders
+// that are still open.
+func (fes *APIServer) ConstructCancelAllDAOCoinLimitOrders(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := ConstructCancelAllDAOCoinLimitOrdersRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("ConstructCancelAllDAOCoinLimitOrders: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.TransactorPublicKeyBase58Check == "" {
+		_AddBadRequestError(ww, "ConstructCancelAllDAOCoinLimitOrders: must provide a TransactorPublicKeyBase58Check")
+		return
+	}
+	// An empty coin means no pair was given here rather than $DESO, so this is checked before normalizing.
+	coin1 := requestData.DAOCoin1CreatorPublicKeyBase58Check
+	coin2 := requestData.DAOCoin2CreatorPublicKeyBase58Check
+	if (coin1 == "") != (coin2 == "") {
+		_AddBadRequestError(ww, "ConstructCancelAllDAOCoinLimitOrders: Must provide either two different coins "+
+			"for DAOCoin1CreatorPublicKeyBase58Check and DAOCoin2CreatorPublicKeyBase58Check or neither")
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("ConstructCancelAllDAOCoinLimitOrders: problem fetching utxoView: %v", err))
+		return
+	}
+
+	transactorPKID, err := fes.getPKIDFromPublicKeyBase58Check(utxoView, requestData.TransactorPublicKeyBase58Check)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"ConstructCancelAllDAOCoinLimitOrders: Invalid TransactorPublicKeyBase58Check: %v", err))
+		return
+	}
+	var coin1PKID, coin2PKID *lib.PKID
+	if coin1 != "" {
+		coin1PKID, err = fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin1)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"ConstructCancelAllDAOCoinLimitOrders: Invalid DAOCoin1CreatorPublicKeyBase58Check: %v", err))
+			return
+		}
+		coin2PKID, err = fes.getPKIDForCoinPublicKeyBase58CheckOrDESO(utxoView, coin2)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf(
+				"ConstructCancelAllDAOCoinLimitOrders: Invalid DAOCoin2CreatorPublicKeyBase58Check: %v", err))
+			return
+		}
+		// Compare PKIDs so that the same coin spelled two ways, ex: "DESO" and "$DESO", is caught too.
+		if coin1PKID.Eq(coin2PKID) {
+			_AddBadRequestError(ww, fmt.Sprintf("ConstructCancelAllDAOCoinLimitOrders: "+
+				"DAOCoin1CreatorPublicKeyBase58Check %v and DAOCoin2CreatorPublicKeyBase58Check %v refer to the "+
+				"same coin", coin1, coin2))
+			return
+		}
+	}
+
+	orders, err := utxoView.GetAllDAOCoinLimitOrdersForThisTransactor(transactorPKID)
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"ConstructCancelAllDAOCoinLimitOrders: Error getting limit orders: %v", err))
+		return
+	}
+	ordersToCancel := []*lib.DAOCoinLimitOrderEntry{}
+	for _, order := range orders {
+		if coin1PKID != nil &&
+			!(order.BuyingDAOCoinCreatorPKID.Eq(coin1PKID) && order.SellingDAOCoinCreatorPKID.Eq(coin2PKID)) &&
+			!(order.BuyingDAOCoinCreatorPKID.Eq(coin2PKID) && order.SellingDAOCoinCreatorPKID.Eq(coin1PKID)) {
+			continue
+		}
+		ordersToCancel = append(ordersToCancel, order)
+	}
+	sort.Slice(ordersToCancel, func(ii, jj int) bool {
+		return bytes.Compare(ordersToCancel[ii].OrderID[:], ordersToCancel[jj].OrderID[:]) < 0
+	})
+
+	res := ConstructCancelAllDAOCoinLimitOrdersResponse{
+		Transactions:  []CancelDAOCoinLimitOrderTxnResponse{},
+		NumOpenOrders: len(ordersToCancel),
+	}
+	if len(ordersToCancel) > MaxCancelAllDAOCoinLimitOrders {
+		ordersToCancel = ordersToCancel[:MaxCancelAllDAOCoinLimitOrders]
+	}
+	for _, order := range ordersToCancel {
+		txnRes, err := fes.createDAOCoinLimitOrderResponse(
+			utxoView,
+			requestData.TransactorPublicKeyBase58Check,
+			nil,
+			nil,
+			nil,
+			nil,
+			0,
+			0,
+			order.OrderID,
+			requestData.MinFeeRateNanosPerKB,
+			requestData.TransactionFees,
+		)
+		if err != nil {
+			_AddInternalServerError(ww, fmt.Sprintf(
+				"ConstructCancelAllDAOCoinLimitOrders: Problem constructing txn cancelling order %v: %v",
+				order.OrderID, err))
+			return
+		}
+		res.Transactions = append(res.Transactions, CancelDAOCoinLimitOrderTxnResponse{
+			CancelOrderID:             order.OrderID.String(),
+			DAOCoinLimitOrderResponse: txnRes,
+		})
+		res.TotalFeeNanos += txnRes.FeeNanos
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf(
+			"ConstructCancelAllDAOCoinLimitOrders: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
 func (fes *APIServer) createDAOCoinLimitOrderResponse(
 	utxoView *lib.UtxoView,
 	transactorPublicKeyBase58Check string,
@@ -3246,7 +3435,7 @@ type AuthorizeDerivedKeyResponse struct {
 
 // AuthorizeDerivedKey ...
 func (fes *APIServer) AuthorizeDerivedKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AuthorizeDerivedKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AuthorizeDerivedKey: Problem parsing request body: %v", err))
@@ -3601,7 +3790,7 @@ type AppendExtraDataResponse struct {
 // AppendExtraData ...
 // This endpoint allows setting custom ExtraData for a given transaction hex.
 func (fes *APIServer) AppendExtraData(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := AppendExtraDataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("AppendExtraData: Problem parsing request body: %v", err))
@@ -3671,7 +3860,7 @@ type GetTransactionSpendingResponse struct {
 // by subtracting transaction output to sender from transaction inputs.
 // Note, this endpoint doesn't check if transaction is valid.
 func (fes *APIServer) GetTransactionSpending(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetTransactionSpendingRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetTransactionSpending: Problem parsing request body: %v", err))
@@ -3748,6 +3937,103 @@ func (fes *APIServer) GetTransactionSpending(ww http.ResponseWriter, req *http.R
 	return
 }
 
+// DecodeTransactionHexRequest ...
+type DecodeTransactionHexRequest struct {
+	// Transaction hex. May be signed or unsigned.
+	TransactionHex string `safeForLogging:"true"`
+}
+
+// DecodeTransactionHexResponse ...
+type DecodeTransactionHexResponse struct {
+	TxnHashHex      string `safeForLogging:"true"`
+	TransactionType string `safeForLogging:"true"`
+
+	TransactorPublicKeyBase58Check string `safeForLogging:"true"`
+
+	Inputs  []*InputResponse  `safeForLogging:"true"`
+	Outputs []*OutputResponse `safeForLogging:"true"`
+
+	// The decoded transaction metadata for the transaction type
+	TxnMeta lib.DeSoTxnMetadata `safeForLogging:"true"`
+
+	// The ExtraData on the transaction, decoded the same way as in transaction responses elsewhere in the API. For a
+	// transaction signed with a derived key this includes the DerivedPublicKey.
+	ExtraData map[string]string `safeForLogging:"true"`
+
+	IsSigned     bool   `safeForLogging:"true"`
+	SignatureHex string `safeForLogging:"true"`
+}
+
+// DecodeTransactionHex ...
+// This endpoint decodes a transaction hex into a human-readable structure, which is useful when debugging
+// derived key flows. It only deserializes the transaction; it doesn't validate or sign it.
+func (fes *APIServer) DecodeTransactionHex(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := DecodeTransactionHexRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("DecodeTransactionHex: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.TransactionHex == "" {
+		_AddBadRequestError(ww, "DecodeTransactionHex: Must provide a TransactionHex")
+		return
+	}
+
+	// Get the transaction bytes from the request data.
+	txnBytes, err := hex.DecodeString(requestData.TransactionHex)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("DecodeTransactionHex: Problem decoding transaction hex %v", err))
+		return
+	}
+
+	// Deserialize transaction from transaction bytes.
+	txn := &lib.MsgDeSoTxn{}
+	if err = txn.FromBytes(txnBytes); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("DecodeTransactionHex: Problem deserializing transaction from bytes: %v", err))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("DecodeTransactionHex: Problem fetching utxoView: %v", err))
+		return
+	}
+
+	res := DecodeTransactionHexResponse{
+		TxnHashHex:      txn.Hash().String(),
+		TransactionType: txn.TxnMeta.GetTxnType().String(),
+		TxnMeta:         txn.TxnMeta,
+		ExtraData:       DecodeExtraDataMap(fes.Params, utxoView, txn.ExtraData),
+		Inputs:          []*InputResponse{},
+		Outputs:         []*OutputResponse{},
+	}
+	if len(txn.PublicKey) > 0 {
+		res.TransactorPublicKeyBase58Check = lib.PkToString(txn.PublicKey, fes.Params)
+	}
+	for _, input := range txn.TxInputs {
+		res.Inputs = append(res.Inputs, &InputResponse{
+			TransactionIDBase58Check: lib.PkToString(input.TxID[:], fes.Params),
+			Index:                    int64(input.Index),
+		})
+	}
+	for _, output := range txn.TxOutputs {
+		res.Outputs = append(res.Outputs, &OutputResponse{
+			PublicKeyBase58Check: lib.PkToString(output.PublicKey, fes.Params),
+			AmountNanos:          output.AmountNanos,
+		})
+	}
+	if txn.Signature.Sign != nil {
+		res.IsSigned = true
+		res.SignatureHex = hex.EncodeToString(txn.Signature.Sign.Serialize())
+	}
+
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("DecodeTransactionHex: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
 func (fes *APIServer) simulateSubmitTransaction(utxoView *lib.UtxoView, txn *lib.MsgDeSoTxn) (uint64, error) {
 	bestHeight := fes.blockchain.BlockTip().Height + 1
 	_, _, _, fees, err := utxoView.ConnectTransaction(
@@ -41,7 +41,7 @@ type GetUsersResponse struct {
 
 // GetUsersStateless ...
 func (fes *APIServer) GetUsersStateless(ww http.ResponseWriter, rr *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	getUsersRequest := GetUsersStatelessRequest{}
 	if err := decoder.Decode(&getUsersRequest); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetUsersStateless: Error parsing request body: %v", err))
@@ -525,7 +525,7 @@ type DeleteIdentityResponse struct{}
 
 func (fes *APIServer) DeleteIdentities(ww http.ResponseWriter, req *http.Request) {
 	// Decode the request data.
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := DeleteIdentityRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("DeleteIdentities: Problem parsing request body: %v", err))
@@ -649,7 +649,7 @@ type DAOCoinEntryResponse struct {
 func (fes *APIServer) GetProfiles(ww http.ResponseWriter, req *http.Request) {
 	profileEntryResponses := []*ProfileEntryResponse{}
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetProfilesRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetProfiles: Problem parsing request body: %v", err))
@@ -1156,7 +1156,7 @@ func (fes *APIServer) GetSingleProfilePicture(ww http.ResponseWriter, req *http.
 	}
 	publicKeyBytes, _, err := lib.Base58CheckDecode(publicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetSingleProfilePicture: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetSingleProfilePicture: Problem decoding user public key: %v", err))
 		return
 	}
 	// Get the profile picture.
@@ -1203,7 +1203,7 @@ type GetSingleProfileResponse struct {
 
 // GetSingleProfile...
 func (fes *APIServer) GetSingleProfile(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetSingleProfileRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetSingleProfile: Error parsing request body: %v", err))
@@ -1224,7 +1224,7 @@ func (fes *APIServer) GetSingleProfile(ww http.ResponseWriter, req *http.Request
 		publicKeyBase58Check = requestData.PublicKeyBase58Check
 		publicKeyBytes, _, err = lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetSingleProfile: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetSingleProfile: Problem decoding user public key: %v", err))
 			return
 		}
 		profileEntry = utxoView.GetProfileEntryForPublicKey(publicKeyBytes)
@@ -1270,6 +1270,83 @@ func (fes *APIServer) GetSingleProfile(ww http.ResponseWriter, req *http.Request
 	}
 }
 
+// getProfileEntryResponseForPKID returns the ProfileEntryResponse for the given PKID. If the PKID has no profile,
+// this is an anon profile, so we just populate the pub key and call it good.
+func (fes *APIServer) getProfileEntryResponseForPKID(pkid *lib.PKID, utxoView *lib.UtxoView) *ProfileEntryResponse {
+	profileEntry := utxoView.GetProfileEntryForPKID(pkid)
+	if profileEntry != nil && !profileEntry.IsDeleted() {
+		return fes._profileEntryToResponse(profileEntry, utxoView)
+	}
+	return &ProfileEntryResponse{
+		PublicKeyBase58Check: lib.PkToString(utxoView.GetPublicKeyForPKID(pkid), fes.Params),
+	}
+}
+
+// MaxProfilesBatchSize is the maximum number of public keys and PKIDs that can be looked up in a single
+// GetProfilesBatch request.
+const MaxProfilesBatchSize = 500
+
+type GetProfilesBatchRequest struct {
+	PublicKeysBase58Check []string `safeForLogging:"true"`
+	PKIDsBase58Check      []string `safeForLogging:"true"`
+}
+
+type GetProfilesBatchResponse struct {
+	// Profiles for PublicKeysBase58Check followed by profiles for PKIDsBase58Check, in the order they were requested.
+	// Users without a profile are returned with only PublicKeyBase58Check set.
+	ProfileEntryResponses []*ProfileEntryResponse
+}
+
+// GetProfilesBatch resolves the profiles for a list of public keys and PKIDs in a single call.
+func (fes *APIServer) GetProfilesBatch(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetProfilesBatchRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetProfilesBatch: Error parsing request body: %v", err))
+		return
+	}
+
+	if len(requestData.PublicKeysBase58Check)+len(requestData.PKIDsBase58Check) > MaxProfilesBatchSize {
+		_AddBadRequestError(ww, fmt.Sprintf(
+			"GetProfilesBatch: Cannot request more than %d profiles at once", MaxProfilesBatchSize))
+		return
+	}
+
+	utxoView, err := fes.backendServer.GetMempool().GetAugmentedUniversalView()
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetProfilesBatch: Error getting utxoView: %v", err))
+		return
+	}
+
+	profileEntryResponses := []*ProfileEntryResponse{}
+	for _, publicKeyBase58Check := range requestData.PublicKeysBase58Check {
+		publicKeyBytes, err := GetPubKeyBytesFromBase58Check(publicKeyBase58Check)
+		if err != nil {
+			_AddBadRequestError(ww, fmt.Sprintf("GetProfilesBatch: %v", err))
+			return
+		}
+		pkid := utxoView.GetPKIDForPublicKey(publicKeyBytes).PKID
+		profileEntryResponses = append(profileEntryResponses, fes.getProfileEntryResponseForPKID(pkid, utxoView))
+	}
+	for _, pkidBase58Check := range requestData.PKIDsBase58Check {
+		pkidBytes, _, err := lib.Base58CheckDecode(pkidBase58Check)
+		if err != nil || len(pkidBytes) != btcec.PubKeyBytesLenCompressed {
+			_AddBadRequestError(ww, fmt.Sprintf("GetProfilesBatch: Problem decoding PKID %s: %v", pkidBase58Check, err))
+			return
+		}
+		profileEntryResponses = append(profileEntryResponses, fes.getProfileEntryResponseForPKID(
+			lib.PublicKeyToPKID(pkidBytes), utxoView))
+	}
+
+	res := GetProfilesBatchResponse{
+		ProfileEntryResponses: profileEntryResponses,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetProfilesBatch: Problem serializing object to JSON: %v", err))
+		return
+	}
+}
+
 type TopHodlerSortType string
 
 const (
@@ -1336,7 +1413,7 @@ func (fes *APIServer) ComputeWealth(
 
 // GetHodlersForPublicKey... Get BalanceEntryResponses for hodlings.
 func (fes *APIServer) GetHodlersForPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetHodlersForPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -1356,7 +1433,7 @@ func (fes *APIServer) GetHodlersForPublicKey(ww http.ResponseWriter, req *http.R
 	if requestData.PublicKeyBase58Check != "" {
 		publicKeyBytes, _, err = lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetHodlersForPublicKey: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetHodlersForPublicKey: Problem decoding user public key: %v", err))
 			return
 		}
 	} else {
@@ -1482,7 +1559,7 @@ type GetHolderCountForPublicKeysRequest struct {
 }
 
 func (fes *APIServer) GetHodlersCountForPublicKeys(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetHolderCountForPublicKeysRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -1552,7 +1629,7 @@ type GetDiamondsForPublicKeyResponse struct {
 }
 
 func (fes *APIServer) GetDiamondsForPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetDiamondsForPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -1570,7 +1647,7 @@ func (fes *APIServer) GetDiamondsForPublicKey(ww http.ResponseWriter, req *http.
 	// Decode the public key for which we are fetching diamonds.
 	publicKeyBytes, _, err := lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetDiamondsForPublicKey: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetDiamondsForPublicKey: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -1811,7 +1888,7 @@ func (fes *APIServer) getPublicKeyToProfileEntryMapForFollows(publicKeyBytes []b
 //   - GET /:username/followers
 //   - GET /:username/following
 func (fes *APIServer) GetFollowsStateless(ww http.ResponseWriter, rr *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	getFollowsRequest := GetFollowsStatelessRequest{}
 	if err := decoder.Decode(&getFollowsRequest); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetFollowsStateless: Error parsing request body: %v", err))
@@ -1829,7 +1906,7 @@ func (fes *APIServer) GetFollowsStateless(ww http.ResponseWriter, rr *http.Reque
 	if getFollowsRequest.PublicKeyBase58Check != "" {
 		publicKeyBytes, _, err = lib.Base58CheckDecode(getFollowsRequest.PublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetFollowsStateless: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetFollowsStateless: Problem decoding user public key: %v", err))
 			return
 		}
 	} else {
@@ -1849,7 +1926,7 @@ func (fes *APIServer) GetFollowsStateless(ww http.ResponseWriter, rr *http.Reque
 	if getFollowsRequest.LastPublicKeyBase58Check != "" {
 		lastPublicKeySeenBytes, _, err = lib.Base58CheckDecode(getFollowsRequest.LastPublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetFollowsStateless: Problem decoding last public key seen: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetFollowsStateless: Problem decoding last public key seen: %v", err))
 			return
 		}
 	}
@@ -1894,7 +1971,7 @@ type GetUserGlobalMetadataResponse struct {
 // GetUserGlobalMetadata ...
 // Allows a user to change the global metadata for a public key, if they prove ownership.
 func (fes *APIServer) GetUserGlobalMetadata(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetUserGlobalMetadataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetUserGlobalMetadata: Problem parsing request body: %v", err))
@@ -1959,7 +2036,7 @@ type UpdateUserGlobalMetadataResponse struct{}
 // UpdateUserGlobalMetadata ...
 // Allows a user to change the global metadata for a public key, if they prove ownership.
 func (fes *APIServer) UpdateUserGlobalMetadata(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := UpdateUserGlobalMetadataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("UpdateUserGlobalMetadata: Problem parsing request body: %v", err))
@@ -2043,7 +2120,7 @@ type GetNotificationsCountResponse struct {
 }
 
 func (fes *APIServer) GetNotificationsCount(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNotificationsCountRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -2126,7 +2203,7 @@ type GetNotificationsResponse struct {
 }
 
 func (fes *APIServer) GetNotifications(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetNotificationsRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -2327,7 +2404,7 @@ type SetNotificationMetadataRequest struct {
 }
 
 func (fes *APIServer) SetNotificationMetadata(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SetNotificationMetadataRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -2892,7 +2969,7 @@ type BlockPublicKeyResponse struct {
 // This endpoint is used for blocking and unblocking users.  A boolean flag Unblock is passed to indicate whether
 // a user should be blocked or unblocked.
 func (fes *APIServer) BlockPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := BlockPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -2977,7 +3054,7 @@ type IsFolllowingPublicKeyResponse struct {
 
 func (fes *APIServer) IsFollowingPublicKey(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := IsFollowingPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -3034,7 +3111,7 @@ type IsHodlingPublicKeyResponse struct {
 
 func (fes *APIServer) IsHodlingPublicKey(ww http.ResponseWriter, req *http.Request) {
 
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := IsHodlingPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -3103,7 +3180,7 @@ func (fes *APIServer) GetUsernameForPublicKey(ww http.ResponseWriter, req *http.
 	}
 	publicKeyBytes, _, err := lib.Base58CheckDecode(publicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetUsernameForPublicKey: Problem decoding user public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetUsernameForPublicKey: Problem decoding user public key: %v", err))
 		return
 	}
 
@@ -3192,7 +3269,7 @@ type GetUserDerivedKeysResponse struct {
 }
 
 func (fes *APIServer) GetUserDerivedKeys(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetUserDerivedKeysRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf(
@@ -3332,7 +3409,7 @@ type GetTransactionSpendingLimitHexStringResponse struct {
 }
 
 func (fes *APIServer) GetTransactionSpendingLimitHexString(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetTransactionSpendingLimitHexStringRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetTransactionSpendingLimitHexString: Error parsing request body: %v", err))
@@ -3372,7 +3449,7 @@ type GetAccessBytesResponse struct {
 }
 
 func (fes *APIServer) GetAccessBytes(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetAccessBytesRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetAccessBytes: Error parsing request body: %v", err))
@@ -3380,7 +3457,7 @@ func (fes *APIServer) GetAccessBytes(ww http.ResponseWriter, req *http.Request)
 	}
 	derivedPublicKey, _, err := lib.Base58CheckDecode(requestData.DerivedPublicKeyBase58Check)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("GetAccessBytes: Problem decoding derived public key: %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetAccessBytes: Problem decoding derived public key: %v", err))
 		return
 	}
 
@@ -3457,7 +3534,7 @@ type DeletePIIRequest struct {
 }
 
 func (fes *APIServer) DeletePII(ww http.ResponseWriter, rr *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := DeletePIIRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("DeletePII: Error parsing request body: %v", err))
@@ -3484,7 +3561,7 @@ func (fes *APIServer) DeletePII(ww http.ResponseWriter, rr *http.Request) {
 	if requestData.PublicKeyBase58Check != "" {
 		publicKeyBytes, _, err = lib.Base58CheckDecode(requestData.PublicKeyBase58Check)
 		if err != nil {
-			_AddBadRequestError(ww, fmt.Sprintf("GetFollowsStateless: Problem decoding user public key: %v", err))
+			_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("GetFollowsStateless: Problem decoding user public key: %v", err))
 			return
 		}
 	} else {
@@ -3565,7 +3642,7 @@ func (fes *APIServer) GetPubKeyAndProfileEntryForUsernameOrPublicKeyBase58Check(
 	var err error
 	if !strings.HasPrefix(pubKeyOrUsername, fes.GetPublicKeyPrefix()) {
 		// The receiver string is too short to be a public key.  Lookup the username.
-		profileEntry = utxoView.GetProfileEntryForUsername([]byte(pubKeyOrUsername))
+		profileEntry = fes.getProfileEntryForUsernameWithCache(pubKeyOrUsername, utxoView)
 		if profileEntry == nil {
 			return nil, nil, fmt.Errorf("Problem getting profile for username %s", pubKeyOrUsername)
 		}
@@ -3581,6 +3658,33 @@ func (fes *APIServer) GetPubKeyAndProfileEntryForUsernameOrPublicKeyBase58Check(
 	return pubKeyBytes, profileEntry, nil
 }
 
+// getProfileEntryForUsernameWithCache looks up the profile for a username, using the UsernameToPKIDCache if it is
+// enabled. A cached PKID is only used if the profile it resolves to still has the requested username, so a stale
+// entry falls back to a fresh lookup rather than returning the wrong profile.
+func (fes *APIServer) getProfileEntryForUsernameWithCache(username string, utxoView *lib.UtxoView) *lib.ProfileEntry {
+	if fes.UsernameToPKIDCache == nil {
+		return utxoView.GetProfileEntryForUsername([]byte(username))
+	}
+
+	if _, pkid, found := fes.UsernameToPKIDCache.Get(username); found {
+		profileEntry := utxoView.GetProfileEntryForPKID(pkid)
+		if profileEntry != nil && !profileEntry.IsDeleted() &&
+			strings.EqualFold(string(profileEntry.Username), username) {
+			return profileEntry
+		}
+		fes.UsernameToPKIDCache.Delete(username)
+	}
+
+	profileEntry := utxoView.GetProfileEntryForUsername([]byte(username))
+	if profileEntry != nil {
+		pkidEntry := utxoView.GetPKIDForPublicKey(profileEntry.PublicKey)
+		if pkidEntry != nil {
+			fes.UsernameToPKIDCache.Put(username, profileEntry.PublicKey, pkidEntry.PKID)
+		}
+	}
+	return profileEntry
+}
+
 func GetPubKeyBytesFromBase58Check(pubKeyBase58Check string) (_pubKeyBytes []byte, _err error) {
 	pubKeyBytes, _, err := lib.Base58CheckDecode(pubKeyBase58Check)
 	if err != nil || len(pubKeyBytes) != btcec.PubKeyBytesLenCompressed {
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deso-smart/deso-core/v3/lib"
+)
+
+// UsernameToPKIDCache is a short-lived, in-memory cache of lowercase usernames to the public key and PKID they
+// resolved to. Username to PKID mappings only change when a user swaps identities or updates their profile, so
+// caching these lookups avoids repeated calls to GetProfileEntryForUsername on hot paths. Entries are considered
+// stale after the configured TTL, and the whole cache is cleared whenever this node broadcasts a swap identity or
+// update profile transaction.
+type UsernameToPKIDCache struct {
+	mtx     sync.RWMutex
+	ttl     time.Duration
+	entries map[string]*usernameToPKIDCacheEntry
+	// Every Put in the order it happened. Since the TTL is fixed this is also the order entries expire in, so
+	// expired entries can be swept from the front without scanning the whole map.
+	expiryQueue []usernameToPKIDCacheExpiry
+}
+
+type usernameToPKIDCacheEntry struct {
+	PublicKey       []byte
+	PKID            *lib.PKID
+	ExpiresAtTstamp time.Time
+}
+
+type usernameToPKIDCacheExpiry struct {
+	lowercaseUsername string
+	expiresAtTstamp   time.Time
+}
+
+func NewUsernameToPKIDCache(ttl time.Duration) *UsernameToPKIDCache {
+	return &UsernameToPKIDCache{
+		ttl:     ttl,
+		entries: make(map[string]*usernameToPKIDCacheEntry),
+	}
+}
+
+// Get returns the cached public key and PKID for the username, if there is an entry that has not expired yet.
+func (cache *UsernameToPKIDCache) Get(username string) (_publicKey []byte, _pkid *lib.PKID, _found bool) {
+	cache.mtx.RLock()
+	defer cache.mtx.RUnlock()
+
+	entry, exists := cache.entries[strings.ToLower(username)]
+	if !exists || time.Now().After(entry.ExpiresAtTstamp) {
+		return nil, nil, false
+	}
+	return entry.PublicKey, entry.PKID, true
+}
+
+func (cache *UsernameToPKIDCache) Put(username string, publicKey []byte, pkid *lib.PKID) {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	// Sweep out expired entries on write so the map doesn't grow without bound. An entry that was put again since
+	// is left alone, since it has a later expiry further back in the queue.
+	now := time.Now()
+	for len(cache.expiryQueue) > 0 && now.After(cache.expiryQueue[0].expiresAtTstamp) {
+		expiry := cache.expiryQueue[0]
+		cache.expiryQueue = cache.expiryQueue[1:]
+		if entry, exists := cache.entries[expiry.lowercaseUsername]; exists &&
+			!entry.ExpiresAtTstamp.After(expiry.expiresAtTstamp) {
+			delete(cache.entries, expiry.lowercaseUsername)
+		}
+	}
+
+	lowercaseUsername := strings.ToLower(username)
+	expiresAtTstamp := now.Add(cache.ttl)
+	cache.entries[lowercaseUsername] = &usernameToPKIDCacheEntry{
+		PublicKey:       publicKey,
+		PKID:            pkid,
+		ExpiresAtTstamp: expiresAtTstamp,
+	}
+	cache.expiryQueue = append(cache.expiryQueue, usernameToPKIDCacheExpiry{
+		lowercaseUsername: lowercaseUsername,
+		expiresAtTstamp:   expiresAtTstamp,
+	})
+}
+
+func (cache *UsernameToPKIDCache) Delete(username string) {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	delete(cache.entries, strings.ToLower(username))
+}
+
+// Clear removes all entries from the cache.
+func (cache *UsernameToPKIDCache) Clear() {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	cache.entries = make(map[string]*usernameToPKIDCacheEntry)
+	cache.expiryQueue = nil
+}
@@ -6,6 +6,36 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Fallbacks for when the page size config values are unset, ex: in tests that don't load a config.
+const (
+	fallbackDefaultPageSize = 100
+	fallbackMaxPageSize     = 1000
+)
+
+// getPageSize returns the page size a paginated endpoint should use for a request. If requestedPageSize is zero, the
+// node's --default-page-size is used. The result is clamped to --max-page-size.
+func (fes *APIServer) getPageSize(requestedPageSize uint64) int {
+	defaultPageSize := uint64(fallbackDefaultPageSize)
+	maxPageSize := uint64(fallbackMaxPageSize)
+	if fes.Config != nil {
+		if fes.Config.DefaultPageSize > 0 {
+			defaultPageSize = fes.Config.DefaultPageSize
+		}
+		if fes.Config.MaxPageSize > 0 {
+			maxPageSize = fes.Config.MaxPageSize
+		}
+	}
+
+	pageSize := requestedPageSize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return int(pageSize)
+}
+
 // decodeBlockHashFromHex Decodes a BlockHash given a valid hex encoding. If the input does not represent a valid
 // BlockHash, this returns the corresponding error
 func decodeBlockHashFromHex(hexEncoding string) (*lib.BlockHash, error) {
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sendgrid/rest"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 
@@ -50,7 +51,7 @@ How verification works:
      B. phoneNumberMetadata is created, which maps phone number => user's public key
 *************************************************************/
 func (fes *APIServer) SendPhoneNumberVerificationText(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SendPhoneNumberVerificationTextRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SendPhoneNumberVerificationText: Problem parsing request body: %v", err))
@@ -260,7 +261,7 @@ type SubmitPhoneNumberVerificationCodeResponse struct {
 }
 
 func (fes *APIServer) SubmitPhoneNumberVerificationCode(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := SubmitPhoneNumberVerificationCodeRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("SubmitPhoneNumberVerificationCode: Problem parsing request body: %v", err))
@@ -379,12 +380,32 @@ func (fes *APIServer) SubmitPhoneNumberVerificationCode(ww http.ResponseWriter,
 			amountToSendNanos = fes.GetPhoneVerificationAmountToSendNanos(requestData.PhoneNumber)
 		}
 
+		// A usable referral link the user signed up with overrides the phone prefix amount.
+		var referralAmountNanos uint64
+		if fes.Config.EnableReferrals && userMetadata.ReferralHashBase58Check != "" {
+			referralAmountNanos = fes.reserveReferralStarterDeSoNanos(userMetadata.ReferralHashBase58Check)
+			if referralAmountNanos > 0 {
+				amountToSendNanos = referralAmountNanos
+			}
+		}
+
 		var txnHash *lib.BlockHash
 		txnHash, err = fes.SendSeedDeSo(userMetadata.PublicKey, amountToSendNanos, false)
 		if err != nil {
+			if referralAmountNanos > 0 {
+				fes.releaseReferralStarterDeSoNanos(userMetadata.ReferralHashBase58Check, referralAmountNanos)
+			}
 			_AddBadRequestError(ww, fmt.Sprintf("SubmitPhoneNumberVerificationCode: Error sending seed DeSo: %v", err))
 			return
 		}
+
+		// The payout already went out, so failing to record it is logged rather than returned.
+		if referralAmountNanos > 0 {
+			userMetadata.PhoneVerificationReferralDeSoNanos = referralAmountNanos
+			if err = fes.putUserMetadataInGlobalState(userMetadata); err != nil {
+				glog.Errorf("SubmitPhoneNumberVerificationCode: Error saving referral payout in user metadata: %v", err)
+			}
+		}
 		res := SubmitPhoneNumberVerificationCodeResponse{
 			TxnHashHex: txnHash.String(),
 		}
@@ -395,7 +416,76 @@ func (fes *APIServer) SubmitPhoneNumberVerificationCode(ww http.ResponseWriter,
 	}
 }
 
+// TwilioVerifyService holds the subset of fields returned by Twilio's fetch service endpoint that we surface to
+// admins.
+type TwilioVerifyService struct {
+	Sid          string `json:"sid"`
+	FriendlyName string `json:"friendly_name"`
+	CodeLength   int    `json:"code_length"`
+}
+
+type AdminTestTwilioConfigRequest struct {
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminTestTwilioConfigResponse struct {
+	IsValid bool
+
+	ServiceSid          string `safeForLogging:"true"`
+	ServiceFriendlyName string `safeForLogging:"true"`
+	ServiceCodeLength   int    `safeForLogging:"true"`
+
+	// The error returned by Twilio, if the credentials or service ID were rejected.
+	Error string `safeForLogging:"true"`
+}
+
+// AdminTestTwilioConfig fetches the configured verify service from Twilio. This exercises the account SID, auth
+// token, and verify service ID without sending any texts.
+func (fes *APIServer) AdminTestTwilioConfig(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminTestTwilioConfigRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminTestTwilioConfig: Problem parsing request body: %v", err))
+		return
+	}
+
+	if fes.Twilio == nil {
+		_AddBadRequestError(ww, "AdminTestTwilioConfig: Twilio not configured")
+		return
+	}
+	if fes.Config.TwilioVerifyServiceID == "" {
+		_AddBadRequestError(ww, "AdminTestTwilioConfig: Twilio verify service ID not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	service := TwilioVerifyService{}
+	res := AdminTestTwilioConfigResponse{}
+	if err := fes.Twilio.Verify.GetResource(ctx, "Services", fes.Config.TwilioVerifyServiceID, &service); err != nil {
+		res.Error = err.Error()
+	} else {
+		res.IsValid = true
+		res.ServiceSid = service.Sid
+		res.ServiceFriendlyName = service.FriendlyName
+		res.ServiceCodeLength = service.CodeLength
+	}
+
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminTestTwilioConfig: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
 func (fes *APIServer) GetPhoneVerificationAmountToSendNanos(phoneNumber string) uint64 {
+	_, amountNanos := fes.getStarterPrefixAndAmountForPhoneNumber(phoneNumber)
+	return amountNanos
+}
+
+// getStarterPrefixAndAmountForPhoneNumber returns the prefix from the starter prefix nanos map that matches
+// phoneNumber, along with the amount of starter DeSo for that prefix. If no prefix matches, the prefix is
+// empty and the default starter DeSo amount is returned.
+func (fes *APIServer) getStarterPrefixAndAmountForPhoneNumber(phoneNumber string) (_prefix string, _amountNanos uint64) {
 	// We sort the country codes by size, with the longest prefix
 	// first so that we match on the longest prefix when we iterate.
 	sortedPrefixExceptionMap := []string{}
@@ -408,10 +498,92 @@ func (fes *APIServer) GetPhoneVerificationAmountToSendNanos(phoneNumber string)
 	for _, countryPrefix := range sortedPrefixExceptionMap {
 		amountForPrefix := fes.Config.StarterPrefixNanosMap[countryPrefix]
 		if strings.Contains(phoneNumber, countryPrefix) {
-			return amountForPrefix
+			return countryPrefix, amountForPrefix
+		}
+	}
+	return "", fes.Config.StarterDESONanos
+}
+
+// reserveReferralStarterDeSoNanos returns the starter DeSo, converted from the referral link's RefereeAmountUSDCents at
+// the current exchange rate, for a user who signed up through it. The referral is counted against MaxReferrals and
+// the payout added to TotalRefereeDeSoNanos under the referral hash's lock so that concurrent sign-ups can't exceed
+// the limit. It returns 0 without counting anything if the link doesn't exist, is inactive or expired, has hit
+// MaxReferrals, or has no referee amount, in which case the phone prefix amount should be used instead.
+func (fes *APIServer) reserveReferralStarterDeSoNanos(referralHashBase58 string) (_amountNanos uint64) {
+	var amountNanos uint64
+	if _, err := fes.updateReferralInfoForReferralHash(referralHashBase58, func(latestReferralInfo *ReferralInfo) error {
+		if latestReferralInfo.MaxReferrals > 0 && latestReferralInfo.TotalReferrals >= latestReferralInfo.MaxReferrals {
+			return fmt.Errorf("max referrals reached")
+		}
+		if !fes.isReferralHashActive(latestReferralInfo) {
+			return fmt.Errorf("referral hash is inactive or expired")
+		}
+		amountNanos = fes.GetNanosFromUSDCents(float64(latestReferralInfo.RefereeAmountUSDCents), 0)
+		if amountNanos == 0 {
+			return fmt.Errorf("referral hash has no referee amount")
+		}
+		latestReferralInfo.TotalReferrals++
+		latestReferralInfo.TotalRefereeDeSoNanos += amountNanos
+		return nil
+	}); err != nil {
+		glog.Infof("reserveReferralStarterDeSoNanos: Not using referral amount for %s: %v", referralHashBase58, err)
+		return 0
+	}
+	return amountNanos
+}
+
+// releaseReferralStarterDeSoNanos undoes reserveReferralStarterDeSoNanos when the starter DeSo couldn't be sent.
+func (fes *APIServer) releaseReferralStarterDeSoNanos(referralHashBase58 string, amountNanos uint64) {
+	if _, err := fes.updateReferralInfoForReferralHash(referralHashBase58, func(latestReferralInfo *ReferralInfo) error {
+		if latestReferralInfo.TotalReferrals > 0 {
+			latestReferralInfo.TotalReferrals--
 		}
+		if latestReferralInfo.TotalRefereeDeSoNanos >= amountNanos {
+			latestReferralInfo.TotalRefereeDeSoNanos -= amountNanos
+		}
+		return nil
+	}); err != nil {
+		glog.Errorf("releaseReferralStarterDeSoNanos: Error releasing referral for %s: %v", referralHashBase58, err)
+	}
+}
+
+type GetStarterDeSoForPrefixRequest struct {
+	// A phone number prefix (ex: +1) or full phone number.
+	PhonePrefix string `safeForLogging:"true"`
+}
+
+type GetStarterDeSoForPrefixResponse struct {
+	StarterDeSoNanos uint64
+	// The prefix in the starter prefix nanos map that PhonePrefix matched. Empty if the default amount is used.
+	MatchedPrefix string
+	IsDefault     bool
+}
+
+// GetStarterDeSoForPrefix returns the amount of starter DeSo a user who verifies a phone number with the given prefix
+// would receive.
+func (fes *APIServer) GetStarterDeSoForPrefix(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := GetStarterDeSoForPrefixRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("GetStarterDeSoForPrefix: Problem parsing request body: %v", err))
+		return
+	}
+
+	if requestData.PhonePrefix == "" {
+		_AddBadRequestError(ww, "GetStarterDeSoForPrefix: PhonePrefix is required")
+		return
+	}
+
+	matchedPrefix, amountNanos := fes.getStarterPrefixAndAmountForPhoneNumber(requestData.PhonePrefix)
+	res := GetStarterDeSoForPrefixResponse{
+		StarterDeSoNanos: amountNanos,
+		MatchedPrefix:    matchedPrefix,
+		IsDefault:        matchedPrefix == "",
+	}
+	if err := json.NewEncoder(ww).Encode(res); err != nil {
+		_AddInternalServerError(ww, fmt.Sprintf("GetStarterDeSoForPrefix: Problem encoding response as JSON: %v", err))
+		return
 	}
-	return fes.Config.StarterDESONanos
 }
 
 type ResendVerifyEmailRequest struct {
@@ -420,7 +592,7 @@ type ResendVerifyEmailRequest struct {
 }
 
 func (fes *APIServer) ResendVerifyEmail(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := ResendVerifyEmailRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("ResendVerifyEmail: Problem parsing request body: %v", err))
@@ -464,7 +636,7 @@ type VerifyEmailRequest struct {
 }
 
 func (fes *APIServer) VerifyEmail(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := VerifyEmailRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("VerifyEmail: Problem parsing request body: %v", err))
@@ -532,19 +704,82 @@ func (fes *APIServer) sendEmail(email *mail.SGMailV3) {
 		return
 	}
 
-	request := sendgrid.GetRequest(fes.Config.SendgridApiKey, "/v3/mail/send", "https://api.sendgrid.com")
-	request.Method = "POST"
-	request.Body = mail.GetRequestBody(email)
-	response, err := sendgrid.API(request)
+	response, err := fes.sendEmailWithResponse(email)
 	if err != nil {
 		glog.Errorf("%v: %v", err, response)
 	}
 }
 
+// sendEmailWithResponse sends the email through Sendgrid and returns Sendgrid's response so that callers
+// can inspect the status code and body.
+func (fes *APIServer) sendEmailWithResponse(email *mail.SGMailV3) (*rest.Response, error) {
+	request := sendgrid.GetRequest(fes.Config.SendgridApiKey, "/v3/mail/send", "https://api.sendgrid.com")
+	request.Method = "POST"
+	request.Body = mail.GetRequestBody(email)
+	return sendgrid.API(request)
+}
+
 func (fes *APIServer) IsConfiguredForSendgrid() bool {
 	return fes.Config.SendgridApiKey != ""
 }
 
+type AdminSendTestEmailRequest struct {
+	EmailAddress string `safeForLogging:"true"`
+
+	AdminPublicKey string `safeForLogging:"true"`
+}
+
+type AdminSendTestEmailResponse struct {
+	Success bool
+
+	// The status code and body returned by Sendgrid.
+	StatusCode   int
+	ResponseBody string
+}
+
+// AdminSendTestEmail sends a plain test email using the node's Sendgrid configuration so that super admins
+// can confirm the integration works without triggering a real verification flow.
+func (fes *APIServer) AdminSendTestEmail(ww http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
+	requestData := AdminSendTestEmailRequest{}
+	if err := decoder.Decode(&requestData); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminSendTestEmail: Problem parsing request body: %v", err))
+		return
+	}
+
+	if !fes.IsConfiguredForSendgrid() {
+		_AddBadRequestError(ww, "AdminSendTestEmail: Sendgrid not configured")
+		return
+	}
+
+	emailAddress := strings.TrimSpace(requestData.EmailAddress)
+	if emailAddress == "" {
+		_AddBadRequestError(ww, "AdminSendTestEmail: EmailAddress is required")
+		return
+	}
+
+	from := mail.NewEmail(fes.Config.SendgridFromName, fes.Config.SendgridFromEmail)
+	to := mail.NewEmail("", emailAddress)
+	email := mail.NewSingleEmail(from, "Test email", to,
+		"This is a test email sent to verify this node's Sendgrid configuration.", "")
+
+	response, err := fes.sendEmailWithResponse(email)
+	if err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminSendTestEmail: Problem sending email: %v", err))
+		return
+	}
+
+	res := AdminSendTestEmailResponse{
+		Success:      response.StatusCode >= 200 && response.StatusCode < 300,
+		StatusCode:   response.StatusCode,
+		ResponseBody: response.Body,
+	}
+	if err = json.NewEncoder(ww).Encode(res); err != nil {
+		_AddBadRequestError(ww, fmt.Sprintf("AdminSendTestEmail: Problem encoding response as JSON: %v", err))
+		return
+	}
+}
+
 //
 // JUMIO
 //
@@ -578,7 +813,7 @@ type JumioBeginResponse struct {
 }
 
 func (fes *APIServer) JumioBegin(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := JumioBeginRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("JumioBegin: Problem parsing request body: %v", err))
@@ -612,15 +847,17 @@ func (fes *APIServer) JumioBegin(ww http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if requestData.ReferralHashBase58 != "" {
+	if fes.Config.EnableReferrals && requestData.ReferralHashBase58 != "" {
 		var referralInfo *ReferralInfo
 		referralInfo, err = fes.getInfoForReferralHashBase58(requestData.ReferralHashBase58)
 		if err != nil {
 			glog.Errorf("JumioBegin: Error getting referral info: %v", err)
 		} else if referralInfo != nil {
-			userMetadata.ReferralHashBase58Check = requestData.ReferralHashBase58
-			referralInfo.NumJumioAttempts++
-			if err = fes.putReferralHashWithInfo(referralInfo.ReferralHashBase58, referralInfo); err != nil {
+			userMetadata.ReferralHashBase58Check = referralInfo.ReferralHashBase58
+			if _, err = fes.updateReferralInfoForReferralHash(referralInfo.ReferralHashBase58, func(latestReferralInfo *ReferralInfo) error {
+				latestReferralInfo.NumJumioAttempts++
+				return nil
+			}); err != nil {
 				glog.Errorf("JumioBegin: Error updating referral info: %v", err)
 			}
 		}
@@ -713,7 +950,7 @@ type JumioFlowFinishedRequest struct {
 }
 
 func (fes *APIServer) JumioFlowFinished(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := JumioFlowFinishedRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("JumioFlowFinished: Problem parsing request body: %v", err))
@@ -804,7 +1041,7 @@ func (fes *APIServer) JumioCallback(ww http.ResponseWriter, req *http.Request) {
 	}
 	publicKeyBytes, _, err := lib.Base58CheckDecode(userReference)
 	if err != nil {
-		_AddBadRequestError(ww, fmt.Sprintf("JumioCallback: Problem decoding user public key (customerId): %v", err))
+		_AddBadRequestErrorWithCode(ww, ErrorCodeInvalidPublicKey, fmt.Sprintf("JumioCallback: Problem decoding user public key (customerId): %v", err))
 		return
 	}
 
@@ -1024,32 +1261,48 @@ func (fes *APIServer) JumioVerifiedHandler(userMetadata *UserMetadata, jumioTran
 	// We will always get a valid signUpBonusMetadataObject, so glog the error and proceed.
 	signUpBonusMetadata := fes.GetSingleCountrySignUpBonus(jumioCountryCode)
 
+	// The referral hash the user signed up with is ignored when referrals are turned off.
+	referralHashBase58 := ""
+	if fes.Config.EnableReferrals {
+		referralHashBase58 = userMetadata.ReferralHashBase58Check
+	}
+
 	// Decide whether or not the user is going to get paid.
-	if signUpBonusMetadata.ReferralAmountOverrideUSDCents > 0 || userMetadata.ReferralHashBase58Check != "" {
+	if signUpBonusMetadata.ReferralAmountOverrideUSDCents > 0 || referralHashBase58 != "" {
 		payReferrer := false
+		// If the referee was already paid the referral amount when they verified their phone number, the referral
+		// was counted against MaxReferrals then and that payout is deducted from the referee bonus below.
+		referralCountedAtPhoneVerification := userMetadata.PhoneVerificationReferralDeSoNanos > 0
 
 		referralAmountUSDCents := uint64(0)
 		// Decide whether the user should be paid the standard amount or a special referral amount.
-		if userMetadata.ReferralHashBase58Check != "" {
+		if referralHashBase58 != "" {
 			var referralInfo *ReferralInfo
-			referralInfo, err = fes.getInfoForReferralHashBase58(userMetadata.ReferralHashBase58Check)
+			referralInfo, err = fes.getInfoForReferralHashBase58(referralHashBase58)
 			if err != nil {
 				glog.Errorf("JumioVerifiedHandler: Error getting referral info: %v", err)
-			} else if referralInfo != nil && (referralInfo.TotalReferrals < referralInfo.MaxReferrals || referralInfo.MaxReferrals == 0) && fes.getReferralHashStatus(referralInfo.ReferrerPKID, referralInfo.ReferralHashBase58) {
+			} else if referralInfo != nil && (referralCountedAtPhoneVerification || referralInfo.TotalReferrals < referralInfo.MaxReferrals || referralInfo.MaxReferrals == 0) && fes.isReferralHashActive(referralInfo) {
 				referralAmountUSDCents = referralInfo.RefereeAmountUSDCents
 				payReferrer = true
 			}
 		}
 
 		refereeSignUpBonusDeSoNanos := fes.GetRefereeSignUpBonusAmount(signUpBonusMetadata, referralAmountUSDCents)
+		if refereeSignUpBonusDeSoNanos > userMetadata.PhoneVerificationReferralDeSoNanos {
+			refereeSignUpBonusDeSoNanos -= userMetadata.PhoneVerificationReferralDeSoNanos
+		} else {
+			refereeSignUpBonusDeSoNanos = 0
+		}
+		refereeTxnHashHex := ""
 
 		publicKeyString := lib.PkToString(publicKeyBytes, fes.Params)
 		glog.Infof("JumioVerifiedHandler: Paying %d nanos to public key %s as referee sign-up bonus. "+
 			"Country code: %s. Country Allow Custom Referral Amount: %t. "+
-			"Country Referral amount override: %d. Referrer Amount from Referral Code: %d.",
+			"Country Referral amount override: %d. Referrer Amount from Referral Code: %d. "+
+			"Already paid at phone verification: %d.",
 			refereeSignUpBonusDeSoNanos, publicKeyString, jumioCountryCode,
 			signUpBonusMetadata.AllowCustomReferralAmount, signUpBonusMetadata.ReferralAmountOverrideUSDCents,
-			referralAmountUSDCents)
+			referralAmountUSDCents, userMetadata.PhoneVerificationReferralDeSoNanos)
 
 		// Pay the referee.
 		if refereeSignUpBonusDeSoNanos > 0 {
@@ -1073,35 +1326,50 @@ func (fes *APIServer) JumioVerifiedHandler(userMetadata *UserMetadata, jumioTran
 			eventDataMap := make(map[string]interface{})
 			eventDataMap["amountNanos"] = refereeSignUpBonusDeSoNanos
 			eventDataMap["txnHashHex"] = txnHash.String()
-			eventDataMap["referralCode"] = userMetadata.ReferralHashBase58Check
+			eventDataMap["referralCode"] = referralHashBase58
 			if err = fes.logAmplitudeEvent(lib.PkToString(publicKeyBytes, fes.Params), "referral : payout : referee", eventDataMap); err != nil {
 				glog.Errorf("JumioVerifiedhandler: Error logging payout to referee in amplitude: %v", err)
 			}
 
 			// Save transaction hash hex in user metadata.
 			userMetadata.JumioStarterDeSoTxnHashHex = txnHash.String()
+			refereeTxnHashHex = txnHash.String()
 		}
 
 		// Pay the referrer.
-		if userMetadata.ReferralHashBase58Check != "" && payReferrer {
+		if referralHashBase58 != "" && payReferrer {
 			// We get the referral info again from global state. It is possible that another referral has been given out
 			// and to make sure the stats are correct, we pull the latest referral info.
 			var referralInfo *ReferralInfo
-			referralInfo, err = fes.getInfoForReferralHashBase58(userMetadata.ReferralHashBase58Check)
+			referralInfo, err = fes.getInfoForReferralHashBase58(referralHashBase58)
 			if err != nil {
 				return userMetadata, fmt.Errorf("JumioVerifiedHandler: Error getting referral info: %v", err)
 			}
 
 			kickbackAmountDeSoNanos := fes.GetReferrerSignUpBonusAmount(signUpBonusMetadata,
 				referralInfo.ReferrerAmountUSDCents)
-			// Add an index for logging all the PKIDs referred by a single PKID+ReferralHash pair.
+			currTimestampNanos := uint64(time.Now().UTC().UnixNano()) // current tstamp
+			// Add an index for logging all the PKIDs referred by a single PKID+ReferralHash pair. We record the
+			// amounts paid and the exchange rate used so that payouts can be audited later. The referrer's amount is
+			// only filled in once they've actually been paid, so that a failed payout isn't recorded as sent.
 			refereePKID := utxoView.GetPKIDForPublicKey(publicKeyBytes)
+			refereePayoutInfo := &RefereePayoutInfo{
+				UsdCentsPerDeSoExchangeRate: fes.GetExchangeDeSoPrice(),
+				RefereeDeSoNanos:            refereeSignUpBonusDeSoNanos,
+				ReferrerDeSoNanos:           0,
+				TstampNanos:                 currTimestampNanos,
+				RefereeTxnHashHex:           refereeTxnHashHex,
+			}
 			pkidReferralHashRefereePKIDKey := GlobalStateKeyForPKIDReferralHashRefereePKID(referralInfo.ReferrerPKID, []byte(referralInfo.ReferralHashBase58), refereePKID.PKID)
-			if err = fes.GlobalState.Put(pkidReferralHashRefereePKIDKey, []byte{1}); err != nil {
-				glog.Errorf("JumioVerifiedHandler: Error adding to the index of users who were referred by a given referral code")
+			if err = fes.putRefereePayoutInfo(pkidReferralHashRefereePKIDKey, refereePayoutInfo); err != nil {
+				glog.Errorf("JumioVerifiedHandler: Error adding to the index of users who were referred by a given referral code: %v", err)
+			}
+			// Also index the referral hash by the referee alone, so it can be found without knowing the referrer.
+			if err = fes.GlobalState.Put(GlobalStateKeyForRefereePKIDToReferralHash(refereePKID.PKID),
+				[]byte(referralInfo.ReferralHashBase58)); err != nil {
+				glog.Errorf("JumioVerifiedHandler: Error adding to the index of referral hashes by referee: %v", err)
 			}
 			// Same as the index above but sorted by timestamp.
-			currTimestampNanos := uint64(time.Now().UTC().UnixNano()) // current tstamp
 			tstampPKIDReferralHashRefereePKIDKey := GlobalStateKeyForTimestampPKIDReferralHashRefereePKID(
 				currTimestampNanos, referralInfo.ReferrerPKID, []byte(referralInfo.ReferralHashBase58), refereePKID.PKID)
 			if err = fes.GlobalState.Put(tstampPKIDReferralHashRefereePKIDKey, []byte{1}); err != nil {
@@ -1117,7 +1385,7 @@ func (fes *APIServer) JumioVerifiedHandler(userMetadata *UserMetadata, jumioTran
 				kickbackAmountDeSoNanos, referrerPublicKeyString, jumioCountryCode,
 				signUpBonusMetadata.AllowCustomKickbackAmount, signUpBonusMetadata.KickbackAmountOverrideUSDCents,
 				referralInfo.ReferrerAmountUSDCents)
-			if referralInfo.TotalReferrals >= referralInfo.MaxReferrals && referralInfo.MaxReferrals > 0 {
+			if !referralCountedAtPhoneVerification && referralInfo.TotalReferrals >= referralInfo.MaxReferrals && referralInfo.MaxReferrals > 0 {
 				glog.Info("JumioVerifiedHandler: Not paying for kickback. Max Referrals exceeded")
 				return userMetadata, nil
 			}
@@ -1131,14 +1399,20 @@ func (fes *APIServer) JumioVerifiedHandler(userMetadata *UserMetadata, jumioTran
 				return userMetadata, fmt.Errorf("JumioVerifiedHandler: Balance insufficient to pay referrer")
 			}
 
-			// Increment JumioSuccesses, TotalReferrals and add to TotralRefereeDeSoNanos and TotalReferrerDeSoNanos
-			referralInfo.NumJumioSuccesses++
-			referralInfo.TotalReferrals++
-			referralInfo.TotalRefereeDeSoNanos += refereeSignUpBonusDeSoNanos
-			referralInfo.TotalReferrerDeSoNanos += kickbackAmountDeSoNanos
-
-			// Update the referral info in global state.
-			if err = fes.putReferralHashWithInfo(userMetadata.ReferralHashBase58Check, referralInfo); err != nil {
+			// Increment JumioSuccesses, TotalReferrals and add to TotralRefereeDeSoNanos and TotalReferrerDeSoNanos.
+			// We apply the increments to the latest copy of the referral info in global state so that concurrent
+			// payouts for the same referral hash don't overwrite each other's totals. A referral that was counted at
+			// phone verification isn't counted again.
+			referralInfo, err = fes.updateReferralInfoForReferralHash(referralHashBase58, func(latestReferralInfo *ReferralInfo) error {
+				latestReferralInfo.NumJumioSuccesses++
+				if !referralCountedAtPhoneVerification {
+					latestReferralInfo.TotalReferrals++
+				}
+				latestReferralInfo.TotalRefereeDeSoNanos += refereeSignUpBonusDeSoNanos
+				latestReferralInfo.TotalReferrerDeSoNanos += kickbackAmountDeSoNanos
+				return nil
+			})
+			if err != nil {
 				return userMetadata, fmt.Errorf("JumioVerifiedHandler: Error updating referral info. Skipping paying referrer: %v", err)
 			}
 			// Check that we actually have to pay the referrer before proceeding
@@ -1155,20 +1429,42 @@ func (fes *APIServer) JumioVerifiedHandler(userMetadata *UserMetadata, jumioTran
 			eventDataMap := make(map[string]interface{})
 			eventDataMap["amountNanos"] = kickbackAmountDeSoNanos
 			eventDataMap["txnHashHex"] = referrerTxnHash.String()
-			eventDataMap["referralCode"] = userMetadata.ReferralHashBase58Check
+			eventDataMap["referralCode"] = referralHashBase58
 			eventDataMap["refereePublicKey"] = lib.PkToString(publicKeyBytes, fes.Params)
 			eventDataMap["totalReferrals"] = referralInfo.TotalReferrals
 			eventDataMap["totalReferrerPayoutNanos"] = referralInfo.TotalReferrerDeSoNanos
 			if err = fes.logAmplitudeEvent(lib.PkToString(referrerPublicKeyBytes, fes.Params), "referral : payout : referrer", eventDataMap); err != nil {
 				glog.Errorf("JumioVerifiedhandler: Error logging payout to referrer in amplitude: %v", err)
 			}
-			// Set the referrer deso txn hash.
+			// Set the referrer deso txn hash, and record the referrer's payout now that it's been sent.
 			userMetadata.ReferrerDeSoTxnHash = referrerTxnHash.String()
+			refereePayoutInfo.ReferrerDeSoNanos = kickbackAmountDeSoNanos
+			refereePayoutInfo.ReferrerTxnHashHex = referrerTxnHash.String()
+			if err = fes.putRefereePayoutInfo(pkidReferralHashRefereePKIDKey, refereePayoutInfo); err != nil {
+				glog.Errorf("JumioVerifiedHandler: Error recording referrer payout in referee payout info: %v", err)
+			}
 		}
 	}
 	return userMetadata, nil
 }
 
+// putRefereePayoutInfo writes the referee index entry recording what was paid out for a referee. If the payout info
+// can't be encoded we still write the placeholder value so that the referee is counted.
+func (fes *APIServer) putRefereePayoutInfo(refereeIndexKey []byte, refereePayoutInfo *RefereePayoutInfo) error {
+	refereeIndexVal := []byte{1}
+	refereePayoutInfoBytes, encodeErr := encodeRefereePayoutInfo(refereePayoutInfo)
+	if encodeErr == nil {
+		refereeIndexVal = refereePayoutInfoBytes
+	}
+	if err := fes.GlobalState.Put(refereeIndexKey, refereeIndexVal); err != nil {
+		return fmt.Errorf("putRefereePayoutInfo: Problem putting referee index entry: %v", err)
+	}
+	if encodeErr != nil {
+		return fmt.Errorf("putRefereePayoutInfo: Problem encoding referee payout info: %v", encodeErr)
+	}
+	return nil
+}
+
 // SetJumioUSDCents sets the cached value of the default amount a user receives for verifying with Jumio without a
 // referral code.
 func (fes *APIServer) SetJumioUSDCents() {
@@ -1217,7 +1513,7 @@ type GetJumioStatusForPublicKeyResponse struct {
 }
 
 func (fes *APIServer) GetJumioStatusForPublicKey(ww http.ResponseWriter, rr *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(rr.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(rr.Body, getMaxRequestBodySizeBytesForRequest(rr)))
 	requestData := GetJumioStatusForPublicKeyRequest{}
 	if err := decoder.Decode(&requestData); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetJumioStatusForPublicKey: Error parsing request body: %v", err))
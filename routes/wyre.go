@@ -119,7 +119,7 @@ func (fes *APIServer) WyreWalletOrderSubscription(ww http.ResponseWriter, req *h
 	}
 
 	// Decode the request body
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	wyreWalletOrderWebhookRequest := WyreWalletOrderWebhookPayload{}
 	if err := decoder.Decode(&wyreWalletOrderWebhookRequest); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("WyreWalletOrderSubscription: Error parsing request body: %v", err))
@@ -361,7 +361,7 @@ func (fes *APIServer) GetWyreWalletOrderQuotation(ww http.ResponseWriter, req *h
 		return
 	}
 	// Decode the request body
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	wyreWalletOrderQuotationRequest := WalletOrderQuotationRequest{}
 	if err := decoder.Decode(&wyreWalletOrderQuotationRequest); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetWyreWalletOrderQuotation: Error parsing request body: %v", err))
@@ -419,7 +419,7 @@ func (fes *APIServer) GetWyreWalletOrderReservation(ww http.ResponseWriter, req
 		return
 	}
 	// Decode the request body
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	wyreWalletOrderReservationRequest := WalletOrderReservationRequest{}
 	if err := decoder.Decode(&wyreWalletOrderReservationRequest); err != nil {
 		_AddBadRequestError(ww, fmt.Sprintf("GetWyreWalletOrderReservation: Error parsing request body: %v", err))
@@ -590,7 +590,7 @@ type GetWyreWalletOrderForPublicKeyResponse struct {
 }
 
 func (fes *APIServer) GetWyreWalletOrdersForPublicKey(ww http.ResponseWriter, req *http.Request) {
-	decoder := json.NewDecoder(io.LimitReader(req.Body, MaxRequestBodySizeBytes))
+	decoder := json.NewDecoder(io.LimitReader(req.Body, getMaxRequestBodySizeBytesForRequest(req)))
 	requestData := GetWyreWalletOrderForPublicKeyRequest{}
 	var err error
 	if err = decoder.Decode(&requestData); err != nil {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/deso-smart/deso-backend/v3/routes"
@@ -47,7 +48,7 @@ func main() {
 	fmt.Println("Public key found:", lib.PkToString(updaterPubKey.SerializeCompressed(), params))
 
 	// Submit the update transaction
-	err = toolslib.UpdateBitcoinUSDExchangeRate(updaterPubKey, updaterPrivKey, newUSDCentsPerBitcoin, params, node)
+	err = toolslib.UpdateBitcoinUSDExchangeRate(context.Background(), updaterPubKey, updaterPrivKey, newUSDCentsPerBitcoin, params, node)
 	if err != nil {
 		panic(err)
 	}
@@ -2,16 +2,36 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"github.com/deso-smart/deso-backend/v3/scripts/tools/toolslib"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/semaphore"
+	"os"
 	"sort"
 	"time"
 )
 
+const (
+	minChunkBytes = uint64(1 << 10)
+	maxChunkBytes = uint64(1 << 30)
+)
+
 func main() {
+	flagChunkBytes := flag.Uint64("chunk-bytes", 8<<20,
+		"The maximum number of bytes to fetch per DBIteratePrefixKeys call. Smaller values use less memory;\n"+
+			"larger values are faster on machines with plenty of memory.")
+	flag.Parse()
+
+	if *flagChunkBytes < minChunkBytes || *flagChunkBytes > maxChunkBytes {
+		fmt.Fprintf(os.Stderr, "Invalid --chunk-bytes %d: must be between %d and %d\n",
+			*flagChunkBytes, minChunkBytes, maxChunkBytes)
+		os.Exit(1)
+	}
+	maxBytes := uint32(*flagChunkBytes)
+	fmt.Printf("Using chunk size: %d bytes\n", maxBytes)
+
 	dirSnap := "$HOME/data_dirs/hypersync/final_nodes/runner_node"
 	time.Sleep(1 * time.Millisecond)
 	dbSnap, err := toolslib.OpenDataDir(dirSnap)
@@ -27,7 +47,6 @@ func main() {
 	snap.CurrentEpochSnapshotMetadata.SnapshotBlockHeight = 114000
 	snap.Checksum.ResetChecksum()
 
-	maxBytes := uint32(8 << 20)
 	var prefixes [][]byte
 	for prefix, isState := range lib.StatePrefixes.StatePrefixesMap {
 		if !isState {
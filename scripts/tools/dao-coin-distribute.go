@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -409,7 +410,7 @@ func main() {
 		fmt.Printf("Sending %s Base Units to %s (pk %d of %d)\n", receiverAmountNanosDAOCoinBaseUnits.ToBig().String(),
 			receiverPublicKey, ii+1, len(distributionPublicKeys))
 		fmt.Printf("Public key has assocaited serial numbers: %v\n", publicKeyToAssociatedSerialNumbers[receiverPublicKey])
-		err := toolslib.TransferDAOCoin(distributorPubKey, distributorPrivKey, *flagParamDAOCoinPublicKey,
+		err := toolslib.TransferDAOCoin(context.Background(), distributorPubKey, distributorPrivKey, *flagParamDAOCoinPublicKey,
 			receiverPublicKey, receiverAmountNanosDAOCoinBaseUnits, params, desoNodeURL.String())
 		if err != nil {
 			fmt.Printf("main(): Ran into an error when trying to distribute for pk (%s): %s\n", receiverPublicKey, err.Error())
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -323,7 +324,7 @@ func main() {
 
 		// Send the message.
 		if !*flagParamTestrun {
-			err = toolslib.SendMessage(fromPubKey, fromPrivKey, toBTCECPubKey, message, params, desoNodeURL.String())
+			err = toolslib.SendMessage(context.Background(), fromPubKey, fromPrivKey, toBTCECPubKey, message, params, desoNodeURL.String())
 			if err != nil {
 				fmt.Printf("main(): Ran into an error when trying to message pk (%s): %s\n", toPubKey, err.Error())
 
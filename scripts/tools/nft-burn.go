@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -198,7 +199,7 @@ func main() {
 		// Make sure the NFT is no longer for sale.
 		fmt.Printf("Closing Sale For Serial Number #%d (#%d of #%d)\n",
 			int(burnableNFTRespone.SerialNumber), ii+1, len(burnableNFTEntryResponses))
-		err := toolslib.UpdateNFT(burnerPubKey, burnerPrivKey, nftPostHash, int(burnableNFTRespone.SerialNumber),
+		err := toolslib.UpdateNFT(context.Background(), burnerPubKey, burnerPrivKey, nftPostHash, int(burnableNFTRespone.SerialNumber),
 			false, int(burnableNFTRespone.MinBidAmountNanos), burnableNFTRespone.IsBuyNow,
 			burnableNFTRespone.BuyNowPriceNanos, params, desoNodeURL.String())
 		if err != nil {
@@ -230,7 +231,7 @@ func main() {
 		// Burn the NFT.
 		fmt.Printf("Burning Serial Number #%d (#%d of #%d)\n",
 			int(burnableNFTRespone.SerialNumber), ii+1, len(burnableNFTEntryResponses))
-		err = toolslib.BurnNFT(burnerPubKey, burnerPrivKey, nftPostHash, int(burnableNFTRespone.SerialNumber),
+		err = toolslib.BurnNFT(context.Background(), burnerPubKey, burnerPrivKey, nftPostHash, int(burnableNFTRespone.SerialNumber),
 			params, desoNodeURL.String())
 		if err != nil {
 			fmt.Printf("main(): Ran into an error when trying to burn NFT: %s\n", err.Error())
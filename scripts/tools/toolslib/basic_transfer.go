@@ -1,18 +1,15 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
 // _generateUnsignedSendDeSo...
-func _generateUnsignedSendDeSo(senderPubKey *btcec.PublicKey, recipientPubKey *btcec.PublicKey, amountNanos int64,
+func _generateUnsignedSendDeSo(ctx context.Context, senderPubKey *btcec.PublicKey, recipientPubKey *btcec.PublicKey, amountNanos int64,
 	params *lib.DeSoParams, node string) (*routes.SendDeSoResponse, error) {
 	endpoint := node + routes.RoutePathSendDeSo
 
@@ -23,44 +20,23 @@ func _generateUnsignedSendDeSo(senderPubKey *btcec.PublicKey, recipientPubKey *b
 		AmountNanos:                  amountNanos,
 		MinFeeRateNanosPerKB:         1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDeSo() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDeSo() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedSendDeSo(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	sendDeSoResponse := routes.SendDeSoResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&sendDeSoResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDeSo(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDeSo(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &sendDeSoResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedSendDeSo() failed to execute request")
 	}
 
 	return &sendDeSoResponse, nil
 }
 
 // SendDeSo...
-func SendDeSo(senderPubKey *btcec.PublicKey,
+func SendDeSo(ctx context.Context, senderPubKey *btcec.PublicKey,
 	senderPrivKey *btcec.PrivateKey,
 	recipientPubKey *btcec.PublicKey, amountNanos int64, params *lib.DeSoParams, node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedSendDeSo, err := _generateUnsignedSendDeSo(senderPubKey, recipientPubKey, amountNanos, params, node)
+	unsignedSendDeSo, err := _generateUnsignedSendDeSo(ctx, senderPubKey, recipientPubKey, amountNanos, params, node)
 	if err != nil {
 		return errors.Wrap(err, "SendDeSo() failed to call _generateSendDeSo()")
 	}
@@ -74,7 +50,7 @@ func SendDeSo(senderPubKey *btcec.PublicKey,
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "SendDeSo() failed to submit transaction")
 	}
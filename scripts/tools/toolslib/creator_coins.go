@@ -1,18 +1,15 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
 // _generateUnsignedCreatorCoinBuy...
-func _generateUnsignedCreatorCoinBuy(buyerPubKey *btcec.PublicKey, creatorPubKey *btcec.PublicKey,
+func _generateUnsignedCreatorCoinBuy(ctx context.Context, buyerPubKey *btcec.PublicKey, creatorPubKey *btcec.PublicKey,
 	amountNanos uint64, params *lib.DeSoParams, node string) (*routes.BuyOrSellCreatorCoinResponse, error) {
 	endpoint := node + routes.RoutePathBuyOrSellCreatorCoin
 
@@ -28,43 +25,22 @@ func _generateUnsignedCreatorCoinBuy(buyerPubKey *btcec.PublicKey, creatorPubKey
 		MinCreatorCoinExpectedNanos: 0,
 		MinFeeRateNanosPerKB:        1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedCreatorCoinBuy() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedCreatorCoinBuy() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedCreatorCoinBuy(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	buyCCResponse := routes.BuyOrSellCreatorCoinResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&buyCCResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedCreatorCoinBuy(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedCreatorCoinBuy(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &buyCCResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedCreatorCoinBuy() failed to execute request")
 	}
 
 	return &buyCCResponse, nil
 }
 
 // BuyCreator...
-func BuyCreator(buyerPubKey *btcec.PublicKey, buyerPrivKey *btcec.PrivateKey, creatorPubKey *btcec.PublicKey,
+func BuyCreator(ctx context.Context, buyerPubKey *btcec.PublicKey, buyerPrivKey *btcec.PrivateKey, creatorPubKey *btcec.PublicKey,
 	amountNanos uint64, params *lib.DeSoParams, node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedCCBuy, err := _generateUnsignedCreatorCoinBuy(buyerPubKey, creatorPubKey, amountNanos, params, node)
+	unsignedCCBuy, err := _generateUnsignedCreatorCoinBuy(ctx, buyerPubKey, creatorPubKey, amountNanos, params, node)
 	if err != nil {
 		return errors.Wrap(err, "BuyCreator() failed to call _generateUnsignedCreatorCoinBuy()")
 	}
@@ -78,7 +54,7 @@ func BuyCreator(buyerPubKey *btcec.PublicKey, buyerPrivKey *btcec.PrivateKey, cr
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "BuyCreator() failed to submit transaction")
 	}
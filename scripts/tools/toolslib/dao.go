@@ -1,18 +1,15 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/holiman/uint256"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
-func _generateUnsignedTransferDAOCoin(senderPubKey *btcec.PublicKey, profilePubKeyBase58Check string,
+func _generateUnsignedTransferDAOCoin(ctx context.Context, senderPubKey *btcec.PublicKey, profilePubKeyBase58Check string,
 	receiverPubKeyBase58Check string, daoCoinToTransferNanos uint256.Int, params *lib.DeSoParams,
 	node string) (*routes.TransferDAOCoinResponse, error) {
 	endpoint := node + routes.RoutePathTransferDAOCoin
@@ -25,43 +22,22 @@ func _generateUnsignedTransferDAOCoin(senderPubKey *btcec.PublicKey, profilePubK
 		DAOCoinToTransferNanos:                 daoCoinToTransferNanos,
 		MinFeeRateNanosPerKB:                   1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedTransferDAOCoin() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedTransferDAOCoin() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedTransferDAOCoin(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	transferDAOCoinResponse := routes.TransferDAOCoinResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&transferDAOCoinResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedTransferDAOCoin(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedTransferDAOCoin(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &transferDAOCoinResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedTransferDAOCoin() failed to execute request")
 	}
 
 	return &transferDAOCoinResponse, nil
 }
 
-func TransferDAOCoin(senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey, profilePubKeyBase58Check string,
+func TransferDAOCoin(ctx context.Context, senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey, profilePubKeyBase58Check string,
 	receiverPubKeyBase58Check string, daoCoinToTransferNanos uint256.Int, params *lib.DeSoParams,
 	node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedMessage, err := _generateUnsignedTransferDAOCoin(senderPubKey, profilePubKeyBase58Check,
+	unsignedMessage, err := _generateUnsignedTransferDAOCoin(ctx, senderPubKey, profilePubKeyBase58Check,
 		receiverPubKeyBase58Check, daoCoinToTransferNanos, params, node)
 	if err != nil {
 		return errors.Wrap(err, "TrasnferDAOCoin() failed to call _generateUnsignedTrasnferDAOCoin()")
@@ -76,9 +52,90 @@ func TransferDAOCoin(senderPubKey *btcec.PublicKey, senderPrivKey *btcec.Private
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "TrasnferDAOCoin() failed to submit transaction")
 	}
 	return nil
 }
+
+// _generateUnsignedCreateDAOCoinLimitOrder...
+func _generateUnsignedCreateDAOCoinLimitOrder(ctx context.Context, transactorPubKey *btcec.PublicKey, buyingCoinPubKeyBase58Check string,
+	sellingCoinPubKeyBase58Check string, price string, quantity string,
+	operationType routes.DAOCoinLimitOrderOperationTypeString, fillType routes.DAOCoinLimitOrderFillTypeString,
+	params *lib.DeSoParams, node string) (*routes.DAOCoinLimitOrderResponse, error) {
+	endpoint := node + routes.RoutePathCreateDAOCoinLimitOrder
+
+	// Setup request
+	payload := &routes.DAOCoinLimitOrderCreationRequest{
+		TransactorPublicKeyBase58Check:            lib.PkToString(transactorPubKey.SerializeCompressed(), params),
+		BuyingDAOCoinCreatorPublicKeyBase58Check:  buyingCoinPubKeyBase58Check,
+		SellingDAOCoinCreatorPublicKeyBase58Check: sellingCoinPubKeyBase58Check,
+		Price:                price,
+		Quantity:             quantity,
+		OperationType:        operationType,
+		FillType:             fillType,
+		MinFeeRateNanosPerKB: 1000,
+	}
+
+	// Execute request
+	daoCoinLimitOrderResponse := routes.DAOCoinLimitOrderResponse{}
+	if err := postJSON(ctx, endpoint, payload, &daoCoinLimitOrderResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedCreateDAOCoinLimitOrder() failed to execute request")
+	}
+
+	return &daoCoinLimitOrderResponse, nil
+}
+
+// CreateDAOCoinLimitOrder creates, signs, and submits a DAO coin limit order, and returns the new order's OrderID.
+// Coins are identified by their creator's public key, or routes.DESOCoinIdentifierString for $DESO. Price and
+// quantity are decimal strings in the same terms as the CreateDAOCoinLimitOrder endpoint, and are checked with the
+// same scaling helpers the node uses before the request is sent.
+func CreateDAOCoinLimitOrder(ctx context.Context, transactorPubKey *btcec.PublicKey, transactorPrivKey *btcec.PrivateKey,
+	buyingCoinPubKeyBase58Check string, sellingCoinPubKeyBase58Check string, price string, quantity string,
+	operationType routes.DAOCoinLimitOrderOperationTypeString, fillType routes.DAOCoinLimitOrderFillTypeString,
+	params *lib.DeSoParams, node string) (string, error) {
+
+	// Validate the price and quantity locally so that malformed values fail before hitting the node
+	libOperationType := lib.DAOCoinLimitOrderOperationTypeBID
+	switch operationType {
+	case routes.DAOCoinLimitOrderOperationTypeStringBID:
+	case routes.DAOCoinLimitOrderOperationTypeStringASK:
+		libOperationType = lib.DAOCoinLimitOrderOperationTypeASK
+	default:
+		return "", errors.Errorf("CreateDAOCoinLimitOrder() unknown operation type %v", operationType)
+	}
+	if _, err := routes.CalculateScaledExchangeRateFromPriceString(
+		buyingCoinPubKeyBase58Check, sellingCoinPubKeyBase58Check, price, libOperationType); err != nil {
+		return "", errors.Wrap(err, "CreateDAOCoinLimitOrder() invalid price")
+	}
+	if _, err := routes.CalculateQuantityToFillAsBaseUnits(
+		buyingCoinPubKeyBase58Check, sellingCoinPubKeyBase58Check, operationType, quantity); err != nil {
+		return "", errors.Wrap(err, "CreateDAOCoinLimitOrder() invalid quantity")
+	}
+
+	// Request an unsigned transaction from the node
+	unsignedOrder, err := _generateUnsignedCreateDAOCoinLimitOrder(ctx, transactorPubKey, buyingCoinPubKeyBase58Check,
+		sellingCoinPubKeyBase58Check, price, quantity, operationType, fillType, params, node)
+	if err != nil {
+		return "", errors.Wrap(err, "CreateDAOCoinLimitOrder() failed to call _generateUnsignedCreateDAOCoinLimitOrder()")
+	}
+	txn := unsignedOrder.Transaction
+
+	// Sign the transaction
+	signature, err := txn.Sign(transactorPrivKey)
+	if err != nil {
+		return "", errors.Wrap(err, "CreateDAOCoinLimitOrder() failed to sign transaction")
+	}
+	txn.Signature.SetSignature(signature)
+
+	// Submit the transaction to the node
+	err = SubmitTransactionToNode(ctx, txn, node)
+	if err != nil {
+		return "", errors.Wrap(err, "CreateDAOCoinLimitOrder() failed to submit transaction")
+	}
+
+	// An order's ID is the hash of the signed transaction that created it, so it differs from the TxnHashHex on the
+	// unsigned transaction returned by the node.
+	return txn.Hash().String(), nil
+}
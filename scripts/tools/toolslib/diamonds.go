@@ -1,19 +1,16 @@
 package toolslib
 
 import (
-	"bytes"
+	"context"
 	"encoding/hex"
-	"encoding/json"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
 // _generateUnsignedGiveDiamonds...
-func _generateUnsignedSendDiamonds(senderPubKey *btcec.PublicKey, postHashHex string, receiverPublicKeyBase58Check string,
+func _generateUnsignedSendDiamonds(ctx context.Context, senderPubKey *btcec.PublicKey, postHashHex string, receiverPublicKeyBase58Check string,
 	diamondLevel int64, params *lib.DeSoParams, node string) (*routes.SendDiamondsResponse, error) {
 	endpoint := node + routes.RoutePathSendDiamonds
 
@@ -25,32 +22,10 @@ func _generateUnsignedSendDiamonds(senderPubKey *btcec.PublicKey, postHashHex st
 	payload.DiamondLevel = diamondLevel
 	payload.MinFeeRateNanosPerKB = 1000
 
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDiamonds() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
-
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDiamonds() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedSendDiamonds(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	sendDiamondsResponse := routes.SendDiamondsResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&sendDiamondsResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDiamonds(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedSendDiamonds(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &sendDiamondsResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedSendDiamonds() failed to execute request")
 	}
 
 	// TODO: Figure out why Decode() loses ExtraData field
@@ -71,11 +46,11 @@ func _generateUnsignedSendDiamonds(senderPubKey *btcec.PublicKey, postHashHex st
 }
 
 // SendDiamonds
-func SendDiamonds(senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey, postHashHex string,
+func SendDiamonds(ctx context.Context, senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey, postHashHex string,
 	receiverPublicKeyBase58Check string, diamondLevel int64, params *lib.DeSoParams, node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedSendDiamonds, err := _generateUnsignedSendDiamonds(senderPubKey, postHashHex, receiverPublicKeyBase58Check,
+	unsignedSendDiamonds, err := _generateUnsignedSendDiamonds(ctx, senderPubKey, postHashHex, receiverPublicKeyBase58Check,
 		diamondLevel, params, node)
 	if err != nil {
 		return errors.Wrap(err, "SendDiamonds() failed to call _generateUnsignedSendDiamonds()")
@@ -90,7 +65,7 @@ func SendDiamonds(senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "SendDiamonds() failed to submit transaction")
 	}
@@ -0,0 +1,95 @@
+package toolslib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultHTTPTimeout is the timeout applied to each individual request made to a node.
+const DefaultHTTPTimeout = 30 * time.Second
+
+// MaxHTTPRetries is the number of times a request is retried after a connection error or a 5xx response.
+// Any other non-200 response is returned to the caller immediately.
+var MaxHTTPRetries = 3
+
+// HTTPRetryBaseBackoff is the delay before the first retry. The delay doubles after every subsequent attempt.
+var HTTPRetryBaseBackoff = 500 * time.Millisecond
+
+// httpClient is shared by every toolslib helper so that connections to the node are reused.
+var httpClient = &http.Client{Timeout: DefaultHTTPTimeout}
+
+// SetHTTPTimeout overrides the per-request timeout used when talking to a node.
+func SetHTTPTimeout(timeout time.Duration) {
+	httpClient.Timeout = timeout
+}
+
+// postJSON marshals payload, POSTs it to endpoint and decodes a 200 response into response. Connection
+// errors and 5xx responses are retried with exponential backoff up to MaxHTTPRetries times. Note that
+// a 5xx from the node doesn't guarantee the request had no effect, so resubmitting a transaction may
+// surface as a duplicate-transaction error on the retry.
+func postJSON(ctx context.Context, endpoint string, payload interface{}, response interface{}) error {
+	postBody, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "postJSON() failed to marshal struct")
+	}
+
+	backoff := HTTPRetryBaseBackoff
+	for attempt := 0; ; attempt++ {
+		bodyBytes, statusCode, err := _postJSONOnce(ctx, endpoint, postBody)
+		if err == nil && statusCode < 500 {
+			if statusCode != 200 {
+				return errors.Errorf("postJSON(): Received non 200 response code: "+
+					"Status Code: %v Body: %v", statusCode, string(bodyBytes))
+			}
+			if response == nil {
+				return nil
+			}
+			if err = json.Unmarshal(bodyBytes, response); err != nil {
+				return errors.Wrap(err, "postJSON(): failed decoding body")
+			}
+			return nil
+		}
+
+		// Don't keep retrying if we're out of attempts or the caller has given up.
+		if attempt >= MaxHTTPRetries || ctx.Err() != nil {
+			if err != nil {
+				return errors.Wrapf(err, "postJSON(): failed to execute request after %d attempts", attempt+1)
+			}
+			return errors.Errorf("postJSON(): Received non 200 response code after %d attempts: "+
+				"Status Code: %v Body: %v", attempt+1, statusCode, string(bodyBytes))
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "postJSON(): context done while waiting to retry")
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// _postJSONOnce makes a single POST request and returns the response body and status code.
+func _postJSONOnce(ctx context.Context, endpoint string, postBody []byte) (_body []byte, _statusCode int, _err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(postBody))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "_postJSONOnce() failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "_postJSONOnce() failed reading body")
+	}
+	return bodyBytes, resp.StatusCode, nil
+}
@@ -3,6 +3,7 @@ package toolslib
 import (
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-core/v3/lib"
+	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip39"
 )
 
@@ -10,7 +11,24 @@ import (
 func GenerateMnemonicPublicPrivate(params *lib.DeSoParams) (mnemonic string, pubKey *btcec.PublicKey, privKey *btcec.PrivateKey) {
 	entropy, _ := bip39.NewEntropy(128)
 	mnemonic, _ = bip39.NewMnemonic(entropy)
-	seedBytes, _ := bip39.NewSeedWithErrorChecking(mnemonic, "")
-	pubKey, privKey, _, _ = lib.ComputeKeysFromSeed(seedBytes, 0, params)
+	pubKey, privKey, _ = DeriveKeysFromMnemonic(mnemonic, 0, params)
 	return
 }
+
+// DeriveKeysFromMnemonic returns the keypair for the account at the given index of the mnemonic. Index 0 is
+// the account the DeSo wallet uses by default.
+func DeriveKeysFromMnemonic(mnemonic string, index uint32, params *lib.DeSoParams) (
+	_pubKey *btcec.PublicKey, _privKey *btcec.PrivateKey, _err error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, nil, errors.New("DeriveKeysFromMnemonic() invalid mnemonic")
+	}
+	seedBytes, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "DeriveKeysFromMnemonic() failed to generate seed")
+	}
+	pubKey, privKey, _, err := lib.ComputeKeysFromSeed(seedBytes, index, params)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "DeriveKeysFromMnemonic() failed to compute keys at index %d", index)
+	}
+	return pubKey, privKey, nil
+}
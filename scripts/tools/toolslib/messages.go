@@ -1,17 +1,14 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
-func _generateUnsignedMessage(senderPubKey *btcec.PublicKey, recipientPubKey *btcec.PublicKey, message string,
+func _generateUnsignedMessage(ctx context.Context, senderPubKey *btcec.PublicKey, recipientPubKey *btcec.PublicKey, message string,
 	params *lib.DeSoParams, node string) (*routes.SendMessageStatelessResponse, error) {
 	endpoint := node + routes.RoutePathSendMessageStateless
 
@@ -22,42 +19,21 @@ func _generateUnsignedMessage(senderPubKey *btcec.PublicKey, recipientPubKey *bt
 		MessageText:                   message,
 		MinFeeRateNanosPerKB:          1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedMessage() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedMessage() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedMessage(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	sendMessageResponse := routes.SendMessageStatelessResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&sendMessageResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedMessage(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedMessage(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &sendMessageResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedMessage() failed to execute request")
 	}
 
 	return &sendMessageResponse, nil
 }
 
-func SendMessage(senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey,
+func SendMessage(ctx context.Context, senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey,
 	recipientPubKey *btcec.PublicKey, message string, params *lib.DeSoParams, node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedMessage, err := _generateUnsignedMessage(senderPubKey, recipientPubKey, message, params, node)
+	unsignedMessage, err := _generateUnsignedMessage(ctx, senderPubKey, recipientPubKey, message, params, node)
 	if err != nil {
 		return errors.Wrap(err, "SendMessage() failed to call _generateSendDeSo()")
 	}
@@ -71,7 +47,7 @@ func SendMessage(senderPubKey *btcec.PublicKey, senderPrivKey *btcec.PrivateKey,
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "SendMessage() failed to submit transaction")
 	}
@@ -1,17 +1,14 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
-func _generateUnsignedUpdateNFT(updaterPubKey *btcec.PublicKey, nftPostHashHex string, serialNumber int,
+func _generateUnsignedUpdateNFT(ctx context.Context, updaterPubKey *btcec.PublicKey, nftPostHashHex string, serialNumber int,
 	isForSale bool, minBidAmountNanos int, isBuyNow bool, buyNowPriceNanos uint64, params *lib.DeSoParams,
 	node string) (*routes.UpdateNFTResponse, error) {
 	endpoint := node + routes.RoutePathUpdateNFT
@@ -26,37 +23,16 @@ func _generateUnsignedUpdateNFT(updaterPubKey *btcec.PublicKey, nftPostHashHex s
 		BuyNowPriceNanos:            buyNowPriceNanos,
 		MinFeeRateNanosPerKB:        1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateNFT() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateNFT() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedUpdateNFT(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	updateNFTResponse := routes.UpdateNFTResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&updateNFTResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateNFT(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateNFT(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &updateNFTResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedUpdateNFT() failed to execute request")
 	}
 
 	return &updateNFTResponse, nil
 }
-func _generateUnsignedBurnNFT(burnerPubKey *btcec.PublicKey, nftPostHashHex string, serialNumber int,
+func _generateUnsignedBurnNFT(ctx context.Context, burnerPubKey *btcec.PublicKey, nftPostHashHex string, serialNumber int,
 	params *lib.DeSoParams, node string) (*routes.BurnNFTResponse, error) {
 	endpoint := node + routes.RoutePathBurnNFT
 
@@ -67,43 +43,22 @@ func _generateUnsignedBurnNFT(burnerPubKey *btcec.PublicKey, nftPostHashHex stri
 		SerialNumber:                serialNumber,
 		MinFeeRateNanosPerKB:        1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBurnNFT() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBurnNFT() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedBurnNFT(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	burnNFTResponse := routes.BurnNFTResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&burnNFTResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBurnNFT(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBurnNFT(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &burnNFTResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedBurnNFT() failed to execute request")
 	}
 
 	return &burnNFTResponse, nil
 }
 
-func UpdateNFT(updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey, nftPostHashHex string, serialNumber int,
+func UpdateNFT(ctx context.Context, updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey, nftPostHashHex string, serialNumber int,
 	isForSale bool, minBidAmountNanos int, isBuyNow bool, buyNowPriceNanos uint64, params *lib.DeSoParams,
 	node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedMessage, err := _generateUnsignedUpdateNFT(updaterPubKey, nftPostHashHex, serialNumber, isForSale,
+	unsignedMessage, err := _generateUnsignedUpdateNFT(ctx, updaterPubKey, nftPostHashHex, serialNumber, isForSale,
 		minBidAmountNanos, isBuyNow, buyNowPriceNanos, params, node)
 	if err != nil {
 		return errors.Wrap(err, "UpdateNFT() failed to call _generateUnsignedBurnNFT()")
@@ -118,18 +73,18 @@ func UpdateNFT(updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey,
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "UpdateNFT() failed to submit transaction")
 	}
 	return nil
 }
 
-func BurnNFT(burnerPubKey *btcec.PublicKey, burnerPrivKey *btcec.PrivateKey,
+func BurnNFT(ctx context.Context, burnerPubKey *btcec.PublicKey, burnerPrivKey *btcec.PrivateKey,
 	nftPostHashHex string, serialNumber int, params *lib.DeSoParams, node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedMessage, err := _generateUnsignedBurnNFT(burnerPubKey, nftPostHashHex, serialNumber, params, node)
+	unsignedMessage, err := _generateUnsignedBurnNFT(ctx, burnerPubKey, nftPostHashHex, serialNumber, params, node)
 	if err != nil {
 		return errors.Wrap(err, "BurnNFT() failed to call _generateUnsignedBurnNFT()")
 	}
@@ -143,7 +98,7 @@ func BurnNFT(burnerPubKey *btcec.PublicKey, burnerPrivKey *btcec.PrivateKey,
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "BurnNFT() failed to submit transaction")
 	}
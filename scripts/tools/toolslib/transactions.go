@@ -1,44 +1,31 @@
 package toolslib
 
 import (
-	"bytes"
+	"context"
 	"encoding/hex"
-	"encoding/json"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
-// SubmitTransactionToNode...
-func SubmitTransactionToNode(txn *lib.MsgDeSoTxn, node string) error {
+// SubmitTransactionToNode submits a signed transaction to the node. Connection errors and 5xx responses are
+// retried, see postJSON. Cancelling ctx aborts any in-flight request or pending retry.
+func SubmitTransactionToNode(ctx context.Context, txn *lib.MsgDeSoTxn, node string) error {
 	endpoint := node + routes.RoutePathSubmitTransaction
 
 	// Encode the signed transaction to hex
 	txnBytes, err := txn.ToBytes(false)
 	if err != nil {
-		return errors.Wrap(err, "SubmitTransactionToServer() failed to convert txn to bytes")
+		return errors.Wrap(err, "SubmitTransactionToNode() failed to convert txn to bytes")
 	}
 	txnHex := hex.EncodeToString(txnBytes)
 
 	// Setup request
-	payload := &routes.SubmitTransactionRequest{txnHex}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return errors.Wrap(err, "SubmitTransactionToServer() failed to marshal struct")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
+	payload := &routes.SubmitTransactionRequest{TransactionHex: txnHex}
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return errors.Wrap(err, "SubmitTransactionToServer() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return errors.Errorf("_generateUnsignedUpdateProfile(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
+	if err := postJSON(ctx, endpoint, payload, nil); err != nil {
+		return errors.Wrap(err, "SubmitTransactionToNode() failed to execute request")
 	}
 	return nil
 }
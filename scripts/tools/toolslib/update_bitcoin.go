@@ -1,18 +1,15 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 )
 
 // _generateUnsignedBTCPriceUpdate...
-func _generateUnsignedBTCPriceUpdate(updaterPubKey *btcec.PublicKey, newUSDCentsPerBitcoin uint64,
+func _generateUnsignedBTCPriceUpdate(ctx context.Context, updaterPubKey *btcec.PublicKey, newUSDCentsPerBitcoin uint64,
 	params *lib.DeSoParams, node string) (*routes.UpdateGlobalParamsResponse, error) {
 	endpoint := node + routes.RoutePathUpdateGlobalParams
 
@@ -22,42 +19,21 @@ func _generateUnsignedBTCPriceUpdate(updaterPubKey *btcec.PublicKey, newUSDCents
 		USDCentsPerBitcoin:          int64(newUSDCentsPerBitcoin),
 		MinFeeRateNanosPerKB:        1000,
 	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBTCPriceUpdate() failed to marshal json")
-	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBTCPriceUpdate() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedBTCPriceUpdate(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	updateBitcoinUSDExchangeRateResponse := routes.UpdateGlobalParamsResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&updateBitcoinUSDExchangeRateResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBTCPriceUpdate(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedBTCPriceUpdate(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &updateBitcoinUSDExchangeRateResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedBTCPriceUpdate() failed to execute request")
 	}
 	return &updateBitcoinUSDExchangeRateResponse, nil
 }
 
 // UpdateBitcoinUSDExchangeRate...
-func UpdateBitcoinUSDExchangeRate(updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey, newUSDCentsPerBitcoin uint64,
+func UpdateBitcoinUSDExchangeRate(ctx context.Context, updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey, newUSDCentsPerBitcoin uint64,
 	params *lib.DeSoParams, node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedUpdateBitcoinUSDExchangeRate, err := _generateUnsignedBTCPriceUpdate(updaterPubKey, newUSDCentsPerBitcoin, params, node)
+	unsignedUpdateBitcoinUSDExchangeRate, err := _generateUnsignedBTCPriceUpdate(ctx, updaterPubKey, newUSDCentsPerBitcoin, params, node)
 	if err != nil {
 		return errors.Wrap(err, "UpdateBitcoinUSDExchangeRate() failed to generate unsigned transaction")
 	}
@@ -71,7 +47,7 @@ func UpdateBitcoinUSDExchangeRate(updaterPubKey *btcec.PublicKey, updaterPrivKey
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "UpdateBitcoinUSDExchangeRate() failed to submit transaction")
 	}
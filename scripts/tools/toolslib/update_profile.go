@@ -1,70 +1,78 @@
 package toolslib
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/deso-smart/deso-backend/v3/routes"
 	"github.com/deso-smart/deso-core/v3/lib"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
+)
+
+// UpdateProfileOptions holds the less commonly used UpdateProfile fields. Zero values fall back to the
+// defaults below, so a nil or empty options struct updates the updater's own, visible profile.
+type UpdateProfileOptions struct {
+	// ProfilePublicKeyBase58Check is the profile to update if it differs from the updater, e.g. when
+	// a paramUpdater is editing someone else's profile.
+	ProfilePublicKeyBase58Check string
+	// NewStakeMultipleBasisPoints defaults to DefaultUpdateProfileStakeMultipleBasisPoints.
+	NewStakeMultipleBasisPoints uint64
+	IsHidden                    bool
+	// MinFeeRateNanosPerKB defaults to DefaultUpdateProfileMinFeeRateNanosPerKB.
+	MinFeeRateNanosPerKB uint64
+}
+
+const (
+	DefaultUpdateProfileStakeMultipleBasisPoints = 12500
+	DefaultUpdateProfileMinFeeRateNanosPerKB     = 1000
 )
 
 // _generateUnsignedUpdateProfile...
-func _generateUnsignedUpdateProfile(updaterPubKey *btcec.PublicKey, newUsername string, newDescription string,
-	newProfilePic string, newCreatorBasisPoints uint64, params *lib.DeSoParams, node string) (*routes.UpdateProfileResponse, error) {
+func _generateUnsignedUpdateProfile(ctx context.Context, updaterPubKey *btcec.PublicKey, newUsername string, newDescription string,
+	newProfilePic string, newCreatorBasisPoints uint64, opts *UpdateProfileOptions, params *lib.DeSoParams,
+	node string) (*routes.UpdateProfileResponse, error) {
 	endpoint := node + routes.RoutePathUpdateProfile
 
+	// Fill in defaults for anything the caller left unset
+	resolvedOpts := UpdateProfileOptions{}
+	if opts != nil {
+		resolvedOpts = *opts
+	}
+	if resolvedOpts.NewStakeMultipleBasisPoints == 0 {
+		resolvedOpts.NewStakeMultipleBasisPoints = DefaultUpdateProfileStakeMultipleBasisPoints
+	}
+	if resolvedOpts.MinFeeRateNanosPerKB == 0 {
+		resolvedOpts.MinFeeRateNanosPerKB = DefaultUpdateProfileMinFeeRateNanosPerKB
+	}
+
 	// Setup request
 	payload := &routes.UpdateProfileRequest{
 		UpdaterPublicKeyBase58Check: lib.PkToString(updaterPubKey.SerializeCompressed(), params),
-		ProfilePublicKeyBase58Check: "",
+		ProfilePublicKeyBase58Check: resolvedOpts.ProfilePublicKeyBase58Check,
 		NewUsername:                 newUsername,
 		NewDescription:              newDescription,
 		NewProfilePic:               newProfilePic,
 		NewCreatorBasisPoints:       newCreatorBasisPoints,
-		NewStakeMultipleBasisPoints: 12500,
-		IsHidden:                    false,
-		MinFeeRateNanosPerKB:        1000,
-	}
-	postBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateProfile() failed to marshal json")
+		NewStakeMultipleBasisPoints: resolvedOpts.NewStakeMultipleBasisPoints,
+		IsHidden:                    resolvedOpts.IsHidden,
+		MinFeeRateNanosPerKB:        resolvedOpts.MinFeeRateNanosPerKB,
 	}
-	postBuffer := bytes.NewBuffer(postBody)
 
 	// Execute request
-	resp, err := http.Post(endpoint, "application/json", postBuffer)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateProfile() failed to execute request")
-	}
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		return nil, errors.Errorf("_generateUnsignedUpdateProfile(): Received non 200 response code: "+
-			"Status Code: %v Body: %v", resp.StatusCode, string(bodyBytes))
-	}
-
-	// Process response
 	updateProfileResponse := routes.UpdateProfileResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&updateProfileResponse)
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateProfile(): failed decoding body")
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, errors.Wrap(err, "_generateUnsignedUpdateProfile(): failed closing body")
+	if err := postJSON(ctx, endpoint, payload, &updateProfileResponse); err != nil {
+		return nil, errors.Wrap(err, "_generateUnsignedUpdateProfile() failed to execute request")
 	}
 	return &updateProfileResponse, nil
 }
 
-// UpdateProfile...
-func UpdateProfile(updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey, newUsername string, newDescription string,
-	newProfilePic string, newCreatorBasisPoints uint64, params *lib.DeSoParams, node string) error {
+// UpdateProfile... opts may be nil to use the default stake multiple and fee rate.
+func UpdateProfile(ctx context.Context, updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.PrivateKey, newUsername string, newDescription string,
+	newProfilePic string, newCreatorBasisPoints uint64, opts *UpdateProfileOptions, params *lib.DeSoParams,
+	node string) error {
 
 	// Request an unsigned transaction from the node
-	unsignedUpdateProfile, err := _generateUnsignedUpdateProfile(updaterPubKey, newUsername, newDescription,
-		newProfilePic, newCreatorBasisPoints, params, node)
+	unsignedUpdateProfile, err := _generateUnsignedUpdateProfile(ctx, updaterPubKey, newUsername, newDescription,
+		newProfilePic, newCreatorBasisPoints, opts, params, node)
 	if err != nil {
 		return errors.Wrap(err, "UpdateProfile() failed to generate unsigned transaction")
 	}
@@ -78,7 +86,7 @@ func UpdateProfile(updaterPubKey *btcec.PublicKey, updaterPrivKey *btcec.Private
 	txn.Signature.SetSignature(signature)
 
 	// Submit the transaction to the node
-	err = SubmitTransactionToNode(txn, node)
+	err = SubmitTransactionToNode(ctx, txn, node)
 	if err != nil {
 		return errors.Wrap(err, "UpdateProfile() failed to submit transaction")
 	}